@@ -51,6 +51,9 @@ func convertToEntityConfig(cfg *configs.Config) *entity.Config {
 			Port:     cfg.SMTP.Port,
 			Password: cfg.SMTP.Password,
 		},
+		JWT: entity.JWTConfig{
+			SecretKey: cfg.JWT.SecretKey,
+		},
 		// Add other fields as needed
 	}
 }
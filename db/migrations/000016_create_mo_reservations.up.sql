@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS mo_reservations (
+    id_reservation VARCHAR(255) PRIMARY KEY NOT NULL,
+    id_mo VARCHAR(255) NOT NULL,
+    id_material VARCHAR(255) NOT NULL,
+    qty_required FLOAT NOT NULL DEFAULT 0,
+    qty_reserved FLOAT NOT NULL DEFAULT 0,
+    status VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+COMMIT;
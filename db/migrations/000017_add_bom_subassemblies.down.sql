@@ -0,0 +1,4 @@
+
+ALTER TABLE mos DROP COLUMN IF EXISTS id_parent_mo;
+ALTER TABLE mo_reservations DROP COLUMN IF EXISTS id_product;
+ALTER TABLE bom_materials DROP COLUMN IF EXISTS id_product;
@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE bom_materials ADD COLUMN IF NOT EXISTS id_product VARCHAR(255) NULL;
+ALTER TABLE bom_materials ALTER COLUMN id_material DROP NOT NULL;
+
+ALTER TABLE mo_reservations ADD COLUMN IF NOT EXISTS id_product VARCHAR(255) NULL;
+ALTER TABLE mo_reservations ALTER COLUMN id_material DROP NOT NULL;
+
+ALTER TABLE mos ADD COLUMN IF NOT EXISTS id_parent_mo VARCHAR(255) NULL;
+
+COMMIT;
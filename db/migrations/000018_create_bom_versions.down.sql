@@ -0,0 +1,4 @@
+
+ALTER TABLE mos DROP COLUMN IF EXISTS id_bomversion;
+ALTER TABLE bom_materials DROP COLUMN IF EXISTS id_bomversion;
+DROP TABLE IF EXISTS bom_versions;
@@ -0,0 +1,33 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS bom_versions (
+    id_bomversion VARCHAR(255) PRIMARY KEY NOT NULL,
+    id_bom VARCHAR(255) NOT NULL,
+    version INT NOT NULL,
+    quantity VARCHAR(50) NOT NULL,
+    effective_from TIMESTAMPTZ NOT NULL DEFAULT now(),
+    effective_to TIMESTAMPTZ,
+    change_note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ,
+    UNIQUE (id_bom, version)
+);
+
+ALTER TABLE bom_materials ADD COLUMN IF NOT EXISTS id_bomversion VARCHAR(255) NULL;
+ALTER TABLE mos ADD COLUMN IF NOT EXISTS id_bomversion VARCHAR(255) NULL;
+
+-- Every existing BOM becomes version 1, effective since it was created
+INSERT INTO bom_versions (id_bomversion, id_bom, version, quantity, effective_from, change_note)
+SELECT 'BMV-' || LPAD(ROW_NUMBER() OVER (ORDER BY id_bom)::TEXT, 5, '0'), id_bom, 1, quantity, created_at, 'Initial version'
+FROM boms;
+
+UPDATE bom_materials SET id_bomversion = bom_versions.id_bomversion
+FROM bom_versions
+WHERE bom_versions.id_bom = bom_materials.id_bom AND bom_materials.id_bomversion IS NULL;
+
+UPDATE mos SET id_bomversion = bom_versions.id_bomversion
+FROM bom_versions
+WHERE bom_versions.id_bom = mos.id_bom AND mos.id_bomversion IS NULL;
+
+COMMIT;
@@ -0,0 +1,4 @@
+
+DROP TABLE IF EXISTS mrp_proposals;
+DROP TABLE IF EXISTS mrp_runs;
+DROP TABLE IF EXISTS reorder_rules;
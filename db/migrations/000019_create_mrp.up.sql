@@ -0,0 +1,47 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS reorder_rules (
+    id_reorderrule VARCHAR(255) PRIMARY KEY NOT NULL,
+    id_material VARCHAR(255),
+    id_product VARCHAR(255),
+    min_qty FLOAT NOT NULL DEFAULT 0,
+    max_qty FLOAT NOT NULL DEFAULT 0,
+    lead_time_days INT NOT NULL DEFAULT 0,
+    id_vendor VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS mrp_runs (
+    id_mrprun VARCHAR(255) PRIMARY KEY NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    warnings TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS mrp_proposals (
+    id_mrpproposal VARCHAR(255) PRIMARY KEY NOT NULL,
+    id_mrprun VARCHAR(255) NOT NULL,
+    type VARCHAR(10) NOT NULL,
+    id_product VARCHAR(255),
+    id_material VARCHAR(255),
+    id_bom VARCHAR(255),
+    id_vendor VARCHAR(255),
+    name VARCHAR(255),
+    unit VARCHAR(50),
+    quantity FLOAT NOT NULL DEFAULT 0,
+    order_date TIMESTAMPTZ NOT NULL,
+    due_date TIMESTAMPTZ NOT NULL,
+    late BOOLEAN NOT NULL DEFAULT false,
+    reason TEXT,
+    status VARCHAR(50) NOT NULL,
+    id_reference VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+COMMIT;
@@ -0,0 +1,5 @@
+
+DROP TABLE IF EXISTS work_order_logs;
+DROP TABLE IF EXISTS work_orders;
+DROP TABLE IF EXISTS routing_operations;
+DROP TABLE IF EXISTS work_centers;
@@ -0,0 +1,56 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS work_centers (
+    id_workcenter VARCHAR(255) PRIMARY KEY NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    capacity FLOAT NOT NULL DEFAULT 1,
+    hourly_cost FLOAT NOT NULL DEFAULT 0,
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS routing_operations (
+    id_operation VARCHAR(255) PRIMARY KEY NOT NULL,
+    id_bom VARCHAR(255) NOT NULL,
+    sequence INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    id_workcenter VARCHAR(255) NOT NULL,
+    duration_minutes FLOAT NOT NULL DEFAULT 0,
+    minutes_per_unit FLOAT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS work_orders (
+    id_workorder VARCHAR(255) PRIMARY KEY NOT NULL,
+    id_mo VARCHAR(255) NOT NULL,
+    id_operation VARCHAR(255) NOT NULL,
+    sequence INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    id_workcenter VARCHAR(255) NOT NULL,
+    planned_minutes FLOAT NOT NULL DEFAULT 0,
+    duration_minutes FLOAT NOT NULL DEFAULT 0,
+    status VARCHAR(50) NOT NULL,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS work_order_logs (
+    id_workorderlog VARCHAR(255) PRIMARY KEY NOT NULL,
+    id_workorder VARCHAR(255) NOT NULL,
+    operator VARCHAR(255) NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ,
+    minutes FLOAT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+COMMIT;
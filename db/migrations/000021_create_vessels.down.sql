@@ -0,0 +1,15 @@
+
+ALTER TABLE boms
+    DROP COLUMN IF EXISTS litres_per_unit,
+    DROP COLUMN IF EXISTS maceration_days;
+
+DROP INDEX IF EXISTS idx_schedules_mo;
+DROP INDEX IF EXISTS idx_schedules_vessel;
+
+ALTER TABLE schedules
+    DROP COLUMN IF EXISTS volume_litres,
+    DROP COLUMN IF EXISTS date_end,
+    DROP COLUMN IF EXISTS id_mo,
+    DROP COLUMN IF EXISTS id_vessel;
+
+DROP TABLE IF EXISTS vessels;
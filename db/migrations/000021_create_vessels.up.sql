@@ -0,0 +1,26 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS vessels (
+    id_vessel VARCHAR(20) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    capacity_litres NUMERIC(12,2) NOT NULL DEFAULT 0,
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+ALTER TABLE schedules
+    ADD COLUMN IF NOT EXISTS id_vessel VARCHAR(20) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS id_mo VARCHAR(20) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS date_end TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS volume_litres NUMERIC(12,2) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_schedules_vessel ON schedules (id_vessel, date_schedules);
+CREATE INDEX IF NOT EXISTS idx_schedules_mo ON schedules (id_mo);
+
+ALTER TABLE boms
+    ADD COLUMN IF NOT EXISTS maceration_days INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS litres_per_unit NUMERIC(12,4) NOT NULL DEFAULT 0;
+
+COMMIT;
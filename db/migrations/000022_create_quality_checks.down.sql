@@ -0,0 +1,7 @@
+
+ALTER TABLE products DROP COLUMN IF EXISTS quarantine_qty;
+ALTER TABLE materials DROP COLUMN IF EXISTS quarantine_qty;
+
+DROP TABLE IF EXISTS qc_results;
+DROP TABLE IF EXISTS qc_inspections;
+DROP TABLE IF EXISTS quality_checks;
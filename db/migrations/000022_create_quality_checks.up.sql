@@ -0,0 +1,60 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS quality_checks (
+    id_qualitycheck VARCHAR(20) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    trigger VARCHAR(20) NOT NULL,
+    id_material VARCHAR(20) NOT NULL DEFAULT '',
+    id_product VARCHAR(20) NOT NULL DEFAULT '',
+    measure_type VARCHAR(20) NOT NULL DEFAULT 'pass_fail',
+    unit VARCHAR(50) NOT NULL DEFAULT '',
+    min_value NUMERIC(14,4),
+    max_value NUMERIC(14,4),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS qc_inspections (
+    id_inspection VARCHAR(20) PRIMARY KEY,
+    trigger VARCHAR(20) NOT NULL,
+    id_rfq VARCHAR(20) NOT NULL DEFAULT '',
+    id_vendor VARCHAR(20) NOT NULL DEFAULT '',
+    id_mo VARCHAR(20) NOT NULL DEFAULT '',
+    id_material VARCHAR(20) NOT NULL DEFAULT '',
+    id_product VARCHAR(20) NOT NULL DEFAULT '',
+    item_name VARCHAR(255) NOT NULL DEFAULT '',
+    quantity NUMERIC(14,4) NOT NULL DEFAULT 0,
+    result VARCHAR(10) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    decided_by VARCHAR(255) NOT NULL DEFAULT '',
+    decided_at TIMESTAMPTZ,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_qc_inspections_status ON qc_inspections (status);
+CREATE INDEX IF NOT EXISTS idx_qc_inspections_vendor ON qc_inspections (id_vendor);
+
+CREATE TABLE IF NOT EXISTS qc_results (
+    id_qcresult VARCHAR(20) PRIMARY KEY,
+    id_inspection VARCHAR(20) NOT NULL REFERENCES qc_inspections (id_inspection) ON DELETE CASCADE,
+    id_qualitycheck VARCHAR(20) NOT NULL,
+    check_name VARCHAR(255) NOT NULL,
+    passed BOOLEAN NOT NULL,
+    measured_value NUMERIC(14,4),
+    unit VARCHAR(50) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    recorded_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+ALTER TABLE materials ADD COLUMN IF NOT EXISTS quarantine_qty NUMERIC(14,4) NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS quarantine_qty NUMERIC(14,4) NOT NULL DEFAULT 0;
+
+COMMIT;
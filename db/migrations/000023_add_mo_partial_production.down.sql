@@ -0,0 +1,8 @@
+
+DROP TABLE IF EXISTS mo_productions;
+
+ALTER TABLE mo_reservations DROP COLUMN IF EXISTS qty_consumed;
+
+ALTER TABLE mos
+    DROP COLUMN IF EXISTS id_backorder_of,
+    DROP COLUMN IF EXISTS qty_produced;
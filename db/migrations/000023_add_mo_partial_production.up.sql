@@ -0,0 +1,27 @@
+BEGIN;
+
+ALTER TABLE mos
+    ADD COLUMN IF NOT EXISTS qty_produced NUMERIC(14,4) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS id_backorder_of VARCHAR(20) NOT NULL DEFAULT '';
+
+UPDATE mos SET qty_produced = qtytoproduce::NUMERIC
+WHERE status = 'done' AND qtytoproduce ~ '^[0-9]+(\.[0-9]+)?$';
+
+ALTER TABLE mo_reservations ADD COLUMN IF NOT EXISTS qty_consumed NUMERIC(14,4) NOT NULL DEFAULT 0;
+
+UPDATE mo_reservations SET qty_consumed = qty_reserved WHERE status = 'consumed';
+
+CREATE TABLE IF NOT EXISTS mo_productions (
+    id_moproduction VARCHAR(20) PRIMARY KEY,
+    id_mo VARCHAR(20) NOT NULL,
+    quantity NUMERIC(14,4) NOT NULL,
+    produced_by VARCHAR(255) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_mo_productions_mo ON mo_productions (id_mo);
+
+COMMIT;
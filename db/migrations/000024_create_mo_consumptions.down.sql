@@ -0,0 +1,2 @@
+
+DROP TABLE IF EXISTS mo_consumptions;
@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS mo_consumptions (
+    id_moconsumption VARCHAR(20) PRIMARY KEY,
+    id_mo VARCHAR(20) NOT NULL,
+    id_moproduction VARCHAR(20) NOT NULL DEFAULT '',
+    id_material VARCHAR(20) NOT NULL DEFAULT '',
+    id_product VARCHAR(20) NOT NULL DEFAULT '',
+    component_name VARCHAR(255) NOT NULL DEFAULT '',
+    unit VARCHAR(50) NOT NULL DEFAULT '',
+    qty_theoretical NUMERIC(14,4) NOT NULL DEFAULT 0,
+    qty_actual NUMERIC(14,4) NOT NULL DEFAULT 0,
+    unit_cost NUMERIC(14,4) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_mo_consumptions_mo ON mo_consumptions (id_mo);
+CREATE INDEX IF NOT EXISTS idx_mo_consumptions_created ON mo_consumptions (created_at);
+
+COMMIT;
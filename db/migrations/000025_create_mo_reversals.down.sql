@@ -0,0 +1,3 @@
+
+DROP TABLE IF EXISTS mo_reversal_lines;
+DROP TABLE IF EXISTS mo_reversals;
@@ -0,0 +1,29 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS mo_reversals (
+    id_moreversal VARCHAR(20) PRIMARY KEY,
+    id_mo VARCHAR(20) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    quantity NUMERIC(14,4) NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL,
+    performed_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_mo_reversals_mo ON mo_reversals (id_mo);
+
+CREATE TABLE IF NOT EXISTS mo_reversal_lines (
+    id_moreversalline VARCHAR(20) PRIMARY KEY,
+    id_moreversal VARCHAR(20) NOT NULL REFERENCES mo_reversals (id_moreversal) ON DELETE CASCADE,
+    id_material VARCHAR(20) NOT NULL DEFAULT '',
+    id_product VARCHAR(20) NOT NULL DEFAULT '',
+    component_name VARCHAR(255) NOT NULL DEFAULT '',
+    quantity NUMERIC(14,4) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+COMMIT;
@@ -0,0 +1,3 @@
+
+DROP TABLE IF EXISTS mo_batch_records;
+ALTER TABLE mo_consumptions DROP COLUMN IF EXISTS lot_number;
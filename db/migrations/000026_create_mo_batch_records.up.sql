@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE mo_consumptions ADD COLUMN IF NOT EXISTS lot_number VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS mo_batch_records (
+    id_mo VARCHAR(20) PRIMARY KEY,
+    record TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+COMMIT;
@@ -0,0 +1,3 @@
+
+DROP TABLE IF EXISTS compliance_limits;
+DROP TABLE IF EXISTS material_constituents;
@@ -0,0 +1,30 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS material_constituents (
+    id_constituent VARCHAR(20) PRIMARY KEY,
+    id_material VARCHAR(20) NOT NULL,
+    substance VARCHAR(255) NOT NULL,
+    cas_number VARCHAR(20) NOT NULL DEFAULT '',
+    percentage NUMERIC(8,4) NOT NULL,
+    allergen BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_material_constituents_material ON material_constituents (id_material);
+
+CREATE TABLE IF NOT EXISTS compliance_limits (
+    id_compliancelimit VARCHAR(20) PRIMARY KEY,
+    type VARCHAR(20) NOT NULL,
+    substance VARCHAR(255) NOT NULL DEFAULT '',
+    cas_number VARCHAR(20) NOT NULL DEFAULT '',
+    product_category VARCHAR(255) NOT NULL DEFAULT '',
+    max_percent NUMERIC(10,6) NOT NULL,
+    source VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+COMMIT;
@@ -0,0 +1,13 @@
+
+-- Quantities written since the up migration are encrypted ("enc:" prefix)
+-- with the application key, which the database does not have, so they
+-- cannot be turned back into plain numbers here. Refuse to roll back rather
+-- than truncate them into VARCHAR(50).
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM bom_materials WHERE quantity LIKE 'enc:%') THEN
+        RAISE EXCEPTION 'bom_materials holds encrypted quantities; migration 000028 is irreversible';
+    END IF;
+END $$;
+
+ALTER TABLE bom_materials ALTER COLUMN quantity TYPE VARCHAR(50);
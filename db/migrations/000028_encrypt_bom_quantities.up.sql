@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Line quantities are stored encrypted by the application, which needs more
+-- room than the plain number did. Existing lines are encrypted on startup.
+ALTER TABLE bom_materials ALTER COLUMN quantity TYPE TEXT;
+
+COMMIT;
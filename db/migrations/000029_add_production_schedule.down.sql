@@ -0,0 +1,12 @@
+
+DROP INDEX IF EXISTS idx_work_orders_planned;
+DROP INDEX IF EXISTS idx_mos_planned;
+
+ALTER TABLE work_orders
+    DROP COLUMN IF EXISTS planned_end,
+    DROP COLUMN IF EXISTS planned_start;
+
+ALTER TABLE mos
+    DROP COLUMN IF EXISTS required_date,
+    DROP COLUMN IF EXISTS planned_end,
+    DROP COLUMN IF EXISTS planned_start;
@@ -0,0 +1,15 @@
+BEGIN;
+
+ALTER TABLE mos
+    ADD COLUMN IF NOT EXISTS planned_start TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS planned_end TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS required_date TIMESTAMPTZ;
+
+ALTER TABLE work_orders
+    ADD COLUMN IF NOT EXISTS planned_start TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS planned_end TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_mos_planned ON mos (planned_start, planned_end);
+CREATE INDEX IF NOT EXISTS idx_work_orders_planned ON work_orders (id_workcenter, planned_start, planned_end);
+
+COMMIT;
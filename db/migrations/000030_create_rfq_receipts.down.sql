@@ -0,0 +1,10 @@
+
+DROP TABLE IF EXISTS rfq_receipt_lines;
+DROP TABLE IF EXISTS rfq_receipts;
+
+ALTER TABLE rfqs_products
+    DROP COLUMN IF EXISTS qty_cancelled,
+    DROP COLUMN IF EXISTS qty_received;
+
+UPDATE rfqs SET status = 'Purchase Order' WHERE status = 'Partially Received';
+UPDATE rfqs SET status = 'Recived' WHERE status = 'Received';
@@ -0,0 +1,42 @@
+BEGIN;
+
+UPDATE rfqs SET status = 'Received' WHERE status = 'Recived';
+
+ALTER TABLE rfqs_products
+    ADD COLUMN IF NOT EXISTS qty_received NUMERIC(14,4) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS qty_cancelled NUMERIC(14,4) NOT NULL DEFAULT 0;
+
+-- RFQs received before receipts existed arrived in full.
+UPDATE rfqs_products SET qty_received = quantity::NUMERIC
+WHERE quantity ~ '^[0-9]+(\.[0-9]+)?$'
+  AND id_rfq IN (SELECT id_rfq FROM rfqs WHERE status IN ('Received', 'Billed', 'Done'));
+
+CREATE TABLE IF NOT EXISTS rfq_receipts (
+    id_receipt VARCHAR(20) PRIMARY KEY,
+    id_rfq VARCHAR(255) NOT NULL,
+    id_vendor VARCHAR(255) NOT NULL DEFAULT '',
+    type VARCHAR(20) NOT NULL,
+    received_by VARCHAR(255) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_rfq_receipts_rfq ON rfq_receipts (id_rfq);
+
+CREATE TABLE IF NOT EXISTS rfq_receipt_lines (
+    id_receiptline VARCHAR(20) PRIMARY KEY,
+    id_receipt VARCHAR(20) NOT NULL REFERENCES rfq_receipts (id_receipt) ON DELETE CASCADE,
+    id_rfqproduct VARCHAR(255) NOT NULL,
+    id_product VARCHAR(255) NOT NULL,
+    productname VARCHAR(255) NOT NULL DEFAULT '',
+    quantity NUMERIC(14,4) NOT NULL,
+    lot_number VARCHAR(100) NOT NULL DEFAULT '',
+    id_inspection VARCHAR(20) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+COMMIT;
@@ -0,0 +1,4 @@
+
+DROP TABLE IF EXISTS bill_match_tolerances;
+DROP TABLE IF EXISTS bill_lines;
+ALTER TABLE billrfqs DROP COLUMN IF EXISTS match_status, DROP COLUMN IF EXISTS status;
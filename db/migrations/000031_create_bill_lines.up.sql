@@ -0,0 +1,49 @@
+BEGIN;
+
+ALTER TABLE billrfqs
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'Draft',
+    ADD COLUMN IF NOT EXISTS match_status VARCHAR(20) NOT NULL DEFAULT 'unmatched';
+
+-- Bills entered before matching existed have no lines to match against and
+-- were already passed for payment.
+UPDATE billrfqs SET status = 'Approved';
+
+CREATE TABLE IF NOT EXISTS bill_lines (
+    id_billline VARCHAR(20) PRIMARY KEY,
+    id_bill VARCHAR(255) NOT NULL REFERENCES billrfqs (id_bill) ON DELETE CASCADE,
+    id_rfq VARCHAR(255) NOT NULL,
+    id_rfqproduct VARCHAR(255) NOT NULL,
+    id_product VARCHAR(255) NOT NULL DEFAULT '',
+    productname VARCHAR(255) NOT NULL DEFAULT '',
+    quantity NUMERIC(14,4) NOT NULL,
+    unit_price NUMERIC(14,4) NOT NULL DEFAULT 0,
+    match_status VARCHAR(20) NOT NULL DEFAULT 'unmatched',
+    match_note TEXT NOT NULL DEFAULT '',
+    resolved_by VARCHAR(255) NOT NULL DEFAULT '',
+    resolution_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_bill_lines_bill ON bill_lines (id_bill);
+CREATE INDEX IF NOT EXISTS idx_bill_lines_rfqproduct ON bill_lines (id_rfqproduct);
+
+CREATE TABLE IF NOT EXISTS bill_match_tolerances (
+    id_tolerance VARCHAR(20) PRIMARY KEY,
+    id_vendor VARCHAR(255) NOT NULL DEFAULT '',
+    qty_percent NUMERIC(6,2) NOT NULL DEFAULT 0,
+    price_percent NUMERIC(6,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bill_match_tolerances_vendor
+    ON bill_match_tolerances (id_vendor) WHERE deleted_at IS NULL;
+
+INSERT INTO bill_match_tolerances (id_tolerance, id_vendor, qty_percent, price_percent)
+VALUES ('TOL-00001', '', 0, 1)
+ON CONFLICT DO NOTHING;
+
+COMMIT;
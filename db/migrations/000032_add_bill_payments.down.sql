@@ -0,0 +1,16 @@
+
+DROP TABLE IF EXISTS bill_payments;
+DROP INDEX IF EXISTS idx_billrfqs_status;
+
+UPDATE billrfqs SET status = 'Approved' WHERE status IN ('Needs Review', 'Posted', 'Partially Paid', 'Paid');
+
+ALTER TABLE billrfqs
+    DROP COLUMN IF EXISTS due_date,
+    DROP COLUMN IF EXISTS upfront_due_date,
+    DROP COLUMN IF EXISTS upfront_amount,
+    DROP COLUMN IF EXISTS amount_paid,
+    DROP COLUMN IF EXISTS total;
+
+ALTER TABLE vendors
+    DROP COLUMN IF EXISTS upfront_percent,
+    DROP COLUMN IF EXISTS payment_term_days;
@@ -0,0 +1,41 @@
+BEGIN;
+
+ALTER TABLE vendors
+    ADD COLUMN IF NOT EXISTS payment_term_days INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS upfront_percent NUMERIC(5,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE billrfqs
+    ADD COLUMN IF NOT EXISTS total NUMERIC(14,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS amount_paid NUMERIC(14,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS upfront_amount NUMERIC(14,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS upfront_due_date TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS due_date TIMESTAMPTZ;
+
+-- Bills from before bill lines carry no amount and no payment is known for
+-- them. They are held for someone to enter their lines and post them again.
+UPDATE billrfqs SET status = 'Needs Review'
+WHERE status = 'Approved' AND id_bill NOT IN (SELECT id_bill FROM bill_lines);
+
+UPDATE billrfqs b SET status = 'Posted',
+    total = (SELECT COALESCE(ROUND(SUM(l.quantity * l.unit_price), 2), 0) FROM bill_lines l WHERE l.id_bill = b.id_bill AND l.deleted_at IS NULL),
+    due_date = b.bill_date,
+    upfront_due_date = b.bill_date
+WHERE status = 'Approved';
+
+CREATE INDEX IF NOT EXISTS idx_billrfqs_status ON billrfqs (status);
+
+CREATE TABLE IF NOT EXISTS bill_payments (
+    id_payment VARCHAR(20) PRIMARY KEY,
+    id_bill VARCHAR(255) NOT NULL REFERENCES billrfqs (id_bill) ON DELETE CASCADE,
+    amount NUMERIC(14,2) NOT NULL,
+    payment_date TIMESTAMPTZ NOT NULL,
+    method VARCHAR(50) NOT NULL DEFAULT '',
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_bill_payments_bill ON bill_payments (id_bill);
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS vendor_prices (
+    id_vendorprice VARCHAR(20) PRIMARY KEY,
+    id_vendor VARCHAR(255) NOT NULL,
+    id_item VARCHAR(255) NOT NULL,
+    item_name VARCHAR(255) NOT NULL DEFAULT '',
+    min_qty NUMERIC(14,4) NOT NULL DEFAULT 0,
+    unit_price NUMERIC(14,4) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    valid_from TIMESTAMPTZ,
+    valid_to TIMESTAMPTZ,
+    lead_time_days INT NOT NULL DEFAULT 0,
+    source VARCHAR(20) NOT NULL DEFAULT 'manual',
+    id_rfq VARCHAR(255) NOT NULL DEFAULT '',
+    superseded_by VARCHAR(20) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_vendor_prices_item ON vendor_prices (id_item, id_vendor);
+CREATE INDEX IF NOT EXISTS idx_vendor_prices_vendor ON vendor_prices (id_vendor);
+
+COMMIT;
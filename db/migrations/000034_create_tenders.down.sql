@@ -0,0 +1,7 @@
+
+DROP TABLE IF EXISTS tender_bids;
+DROP TABLE IF EXISTS tender_vendors;
+DROP TABLE IF EXISTS tender_lines;
+DROP TABLE IF EXISTS tenders;
+
+ALTER TABLE rfqs DROP COLUMN IF EXISTS id_tender;
@@ -0,0 +1,56 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS tenders (
+    id_tender VARCHAR(20) PRIMARY KEY,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    order_date VARCHAR(50) NOT NULL DEFAULT '',
+    deadline TIMESTAMPTZ,
+    status VARCHAR(20) NOT NULL DEFAULT 'Open',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS tender_lines (
+    id_tenderline VARCHAR(20) PRIMARY KEY,
+    id_tender VARCHAR(20) NOT NULL REFERENCES tenders (id_tender) ON DELETE CASCADE,
+    id_item VARCHAR(255) NOT NULL,
+    item_name VARCHAR(255) NOT NULL DEFAULT '',
+    quantity NUMERIC(14,4) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS tender_vendors (
+    id_tendervendor VARCHAR(20) PRIMARY KEY,
+    id_tender VARCHAR(20) NOT NULL REFERENCES tenders (id_tender) ON DELETE CASCADE,
+    id_vendor VARCHAR(255) NOT NULL,
+    id_rfq VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'invited',
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS tender_bids (
+    id_bid VARCHAR(20) PRIMARY KEY,
+    id_tender VARCHAR(20) NOT NULL REFERENCES tenders (id_tender) ON DELETE CASCADE,
+    id_tenderline VARCHAR(20) NOT NULL REFERENCES tender_lines (id_tenderline) ON DELETE CASCADE,
+    id_vendor VARCHAR(255) NOT NULL,
+    unit_price NUMERIC(14,4) NOT NULL,
+    lead_time_days INT NOT NULL DEFAULT 0,
+    note TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'submitted',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+ALTER TABLE rfqs ADD COLUMN IF NOT EXISTS id_tender VARCHAR(20) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_tender_vendors_tender ON tender_vendors (id_tender);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tender_bids_line_vendor ON tender_bids (id_tenderline, id_vendor) WHERE deleted_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,3 @@
+
+DROP TABLE IF EXISTS rfq_approvals;
+DROP TABLE IF EXISTS po_approval_rules;
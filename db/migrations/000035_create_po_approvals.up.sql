@@ -0,0 +1,36 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS po_approval_rules (
+    id_approvalrule VARCHAR(20) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    min_amount NUMERIC(16,2) NOT NULL DEFAULT 0,
+    id_vendor VARCHAR(255) NOT NULL DEFAULT '',
+    category VARCHAR(255) NOT NULL DEFAULT '',
+    approver_name VARCHAR(255) NOT NULL,
+    approver_email VARCHAR(255) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS rfq_approvals (
+    id_rfqapproval VARCHAR(20) PRIMARY KEY,
+    id_rfq VARCHAR(255) NOT NULL,
+    id_approvalrule VARCHAR(20) NOT NULL,
+    rule_name VARCHAR(255) NOT NULL DEFAULT '',
+    approver_name VARCHAR(255) NOT NULL DEFAULT '',
+    approver_email VARCHAR(255) NOT NULL DEFAULT '',
+    amount NUMERIC(16,2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    decided_by VARCHAR(255) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    decided_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_rfq_approvals_rfq ON rfq_approvals (id_rfq);
+
+COMMIT;
@@ -0,0 +1,4 @@
+
+DROP INDEX IF EXISTS idx_rfqs_vendor_ordered;
+ALTER TABLE rfqs_products DROP COLUMN IF EXISTS promised_date;
+ALTER TABLE rfqs DROP COLUMN IF EXISTS ordered_at;
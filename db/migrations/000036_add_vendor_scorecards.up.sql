@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE rfqs ADD COLUMN IF NOT EXISTS ordered_at TIMESTAMPTZ;
+ALTER TABLE rfqs_products ADD COLUMN IF NOT EXISTS promised_date TIMESTAMPTZ;
+
+-- Orders placed before the column existed are dated from their creation,
+-- the closest record we have of when they were ordered.
+UPDATE rfqs SET ordered_at = created_at
+WHERE ordered_at IS NULL AND status NOT IN ('RFQ', 'Cancelled');
+
+CREATE INDEX IF NOT EXISTS idx_rfqs_vendor_ordered ON rfqs (id_vendor, ordered_at);
+
+COMMIT;
@@ -0,0 +1,6 @@
+
+UPDATE rfqs_products SET id_product = id_material WHERE line_type = 'material';
+DROP INDEX IF EXISTS idx_rfqs_products_material;
+ALTER TABLE rfqs_products DROP COLUMN IF EXISTS unit;
+ALTER TABLE rfqs_products DROP COLUMN IF EXISTS id_material;
+ALTER TABLE rfqs_products DROP COLUMN IF EXISTS line_type;
@@ -0,0 +1,22 @@
+BEGIN;
+
+ALTER TABLE rfqs_products ADD COLUMN IF NOT EXISTS line_type VARCHAR(10) NOT NULL DEFAULT 'product';
+ALTER TABLE rfqs_products ADD COLUMN IF NOT EXISTS id_material VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE rfqs_products ADD COLUMN IF NOT EXISTS unit VARCHAR(50) NOT NULL DEFAULT '';
+
+-- Lines used to keep whatever they bought in id_product. Those pointing at a
+-- material become material lines and take the material's unit.
+UPDATE rfqs_products rp
+SET line_type = 'material',
+    id_material = rp.id_product,
+    id_product = '',
+    unit = m.unit,
+    productname = CASE WHEN rp.productname = '' THEN m.materialname ELSE rp.productname END
+FROM materials m
+WHERE m.id_material = rp.id_product;
+
+UPDATE rfqs_products SET unit = 'pcs' WHERE line_type = 'product' AND unit = '';
+
+CREATE INDEX IF NOT EXISTS idx_rfqs_products_material ON rfqs_products (id_material);
+
+COMMIT;
@@ -0,0 +1,18 @@
+
+ALTER TABLE quotations DROP COLUMN IF EXISTS amount_total;
+ALTER TABLE quotations DROP COLUMN IF EXISTS amount_tax;
+ALTER TABLE quotations DROP COLUMN IF EXISTS amount_untaxed;
+ALTER TABLE quotations DROP COLUMN IF EXISTS tax_rounding;
+ALTER TABLE rfqs DROP COLUMN IF EXISTS amount_total;
+ALTER TABLE rfqs DROP COLUMN IF EXISTS amount_tax;
+ALTER TABLE rfqs DROP COLUMN IF EXISTS amount_untaxed;
+ALTER TABLE rfqs DROP COLUMN IF EXISTS tax_rounding;
+ALTER TABLE quotations_products DROP COLUMN IF EXISTS total;
+ALTER TABLE quotations_products DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE quotations_products DROP COLUMN IF EXISTS id_tax;
+ALTER TABLE quotations_products DROP COLUMN IF EXISTS discount;
+ALTER TABLE rfqs_products DROP COLUMN IF EXISTS total;
+ALTER TABLE rfqs_products DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE rfqs_products DROP COLUMN IF EXISTS id_tax;
+ALTER TABLE rfqs_products DROP COLUMN IF EXISTS discount;
+DROP TABLE IF EXISTS taxes;
@@ -0,0 +1,69 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS taxes (
+    id_tax VARCHAR(20) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    rate NUMERIC(8,4) NOT NULL DEFAULT 0,
+    price_included BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+ALTER TABLE rfqs_products ADD COLUMN IF NOT EXISTS discount NUMERIC(8,4) NOT NULL DEFAULT 0;
+ALTER TABLE rfqs_products ADD COLUMN IF NOT EXISTS id_tax VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE rfqs_products ADD COLUMN IF NOT EXISTS tax_amount NUMERIC(16,2) NOT NULL DEFAULT 0;
+ALTER TABLE rfqs_products ADD COLUMN IF NOT EXISTS total NUMERIC(16,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE quotations_products ADD COLUMN IF NOT EXISTS discount NUMERIC(8,4) NOT NULL DEFAULT 0;
+ALTER TABLE quotations_products ADD COLUMN IF NOT EXISTS id_tax VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE quotations_products ADD COLUMN IF NOT EXISTS tax_amount NUMERIC(16,2) NOT NULL DEFAULT 0;
+ALTER TABLE quotations_products ADD COLUMN IF NOT EXISTS total NUMERIC(16,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE rfqs ADD COLUMN IF NOT EXISTS tax_rounding VARCHAR(10) NOT NULL DEFAULT 'line';
+ALTER TABLE rfqs ADD COLUMN IF NOT EXISTS amount_untaxed NUMERIC(16,2) NOT NULL DEFAULT 0;
+ALTER TABLE rfqs ADD COLUMN IF NOT EXISTS amount_tax NUMERIC(16,2) NOT NULL DEFAULT 0;
+ALTER TABLE rfqs ADD COLUMN IF NOT EXISTS amount_total NUMERIC(16,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE quotations ADD COLUMN IF NOT EXISTS tax_rounding VARCHAR(10) NOT NULL DEFAULT 'line';
+ALTER TABLE quotations ADD COLUMN IF NOT EXISTS amount_untaxed NUMERIC(16,2) NOT NULL DEFAULT 0;
+ALTER TABLE quotations ADD COLUMN IF NOT EXISTS amount_tax NUMERIC(16,2) NOT NULL DEFAULT 0;
+ALTER TABLE quotations ADD COLUMN IF NOT EXISTS amount_total NUMERIC(16,2) NOT NULL DEFAULT 0;
+
+-- Subtotals used to be whatever the client sent. Recompute them from
+-- quantity and unit price, reading the old tax column as a percentage added
+-- on top; lines whose figures are not numbers are left at zero.
+UPDATE rfqs_products
+SET subtotal = ROUND(quantity::NUMERIC * unitprice::NUMERIC, 2)::TEXT,
+    tax_amount = ROUND(quantity::NUMERIC * unitprice::NUMERIC
+        * CASE WHEN tax ~ '^[0-9]+(\.[0-9]+)?$' THEN tax::NUMERIC ELSE 0 END / 100, 2)
+WHERE quantity ~ '^[0-9]+(\.[0-9]+)?$' AND unitprice ~ '^[0-9]+(\.[0-9]+)?$';
+UPDATE rfqs_products
+SET total = CASE WHEN subtotal ~ '^[0-9]+(\.[0-9]+)?$' THEN subtotal::NUMERIC ELSE 0 END + tax_amount;
+
+UPDATE quotations_products
+SET subtotal = ROUND(quantity::NUMERIC * unitprice::NUMERIC, 2)::TEXT,
+    tax_amount = ROUND(quantity::NUMERIC * unitprice::NUMERIC
+        * CASE WHEN tax ~ '^[0-9]+(\.[0-9]+)?$' THEN tax::NUMERIC ELSE 0 END / 100, 2)
+WHERE quantity ~ '^[0-9]+(\.[0-9]+)?$' AND unitprice ~ '^[0-9]+(\.[0-9]+)?$';
+UPDATE quotations_products
+SET total = CASE WHEN subtotal ~ '^[0-9]+(\.[0-9]+)?$' THEN subtotal::NUMERIC ELSE 0 END + tax_amount;
+
+UPDATE rfqs r
+SET amount_untaxed = t.total - t.tax, amount_tax = t.tax, amount_total = t.total
+FROM (
+    SELECT id_rfq, SUM(total) AS total, SUM(tax_amount) AS tax
+    FROM rfqs_products WHERE deleted_at IS NULL GROUP BY id_rfq
+) t
+WHERE t.id_rfq = r.id_rfq;
+
+UPDATE quotations q
+SET amount_untaxed = t.total - t.tax, amount_tax = t.tax, amount_total = t.total
+FROM (
+    SELECT id_quotation, SUM(total) AS total, SUM(tax_amount) AS tax
+    FROM quotations_products WHERE deleted_at IS NULL GROUP BY id_quotation
+) t
+WHERE t.id_quotation = q.id_quotation;
+
+COMMIT;
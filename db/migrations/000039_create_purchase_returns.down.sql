@@ -0,0 +1,7 @@
+
+ALTER TABLE bill_payments
+    DROP COLUMN IF EXISTS id_creditnote;
+
+DROP TABLE IF EXISTS vendor_credit_notes;
+DROP TABLE IF EXISTS purchase_return_lines;
+DROP TABLE IF EXISTS purchase_returns;
@@ -0,0 +1,56 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS purchase_returns (
+    id_return VARCHAR(20) PRIMARY KEY,
+    id_receipt VARCHAR(20) NOT NULL REFERENCES rfq_receipts (id_receipt),
+    id_rfq VARCHAR(255) NOT NULL,
+    id_vendor VARCHAR(255) NOT NULL,
+    returned_by VARCHAR(255) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_returns_vendor ON purchase_returns (id_vendor);
+CREATE INDEX IF NOT EXISTS idx_purchase_returns_rfq ON purchase_returns (id_rfq);
+
+CREATE TABLE IF NOT EXISTS purchase_return_lines (
+    id_returnline VARCHAR(20) PRIMARY KEY,
+    id_return VARCHAR(20) NOT NULL REFERENCES purchase_returns (id_return) ON DELETE CASCADE,
+    id_receiptline VARCHAR(20) NOT NULL REFERENCES rfq_receipt_lines (id_receiptline),
+    id_rfqproduct VARCHAR(255) NOT NULL,
+    id_product VARCHAR(255) NOT NULL,
+    productname VARCHAR(255) NOT NULL DEFAULT '',
+    quantity NUMERIC(14,4) NOT NULL,
+    unit_price NUMERIC(16,4) NOT NULL DEFAULT 0,
+    amount NUMERIC(16,2) NOT NULL DEFAULT 0,
+    reason VARCHAR(50) NOT NULL,
+    reason_note TEXT NOT NULL DEFAULT '',
+    stock_source VARCHAR(20) NOT NULL DEFAULT '',
+    id_inspection VARCHAR(20) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_return_lines_receiptline ON purchase_return_lines (id_receiptline);
+
+CREATE TABLE IF NOT EXISTS vendor_credit_notes (
+    id_creditnote VARCHAR(20) PRIMARY KEY,
+    id_vendor VARCHAR(255) NOT NULL,
+    id_return VARCHAR(20) NOT NULL REFERENCES purchase_returns (id_return),
+    amount NUMERIC(14,2) NOT NULL,
+    amount_applied NUMERIC(14,2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_vendor_credit_notes_vendor ON vendor_credit_notes (id_vendor, status);
+
+ALTER TABLE bill_payments
+    ADD COLUMN IF NOT EXISTS id_creditnote VARCHAR(20) NOT NULL DEFAULT '';
+
+COMMIT;
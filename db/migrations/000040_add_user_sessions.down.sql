@@ -0,0 +1,4 @@
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS jwt_token_expires_at,
+    DROP COLUMN IF EXISTS jwt_token;
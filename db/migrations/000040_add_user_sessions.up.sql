@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS jwt_token TEXT,
+    ADD COLUMN IF NOT EXISTS jwt_token_expires_at TIMESTAMPTZ;
+
+COMMIT;
@@ -0,0 +1,2 @@
+
+ALTER TABLE vendor_prices DROP COLUMN IF EXISTS superseded_at;
@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE vendor_prices ADD COLUMN IF NOT EXISTS superseded_at TIMESTAMPTZ;
+
+-- A price was in effect until its replacement was entered.
+UPDATE vendor_prices p SET superseded_at = r.created_at
+FROM vendor_prices r
+WHERE p.superseded_by = r.id_vendorprice AND p.superseded_at IS NULL;
+
+COMMIT;
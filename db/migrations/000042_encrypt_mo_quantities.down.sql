@@ -0,0 +1,17 @@
+
+-- Encrypted quantities cannot be turned back into numbers without the
+-- application key; see 000028.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM mo_reservations WHERE qty_required LIKE 'enc:%')
+        OR EXISTS (SELECT 1 FROM mo_consumptions WHERE qty_theoretical LIKE 'enc:%' OR qty_actual LIKE 'enc:%') THEN
+        RAISE EXCEPTION 'MO quantities are encrypted; migration 000042 is irreversible';
+    END IF;
+END $$;
+
+ALTER TABLE mo_consumptions ALTER COLUMN qty_actual TYPE NUMERIC(14,4) USING qty_actual::NUMERIC;
+ALTER TABLE mo_consumptions ALTER COLUMN qty_actual SET DEFAULT 0;
+ALTER TABLE mo_consumptions ALTER COLUMN qty_theoretical TYPE NUMERIC(14,4) USING qty_theoretical::NUMERIC;
+ALTER TABLE mo_consumptions ALTER COLUMN qty_theoretical SET DEFAULT 0;
+ALTER TABLE mo_reservations ALTER COLUMN qty_required TYPE FLOAT USING qty_required::FLOAT;
+ALTER TABLE mo_reservations ALTER COLUMN qty_required SET DEFAULT 0;
@@ -0,0 +1,13 @@
+BEGIN;
+
+-- Required and consumed quantities of MOs are the formula scaled to the
+-- batch, so they are stored encrypted by the application like the BOM lines.
+-- Existing rows are encrypted on startup.
+ALTER TABLE mo_reservations ALTER COLUMN qty_required TYPE TEXT USING qty_required::TEXT;
+ALTER TABLE mo_reservations ALTER COLUMN qty_required DROP DEFAULT;
+ALTER TABLE mo_consumptions ALTER COLUMN qty_theoretical TYPE TEXT USING qty_theoretical::TEXT;
+ALTER TABLE mo_consumptions ALTER COLUMN qty_theoretical DROP DEFAULT;
+ALTER TABLE mo_consumptions ALTER COLUMN qty_actual TYPE TEXT USING qty_actual::TEXT;
+ALTER TABLE mo_consumptions ALTER COLUMN qty_actual DROP DEFAULT;
+
+COMMIT;
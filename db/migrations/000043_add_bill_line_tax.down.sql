@@ -0,0 +1,8 @@
+
+ALTER TABLE purchase_return_lines DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE bill_lines
+    DROP COLUMN IF EXISTS total,
+    DROP COLUMN IF EXISTS tax_amount,
+    DROP COLUMN IF EXISTS subtotal,
+    DROP COLUMN IF EXISTS tax_included,
+    DROP COLUMN IF EXISTS tax_rate;
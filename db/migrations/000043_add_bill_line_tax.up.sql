@@ -0,0 +1,17 @@
+BEGIN;
+
+-- Bill lines are taxed at the rate their RFQ line was ordered at; the
+-- amounts are worked out again the next time a bill is matched.
+ALTER TABLE bill_lines
+    ADD COLUMN IF NOT EXISTS tax_rate NUMERIC(8,4) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax_included BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS subtotal NUMERIC(16,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax_amount NUMERIC(16,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS total NUMERIC(16,2) NOT NULL DEFAULT 0;
+
+UPDATE bill_lines SET subtotal = ROUND(quantity * unit_price, 2), total = ROUND(quantity * unit_price, 2);
+
+-- What a return credits includes the tax paid on the goods sent back.
+ALTER TABLE purchase_return_lines ADD COLUMN IF NOT EXISTS tax_amount NUMERIC(16,2) NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -1,6 +1,8 @@
 package builder
 
 import (
+	"log"
+
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/handler"
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/router"
@@ -10,6 +12,7 @@ import (
 	"github.com/Kevinmajesta/parfume-erp-backend/pkg/email"
 	"github.com/Kevinmajesta/parfume-erp-backend/pkg/encrypt"
 	"github.com/Kevinmajesta/parfume-erp-backend/pkg/route"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/token"
 
 	// "github.com/labstack/echo/"
 
@@ -20,22 +23,29 @@ import (
 func BuildPublicRoutes(db *gorm.DB, redisDB *redis.Client, entityCfg *entity.Config, encryptTool encrypt.EncryptTool) []*route.Route {
 	cacheable := cache.NewCacheable(redisDB)
 	emailService := email.NewEmailSender(entityCfg)
+	tokenUseCase := token.NewTokenUseCase(entityCfg.JWT.SecretKey)
 	userRepository := repository.NewUserRepository(db, cacheable)
-	userService := service.NewUserService(userRepository, encryptTool, emailService)
+	userService := service.NewUserService(userRepository, encryptTool, emailService, tokenUseCase)
 	userHandler := handler.NewUserHandler(userService)
 
 	adminRepository := repository.NewAdminRepository(db, cacheable)
-	adminService := service.NewAdminService(adminRepository, encryptTool, emailService)
+	adminService := service.NewAdminService(adminRepository, encryptTool, emailService, tokenUseCase)
 	adminHandler := handler.NewAdminHandler(adminService)
 
 	return router.PublicRoutes(userHandler, adminHandler)
 }
 
 func BuildPrivateRoutes(db *gorm.DB, redisDB *redis.Client, encryptTool encrypt.EncryptTool, entityCfg *entity.Config) []*route.Route {
+	repository.RegisterFormulaSerializer(encryptTool)
+	if _, err := repository.EncryptStoredFormulas(db); err != nil {
+		log.Fatalf("Error encrypting stored formula data: %v", err)
+	}
+
 	cacheable := cache.NewCacheable(redisDB)
 	emailService := email.NewEmailSender(entityCfg)
+	tokenUseCase := token.NewTokenUseCase(entityCfg.JWT.SecretKey)
 	userRepository := repository.NewUserRepository(db, cacheable)
-	userService := service.NewUserService(userRepository, encryptTool, nil)
+	userService := service.NewUserService(userRepository, encryptTool, nil, tokenUseCase)
 	userHandler := handler.NewUserHandler(userService)
 
 	suggestionRepository := repository.NewSuggestionRepository(db, cacheable)
@@ -43,12 +53,15 @@ func BuildPrivateRoutes(db *gorm.DB, redisDB *redis.Client, encryptTool encrypt.
 	suggestionHandler := handler.NewSuggestionHandler(suggestionService, userService)
 
 	adminRepository := repository.NewAdminRepository(db, cacheable)
-	adminService := service.NewAdminService(adminRepository, encryptTool, nil)
+	adminService := service.NewAdminService(adminRepository, encryptTool, nil, tokenUseCase)
 	adminHandler := handler.NewAdminHandler(adminService)
 
 	schedulesRepository := repository.NewSchedulesRepository(db, cacheable)
-	schedulesService := service.NewSchedulesService(schedulesRepository)
+	vesselRepo := repository.NewVesselRepository(db)
+	schedulesService := service.NewSchedulesService(schedulesRepository, vesselRepo)
 	schedulesHandler := handler.NewSchedulesHandler(schedulesService)
+	vesselService := service.NewVesselService(vesselRepo, schedulesRepository)
+	vesselHandler := handler.NewVesselHandler(vesselService)
 
 	productRepository := repository.NewProductRepository(db, cacheable)
 	productService := service.NewProductService(productRepository)
@@ -60,35 +73,92 @@ func BuildPrivateRoutes(db *gorm.DB, redisDB *redis.Client, encryptTool encrypt.
 
 	bomRepository := repository.NewBOMRepository(db, cacheable)
 	bomMaterialRepo := repository.NewBOMMaterialRepository(db)
-	bomService := service.NewBOMService(bomRepository, bomMaterialRepo)
+	bomVersionRepo := repository.NewBOMVersionRepository(db)
+	complianceRepo := repository.NewComplianceRepository(db)
+	bomService := service.NewBOMService(bomRepository, bomMaterialRepo, bomVersionRepo, complianceRepo)
 	bomHandler := handler.NewBOMHandler(bomService)
-
-	moRepository := repository.NewMoRepository(db, cacheable)
-	moService := service.NewMoService(moRepository)
-	moHandler := handler.NewMoHandler(moService)
+	complianceService := service.NewComplianceService(complianceRepo, materialRepository)
+	complianceHandler := handler.NewComplianceHandler(complianceService)
 
 	vendorRepository := repository.NewVendorRepository(db, cacheable)
-	vendorService := service.NewVendorService(vendorRepository)
-	vendorHandler := handler.NewVendorHandler(vendorService)
 
 	rfqRepository := repository.NewRfqRepository(db, cacheable)
 	rfqProductRepo := repository.NewRfqProductRepository(db)
-	rfqService := service.NewRfqService(rfqRepository, rfqProductRepo, emailService)
+	qualityRepo := repository.NewQualityRepository(db)
+	qualityService := service.NewQualityService(qualityRepo, materialRepository, productRepository, vendorRepository)
+	qualityHandler := handler.NewQualityHandler(qualityService)
+	rfqReceiptRepo := repository.NewRfqReceiptRepository(db)
+	priceListRepo := repository.NewPriceListRepository(db)
+	priceListService := service.NewPriceListService(priceListRepo, vendorRepository, materialRepository, productRepository, rfqRepository)
+	priceListHandler := handler.NewPriceListHandler(priceListService)
+	taxRepo := repository.NewTaxRepository(db)
+	taxService := service.NewTaxService(taxRepo)
+	taxHandler := handler.NewTaxHandler(taxService)
+	poApprovalRepo := repository.NewPoApprovalRepository(db)
+	poApprovalService := service.NewPoApprovalService(poApprovalRepo, rfqRepository, materialRepository, productRepository, taxService, emailService)
+	poApprovalHandler := handler.NewPoApprovalHandler(poApprovalService)
+	rfqService := service.NewRfqService(rfqRepository, rfqProductRepo, rfqReceiptRepo, emailService, qualityService, priceListService, poApprovalService, taxService)
 	rfqHandler := handler.NewRfqHandler(rfqService)
+	tenderRepo := repository.NewTenderRepository(db)
+	tenderService := service.NewTenderService(tenderRepo, rfqRepository, rfqProductRepo, vendorRepository, materialRepository, rfqService, poApprovalService)
+	tenderHandler := handler.NewTenderHandler(tenderService)
+
+	workCenterRepo := repository.NewWorkCenterRepository(db)
+	routingRepo := repository.NewRoutingRepository(db)
+	workCenterService := service.NewWorkCenterService(workCenterRepo, routingRepo, bomRepository)
+	workCenterHandler := handler.NewWorkCenterHandler(workCenterService)
+
+	moRepository := repository.NewMoRepository(db, cacheable)
+	moReservationRepo := repository.NewMoReservationRepository(db)
+	workOrderRepo := repository.NewWorkOrderRepository(db)
+	moProductionRepo := repository.NewMoProductionRepository(db)
+	moReversalRepo := repository.NewMoReversalRepository(db)
+	moService := service.NewMoService(moRepository, moReservationRepo, bomRepository, bomVersionRepo, materialRepository, productRepository,
+		routingRepo, workOrderRepo, workCenterRepo, moProductionRepo, moReversalRepo, vesselRepo, schedulesRepository, qualityService, rfqService, rfqRepository)
+	moHandler := handler.NewMoHandler(moService)
+	varianceService := service.NewVarianceService(moProductionRepo, moRepository, productRepository)
+	varianceHandler := handler.NewVarianceHandler(varianceService)
 
 	costumerRepository := repository.NewCostumerRepository(db, cacheable)
 	costumerService := service.NewCostumerService(costumerRepository)
 	costumerHandler := handler.NewCostumerHandler(costumerService)
 
 	billrfqRepository := repository.NewBillrfqRepository(db, cacheable)
-	billrfqService := service.NewBillrfqService(billrfqRepository)
+	billrfqService := service.NewBillrfqService(billrfqRepository, rfqRepository, rfqProductRepo, vendorRepository, taxRepo)
 	billrfqHandler := handler.NewBillrfqHandler(billrfqService)
 
+	purchaseReturnRepo := repository.NewPurchaseReturnRepository(db)
+	purchaseReturnService := service.NewPurchaseReturnService(purchaseReturnRepo, rfqReceiptRepo, rfqRepository, billrfqRepository, billrfqService, qualityService)
+	purchaseReturnHandler := handler.NewPurchaseReturnHandler(purchaseReturnService)
+
+	scorecardService := service.NewScorecardService(vendorRepository, rfqRepository, rfqReceiptRepo, qualityRepo, billrfqRepository,
+		purchaseReturnRepo, priceListService)
+	scorecardHandler := handler.NewScorecardHandler(scorecardService)
+	vendorService := service.NewVendorService(vendorRepository, scorecardService)
+	vendorHandler := handler.NewVendorHandler(vendorService)
+
 	quoRepository := repository.NewQuoRepository(db, cacheable)
 	quoProductRepo := repository.NewQuoProductRepository(db)
-	quoService := service.NewQuoService(quoRepository, quoProductRepo, emailService)
+	quoService := service.NewQuoService(quoRepository, quoProductRepo, emailService, taxService)
 	quoHandler := handler.NewQuoHandler(quoService)
 
-	return router.PrivateRoutes(userHandler, suggestionHandler, adminHandler, schedulesHandler,
-		productHandler, materialHandler, *bomHandler, moHandler, vendorHandler, rfqHandler, costumerHandler, quoHandler, billrfqHandler)
+	mrpRepository := repository.NewMrpRepository(db)
+	reorderRuleRepo := repository.NewReorderRuleRepository(db)
+	mrpService := service.NewMrpService(mrpRepository, reorderRuleRepo, quoRepository, rfqRepository, moRepository,
+		moReservationRepo, bomRepository, materialRepository, productRepository, moService, rfqService)
+	mrpHandler := handler.NewMrpHandler(mrpService)
+
+	routes := router.PrivateRoutes(userHandler, suggestionHandler, adminHandler, schedulesHandler,
+		productHandler, materialHandler, *bomHandler, moHandler, vendorHandler, rfqHandler, costumerHandler, quoHandler, billrfqHandler,
+		mrpHandler, workCenterHandler, vesselHandler, qualityHandler,
+		varianceHandler, complianceHandler, priceListHandler, tenderHandler, poApprovalHandler, scorecardHandler, taxHandler,
+		purchaseReturnHandler)
+
+	// Requests are not refused without a session, but the handlers that
+	// depend on who is asking see the authenticated user.
+	session := handler.Session(userService)
+	for _, r := range routes {
+		r.Handler = session(r.Handler)
+	}
+	return routes
 }
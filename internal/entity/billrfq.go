@@ -1,12 +1,80 @@
 package entity
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
+// Billrfq is a vendor bill. It moves from "Draft" to "Posted" once it
+// matches, then to "Partially Paid" and "Paid" as payments come in; a bill
+// nothing has been paid on can be "Cancelled". Bills approved before bills
+// had lines are "Needs Review" and are completed like drafts. The amounts and
+// due dates are fixed when the bill is posted, from the vendor's payment
+// terms.
 type Billrfq struct {
-	BillrfqId string `json:"id_bill" gorm:"column:id_bill;primaryKey"`
-	VendorId  string `json:"id_vendor" gorm:"column:id_vendor"`
-	Bill_date string `json:"bill_date"`
-	Payment   string `json:"payment"`
+	BillrfqId      string        `json:"id_bill" gorm:"column:id_bill;primaryKey"`
+	VendorId       string        `json:"id_vendor" gorm:"column:id_vendor"`
+	Bill_date      string        `json:"bill_date"`
+	Payment        string        `json:"payment"`
+	Status         string        `json:"status" gorm:"column:status"`
+	MatchStatus    string        `json:"match_status" gorm:"column:match_status"` // "unmatched", "matched" or "mismatch"
+	Total          float64       `json:"total" gorm:"column:total"`
+	AmountPaid     float64       `json:"amount_paid" gorm:"column:amount_paid"`
+	UpfrontAmount  float64       `json:"upfront_amount" gorm:"column:upfront_amount"`
+	UpfrontDueDate *time.Time    `json:"upfront_due_date" gorm:"column:upfront_due_date"`
+	DueDate        *time.Time    `json:"due_date" gorm:"column:due_date"`
+	Lines          []BillLine    `json:"lines" gorm:"foreignKey:BillrfqId;references:BillrfqId"`
+	Payments       []BillPayment `json:"payments" gorm:"foreignKey:BillrfqId;references:BillrfqId"`
+	Auditable
+}
+
+// BillLine is what the vendor bills for one RFQ line. MatchStatus is the
+// outcome of the three-way match against the ordered and received
+// quantities; a "mismatch" can be accepted by hand, which marks it
+// "resolved". The unit price is after discount; the line is taxed at the
+// rate its RFQ line was ordered at.
+type BillLine struct {
+	BillLineId     string  `json:"id_billline" gorm:"column:id_billline;primaryKey"`
+	BillrfqId      string  `json:"id_bill" gorm:"column:id_bill"`
+	RfqId          string  `json:"id_rfq" gorm:"column:id_rfq"`
+	RfqsProductId  string  `json:"id_rfqproduct" gorm:"column:id_rfqproduct"`
+	ProductId      string  `json:"id_product" gorm:"column:id_product"`
+	ProductName    string  `json:"productname" gorm:"column:productname"`
+	Quantity       float64 `json:"quantity" gorm:"column:quantity"`
+	UnitPrice      float64 `json:"unit_price" gorm:"column:unit_price"`
+	TaxRate        float64 `json:"tax_rate" gorm:"column:tax_rate"`
+	TaxIncluded    bool    `json:"tax_included" gorm:"column:tax_included"`
+	Subtotal       float64 `json:"subtotal" gorm:"column:subtotal"`
+	TaxAmount      float64 `json:"tax_amount" gorm:"column:tax_amount"`
+	Total          float64 `json:"total" gorm:"column:total"`
+	MatchStatus    string  `json:"match_status" gorm:"column:match_status"`
+	MatchNote      string  `json:"match_note" gorm:"column:match_note"`
+	ResolvedBy     string  `json:"resolved_by" gorm:"column:resolved_by"`
+	ResolutionNote string  `json:"resolution_note" gorm:"column:resolution_note"`
+	Auditable
+}
+
+// BillPayment is a payment made against a bill. CreditNoteId is set when the
+// bill was settled with a vendor credit note rather than paid.
+type BillPayment struct {
+	PaymentId    string    `json:"id_payment" gorm:"column:id_payment;primaryKey"`
+	BillrfqId    string    `json:"id_bill" gorm:"column:id_bill"`
+	Amount       float64   `json:"amount" gorm:"column:amount"`
+	PaymentDate  time.Time `json:"payment_date" gorm:"column:payment_date"`
+	Method       string    `json:"method" gorm:"column:method"`
+	Reference    string    `json:"reference" gorm:"column:reference"`
+	CreditNoteId string    `json:"id_creditnote" gorm:"column:id_creditnote"`
+	Auditable
+}
+
+// BillMatchTolerance is how far, in percent, a bill may exceed the received
+// quantity and deviate from the ordered price and still match. An empty
+// VendorId is the default for vendors without their own tolerance.
+type BillMatchTolerance struct {
+	ToleranceId  string  `json:"id_tolerance" gorm:"column:id_tolerance;primaryKey"`
+	VendorId     string  `json:"id_vendor" gorm:"column:id_vendor"`
+	QtyPercent   float64 `json:"qty_percent" gorm:"column:qty_percent"`
+	PricePercent float64 `json:"price_percent" gorm:"column:price_percent"`
 	Auditable
 }
 
@@ -22,12 +90,77 @@ func generateBillRfqId(lastId string) string {
 	return fmt.Sprintf("BRQ-%05d", newNumber)
 }
 
+func generateBillLineId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "BLL-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("BLL-%05d", newNumber)
+}
+
+func generateBillToleranceId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "TOL-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("TOL-%05d", newNumber)
+}
+
+func generateBillPaymentId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "PAY-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("PAY-%05d", newNumber)
+}
+
 func NewBillrfq(lastId, id_vendor, bill_date, payment string) *Billrfq {
 	return &Billrfq{
-		BillrfqId: generateBillRfqId(lastId),
-		VendorId:  id_vendor,
-		Bill_date: bill_date,
-		Payment:   payment,
-		Auditable: NewAuditable(),
+		BillrfqId:   generateBillRfqId(lastId),
+		VendorId:    id_vendor,
+		Bill_date:   bill_date,
+		Payment:     payment,
+		Status:      "Draft",
+		MatchStatus: "unmatched",
+		Auditable:   NewAuditable(),
+	}
+}
+
+func NewBillLine(lastId, billId string, line BillLine) *BillLine {
+	line.BillLineId = generateBillLineId(lastId)
+	line.BillrfqId = billId
+	line.MatchStatus = "unmatched"
+	line.Auditable = NewAuditable()
+	return &line
+}
+
+func NewBillMatchTolerance(lastId, vendorId string, qtyPercent, pricePercent float64) *BillMatchTolerance {
+	return &BillMatchTolerance{
+		ToleranceId:  generateBillToleranceId(lastId),
+		VendorId:     vendorId,
+		QtyPercent:   qtyPercent,
+		PricePercent: pricePercent,
+		Auditable:    NewAuditable(),
+	}
+}
+
+func NewBillPayment(lastId, billId string, amount float64, paymentDate time.Time, method, reference string) *BillPayment {
+	return &BillPayment{
+		PaymentId:   generateBillPaymentId(lastId),
+		BillrfqId:   billId,
+		Amount:      amount,
+		PaymentDate: paymentDate,
+		Method:      method,
+		Reference:   reference,
+		Auditable:   NewAuditable(),
 	}
 }
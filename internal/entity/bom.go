@@ -2,6 +2,7 @@ package entity
 
 import (
 	"fmt"
+	"time"
 )
 
 type Bom struct {
@@ -10,16 +11,38 @@ type Bom struct {
     ProductName       string        `json:"productname" gorm:"column:productname"`
     ProductPreference string        `json:"productpreference" gorm:"column:productpreference"`
     Quantity          string        `json:"quantity" gorm:"column:quantity"`
-    Materials         []BomMaterial `json:"materials" gorm:"foreignKey:BomId"` // Gunakan foreignKey
+    Materials         []BomMaterial `json:"materials" gorm:"-"` // lines of the version below
+    VersionId         string        `json:"id_bomversion" gorm:"-"`
+    Version           int           `json:"version" gorm:"-"`
+    EffectiveFrom     time.Time     `json:"effective_from" gorm:"-"`
+    ChangeNote        string        `json:"change_note" gorm:"-"`
+    MacerationDays    int           `json:"maceration_days" gorm:"column:maceration_days"` // days the batch rests in a tank
+    LitresPerUnit     float64       `json:"litres_per_unit" gorm:"column:litres_per_unit"` // tank volume per unit produced
     Auditable
 }
 
+// BomVersion is an immutable snapshot of a BOM's formula. Editing a BOM adds
+// a new version and closes the previous one instead of rewriting its lines.
+type BomVersion struct {
+	BomVersionId  string        `json:"id_bomversion" gorm:"column:id_bomversion;primaryKey"`
+	BomId         string        `json:"id_bom" gorm:"column:id_bom"`
+	Version       int           `json:"version" gorm:"column:version"`
+	Quantity      string        `json:"quantity" gorm:"column:quantity"`
+	EffectiveFrom time.Time     `json:"effective_from" gorm:"column:effective_from"`
+	EffectiveTo   *time.Time    `json:"effective_to" gorm:"column:effective_to"`
+	ChangeNote    string        `json:"change_note" gorm:"column:change_note"`
+	Materials     []BomMaterial `json:"materials" gorm:"foreignKey:BomVersionId;references:BomVersionId"`
+	Auditable
+}
+
 type BomMaterial struct {
 	IdBomMaterial string `json:"id_bommaterial" gorm:"column:id_bommaterial"`
 	IdMaterial    string `json:"id_material" gorm:"column:id_material"`
+	IdProduct     string `json:"id_product" gorm:"column:id_product"` // set when the line is a sub-assembly
 	BomId         string `json:"id_bom" gorm:"column:id_bom"`
+	BomVersionId  string `json:"id_bomversion" gorm:"column:id_bomversion"`
 	MaterialName  string `json:"material_name" gorm:"column:materialname"`
-	Quantity      string `json:"quantity" gorm:"column:quantity"`
+	Quantity      string `json:"quantity,omitempty" gorm:"column:quantity;serializer:formula"` // encrypted at rest, empty when redacted
 	Unit          string `json:"unit" gorm:"column:unit"`
 	Auditable
 }
@@ -35,6 +58,29 @@ func generateBomId(lastId string) string {
 	return fmt.Sprintf("BOM-%05d", newNumber)
 }
 
+func generateBomVersionId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "BMV-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("BMV-%05d", newNumber)
+}
+
+func NewBomVersion(lastId, bomId string, version int, quantity string, effectiveFrom time.Time, changeNote string) *BomVersion {
+	return &BomVersion{
+		BomVersionId:  generateBomVersionId(lastId),
+		BomId:         bomId,
+		Version:       version,
+		Quantity:      quantity,
+		EffectiveFrom: effectiveFrom,
+		ChangeNote:    changeNote,
+		Auditable:     NewAuditable(),
+	}
+}
+
 func NewBom(lastId, productId, productName, productpreference, quantity string) *Bom {
 	return &Bom{
 		BomId:             generateBomId(lastId),
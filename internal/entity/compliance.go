@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"fmt"
+)
+
+// MaterialConstituent is a substance contained in a material, such as the
+// linalool in lavender oil, as a percentage by weight. Allergen marks
+// substances that must be declared on the label above a threshold.
+type MaterialConstituent struct {
+	ConstituentId string  `json:"id_constituent" gorm:"column:id_constituent;primaryKey"`
+	MaterialId    string  `json:"id_material" gorm:"column:id_material"`
+	Substance     string  `json:"substance" gorm:"column:substance"`
+	CasNumber     string  `json:"cas_number" gorm:"column:cas_number"`
+	Percentage    float64 `json:"percentage" gorm:"column:percentage"`
+	Allergen      bool    `json:"allergen" gorm:"column:allergen"`
+	Auditable
+}
+
+// ComplianceLimit is a maximum concentration of a substance in finished
+// products of a category, in percent. Type "restriction" limits use (e.g. an
+// IFRA standard); type "label" sets the concentration above which an
+// allergen must be declared. A label limit without a substance applies to
+// every allergen, and an empty ProductCategory to every category.
+type ComplianceLimit struct {
+	ComplianceLimitId string  `json:"id_compliancelimit" gorm:"column:id_compliancelimit;primaryKey"`
+	Type              string  `json:"type" gorm:"column:type"`
+	Substance         string  `json:"substance" gorm:"column:substance"`
+	CasNumber         string  `json:"cas_number" gorm:"column:cas_number"`
+	ProductCategory   string  `json:"product_category" gorm:"column:product_category"`
+	MaxPercent        float64 `json:"max_percent" gorm:"column:max_percent"`
+	Source            string  `json:"source" gorm:"column:source"`
+	Auditable
+}
+
+func generateConstituentId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "CST-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("CST-%05d", newNumber)
+}
+
+func generateComplianceLimitId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "LIM-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("LIM-%05d", newNumber)
+}
+
+func NewMaterialConstituent(lastId string, constituent MaterialConstituent) *MaterialConstituent {
+	constituent.ConstituentId = generateConstituentId(lastId)
+	constituent.Auditable = NewAuditable()
+	return &constituent
+}
+
+func NewComplianceLimit(lastId string, limit ComplianceLimit) *ComplianceLimit {
+	limit.ComplianceLimitId = generateComplianceLimitId(lastId)
+	limit.Auditable = NewAuditable()
+	return &limit
+}
@@ -4,6 +4,7 @@ type Config struct {
 	Namespace string
 	Redis     RedisConfig
 	SMTP      SMTPConfig
+	JWT       JWTConfig
 }
 
 type SMTPConfig struct {
@@ -12,6 +13,10 @@ type SMTPConfig struct {
 	Password string `env:"PASSWORD" envDefault:"qloscitkfltxwjkb"`
 }
 
+type JWTConfig struct {
+	SecretKey string
+}
+
 type RedisConfig struct {
 	Host string
 	Port string
@@ -14,6 +14,7 @@ type Materials struct {
 	Description      string `form:"description"`
 	Image            string `form:"image"`
 	Qty              float64    `form:"qty"`
+	QuarantineQty    float64    `json:"quarantine_qty" gorm:"column:quarantine_qty"` // held until QC releases it
 	Auditable
 }
 
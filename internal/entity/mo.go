@@ -1,13 +1,23 @@
 package entity
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type Mos struct {
-	MoId         string `json:"id_mo" gorm:"column:id_mo;primaryKey"`
-	ProductId    string `json:"id_product" gorm:"column:id_product"`
-	BomId        string `json:"id_bom" gorm:"column:id_bom"`
-	Qtytoproduce string `json:"qtytoproduce"`
-	Status       string `json:"status"`
+	MoId         string     `json:"id_mo" gorm:"column:id_mo;primaryKey"`
+	ProductId    string     `json:"id_product" gorm:"column:id_product"`
+	BomId        string     `json:"id_bom" gorm:"column:id_bom"`
+	BomVersionId string     `json:"id_bomversion" gorm:"column:id_bomversion"` // formula the MO was created with
+	Qtytoproduce string     `json:"qtytoproduce"`
+	Status       string     `json:"status"`
+	ParentMoId   string     `json:"id_parent_mo" gorm:"column:id_parent_mo"`
+	QtyProduced  float64    `json:"qty_produced" gorm:"column:qty_produced"`
+	BackorderOf  string     `json:"id_backorder_of" gorm:"column:id_backorder_of"` // MO this one produces the remainder of
+	PlannedStart *time.Time `json:"planned_start" gorm:"column:planned_start"`
+	PlannedEnd   *time.Time `json:"planned_end" gorm:"column:planned_end"`
+	RequiredDate *time.Time `json:"required_date" gorm:"column:required_date"` // delivery date the plan works back from
 	Auditable
 }
 
@@ -33,3 +43,166 @@ func NewMos(lastId, id_product, id_bom, qtytoproduce string) *Mos {
 		Auditable:    NewAuditable(),
 	}
 }
+
+type MoReservation struct {
+	ReservationId string  `json:"id_reservation" gorm:"column:id_reservation;primaryKey"`
+	MoId          string  `json:"id_mo" gorm:"column:id_mo"`
+	MaterialId    string  `json:"id_material" gorm:"column:id_material"`
+	ProductId     string  `json:"id_product" gorm:"column:id_product"`
+	QtyRequired   float64 `json:"qty_required" gorm:"column:qty_required;serializer:formula"` // encrypted, it is the formula scaled
+	QtyReserved   float64 `json:"qty_reserved" gorm:"column:qty_reserved"`
+	QtyConsumed   float64 `json:"qty_consumed" gorm:"column:qty_consumed"`
+	Status        string  `json:"status"`
+	Auditable
+}
+
+// MoProduction records a quantity finished on an MO, so output can be
+// registered in several steps.
+type MoProduction struct {
+	ProductionId string  `json:"id_moproduction" gorm:"column:id_moproduction;primaryKey"`
+	MoId         string  `json:"id_mo" gorm:"column:id_mo"`
+	Quantity     float64 `json:"quantity" gorm:"column:quantity"`
+	ProducedBy   string  `json:"produced_by" gorm:"column:produced_by"`
+	Note         string  `json:"note" gorm:"column:note"`
+	Auditable
+}
+
+func generateMoProductionId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "PRD-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("PRD-%05d", newNumber)
+}
+
+func NewMoProduction(lastId, moId string, quantity float64, producedBy, note string) *MoProduction {
+	return &MoProduction{
+		ProductionId: generateMoProductionId(lastId),
+		MoId:         moId,
+		Quantity:     quantity,
+		ProducedBy:   producedBy,
+		Note:         note,
+		Auditable:    NewAuditable(),
+	}
+}
+
+// MoConsumption is what a production step actually used of one component
+// next to what the BOM says it should have used. UnitCost is the component's
+// cost price at the time, so variances keep their value when prices change.
+type MoConsumption struct {
+	ConsumptionId  string  `json:"id_moconsumption" gorm:"column:id_moconsumption;primaryKey"`
+	MoId           string  `json:"id_mo" gorm:"column:id_mo"`
+	ProductionId   string  `json:"id_moproduction" gorm:"column:id_moproduction"`
+	MaterialId     string  `json:"id_material" gorm:"column:id_material"`
+	ProductId      string  `json:"id_product" gorm:"column:id_product"`
+	ComponentName  string  `json:"component_name" gorm:"column:component_name"`
+	Unit           string  `json:"unit" gorm:"column:unit"`
+	LotNumber      string  `json:"lot_number" gorm:"column:lot_number"`
+	QtyTheoretical float64 `json:"qty_theoretical" gorm:"column:qty_theoretical;serializer:formula"` // encrypted like the formula
+	QtyActual      float64 `json:"qty_actual" gorm:"column:qty_actual;serializer:formula"`
+	UnitCost       float64 `json:"unit_cost" gorm:"column:unit_cost"`
+	Auditable
+}
+
+func generateMoConsumptionId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "CNS-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("CNS-%05d", newNumber)
+}
+
+func NewMoConsumption(lastId string, consumption MoConsumption) *MoConsumption {
+	consumption.ConsumptionId = generateMoConsumptionId(lastId)
+	consumption.Auditable = NewAuditable()
+	return &consumption
+}
+
+// MoBatchRecord is the batch manufacturing record of a finished MO, frozen as
+// JSON the first time it is printed so every reprint shows the same data.
+// The record lists the quantities of every material, so it is stored
+// encrypted like the formula itself.
+type MoBatchRecord struct {
+	MoId   string `json:"id_mo" gorm:"column:id_mo;primaryKey"`
+	Record string `json:"record" gorm:"column:record;serializer:formula"`
+	Auditable
+}
+
+// MoReversal records a cancellation or unbuild of an MO: who did it, why,
+// and every stock movement it made. Quantity is the finished goods taken
+// back out of stock.
+type MoReversal struct {
+	ReversalId  string           `json:"id_moreversal" gorm:"column:id_moreversal;primaryKey"`
+	MoId        string           `json:"id_mo" gorm:"column:id_mo"`
+	Type        string           `json:"type" gorm:"column:type"` // "cancel" or "unbuild"
+	Quantity    float64          `json:"quantity" gorm:"column:quantity"`
+	Reason      string           `json:"reason" gorm:"column:reason"`
+	PerformedBy string           `json:"performed_by" gorm:"column:performed_by"`
+	Lines       []MoReversalLine `json:"lines" gorm:"foreignKey:ReversalId;references:ReversalId"`
+	Redacted    bool             `json:"redacted" gorm:"-"` // line quantities left out
+	Auditable
+}
+
+// MoReversalLine is one stock movement of a reversal: positive quantities
+// went back into stock, negative ones were taken out.
+type MoReversalLine struct {
+	LineId        string  `json:"id_moreversalline" gorm:"column:id_moreversalline;primaryKey"`
+	ReversalId    string  `json:"id_moreversal" gorm:"column:id_moreversal"`
+	MaterialId    string  `json:"id_material" gorm:"column:id_material"`
+	ProductId     string  `json:"id_product" gorm:"column:id_product"`
+	ComponentName string  `json:"component_name" gorm:"column:component_name"`
+	Quantity      float64 `json:"quantity,omitempty" gorm:"column:quantity"`
+	Auditable
+}
+
+func generateMoReversalId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "REV-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("REV-%05d", newNumber)
+}
+
+func generateMoReversalLineId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "RVL-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("RVL-%05d", newNumber)
+}
+
+func NewMoReversal(lastId, moId, reversalType string, quantity float64, reason, performedBy string) *MoReversal {
+	return &MoReversal{
+		ReversalId:  generateMoReversalId(lastId),
+		MoId:        moId,
+		Type:        reversalType,
+		Quantity:    quantity,
+		Reason:      reason,
+		PerformedBy: performedBy,
+		Auditable:   NewAuditable(),
+	}
+}
+
+func NewMoReversalLine(lastId, reversalId, materialId, productId, componentName string, quantity float64) *MoReversalLine {
+	return &MoReversalLine{
+		LineId:        generateMoReversalLineId(lastId),
+		ReversalId:    reversalId,
+		MaterialId:    materialId,
+		ProductId:     productId,
+		ComponentName: componentName,
+		Quantity:      quantity,
+		Auditable:     NewAuditable(),
+	}
+}
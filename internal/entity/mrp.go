@@ -0,0 +1,112 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReorderRule keeps an item between a minimum and maximum stock level. Exactly
+// one of MaterialId and ProductId is set. LeadTimeDays is the purchase lead
+// time for materials and the production lead time for products.
+type ReorderRule struct {
+	RuleId       string  `json:"id_reorderrule" gorm:"column:id_reorderrule;primaryKey"`
+	MaterialId   string  `json:"id_material" gorm:"column:id_material"`
+	ProductId    string  `json:"id_product" gorm:"column:id_product"`
+	MinQty       float64 `json:"min_qty" gorm:"column:min_qty"`
+	MaxQty       float64 `json:"max_qty" gorm:"column:max_qty"`
+	LeadTimeDays int     `json:"lead_time_days" gorm:"column:lead_time_days"`
+	VendorId     string  `json:"id_vendor" gorm:"column:id_vendor"`
+	Auditable
+}
+
+type MrpRun struct {
+	RunId     string        `json:"id_mrprun" gorm:"column:id_mrprun;primaryKey"`
+	Status    string        `json:"status"`
+	Warnings  string        `json:"warnings" gorm:"column:warnings"`
+	Proposals []MrpProposal `json:"proposals" gorm:"foreignKey:RunId;references:RunId"`
+	Auditable
+}
+
+// MrpProposal is a suggested MO (Type "mo") or purchase (Type "rfq") that a
+// planner reviews before it is turned into a real document.
+type MrpProposal struct {
+	ProposalId  string    `json:"id_mrpproposal" gorm:"column:id_mrpproposal;primaryKey"`
+	RunId       string    `json:"id_mrprun" gorm:"column:id_mrprun"`
+	Type        string    `json:"type" gorm:"column:type"`
+	ProductId   string    `json:"id_product" gorm:"column:id_product"`
+	MaterialId  string    `json:"id_material" gorm:"column:id_material"`
+	BomId       string    `json:"id_bom" gorm:"column:id_bom"`
+	VendorId    string    `json:"id_vendor" gorm:"column:id_vendor"`
+	Name        string    `json:"name" gorm:"column:name"`
+	Unit        string    `json:"unit" gorm:"column:unit"`
+	Quantity    float64   `json:"quantity" gorm:"column:quantity"`
+	OrderDate   time.Time `json:"order_date" gorm:"column:order_date"`
+	DueDate     time.Time `json:"due_date" gorm:"column:due_date"`
+	Late        bool      `json:"late" gorm:"column:late"`
+	Reason      string    `json:"reason" gorm:"column:reason"`
+	Status      string    `json:"status"`
+	ReferenceId string    `json:"id_reference" gorm:"column:id_reference"`
+	Auditable
+}
+
+func generateReorderRuleId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "RRL-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("RRL-%05d", newNumber)
+}
+
+func generateMrpRunId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "MRP-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("MRP-%05d", newNumber)
+}
+
+func generateMrpProposalId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "MPP-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("MPP-%05d", newNumber)
+}
+
+func NewReorderRule(lastId, materialId, productId string, minQty, maxQty float64, leadTimeDays int, vendorId string) *ReorderRule {
+	return &ReorderRule{
+		RuleId:       generateReorderRuleId(lastId),
+		MaterialId:   materialId,
+		ProductId:    productId,
+		MinQty:       minQty,
+		MaxQty:       maxQty,
+		LeadTimeDays: leadTimeDays,
+		VendorId:     vendorId,
+		Auditable:    NewAuditable(),
+	}
+}
+
+func NewMrpRun(lastId string) *MrpRun {
+	return &MrpRun{
+		RunId:     generateMrpRunId(lastId),
+		Status:    "draft",
+		Auditable: NewAuditable(),
+	}
+}
+
+func NewMrpProposal(lastId, runId string, proposal MrpProposal) *MrpProposal {
+	proposal.ProposalId = generateMrpProposalId(lastId)
+	proposal.RunId = runId
+	proposal.Status = "proposed"
+	proposal.Auditable = NewAuditable()
+	return &proposal
+}
@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// PoApprovalRule says who has to approve an RFQ before it becomes a purchase
+// order. A rule applies from MinAmount upwards and can be narrowed to one
+// vendor, or to RFQs holding a material of one category; empty means any.
+type PoApprovalRule struct {
+	ApprovalRuleId string  `json:"id_approvalrule" gorm:"column:id_approvalrule;primaryKey"`
+	Name           string  `json:"name" gorm:"column:name"`
+	MinAmount      float64 `json:"min_amount" gorm:"column:min_amount"`
+	VendorId       string  `json:"id_vendor" gorm:"column:id_vendor"`
+	Category       string  `json:"category" gorm:"column:category"`
+	ApproverName   string  `json:"approver_name" gorm:"column:approver_name"`
+	ApproverEmail  string  `json:"approver_email" gorm:"column:approver_email"`
+	Active         bool    `json:"active" gorm:"column:active"`
+	Auditable
+}
+
+// RfqApproval is one approval asked for an RFQ under a rule, for the amount
+// the RFQ had at the time. Status is "pending", "approved", "rejected" or
+// "superseded" when the RFQ changed before anyone decided.
+type RfqApproval struct {
+	RfqApprovalId  string     `json:"id_rfqapproval" gorm:"column:id_rfqapproval;primaryKey"`
+	RfqId          string     `json:"id_rfq" gorm:"column:id_rfq"`
+	ApprovalRuleId string     `json:"id_approvalrule" gorm:"column:id_approvalrule"`
+	RuleName       string     `json:"rule_name" gorm:"column:rule_name"`
+	ApproverName   string     `json:"approver_name" gorm:"column:approver_name"`
+	ApproverEmail  string     `json:"approver_email" gorm:"column:approver_email"`
+	Amount         float64    `json:"amount" gorm:"column:amount"`
+	Status         string     `json:"status" gorm:"column:status"`
+	DecidedBy      string     `json:"decided_by" gorm:"column:decided_by"`
+	Note           string     `json:"note" gorm:"column:note"`
+	DecidedAt      *time.Time `json:"decided_at" gorm:"column:decided_at"`
+	Auditable
+}
+
+func generateApprovalRuleId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "APR-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("APR-%05d", newNumber)
+}
+
+func generateRfqApprovalId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "RAP-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("RAP-%05d", newNumber)
+}
+
+func NewPoApprovalRule(lastId string, rule PoApprovalRule) *PoApprovalRule {
+	rule.ApprovalRuleId = generateApprovalRuleId(lastId)
+	rule.Active = true
+	rule.Auditable = NewAuditable()
+	return &rule
+}
+
+func NewRfqApproval(lastId, rfqId string, rule PoApprovalRule, amount float64) *RfqApproval {
+	return &RfqApproval{
+		RfqApprovalId:  generateRfqApprovalId(lastId),
+		RfqId:          rfqId,
+		ApprovalRuleId: rule.ApprovalRuleId,
+		RuleName:       rule.Name,
+		ApproverName:   rule.ApproverName,
+		ApproverEmail:  rule.ApproverEmail,
+		Amount:         amount,
+		Status:         "pending",
+		Auditable:      NewAuditable(),
+	}
+}
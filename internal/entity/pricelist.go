@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// VendorPrice is one price break of a vendor for a material or product: the
+// unit price from MinQty upwards, within the validity dates. A changed price
+// does not overwrite the old entry; the old one is closed and points at its
+// replacement through SupersededBy, which keeps the price history.
+// SupersededAt is when that happened, so the price in effect at any moment
+// can be found again.
+type VendorPrice struct {
+	VendorPriceId string     `json:"id_vendorprice" gorm:"column:id_vendorprice;primaryKey"`
+	VendorId      string     `json:"id_vendor" gorm:"column:id_vendor"`
+	ItemId        string     `json:"id_item" gorm:"column:id_item"` // id_material or id_product
+	ItemName      string     `json:"item_name" gorm:"column:item_name"`
+	MinQty        float64    `json:"min_qty" gorm:"column:min_qty"`
+	UnitPrice     float64    `json:"unit_price" gorm:"column:unit_price"`
+	Currency      string     `json:"currency" gorm:"column:currency"`
+	ValidFrom     *time.Time `json:"valid_from" gorm:"column:valid_from"`
+	ValidTo       *time.Time `json:"valid_to" gorm:"column:valid_to"`
+	LeadTimeDays  int        `json:"lead_time_days" gorm:"column:lead_time_days"`
+	Source        string     `json:"source" gorm:"column:source"` // "manual" or "purchase order"
+	RfqId         string     `json:"id_rfq" gorm:"column:id_rfq"`
+	SupersededBy  string     `json:"superseded_by" gorm:"column:superseded_by"`
+	SupersededAt  *time.Time `json:"superseded_at" gorm:"column:superseded_at"`
+	Auditable
+}
+
+func generateVendorPriceId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "VPL-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("VPL-%05d", newNumber)
+}
+
+func NewVendorPrice(lastId string, price VendorPrice) *VendorPrice {
+	price.VendorPriceId = generateVendorPriceId(lastId)
+	price.SupersededBy = ""
+	price.SupersededAt = nil
+	price.Auditable = NewAuditable()
+	return &price
+}
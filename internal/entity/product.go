@@ -15,6 +15,7 @@ type Products struct {
 	Variant         string  `form:"variant" gorm:"column:variant"`
 	Image           string  `form:"image"`
 	Qty             float64 `form:"qty"`
+	QuarantineQty   float64 `json:"quarantine_qty" gorm:"column:quarantine_qty"` // held until QC releases it
 	Auditable
 }
 
@@ -0,0 +1,118 @@
+package entity
+
+import "fmt"
+
+// PurchaseReturn sends goods of one receipt back to the vendor. The vendor
+// owes what was paid for them, which the return's credit note records.
+type PurchaseReturn struct {
+	ReturnId   string               `json:"id_return" gorm:"column:id_return;primaryKey"`
+	ReceiptId  string               `json:"id_receipt" gorm:"column:id_receipt"`
+	RfqId      string               `json:"id_rfq" gorm:"column:id_rfq"`
+	VendorId   string               `json:"id_vendor" gorm:"column:id_vendor"`
+	ReturnedBy string               `json:"returned_by" gorm:"column:returned_by"`
+	Note       string               `json:"note" gorm:"column:note"`
+	Lines      []PurchaseReturnLine `json:"lines" gorm:"foreignKey:ReturnId;references:ReturnId"`
+	CreditNote *VendorCreditNote    `json:"credit_note" gorm:"foreignKey:ReturnId;references:ReturnId"`
+	Auditable
+}
+
+// PurchaseReturnLine is the quantity of one receipt line sent back. The
+// unit price is what the RFQ line cost before tax, after its discount; the
+// amount credited adds the tax paid on the quantity returned.
+// StockSource tells where the goods were taken from: "quarantine" for a lot
+// still held by QC, "stock" once released, or "written_off" when QC had
+// already rejected the lot.
+type PurchaseReturnLine struct {
+	ReturnLineId  string  `json:"id_returnline" gorm:"column:id_returnline;primaryKey"`
+	ReturnId      string  `json:"id_return" gorm:"column:id_return"`
+	ReceiptLineId string  `json:"id_receiptline" gorm:"column:id_receiptline"`
+	RfqsProductId string  `json:"id_rfqproduct" gorm:"column:id_rfqproduct"`
+	ProductId     string  `json:"id_product" gorm:"column:id_product"` // the material or product returned
+	ProductName   string  `json:"productname" gorm:"column:productname"`
+	Quantity      float64 `json:"quantity" gorm:"column:quantity"`
+	UnitPrice     float64 `json:"unit_price" gorm:"column:unit_price"`
+	TaxAmount     float64 `json:"tax_amount" gorm:"column:tax_amount"`
+	Amount        float64 `json:"amount" gorm:"column:amount"`
+	Reason        string  `json:"reason" gorm:"column:reason"`
+	ReasonNote    string  `json:"reason_note" gorm:"column:reason_note"`
+	StockSource   string  `json:"stock_source" gorm:"column:stock_source"`
+	InspectionId  string  `json:"id_inspection" gorm:"column:id_inspection"`
+	Auditable
+}
+
+// VendorCreditNote is money a vendor owes back. It is applied against the
+// vendor's open bills as payments; Status moves from "open" through
+// "partially applied" to "applied".
+type VendorCreditNote struct {
+	CreditNoteId  string        `json:"id_creditnote" gorm:"column:id_creditnote;primaryKey"`
+	VendorId      string        `json:"id_vendor" gorm:"column:id_vendor"`
+	ReturnId      string        `json:"id_return" gorm:"column:id_return"`
+	Amount        float64       `json:"amount" gorm:"column:amount"`
+	AmountApplied float64       `json:"amount_applied" gorm:"column:amount_applied"`
+	Status        string        `json:"status" gorm:"column:status"`
+	Applications  []BillPayment `json:"applications" gorm:"foreignKey:CreditNoteId;references:CreditNoteId"`
+	Auditable
+}
+
+func generatePurchaseReturnId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "PRT-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("PRT-%05d", newNumber)
+}
+
+func generatePurchaseReturnLineId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "PRL-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("PRL-%05d", newNumber)
+}
+
+func generateCreditNoteId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "VCN-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("VCN-%05d", newNumber)
+}
+
+func NewPurchaseReturn(lastId string, receipt RfqReceipt, returnedBy, note string) *PurchaseReturn {
+	return &PurchaseReturn{
+		ReturnId:   generatePurchaseReturnId(lastId),
+		ReceiptId:  receipt.ReceiptId,
+		RfqId:      receipt.RfqId,
+		VendorId:   receipt.VendorId,
+		ReturnedBy: returnedBy,
+		Note:       note,
+		Auditable:  NewAuditable(),
+	}
+}
+
+func NewPurchaseReturnLine(lastId, returnId string, line PurchaseReturnLine) *PurchaseReturnLine {
+	line.ReturnLineId = generatePurchaseReturnLineId(lastId)
+	line.ReturnId = returnId
+	line.Auditable = NewAuditable()
+	return &line
+}
+
+func NewVendorCreditNote(lastId, vendorId, returnId string, amount float64) *VendorCreditNote {
+	return &VendorCreditNote{
+		CreditNoteId: generateCreditNoteId(lastId),
+		VendorId:     vendorId,
+		ReturnId:     returnId,
+		Amount:       amount,
+		Status:       "open",
+		Auditable:    NewAuditable(),
+	}
+}
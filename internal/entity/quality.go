@@ -0,0 +1,126 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// QualityCheck is a test run on goods when they are received from a vendor
+// (Trigger "receipt") or produced by an MO (Trigger "production"). A check
+// without a material or product applies to every item of its trigger.
+// Measured checks pass when the value lies within MinValue and MaxValue.
+type QualityCheck struct {
+	QualityCheckId string   `json:"id_qualitycheck" gorm:"column:id_qualitycheck;primaryKey"`
+	Name           string   `json:"name" gorm:"column:name"`
+	Trigger        string   `json:"trigger" gorm:"column:trigger"`
+	MaterialId     string   `json:"id_material" gorm:"column:id_material"`
+	ProductId      string   `json:"id_product" gorm:"column:id_product"`
+	MeasureType    string   `json:"measure_type" gorm:"column:measure_type"` // "pass_fail" or "measure"
+	Unit           string   `json:"unit" gorm:"column:unit"`
+	MinValue       *float64 `json:"min_value" gorm:"column:min_value"`
+	MaxValue       *float64 `json:"max_value" gorm:"column:max_value"`
+	Active         bool     `json:"active" gorm:"column:active"`
+	Auditable
+}
+
+// QcInspection covers one received or produced lot. Its quantity stays in
+// quarantine while the inspection is pending or failed, until it is released
+// into stock or rejected.
+type QcInspection struct {
+	InspectionId string     `json:"id_inspection" gorm:"column:id_inspection;primaryKey"`
+	Trigger      string     `json:"trigger" gorm:"column:trigger"`
+	RfqId        string     `json:"id_rfq" gorm:"column:id_rfq"`
+	VendorId     string     `json:"id_vendor" gorm:"column:id_vendor"`
+	MoId         string     `json:"id_mo" gorm:"column:id_mo"`
+	MaterialId   string     `json:"id_material" gorm:"column:id_material"`
+	ProductId    string     `json:"id_product" gorm:"column:id_product"`
+	ItemName     string     `json:"item_name" gorm:"column:item_name"`
+	Quantity     float64    `json:"quantity" gorm:"column:quantity"`
+	Result       string     `json:"result" gorm:"column:result"` // "", "pass" or "fail"
+	Status       string     `json:"status" gorm:"column:status"` // pending, quarantined, released, rejected
+	DecidedBy    string     `json:"decided_by" gorm:"column:decided_by"`
+	DecidedAt    *time.Time `json:"decided_at" gorm:"column:decided_at"`
+	Note         string     `json:"note" gorm:"column:note"`
+	Results      []QcResult `json:"results" gorm:"foreignKey:InspectionId;references:InspectionId"`
+	Auditable
+}
+
+type QcResult struct {
+	ResultId       string   `json:"id_qcresult" gorm:"column:id_qcresult;primaryKey"`
+	InspectionId   string   `json:"id_inspection" gorm:"column:id_inspection"`
+	QualityCheckId string   `json:"id_qualitycheck" gorm:"column:id_qualitycheck"`
+	CheckName      string   `json:"check_name" gorm:"column:check_name"`
+	Passed         bool     `json:"passed" gorm:"column:passed"`
+	MeasuredValue  *float64 `json:"measured_value" gorm:"column:measured_value"`
+	Unit           string   `json:"unit" gorm:"column:unit"`
+	Note           string   `json:"note" gorm:"column:note"`
+	RecordedBy     string   `json:"recorded_by" gorm:"column:recorded_by"`
+	Auditable
+}
+
+func generateQualityCheckId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "QCK-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("QCK-%05d", newNumber)
+}
+
+func generateInspectionId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "QCI-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("QCI-%05d", newNumber)
+}
+
+func generateQcResultId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "QCR-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("QCR-%05d", newNumber)
+}
+
+func NewQualityCheck(lastId string, check QualityCheck) *QualityCheck {
+	check.QualityCheckId = generateQualityCheckId(lastId)
+	check.Auditable = NewAuditable()
+	return &check
+}
+
+func NewQcInspection(lastId, trigger, materialId, productId, itemName string, quantity float64) *QcInspection {
+	return &QcInspection{
+		InspectionId: generateInspectionId(lastId),
+		Trigger:      trigger,
+		MaterialId:   materialId,
+		ProductId:    productId,
+		ItemName:     itemName,
+		Quantity:     quantity,
+		Status:       "pending",
+		Auditable:    NewAuditable(),
+	}
+}
+
+func NewQcResult(lastId, inspectionId string, check QualityCheck, passed bool, measuredValue *float64, note, recordedBy string) *QcResult {
+	return &QcResult{
+		ResultId:       generateQcResultId(lastId),
+		InspectionId:   inspectionId,
+		QualityCheckId: check.QualityCheckId,
+		CheckName:      check.Name,
+		Passed:         passed,
+		MeasuredValue:  measuredValue,
+		Unit:           check.Unit,
+		Note:           note,
+		RecordedBy:     recordedBy,
+		Auditable:      NewAuditable(),
+	}
+}
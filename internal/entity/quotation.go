@@ -3,12 +3,13 @@ package entity
 import "fmt"
 
 type Quotations struct {
-	QuotationsId string              `json:"id_quotation" gorm:"column:id_quotation;primaryKey"`
-	OrderDate    string              `json:"order_date" gorm:"column:order_date"`
-	CostumerId   string              `json:"id_costumer" gorm:"column:id_costumer"`
-	Status       string              `json:"status"`
-	Payment      string              `json:"payment"`
-	Products     []QuotationsProduct `json:"products" gorm:"foreignKey:QuotationsId;references:QuotationsId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;joinTableForeignKey:QuotationsId;table:quotations_products"`
+	QuotationsId string `json:"id_quotation" gorm:"column:id_quotation;primaryKey"`
+	OrderDate    string `json:"order_date" gorm:"column:order_date"`
+	CostumerId   string `json:"id_costumer" gorm:"column:id_costumer"`
+	Status       string `json:"status"`
+	Payment      string `json:"payment"`
+	DocumentTotals
+	Products []QuotationsProduct `json:"products" gorm:"foreignKey:QuotationsId;references:QuotationsId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;joinTableForeignKey:QuotationsId;table:quotations_products"`
 	Auditable
 }
 
@@ -20,8 +21,7 @@ type QuotationsProduct struct {
 	ProductName         string `json:"productname" gorm:"column:productname"`
 	Quantity            string `json:"quantity" gorm:"column:quantity"`
 	UnitPrice           string `json:"unitprice" gorm:"column:unitprice"`
-	Tax                 string `json:"tax" gorm:"column:tax"`
-	Subtotal            string `json:"subtotal" gorm:"column:subtotal"`
+	LineAmounts
 	Auditable
 }
 
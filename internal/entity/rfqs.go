@@ -1,27 +1,78 @@
 package entity
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type Rfqs struct {
-	RfqId     string        `json:"id_rfq" gorm:"column:id_rfq;primaryKey"`
-	OrderDate string        `json:"order_date" gorm:"column:order_date"`
-	VendorId  string        `json:"id_vendor" gorm:"column:id_vendor"`
-	Status    string        `json:"status"`
+	RfqId     string     `json:"id_rfq" gorm:"column:id_rfq;primaryKey"`
+	OrderDate string     `json:"order_date" gorm:"column:order_date"`
+	VendorId  string     `json:"id_vendor" gorm:"column:id_vendor"`
+	Status    string     `json:"status"`
+	TenderId  string     `json:"id_tender" gorm:"column:id_tender"`   // set when the RFQ is a bid in a tender
+	OrderedAt *time.Time `json:"ordered_at" gorm:"column:ordered_at"` // when the RFQ became a purchase order
+	DocumentTotals
 	Products  []RfqsProduct `json:"products" gorm:"foreignKey:RfqId;references:RfqId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;joinTableForeignKey:id_rfq;table:rfqs_products"`
+	Approvals []RfqApproval `json:"approvals" gorm:"foreignKey:RfqId;references:RfqId"` // approval history, oldest first
+	Warnings  []string      `json:"warnings,omitempty" gorm:"-"`                        // lines the price list could not price
 	Auditable
 }
 
 
+// RfqsProduct is one line of an RFQ. LineType says whether it buys the
+// material in MaterialId or the product in ProductId; Unit is the unit of
+// that item the quantity is counted in.
 type RfqsProduct struct {
 	RfqsProductId string `json:"id_rfqproduct" gorm:"column:id_rfqproduct"`
+	LineType      string `json:"line_type" gorm:"column:line_type"` // "material" or "product"
+	MaterialId    string `json:"id_material" gorm:"column:id_material"`
 	ProductId     string `json:"id_product" gorm:"column:id_product"`
 	RfqId         string `json:"id_rfq" gorm:"column:id_rfq"`
 	VendorId      string `json:"id_vendor" gorm:"column:id_vendor"`
 	ProductName   string `json:"productname" gorm:"column:productname"`
 	Quantity      string `json:"quantity" gorm:"column:quantity"`
+	Unit          string `json:"unit" gorm:"column:unit"`
 	UnitPrice     string `json:"unitprice" gorm:"column:unitprice"`
-	Tax           string `json:"tax" gorm:"column:tax"`
-	Subtotal      string `json:"subtotal" gorm:"column:subtotal"`
+	LineAmounts
+	QtyReceived  float64    `json:"qty_received" gorm:"column:qty_received"`
+	QtyCancelled float64    `json:"qty_cancelled" gorm:"column:qty_cancelled"` // backorder nobody waits for any more
+	PromisedDate *time.Time `json:"promised_date" gorm:"column:promised_date"` // delivery date the vendor committed to
+	Auditable
+}
+
+// ItemId is the id of the material or product the line buys.
+func (p RfqsProduct) ItemId() string {
+	if p.LineType == "material" {
+		return p.MaterialId
+	}
+	return p.ProductId
+}
+
+// RfqReceipt is one delivery against a purchase order. A receipt of type
+// "cancellation" closes what was still outstanding instead.
+type RfqReceipt struct {
+	ReceiptId  string           `json:"id_receipt" gorm:"column:id_receipt;primaryKey"`
+	RfqId      string           `json:"id_rfq" gorm:"column:id_rfq"`
+	VendorId   string           `json:"id_vendor" gorm:"column:id_vendor"`
+	Type       string           `json:"type" gorm:"column:type"` // "receipt" or "cancellation"
+	ReceivedBy string           `json:"received_by" gorm:"column:received_by"`
+	Note       string           `json:"note" gorm:"column:note"`
+	Lines      []RfqReceiptLine `json:"lines" gorm:"foreignKey:ReceiptId;references:ReceiptId"`
+	Auditable
+}
+
+// RfqReceiptLine is the quantity of one RFQ line received, or cancelled, by a
+// receipt. InspectionId is set when the goods went to quarantine.
+type RfqReceiptLine struct {
+	ReceiptLineId string  `json:"id_receiptline" gorm:"column:id_receiptline;primaryKey"`
+	ReceiptId     string  `json:"id_receipt" gorm:"column:id_receipt"`
+	RfqsProductId string  `json:"id_rfqproduct" gorm:"column:id_rfqproduct"`
+	ProductId     string  `json:"id_product" gorm:"column:id_product"` // the material or product received
+	ProductName   string  `json:"productname" gorm:"column:productname"`
+	Quantity      float64 `json:"quantity" gorm:"column:quantity"`
+	LotNumber     string  `json:"lot_number" gorm:"column:lot_number"`
+	InspectionId  string  `json:"id_inspection" gorm:"column:id_inspection"`
 	Auditable
 }
 
@@ -62,3 +113,50 @@ func UpdateRfqs(id_rfq, order_date, newStatus, vendorId string, currentStatus st
 		Auditable: UpdateAuditable(),
 	}
 }
+
+func generateRfqReceiptId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "GRN-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("GRN-%05d", newNumber)
+}
+
+func generateRfqReceiptLineId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "GRL-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("GRL-%05d", newNumber)
+}
+
+func NewRfqReceipt(lastId, rfqId, vendorId, receiptType, receivedBy, note string) *RfqReceipt {
+	return &RfqReceipt{
+		ReceiptId:  generateRfqReceiptId(lastId),
+		RfqId:      rfqId,
+		VendorId:   vendorId,
+		Type:       receiptType,
+		ReceivedBy: receivedBy,
+		Note:       note,
+		Auditable:  NewAuditable(),
+	}
+}
+
+func NewRfqReceiptLine(lastId, receiptId string, line RfqsProduct, quantity float64, lotNumber string) *RfqReceiptLine {
+	return &RfqReceiptLine{
+		ReceiptLineId: generateRfqReceiptLineId(lastId),
+		ReceiptId:     receiptId,
+		RfqsProductId: line.RfqsProductId,
+		ProductId:     line.ItemId(),
+		ProductName:   line.ProductName,
+		Quantity:      quantity,
+		LotNumber:     lotNumber,
+		Auditable:     NewAuditable(),
+	}
+}
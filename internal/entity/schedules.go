@@ -1,6 +1,9 @@
 package entity
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -9,6 +12,11 @@ type Schedules struct {
 	Title          string    `json:"title"`
 	Qty_kolam      string    `json:"qty_kolam"`
 	Date_schedules string    `json:"date_schedules"`
+	// Tank bookings: the vessel is occupied from Date_schedules until DateEnd.
+	VesselId     string     `json:"id_vessel" gorm:"column:id_vessel"`
+	MoId         string     `json:"id_mo" gorm:"column:id_mo"`
+	DateEnd      *time.Time `json:"date_end" gorm:"column:date_end"`
+	VolumeLitres float64    `json:"volume_litres" gorm:"column:volume_litres"`
 	Auditable
 }
 
@@ -31,3 +39,17 @@ func UpdateSchedule(id_schedules uuid.UUID, title, qty_kolam, date_schedules str
 		Auditable:      UpdateAuditable(),
 	}
 }
+
+func NewTankBooking(title, vesselId, moId string, start, end time.Time, volumeLitres float64) *Schedules {
+	return &Schedules{
+		SchedulesId:    uuid.New(),
+		Title:          title,
+		Qty_kolam:      fmt.Sprintf("%g", volumeLitres),
+		Date_schedules: start.Format(time.RFC3339),
+		VesselId:       vesselId,
+		MoId:           moId,
+		DateEnd:        &end,
+		VolumeLitres:   volumeLitres,
+		Auditable:      NewAuditable(),
+	}
+}
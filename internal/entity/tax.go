@@ -0,0 +1,56 @@
+package entity
+
+import "fmt"
+
+// Tax is a tax rule RFQ and quotation lines can refer to. Rate is a
+// percentage; a price-included tax is already part of the unit price and is
+// taken out of it instead of being added on top.
+type Tax struct {
+	TaxId         string  `json:"id_tax" gorm:"column:id_tax;primaryKey"`
+	Name          string  `json:"name" gorm:"column:name"`
+	Rate          float64 `json:"rate" gorm:"column:rate"`
+	PriceIncluded bool    `json:"price_included" gorm:"column:price_included"`
+	Active        bool    `json:"active" gorm:"column:active"`
+	Auditable
+}
+
+func generateTaxId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "TAX-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("TAX-%05d", newNumber)
+}
+
+func NewTax(lastId string, tax Tax) *Tax {
+	tax.TaxId = generateTaxId(lastId)
+	tax.Active = true
+	tax.Auditable = NewAuditable()
+	return &tax
+}
+
+// LineAmounts are the priced figures of an RFQ or quotation line. Discount
+// and the tax to apply come from the user; Tax then holds the rate applied in
+// percent, and Subtotal (before tax), TaxAmount and Total are worked out by
+// the server from quantity and unit price.
+type LineAmounts struct {
+	Discount  float64 `json:"discount" gorm:"column:discount"` // percent off the unit price
+	TaxId     string  `json:"id_tax" gorm:"column:id_tax"`
+	Tax       string  `json:"tax" gorm:"column:tax"`
+	Subtotal  string  `json:"subtotal" gorm:"column:subtotal"`
+	TaxAmount float64 `json:"tax_amount" gorm:"column:tax_amount"`
+	Total     float64 `json:"total" gorm:"column:total"`
+}
+
+// DocumentTotals are the amounts of an RFQ or quotation as a whole. With
+// TaxRounding "line" tax is rounded on every line and summed; with
+// "document" it is summed unrounded and rounded once.
+type DocumentTotals struct {
+	TaxRounding   string  `json:"tax_rounding" gorm:"column:tax_rounding"`
+	AmountUntaxed float64 `json:"amount_untaxed" gorm:"column:amount_untaxed"`
+	AmountTax     float64 `json:"amount_tax" gorm:"column:amount_tax"`
+	AmountTotal   float64 `json:"amount_total" gorm:"column:amount_total"`
+}
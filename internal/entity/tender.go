@@ -0,0 +1,147 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tender is a call for tender: the same lines asked from several vendors,
+// each of whom gets an RFQ of their own. It is "Open" while bids come in and
+// becomes "Awarded" or "Cancelled".
+type Tender struct {
+	TenderId  string         `json:"id_tender" gorm:"column:id_tender;primaryKey"`
+	Title     string         `json:"title" gorm:"column:title"`
+	OrderDate string         `json:"order_date" gorm:"column:order_date"`
+	Deadline  *time.Time     `json:"deadline" gorm:"column:deadline"`
+	Status    string         `json:"status" gorm:"column:status"`
+	Lines     []TenderLine   `json:"lines" gorm:"foreignKey:TenderId;references:TenderId"`
+	Vendors   []TenderVendor `json:"vendors" gorm:"foreignKey:TenderId;references:TenderId"`
+	Auditable
+}
+
+// TenderLine is one material or product asked for in a tender.
+type TenderLine struct {
+	TenderLineId string  `json:"id_tenderline" gorm:"column:id_tenderline;primaryKey"`
+	TenderId     string  `json:"id_tender" gorm:"column:id_tender"`
+	ItemId       string  `json:"id_item" gorm:"column:id_item"`
+	ItemName     string  `json:"item_name" gorm:"column:item_name"`
+	Quantity     float64 `json:"quantity" gorm:"column:quantity"`
+	Auditable
+}
+
+// TenderVendor is a vendor invited to a tender, with the RFQ sent to them.
+// Status goes "invited", "sent", "bid" and ends "awarded" or "lost", or
+// "cancelled" with the tender.
+type TenderVendor struct {
+	TenderVendorId string     `json:"id_tendervendor" gorm:"column:id_tendervendor;primaryKey"`
+	TenderId       string     `json:"id_tender" gorm:"column:id_tender"`
+	VendorId       string     `json:"id_vendor" gorm:"column:id_vendor"`
+	RfqId          string     `json:"id_rfq" gorm:"column:id_rfq"`
+	Status         string     `json:"status" gorm:"column:status"`
+	SentAt         *time.Time `json:"sent_at" gorm:"column:sent_at"`
+	Auditable
+}
+
+// TenderBid is what a vendor quoted for one tender line.
+type TenderBid struct {
+	BidId        string  `json:"id_bid" gorm:"column:id_bid;primaryKey"`
+	TenderId     string  `json:"id_tender" gorm:"column:id_tender"`
+	TenderLineId string  `json:"id_tenderline" gorm:"column:id_tenderline"`
+	VendorId     string  `json:"id_vendor" gorm:"column:id_vendor"`
+	UnitPrice    float64 `json:"unit_price" gorm:"column:unit_price"`
+	LeadTimeDays int     `json:"lead_time_days" gorm:"column:lead_time_days"`
+	Note         string  `json:"note" gorm:"column:note"`
+	Status       string  `json:"status" gorm:"column:status"` // "submitted", "awarded" or "lost"
+	Auditable
+}
+
+func generateTenderId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "TND-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("TND-%05d", newNumber)
+}
+
+func generateTenderLineId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "TNL-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("TNL-%05d", newNumber)
+}
+
+func generateTenderVendorId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "TNV-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("TNV-%05d", newNumber)
+}
+
+func generateTenderBidId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "TBD-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("TBD-%05d", newNumber)
+}
+
+func NewTender(lastId, title, orderDate string, deadline *time.Time) *Tender {
+	return &Tender{
+		TenderId:  generateTenderId(lastId),
+		Title:     title,
+		OrderDate: orderDate,
+		Deadline:  deadline,
+		Status:    "Open",
+		Auditable: NewAuditable(),
+	}
+}
+
+func NewTenderLine(lastId, tenderId, itemId, itemName string, quantity float64) *TenderLine {
+	return &TenderLine{
+		TenderLineId: generateTenderLineId(lastId),
+		TenderId:     tenderId,
+		ItemId:       itemId,
+		ItemName:     itemName,
+		Quantity:     quantity,
+		Auditable:    NewAuditable(),
+	}
+}
+
+func NewTenderVendor(lastId, tenderId, vendorId, rfqId string) *TenderVendor {
+	return &TenderVendor{
+		TenderVendorId: generateTenderVendorId(lastId),
+		TenderId:       tenderId,
+		VendorId:       vendorId,
+		RfqId:          rfqId,
+		Status:         "invited",
+		Auditable:      NewAuditable(),
+	}
+}
+
+func NewTenderBid(lastId, tenderId, tenderLineId, vendorId string, unitPrice float64, leadTimeDays int, note string) *TenderBid {
+	return &TenderBid{
+		BidId:        generateTenderBidId(lastId),
+		TenderId:     tenderId,
+		TenderLineId: tenderLineId,
+		VendorId:     vendorId,
+		UnitPrice:    unitPrice,
+		LeadTimeDays: leadTimeDays,
+		Note:         note,
+		Status:       "submitted",
+		Auditable:    NewAuditable(),
+	}
+}
@@ -17,6 +17,10 @@ type Vendors struct {
 	City       string `json:"city"`
 	Zip        string `json:"zip"`
 	Country    string `json:"country"`
+	// Payment terms: UpfrontPercent of a bill is due on the bill date and the
+	// rest PaymentTermDays later, so "net 30" is 0 and 30.
+	PaymentTermDays int     `json:"payment_term_days" gorm:"column:payment_term_days"`
+	UpfrontPercent  float64 `json:"upfront_percent" gorm:"column:upfront_percent"`
 	Auditable
 }
 
@@ -0,0 +1,34 @@
+package entity
+
+import "fmt"
+
+// Vessel is a maceration tank. A tank holds one batch at a time, and the
+// batch volume may not exceed CapacityLitres.
+type Vessel struct {
+	VesselId       string  `json:"id_vessel" gorm:"column:id_vessel;primaryKey"`
+	Name           string  `json:"name" gorm:"column:name"`
+	CapacityLitres float64 `json:"capacity_litres" gorm:"column:capacity_litres"`
+	Description    string  `json:"description" gorm:"column:description"`
+	Auditable
+}
+
+func generateVesselId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "TNK-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("TNK-%05d", newNumber)
+}
+
+func NewVessel(lastId, name string, capacityLitres float64, description string) *Vessel {
+	return &Vessel{
+		VesselId:       generateVesselId(lastId),
+		Name:           name,
+		CapacityLitres: capacityLitres,
+		Description:    description,
+		Auditable:      NewAuditable(),
+	}
+}
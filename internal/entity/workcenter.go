@@ -0,0 +1,73 @@
+package entity
+
+import "fmt"
+
+// WorkCenter is a production station. Capacity is how many work orders it can
+// run at the same time.
+type WorkCenter struct {
+	WorkCenterId string  `json:"id_workcenter" gorm:"column:id_workcenter;primaryKey"`
+	Name         string  `json:"name" gorm:"column:name"`
+	Capacity     float64 `json:"capacity" gorm:"column:capacity"`
+	HourlyCost   float64 `json:"hourly_cost" gorm:"column:hourly_cost"`
+	Description  string  `json:"description" gorm:"column:description"`
+	Auditable
+}
+
+// RoutingOperation is one step of a BOM's routing. The planned time of an MO
+// is DurationMinutes plus MinutesPerUnit for every unit produced.
+type RoutingOperation struct {
+	OperationId     string  `json:"id_operation" gorm:"column:id_operation;primaryKey"`
+	BomId           string  `json:"id_bom" gorm:"column:id_bom"`
+	Sequence        int     `json:"sequence" gorm:"column:sequence"`
+	Name            string  `json:"name" gorm:"column:name"`
+	WorkCenterId    string  `json:"id_workcenter" gorm:"column:id_workcenter"`
+	DurationMinutes float64 `json:"duration_minutes" gorm:"column:duration_minutes"`
+	MinutesPerUnit  float64 `json:"minutes_per_unit" gorm:"column:minutes_per_unit"`
+	Auditable
+}
+
+func generateWorkCenterId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "WC-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("WC-%05d", newNumber)
+}
+
+func generateOperationId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "OPR-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("OPR-%05d", newNumber)
+}
+
+func NewWorkCenter(lastId, name string, capacity, hourlyCost float64, description string) *WorkCenter {
+	return &WorkCenter{
+		WorkCenterId: generateWorkCenterId(lastId),
+		Name:         name,
+		Capacity:     capacity,
+		HourlyCost:   hourlyCost,
+		Description:  description,
+		Auditable:    NewAuditable(),
+	}
+}
+
+func NewRoutingOperation(lastId, bomId string, sequence int, name, workCenterId string, durationMinutes, minutesPerUnit float64) *RoutingOperation {
+	return &RoutingOperation{
+		OperationId:     generateOperationId(lastId),
+		BomId:           bomId,
+		Sequence:        sequence,
+		Name:            name,
+		WorkCenterId:    workCenterId,
+		DurationMinutes: durationMinutes,
+		MinutesPerUnit:  minutesPerUnit,
+		Auditable:       NewAuditable(),
+	}
+}
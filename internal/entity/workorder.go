@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// WorkOrder is one routing operation carried out for an MO. DurationMinutes
+// is the time actually spent, summed from its logs.
+type WorkOrder struct {
+	WorkOrderId     string         `json:"id_workorder" gorm:"column:id_workorder;primaryKey"`
+	MoId            string         `json:"id_mo" gorm:"column:id_mo"`
+	OperationId     string         `json:"id_operation" gorm:"column:id_operation"`
+	Sequence        int            `json:"sequence" gorm:"column:sequence"`
+	Name            string         `json:"name" gorm:"column:name"`
+	WorkCenterId    string         `json:"id_workcenter" gorm:"column:id_workcenter"`
+	PlannedMinutes  float64        `json:"planned_minutes" gorm:"column:planned_minutes"`
+	DurationMinutes float64        `json:"duration_minutes" gorm:"column:duration_minutes"`
+	Status          string         `json:"status"`
+	StartedAt       *time.Time     `json:"started_at" gorm:"column:started_at"`
+	FinishedAt      *time.Time     `json:"finished_at" gorm:"column:finished_at"`
+	PlannedStart    *time.Time     `json:"planned_start" gorm:"column:planned_start"`
+	PlannedEnd      *time.Time     `json:"planned_end" gorm:"column:planned_end"`
+	Logs            []WorkOrderLog `json:"logs" gorm:"foreignKey:WorkOrderId;references:WorkOrderId"`
+	Auditable
+}
+
+// WorkOrderLog is a stretch of work by one operator, from start or resume
+// until pause or finish.
+type WorkOrderLog struct {
+	LogId       string     `json:"id_workorderlog" gorm:"column:id_workorderlog;primaryKey"`
+	WorkOrderId string     `json:"id_workorder" gorm:"column:id_workorder"`
+	Operator    string     `json:"operator" gorm:"column:operator"`
+	StartedAt   time.Time  `json:"started_at" gorm:"column:started_at"`
+	EndedAt     *time.Time `json:"ended_at" gorm:"column:ended_at"`
+	Minutes     float64    `json:"minutes" gorm:"column:minutes"`
+	Auditable
+}
+
+func generateWorkOrderId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "WO-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("WO-%05d", newNumber)
+}
+
+func generateWorkOrderLogId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "WOL-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("WOL-%05d", newNumber)
+}
+
+func NewWorkOrder(lastId, moId string, operation RoutingOperation, plannedMinutes float64) *WorkOrder {
+	return &WorkOrder{
+		WorkOrderId:    generateWorkOrderId(lastId),
+		MoId:           moId,
+		OperationId:    operation.OperationId,
+		Sequence:       operation.Sequence,
+		Name:           operation.Name,
+		WorkCenterId:   operation.WorkCenterId,
+		PlannedMinutes: plannedMinutes,
+		Status:         "pending",
+		Auditable:      NewAuditable(),
+	}
+}
+
+func NewWorkOrderLog(lastId, workOrderId, operator string, startedAt time.Time) *WorkOrderLog {
+	return &WorkOrderLog{
+		LogId:       generateWorkOrderLogId(lastId),
+		WorkOrderId: workOrderId,
+		Operator:    operator,
+		StartedAt:   startedAt,
+		Auditable:   NewAuditable(),
+	}
+}
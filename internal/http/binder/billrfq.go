@@ -1,7 +1,41 @@
 package binder
 
 type BillRfqCreateRequest struct {
-	VendorId  string `json:"vendorId" validate:"required"`
-	Bill_date string `json:"bill_date" validate:"required"`
-	Payment   string `json:"payment" validate:"required"`
+	VendorId  string            `json:"vendorId" validate:"required"`
+	Bill_date string            `json:"bill_date" validate:"required"`
+	Payment   string            `json:"payment" validate:"required"`
+	Lines     []BillLineRequest `json:"lines" validate:"required,min=1,dive"`
+}
+
+type BillLineRequest struct {
+	RfqId         string  `json:"id_rfq" validate:"required"`
+	RfqsProductId string  `json:"id_rfqproduct" validate:"required"`
+	Quantity      float64 `json:"quantity" validate:"gt=0"`
+	UnitPrice     float64 `json:"unit_price" validate:"gte=0"`
+}
+
+type BillLinesUpdateRequest struct {
+	BillId string            `param:"id_bill" validate:"required"`
+	Lines  []BillLineRequest `json:"lines" validate:"required,min=1,dive"`
+}
+
+type BillLineResolveRequest struct {
+	BillId     string `param:"id_bill" validate:"required"`
+	BillLineId string `param:"id_billline" validate:"required"`
+	ResolvedBy string `json:"resolved_by" validate:"required"`
+	Note       string `json:"note" validate:"required"`
+}
+
+type BillToleranceRequest struct {
+	VendorId     string  `json:"id_vendor"`
+	QtyPercent   float64 `json:"qty_percent" validate:"gte=0"`
+	PricePercent float64 `json:"price_percent" validate:"gte=0"`
+}
+
+type BillPaymentRequest struct {
+	BillId      string  `param:"id_bill" validate:"required"`
+	Amount      float64 `json:"amount" validate:"gt=0"`
+	PaymentDate string  `json:"payment_date"`
+	Method      string  `json:"method"`
+	Reference   string  `json:"reference"`
 }
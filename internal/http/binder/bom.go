@@ -7,6 +7,10 @@ type BOMCreateRequest struct {
 	ProductName      string            `json:"productname"`
 	ProductReference string            `json:"productpreference"`
 	Quantity         string            `json:"quantity"`
+	EffectiveFrom    string            `json:"effective_from"` // YYYY-MM-DD, defaults to now
+	ChangeNote       string            `json:"change_note"`
+	MacerationDays   int               `json:"maceration_days"`
+	LitresPerUnit    float64           `json:"litres_per_unit"`
 	Materials        []MaterialRequest `json:"materials"`
 }
 
@@ -16,12 +20,17 @@ type BOMUpdateRequest struct {
     ProductName      string            `json:"productname"`     // Name of the product
     ProductReference string            `json:"productpreference"` // Reference for the product
     Quantity         string            `json:"quantity"`        // Quantity of the product
+    EffectiveFrom    string            `json:"effective_from"`  // Date the new version applies from (YYYY-MM-DD)
+    ChangeNote       string            `json:"change_note"`     // Why the formula changed
+    MacerationDays   int               `json:"maceration_days"` // Days the batch rests in a tank
+    LitresPerUnit    float64           `json:"litres_per_unit"` // Tank volume per unit produced
     Materials        []MaterialRequest `json:"materials"`       // List of materials for the BoM
 }
 
 type MaterialRequest struct {
 	IdBomMaterial string `json:"id_bommaterial"`
 	IdMaterial   string `json:"id_material"`
+	IdProduct    string `json:"id_product"`
 	MaterialName string `json:"material_name"`
 	Quantity     string `json:"quantity"`
 	Unit         string `json:"unit"`
@@ -33,3 +42,18 @@ type BomDeleteRequest struct {
 	BomId string `param:"id_bom" validate:"required"`
 }
 
+type BomDiffRequest struct {
+	BomId string `param:"id_bom" validate:"required"`
+	From  int    `query:"from" validate:"required"`
+	To    int    `query:"to" validate:"required"`
+}
+
+type BomSimulationRequest struct {
+	Overrides []MaterialPriceOverrideRequest `json:"overrides" validate:"required,min=1,dive"`
+}
+
+type MaterialPriceOverrideRequest struct {
+	MaterialId    string   `json:"id_material" validate:"required"`
+	Price         *float64 `json:"price"`          // new make price
+	ChangePercent *float64 `json:"change_percent"` // e.g. 30 for +30%
+}
@@ -0,0 +1,27 @@
+package binder
+
+type MaterialConstituentsRequest struct {
+	MaterialId   string                       `param:"id_material" validate:"required"`
+	Constituents []MaterialConstituentRequest `json:"constituents" validate:"dive"`
+}
+
+type MaterialConstituentRequest struct {
+	Substance  string  `json:"substance" validate:"required"`
+	CasNumber  string  `json:"cas_number"`
+	Percentage float64 `json:"percentage" validate:"required,gt=0,lte=100"` // % by weight of the material
+	Allergen   bool    `json:"allergen"`
+}
+
+type ComplianceLimitRequest struct {
+	ComplianceLimitId string  `param:"id_compliancelimit"`
+	Type              string  `json:"type" validate:"required"` // restriction or label
+	Substance         string  `json:"substance"`
+	CasNumber         string  `json:"cas_number"`
+	ProductCategory   string  `json:"product_category"` // empty for every category
+	MaxPercent        float64 `json:"max_percent" validate:"required,gt=0"`
+	Source            string  `json:"source"` // e.g. IFRA 51st amendment
+}
+
+type ComplianceLimitIdRequest struct {
+	ComplianceLimitId string `param:"id_compliancelimit" validate:"required"`
+}
@@ -13,3 +13,81 @@ type UpdateMoStatusRequest struct {
 type MoDeleteRequest struct {
 	MoId string `param:"id_mo" validate:"required"`
 }
+
+type ShortageRfqRequest struct {
+	VendorId  string `json:"id_vendor" validate:"required"`
+	OrderDate string `json:"order_date" validate:"required"`
+}
+
+type BookTankRequest struct {
+	MoId     string `param:"id_mo" validate:"required"`
+	VesselId string `json:"id_vessel" validate:"required"`
+	Start    string `json:"start" validate:"required"` // YYYY-MM-DD
+}
+
+type WorkOrderRequest struct {
+	WorkOrderId string `param:"id_workorder" validate:"required"`
+	Operator    string `json:"operator"`
+}
+
+type ProduceMoRequest struct {
+	MoId         string                     `param:"id_mo" validate:"required"`
+	Quantity     float64                    `json:"quantity" validate:"required,gt=0"`
+	ProducedBy   string                     `json:"produced_by"`
+	Note         string                     `json:"note"`
+	Consumptions []ActualConsumptionRequest `json:"consumptions" validate:"dive"` // actual usage, defaults to the BOM share
+}
+
+type ActualConsumptionRequest struct {
+	IdMaterial string  `json:"id_material"`
+	IdProduct  string  `json:"id_product"`
+	Quantity   float64 `json:"quantity" validate:"gte=0"`
+	LotNumber  string  `json:"lot_number"`
+}
+
+type VarianceReportRequest struct {
+	GroupBy  string `query:"group_by"` // mo, product (default) or material
+	Interval string `query:"interval"` // week or month, empty for one total
+	From     string `query:"from"`     // YYYY-MM-DD
+	To       string `query:"to"`       // YYYY-MM-DD, inclusive
+}
+
+type CloseMoRequest struct {
+	MoId      string `param:"id_mo" validate:"required"`
+	Backorder bool   `json:"backorder"` // create an MO for the quantity not produced
+}
+
+type CancelMoRequest struct {
+	MoId        string `param:"id_mo" validate:"required"`
+	PerformedBy string `json:"performed_by" validate:"required"`
+	Reason      string `json:"reason" validate:"required"`
+}
+
+type UnbuildMoRequest struct {
+	MoId        string  `param:"id_mo" validate:"required"`
+	Quantity    float64 `json:"quantity" validate:"gte=0"` // 0 unbuilds everything left
+	PerformedBy string  `json:"performed_by" validate:"required"`
+	Reason      string  `json:"reason" validate:"required"`
+}
+
+type GanttRequest struct {
+	From string `query:"from"` // YYYY-MM-DD, defaults to today
+	To   string `query:"to"`   // YYYY-MM-DD, inclusive, defaults to 14 days after from
+}
+
+type ScheduleMoRequest struct {
+	MoId         string `param:"id_mo" validate:"required"`
+	Direction    string `json:"direction" validate:"required,oneof=forward backward"`
+	Start        string `json:"start"`         // earliest start for forward scheduling, defaults to now
+	RequiredDate string `json:"required_date"` // delivery date, needed for backward scheduling
+}
+
+type RescheduleMoRequest struct {
+	MoId         string `param:"id_mo" validate:"required"`
+	PlannedStart string `json:"planned_start" validate:"required"` // RFC 3339 or YYYY-MM-DD HH:MM
+}
+
+type RescheduleWorkOrderRequest struct {
+	WorkOrderId  string `param:"id_workorder" validate:"required"`
+	PlannedStart string `json:"planned_start" validate:"required"` // RFC 3339 or YYYY-MM-DD HH:MM
+}
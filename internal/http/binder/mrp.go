@@ -0,0 +1,33 @@
+package binder
+
+type MrpRunRequest struct {
+	RunId string `param:"id_mrprun" validate:"required"`
+}
+
+type MrpProposalUpdateRequest struct {
+	ProposalId string  `param:"id_mrpproposal" validate:"required"`
+	Quantity   float64 `json:"quantity"`
+	OrderDate  string  `json:"order_date"` // YYYY-MM-DD
+	DueDate    string  `json:"due_date"`   // YYYY-MM-DD
+	VendorId   string  `json:"id_vendor"`
+	Status     string  `json:"status"` // proposed or rejected
+}
+
+type MrpConfirmRequest struct {
+	RunId       string   `param:"id_mrprun" validate:"required"`
+	ProposalIds []string `json:"id_proposals"` // empty confirms every open proposal
+}
+
+type ReorderRuleRequest struct {
+	RuleId       string  `param:"id_reorderrule"`
+	MaterialId   string  `json:"id_material"`
+	ProductId    string  `json:"id_product"`
+	MinQty       float64 `json:"min_qty"`
+	MaxQty       float64 `json:"max_qty"`
+	LeadTimeDays int     `json:"lead_time_days"`
+	VendorId     string  `json:"id_vendor"`
+}
+
+type ReorderRuleDeleteRequest struct {
+	RuleId string `param:"id_reorderrule" validate:"required"`
+}
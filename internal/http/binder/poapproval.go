@@ -0,0 +1,17 @@
+package binder
+
+type PoApprovalRuleRequest struct {
+	ApprovalRuleId string  `param:"id_approvalrule"`
+	Name           string  `json:"name" validate:"required"`
+	MinAmount      float64 `json:"min_amount" validate:"gte=0"`
+	VendorId       string  `json:"id_vendor"`
+	Category       string  `json:"category"`
+	ApproverName   string  `json:"approver_name" validate:"required"`
+	ApproverEmail  string  `json:"approver_email" validate:"required,email"`
+	Active         *bool   `json:"active"`
+}
+
+type PoApprovalDecisionRequest struct {
+	RfqApprovalId string `param:"id_rfqapproval"`
+	Note          string `json:"note"`
+}
@@ -0,0 +1,20 @@
+package binder
+
+type VendorPriceRequest struct {
+	VendorPriceId string  `param:"id_vendorprice"`
+	VendorId      string  `json:"id_vendor"`
+	ItemId        string  `json:"id_item"`
+	MinQty        float64 `json:"min_qty" validate:"gte=0"`
+	UnitPrice     float64 `json:"unit_price" validate:"gte=0"`
+	Currency      string  `json:"currency"`
+	ValidFrom     string  `json:"valid_from"`
+	ValidTo       string  `json:"valid_to"`
+	LeadTimeDays  int     `json:"lead_time_days" validate:"gte=0"`
+}
+
+type PriceQuoteRequest struct {
+	VendorId string  `query:"id_vendor" validate:"required"`
+	ItemId   string  `query:"id_item" validate:"required"`
+	Quantity float64 `query:"quantity" validate:"gt=0"`
+	Date     string  `query:"date"`
+}
@@ -0,0 +1,15 @@
+package binder
+
+type PurchaseReturnRequest struct {
+	ReceiptId  string                      `json:"id_receipt" validate:"required"`
+	ReturnedBy string                      `json:"returned_by" validate:"required"`
+	Note       string                      `json:"note"`
+	Lines      []PurchaseReturnLineRequest `json:"lines" validate:"required,min=1,dive"`
+}
+
+type PurchaseReturnLineRequest struct {
+	ReceiptLineId string  `json:"id_receiptline" validate:"required"`
+	Quantity      float64 `json:"quantity" validate:"gt=0"`
+	Reason        string  `json:"reason" validate:"required"`
+	ReasonNote    string  `json:"reason_note"`
+}
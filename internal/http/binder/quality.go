@@ -0,0 +1,43 @@
+package binder
+
+type QualityCheckRequest struct {
+	QualityCheckId string   `param:"id_qualitycheck"`
+	Name           string   `json:"name" validate:"required"`
+	Trigger        string   `json:"trigger" validate:"required"` // receipt or production
+	MaterialId     string   `json:"id_material"`
+	ProductId      string   `json:"id_product"`
+	MeasureType    string   `json:"measure_type"` // pass_fail (default) or measure
+	Unit           string   `json:"unit"`
+	MinValue       *float64 `json:"min_value"`
+	MaxValue       *float64 `json:"max_value"`
+	Active         *bool    `json:"active"` // defaults to true
+}
+
+type QualityCheckIdRequest struct {
+	QualityCheckId string `param:"id_qualitycheck" validate:"required"`
+}
+
+type QcResultsRequest struct {
+	InspectionId string            `param:"id_inspection" validate:"required"`
+	RecordedBy   string            `json:"recorded_by" validate:"required"`
+	Results      []QcResultRequest `json:"results" validate:"required,dive"`
+}
+
+type QcResultRequest struct {
+	QualityCheckId string   `json:"id_qualitycheck" validate:"required"`
+	Passed         *bool    `json:"passed"`
+	MeasuredValue  *float64 `json:"measured_value"`
+	Note           string   `json:"note"`
+}
+
+type QcDecisionRequest struct {
+	InspectionId string `param:"id_inspection" validate:"required"`
+	DecidedBy    string `json:"decided_by" validate:"required"`
+	Note         string `json:"note"`
+}
+
+type QcFailureReportRequest struct {
+	GroupBy string `query:"group_by"` // vendor (default) or product
+	From    string `query:"from"`     // YYYY-MM-DD
+	To      string `query:"to"`       // YYYY-MM-DD, inclusive
+}
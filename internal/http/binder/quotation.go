@@ -6,6 +6,7 @@ type QUOCreateRequest struct {
 	Status       string           `json:"status"`
 	CostumerId   string           `json:"id_costumer"`
 	Payment      string           `json:"payment"`
+	TaxRounding  string           `json:"tax_rounding"`
 	Products     []ProductRequest `json:"products"`
 }
 
@@ -16,18 +17,20 @@ type QUOUpdateRequest struct {
 	CostumerId   string              `json:"id_costumer"`
 	Status       string              `json:"status"`
 	Payment      string              `json:"payment"`
+	TaxRounding  string              `json:"tax_rounding"`
 	Products     []QUOProductRequest `json:"products"`
 }
 
 type QUOProductRequest struct {
-	QuotationsProductId string `json:"id_quotationsproduct"`
-	ProductId           string `json:"id_product"`
-	CostumerId          string `json:"id_costumer"`
-	ProductName         string `json:"productname"`
-	Quantity            string `json:"quantity"`
-	UnitPrice           string `json:"unitprice"`
-	Tax                 string `json:"tax"`
-	Subtotal            string `json:"subtotal"`
+	QuotationsProductId string  `json:"id_quotationsproduct"`
+	ProductId           string  `json:"id_product"`
+	CostumerId          string  `json:"id_costumer"`
+	ProductName         string  `json:"productname"`
+	Quantity            string  `json:"quantity"`
+	UnitPrice           string  `json:"unitprice"`
+	Discount            float64 `json:"discount"`
+	TaxId               string  `json:"id_tax"`
+	Tax                 string  `json:"tax"` // tax rate in percent, used when no id_tax is given
 }
 
 type UpdateQuoStatusRequest struct {
@@ -1,31 +1,37 @@
 package binder
 
 type RFQCreateRequest struct {
-	RfqId     string           `json:"id_rfq"`
-	OrderDate string           `json:"order_date"`
-	Status    string           `json:"status"`
-	VendorId  string           `json:"id_vendor"`
-	Products  []ProductRequest `json:"products"`
+	RfqId       string           `json:"id_rfq"`
+	OrderDate   string           `json:"order_date"`
+	Status      string           `json:"status"`
+	VendorId    string           `json:"id_vendor"`
+	TaxRounding string           `json:"tax_rounding"`
+	Products    []ProductRequest `json:"products"`
 }
 
 type RFQUpdateRequest struct {
-	RfqId     string           `json:"id_rfq"`
-	ProductId string           `json:"id_product"`
-	OrderDate string           `json:"order_date"`
-	VendorId  string           `json:"id_vendor"`
-	Status    string           `json:"status"`
-	Products  []ProductRequest `json:"products"`
+	RfqId       string           `json:"id_rfq"`
+	ProductId   string           `json:"id_product"`
+	OrderDate   string           `json:"order_date"`
+	VendorId    string           `json:"id_vendor"`
+	Status      string           `json:"status" validate:"omitempty,oneof=RFQ 'Purchase Order' Cancelled"`
+	TaxRounding string           `json:"tax_rounding"`
+	Products    []ProductRequest `json:"products"`
 }
 
 type ProductRequest struct {
-	RfqsProductId string `json:"id_rfqproduct"`
-	ProductId     string `json:"id_product"`
-	VendorId      string `json:"id_vendor"`
-	ProductName   string `json:"productname"`
-	Quantity      string `json:"quantity"`
-	UnitPrice     string `json:"unitprice"`
-	Tax           string `json:"tax"`
-	Subtotal      string `json:"subtotal"`
+	RfqsProductId string  `json:"id_rfqproduct"`
+	LineType      string  `json:"line_type"`
+	ProductId     string  `json:"id_product"`
+	MaterialId    string  `json:"id_material"`
+	VendorId      string  `json:"id_vendor"`
+	ProductName   string  `json:"productname"`
+	Quantity      string  `json:"quantity"`
+	UnitPrice     string  `json:"unitprice"`
+	Discount      float64 `json:"discount"`
+	TaxId         string  `json:"id_tax"`
+	Tax           string  `json:"tax"` // tax rate in percent, used when no id_tax is given
+	PromisedDate  string  `json:"promised_date"`
 }
 
 type UpdateRfqStatusRequest struct {
@@ -35,3 +41,23 @@ type UpdateRfqStatusRequest struct {
 type RFQDeleteRequest struct {
 	RfqId string `param:"id_rfq" validate:"required"`
 }
+
+type RfqReceiptRequest struct {
+	RfqId      string                  `param:"id_rfq" validate:"required"`
+	ReceivedBy string                  `json:"received_by"`
+	Note       string                  `json:"note"`
+	Lines      []RfqReceiptLineRequest `json:"lines" validate:"required,min=1,dive"`
+}
+
+type RfqReceiptLineRequest struct {
+	RfqsProductId string  `json:"id_rfqproduct" validate:"required"`
+	Quantity      float64 `json:"quantity" validate:"gt=0"`
+	LotNumber     string  `json:"lot_number"`
+}
+
+type CancelBackorderRequest struct {
+	RfqId       string   `param:"id_rfq" validate:"required"`
+	CancelledBy string   `json:"cancelled_by" validate:"required"`
+	Reason      string   `json:"reason" validate:"required"`
+	Lines       []string `json:"lines"`
+}
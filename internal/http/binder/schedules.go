@@ -4,6 +4,10 @@ type SchedulesCreateRequest struct {
 	Title          string `json:"title" validate:"required"`
 	Qty_kolam      string `json:"qty_kolam" validate:"required,email"`
 	Date_schedules string `json:"date_schedules" validate:"required"`
+	// Optional: book a tank from date_schedules until date_end (YYYY-MM-DD).
+	VesselId     string  `json:"id_vessel"`
+	DateEnd      string  `json:"date_end"`
+	VolumeLitres float64 `json:"volume_litres"`
 }
 
 type SchedulesUpdateRequest struct {
@@ -0,0 +1,9 @@
+package binder
+
+type TaxRequest struct {
+	TaxId         string  `param:"id_tax"`
+	Name          string  `json:"name" validate:"required"`
+	Rate          float64 `json:"rate" validate:"gte=0"`
+	PriceIncluded bool    `json:"price_included"`
+	Active        *bool   `json:"active"`
+}
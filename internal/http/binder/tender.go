@@ -0,0 +1,33 @@
+package binder
+
+type TenderLineRequest struct {
+	ItemId   string  `json:"id_item" validate:"required"`
+	Quantity float64 `json:"quantity" validate:"gt=0"`
+}
+
+type TenderCreateRequest struct {
+	Title     string              `json:"title" validate:"required"`
+	OrderDate string              `json:"order_date" validate:"required"`
+	Deadline  string              `json:"deadline"`
+	Vendors   []string            `json:"vendors" validate:"required,min=2"`
+	Lines     []TenderLineRequest `json:"lines" validate:"required,min=1,dive"`
+}
+
+type TenderBidLineRequest struct {
+	TenderLineId string  `json:"id_tenderline" validate:"required"`
+	UnitPrice    float64 `json:"unit_price" validate:"gte=0"`
+	LeadTimeDays int     `json:"lead_time_days" validate:"gte=0"`
+	Note         string  `json:"note"`
+}
+
+type TenderBidRequest struct {
+	TenderId string                 `param:"id_tender"`
+	VendorId string                 `json:"id_vendor" validate:"required"`
+	Bids     []TenderBidLineRequest `json:"bids" validate:"required,min=1,dive"`
+}
+
+type TenderAwardRequest struct {
+	TenderId string `param:"id_tender"`
+	// Awards maps each awarded tender line to the winning vendor.
+	Awards map[string]string `json:"awards" validate:"required"`
+}
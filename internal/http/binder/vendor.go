@@ -32,3 +32,15 @@ type VendorUpdateRequest struct {
 type VendorDeleteRequest struct {
 	VendorId string `param:"id_vendor" validate:"required"`
 }
+
+type VendorPaymentTermsRequest struct {
+	VendorId        string  `param:"id_vendor" validate:"required"`
+	PaymentTermDays int     `json:"payment_term_days" validate:"gte=0"`
+	UpfrontPercent  float64 `json:"upfront_percent" validate:"gte=0,lte=100"`
+}
+
+type VendorScorecardRequest struct {
+	VendorId string `param:"id_vendor"`
+	From     string `query:"from"`
+	To       string `query:"to"`
+}
@@ -0,0 +1,23 @@
+package binder
+
+type VesselRequest struct {
+	VesselId       string  `param:"id_vessel"`
+	Name           string  `json:"name" validate:"required"`
+	CapacityLitres float64 `json:"capacity_litres" validate:"required"`
+	Description    string  `json:"description"`
+}
+
+type VesselIdRequest struct {
+	VesselId string `param:"id_vessel" validate:"required"`
+}
+
+type TankCalendarRequest struct {
+	From string `query:"from"` // YYYY-MM-DD, defaults to today
+	To   string `query:"to"`   // YYYY-MM-DD, defaults to 30 days after from
+}
+
+type TankSlotRequest struct {
+	Litres float64 `query:"litres"`
+	Days   int     `query:"days" validate:"required"`
+	From   string  `query:"from"` // YYYY-MM-DD, defaults to today
+}
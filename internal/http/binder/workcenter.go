@@ -0,0 +1,26 @@
+package binder
+
+type WorkCenterRequest struct {
+	WorkCenterId string  `param:"id_workcenter"`
+	Name         string  `json:"name" validate:"required"`
+	Capacity     float64 `json:"capacity"` // parallel work orders, defaults to 1
+	HourlyCost   float64 `json:"hourly_cost"`
+	Description  string  `json:"description"`
+}
+
+type WorkCenterIdRequest struct {
+	WorkCenterId string `param:"id_workcenter" validate:"required"`
+}
+
+type RoutingRequest struct {
+	BomId      string                    `param:"id_bom" validate:"required"`
+	Operations []RoutingOperationRequest `json:"operations" validate:"dive"`
+}
+
+type RoutingOperationRequest struct {
+	Sequence        int     `json:"sequence"`
+	Name            string  `json:"name" validate:"required"`
+	WorkCenterId    string  `json:"id_workcenter" validate:"required"`
+	DurationMinutes float64 `json:"duration_minutes"`
+	MinutesPerUnit  float64 `json:"minutes_per_unit"`
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
@@ -18,14 +20,31 @@ func NewBillrfqHandler(billrfqService service.BillrfqService) BillrfqHandler {
 	return BillrfqHandler{billrfqService: billrfqService}
 }
 
+func billLines(input []binder.BillLineRequest) []entity.BillLine {
+	lines := make([]entity.BillLine, 0, len(input))
+	for _, line := range input {
+		lines = append(lines, entity.BillLine{
+			RfqId:         line.RfqId,
+			RfqsProductId: line.RfqsProductId,
+			Quantity:      line.Quantity,
+			UnitPrice:     line.UnitPrice,
+		})
+	}
+	return lines
+}
+
 func (h *BillrfqHandler) CreateMo(c echo.Context) error {
 	input := binder.BillRfqCreateRequest{}
 
 	if err := c.Bind(&input); err != nil {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
 	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
 
 	NewMo := entity.NewBillrfq("", input.VendorId, input.Bill_date, input.Payment)
+	NewMo.Lines = billLines(input.Lines)
 	mo, err := h.billrfqService.CreateBill(NewMo)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
@@ -33,3 +52,164 @@ func (h *BillrfqHandler) CreateMo(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully created a new bill", mo))
 }
+
+func (h *BillrfqHandler) GetBill(c echo.Context) error {
+	bill, err := h.billrfqService.GetBill(c.Param("id_bill"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show bill", bill))
+}
+
+func (h *BillrfqHandler) UpdateBillLines(c echo.Context) error {
+	var input binder.BillLinesUpdateRequest
+
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	bill, err := h.billrfqService.ReplaceBillLines(input.BillId, billLines(input.Lines))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully updated bill lines", bill))
+}
+
+func (h *BillrfqHandler) MatchBill(c echo.Context) error {
+	bill, err := h.billrfqService.MatchBill(c.Param("id_bill"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Bill matched against orders and receipts", bill))
+}
+
+func (h *BillrfqHandler) ResolveBillLine(c echo.Context) error {
+	var input binder.BillLineResolveRequest
+
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	bill, err := h.billrfqService.ResolveBillLine(input.BillId, input.BillLineId, input.ResolvedBy, input.Note)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully resolved bill line", bill))
+}
+
+func (h *BillrfqHandler) PostBill(c echo.Context) error {
+	bill, err := h.billrfqService.PostBill(c.Param("id_bill"))
+	if err != nil {
+		var mismatchErr *service.BillMismatchError
+		if errors.As(err, &mismatchErr) {
+			return c.JSON(http.StatusConflict, response.ErrorResponseWithData(http.StatusConflict, mismatchErr.Error(), mismatchErr.Lines))
+		}
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Bill posted for payment", bill))
+}
+
+func (h *BillrfqHandler) FindAllTolerances(c echo.Context) error {
+	tolerances, err := h.billrfqService.FindAllTolerances()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show bill match tolerances", tolerances))
+}
+
+func (h *BillrfqHandler) SetTolerance(c echo.Context) error {
+	var input binder.BillToleranceRequest
+
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	tolerance, err := h.billrfqService.SetTolerance(input.VendorId, input.QtyPercent, input.PricePercent)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully saved bill match tolerance", tolerance))
+}
+
+func (h *BillrfqHandler) FindAllBills(c echo.Context) error {
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	bills, err := h.billrfqService.FindAllBills(page, c.QueryParam("status"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show data bills", bills))
+}
+
+func (h *BillrfqHandler) CancelBill(c echo.Context) error {
+	bill, err := h.billrfqService.CancelBill(c.Param("id_bill"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully cancelled bill", bill))
+}
+
+func (h *BillrfqHandler) RegisterPayment(c echo.Context) error {
+	var input binder.BillPaymentRequest
+
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	bill, err := h.billrfqService.RegisterPayment(input.BillId, input.Amount, input.PaymentDate, input.Method, input.Reference)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully registered payment", bill))
+}
+
+func (h *BillrfqHandler) GetOpenPayables(c echo.Context) error {
+	overdue, _ := strconv.ParseBool(c.QueryParam("overdue"))
+
+	payables, err := h.billrfqService.GetOpenPayables(c.QueryParam("id_vendor"), overdue)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show open payables", payables))
+}
+
+func (h *BillrfqHandler) DownloadBillPDF(c echo.Context) error {
+	billId := c.Param("id_bill")
+
+	pdfBytes, err := h.billrfqService.CreateBillPDF(billId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	c.Response().Header().Set("Content-Type", "application/pdf")
+	c.Response().Header().Set("Content-Disposition", "attachment; filename=bill-"+billId+".pdf")
+	c.Response().Write(pdfBytes)
+
+	return nil
+}
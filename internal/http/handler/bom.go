@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -35,14 +36,60 @@ func generateMaterialId(lastId string) string {
 func hasDuplicateMaterials(materials []binder.MaterialRequest) bool {
 	seen := make(map[string]struct{})
 	for _, material := range materials {
-		if _, exists := seen[material.IdMaterial]; exists {
+		// A line references either a material or a sub-assembly product
+		key := material.IdMaterial + material.IdProduct
+		if _, exists := seen[key]; exists {
 			return true // Duplikasi ditemukan
 		}
-		seen[material.IdMaterial] = struct{}{}
+		seen[key] = struct{}{}
 	}
 	return false // Tidak ada duplikasi
 }
 
+// checkComponents makes sure every line points at exactly one existing
+// material or sub-assembly product. It returns the status to respond with.
+func (h *BOMHandler) checkComponents(productId string, materials []binder.MaterialRequest) (int, error) {
+	for _, material := range materials {
+		if material.IdProduct == "" {
+			materialcheck, err := h.bomService.GetCheckIDMaterial(material.IdMaterial)
+			if err != nil {
+				return http.StatusInternalServerError, err
+			}
+			if !materialcheck {
+				return http.StatusNotFound, fmt.Errorf("Material with id %s does not exist", material.IdMaterial)
+			}
+			continue
+		}
+
+		if material.IdMaterial != "" {
+			return http.StatusBadRequest, errors.New("A BoM line must reference either a material or a product, not both")
+		}
+		if material.IdProduct == productId {
+			return http.StatusConflict, fmt.Errorf("Product %s cannot be a component of its own BoM", productId)
+		}
+		productcheck, err := h.bomService.GetCheckIDProduct(material.IdProduct)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if !productcheck {
+			return http.StatusNotFound, fmt.Errorf("Product with id %s does not exist", material.IdProduct)
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// parseEffectiveFrom reads an optional YYYY-MM-DD date; empty means now.
+func parseEffectiveFrom(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	effectiveFrom, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("effective_from must be a date in YYYY-MM-DD format")
+	}
+	return effectiveFrom, nil
+}
+
 func (h *BOMHandler) CreateBOM(c echo.Context) error {
 	var input binder.BOMCreateRequest
 	if err := c.Bind(&input); err != nil {
@@ -61,24 +108,26 @@ func (h *BOMHandler) CreateBOM(c echo.Context) error {
 		return c.JSON(http.StatusConflict, response.ErrorResponseBom(http.StatusConflict, "Duplicate material IDs found in the request"))
 	}
 
-	for _, material := range input.Materials {
-		fmt.Println("Received Material Id:", material.IdMaterial) // Log ID material
+	if status, err := h.checkComponents(input.IdProduct, input.Materials); err != nil {
+		return c.JSON(status, response.ErrorResponseBom(status, err.Error()))
+	}
 
-		materialcheck, err := h.bomService.GetCheckIDMaterial(material.IdMaterial)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, response.ErrorResponseBom(http.StatusInternalServerError, err.Error()))
-		}
-		if !materialcheck {
-			return c.JSON(http.StatusNotFound, response.ErrorResponseBom(http.StatusNotFound, fmt.Sprintf("Material with id %s does not exist", material.IdMaterial)))
-		}
+	effectiveFrom, err := parseEffectiveFrom(input.EffectiveFrom)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponseBom(http.StatusBadRequest, err.Error()))
 	}
 
 	newBom := entity.NewBom("", input.IdProduct, input.ProductName, input.ProductReference, input.Quantity)
+	newBom.EffectiveFrom = effectiveFrom
+	newBom.ChangeNote = input.ChangeNote
+	newBom.MacerationDays = input.MacerationDays
+	newBom.LitresPerUnit = input.LitresPerUnit
 
 	var materials []entity.BomMaterial
 	for _, material := range input.Materials {
 		materials = append(materials, entity.BomMaterial{
 			IdMaterial:   material.IdMaterial,
+			IdProduct:    material.IdProduct,
 			MaterialName: material.MaterialName,
 			Quantity:     material.Quantity,
 			Unit:         material.Unit,
@@ -89,6 +138,13 @@ func (h *BOMHandler) CreateBOM(c echo.Context) error {
 		newBom.Materials = materials
 	}
 
+	if err := h.bomService.ValidateBOMComponents(input.IdProduct, materials); err != nil {
+		if errors.Is(err, service.ErrBOMCycle) {
+			return c.JSON(http.StatusConflict, response.ErrorResponseBom(http.StatusConflict, err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponseBom(http.StatusInternalServerError, err.Error()))
+	}
+
 	newBom.CreatedAt = time.Now()
 	newBom.UpdatedAt = time.Now()
 
@@ -159,8 +215,21 @@ func (h *BOMHandler) UpdateBOM(c echo.Context) error {
 		return c.JSON(http.StatusConflict, response.ErrorResponseBom(http.StatusConflict, "Duplicate material IDs found in the request"))
 	}
 
+	if status, err := h.checkComponents(input.IdProduct, input.Materials); err != nil {
+		return c.JSON(status, response.ErrorResponseBom(status, err.Error()))
+	}
+
 	// Prepare BOM entity using the input and the existing BOM ID
+	effectiveFrom, err := parseEffectiveFrom(input.EffectiveFrom)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponseBom(http.StatusBadRequest, err.Error()))
+	}
+
 	updatedBomEntity := entity.UpdateBOM(bomId, input.IdProduct, input.ProductName, input.ProductReference, input.Quantity)
+	updatedBomEntity.EffectiveFrom = effectiveFrom
+	updatedBomEntity.ChangeNote = input.ChangeNote
+	updatedBomEntity.MacerationDays = input.MacerationDays
+	updatedBomEntity.LitresPerUnit = input.LitresPerUnit
 
 	// Prepare materials for update
 	var updatedMaterials []entity.BomMaterial
@@ -168,6 +237,7 @@ func (h *BOMHandler) UpdateBOM(c echo.Context) error {
 		updatedMaterials = append(updatedMaterials, entity.BomMaterial{
 			IdBomMaterial: material.IdBomMaterial,
 			IdMaterial:    material.IdMaterial,
+			IdProduct:     material.IdProduct,
 			MaterialName:  material.MaterialName,
 			Quantity:      material.Quantity,
 			Unit:          material.Unit,
@@ -176,8 +246,19 @@ func (h *BOMHandler) UpdateBOM(c echo.Context) error {
 	}
 	updatedBomEntity.Materials = updatedMaterials
 
+	// Reject sub-assemblies that would loop back into this product
+	if err := h.bomService.ValidateBOMComponents(input.IdProduct, updatedMaterials); err != nil {
+		if errors.Is(err, service.ErrBOMCycle) {
+			return c.JSON(http.StatusConflict, response.ErrorResponseBom(http.StatusConflict, err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponseBom(http.StatusInternalServerError, err.Error()))
+	}
+
 	// Call the service to update the BOM and materials
 	updatedBom, err := h.bomService.UpdateBOM(updatedBomEntity)
+	if errors.Is(err, service.ErrBOMEffectiveDate) {
+		return c.JSON(http.StatusConflict, response.ErrorResponseBom(http.StatusConflict, err.Error()))
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, response.ErrorResponseBom(http.StatusInternalServerError, "Failed to update BoM"))
 	}
@@ -194,7 +275,7 @@ func (h *BOMHandler) UpdateBOM(c echo.Context) error {
 func (h *BOMHandler) GetBOMByID(c echo.Context) error {
 	bomId := c.Param("id_bom")
 
-	bom, err := h.bomService.GetBOMByID(bomId)
+	bom, err := h.bomService.GetBOMByID(bomId, revealFormula(c))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, response.ErrorResponseBom(http.StatusInternalServerError, err.Error()))
 	}
@@ -218,7 +299,7 @@ func (h *BOMHandler) GetBOMOverview(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "BOM ID cannot be empty"))
 	}
 
-	overview, err := h.bomService.CalculateOverview(bomId)
+	overview, err := h.bomService.CalculateOverview(bomId, revealFormula(c))
 	if err != nil {
 		// Handle specific error scenarios
 		if err.Error() == "BOM not found" {
@@ -237,7 +318,7 @@ func (h *BOMHandler) GetBOMPDF(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "BOM ID cannot be empty"))
 	}
 
-	overview, err := h.bomService.CalculateOverview(bomId)
+	overview, err := h.bomService.CalculateOverview(bomId, revealFormula(c))
 	if err != nil {
 		if err.Error() == "BOM not found" {
 			return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
@@ -258,3 +339,104 @@ func (h *BOMHandler) GetBOMPDF(c echo.Context) error {
 
 	return nil
 }
+
+func (h *BOMHandler) ExplodeBOM(c echo.Context) error {
+	bomId := c.Param("id_bom")
+
+	quantity := 0.0
+	if qty := c.QueryParam("qty"); qty != "" {
+		parsed, err := strconv.ParseFloat(qty, 64)
+		if err != nil || parsed <= 0 {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "qty must be a positive number"))
+		}
+		quantity = parsed
+	}
+
+	explosion, err := h.bomService.ExplodeBOM(bomId, quantity, revealFormula(c))
+	if err != nil {
+		if err.Error() == "BOM not found" {
+			return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+		}
+		if errors.Is(err, service.ErrBOMCycle) {
+			return c.JSON(http.StatusConflict, response.ErrorResponse(http.StatusConflict, err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "BOM explosion retrieved successfully", explosion))
+}
+
+func (h *BOMHandler) GetBOMVersions(c echo.Context) error {
+	bomId := c.Param("id_bom")
+
+	versions, err := h.bomService.GetBOMVersions(bomId, revealFormula(c))
+	if err != nil {
+		if err.Error() == "BOM not found" {
+			return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "BOM versions retrieved successfully", versions))
+}
+
+func (h *BOMHandler) DiffBOMVersions(c echo.Context) error {
+	var input binder.BomDiffRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid input"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	diff, err := h.bomService.DiffBOMVersions(input.BomId, input.From, input.To, revealFormula(c))
+	if err != nil {
+		if err.Error() == "BOM version not found" {
+			return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "BOM version diff retrieved successfully", diff))
+}
+
+func (h *BOMHandler) SimulateBOMCosts(c echo.Context) error {
+	var input binder.BomSimulationRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid input"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	var overrides []service.MaterialPriceOverride
+	for _, override := range input.Overrides {
+		overrides = append(overrides, service.MaterialPriceOverride{
+			MaterialId:    override.MaterialId,
+			Price:         override.Price,
+			ChangePercent: override.ChangePercent,
+		})
+	}
+
+	simulation, err := h.bomService.SimulateBOMCosts(overrides)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "BOM cost simulation calculated successfully", simulation))
+}
+
+func (h *BOMHandler) GetBOMCompliance(c echo.Context) error {
+	compliance, err := h.bomService.CheckCompliance(c.Param("id_bom"), revealFormula(c))
+	if err != nil {
+		if err.Error() == "BOM not found" {
+			return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+		}
+		if errors.Is(err, service.ErrBOMCycle) {
+			return c.JSON(http.StatusConflict, response.ErrorResponse(http.StatusConflict, err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "BOM compliance retrieved successfully", compliance))
+}
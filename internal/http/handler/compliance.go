@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/service"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/response"
+	"github.com/labstack/echo/v4"
+)
+
+type ComplianceHandler struct {
+	complianceService service.ComplianceService
+}
+
+func NewComplianceHandler(complianceService service.ComplianceService) ComplianceHandler {
+	return ComplianceHandler{complianceService: complianceService}
+}
+
+func (h *ComplianceHandler) SetMaterialConstituents(c echo.Context) error {
+	var input binder.MaterialConstituentsRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	constituents := make([]entity.MaterialConstituent, 0, len(input.Constituents))
+	for _, constituent := range input.Constituents {
+		constituents = append(constituents, entity.MaterialConstituent{
+			Substance:  constituent.Substance,
+			CasNumber:  constituent.CasNumber,
+			Percentage: constituent.Percentage,
+			Allergen:   constituent.Allergen,
+		})
+	}
+
+	saved, err := h.complianceService.SetMaterialConstituents(input.MaterialId, constituents)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success update material constituents", saved))
+}
+
+func (h *ComplianceHandler) GetMaterialConstituents(c echo.Context) error {
+	constituents, err := h.complianceService.GetMaterialConstituents(c.Param("id_material"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "successfully displays material constituents", constituents))
+}
+
+func complianceLimitFromRequest(input binder.ComplianceLimitRequest) *entity.ComplianceLimit {
+	return &entity.ComplianceLimit{
+		ComplianceLimitId: input.ComplianceLimitId,
+		Type:              input.Type,
+		Substance:         input.Substance,
+		CasNumber:         input.CasNumber,
+		ProductCategory:   input.ProductCategory,
+		MaxPercent:        input.MaxPercent,
+		Source:            input.Source,
+	}
+}
+
+func (h *ComplianceHandler) CreateLimit(c echo.Context) error {
+	var input binder.ComplianceLimitRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	limit, err := h.complianceService.CreateLimit(complianceLimitFromRequest(input))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully input a new compliance limit", limit))
+}
+
+func (h *ComplianceHandler) UpdateLimit(c echo.Context) error {
+	var input binder.ComplianceLimitRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	limit, err := h.complianceService.UpdateLimit(complianceLimitFromRequest(input))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success update compliance limit", limit))
+}
+
+func (h *ComplianceHandler) DeleteLimit(c echo.Context) error {
+	var input binder.ComplianceLimitIdRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	isDeleted, err := h.complianceService.DeleteLimit(input.ComplianceLimitId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success delete compliance limit", isDeleted))
+}
+
+func (h *ComplianceHandler) FindAllLimits(c echo.Context) error {
+	limits, err := h.complianceService.FindAllLimits()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "successfully displays compliance limits", limits))
+}
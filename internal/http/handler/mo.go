@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
@@ -50,6 +52,14 @@ func (h *MoHandler) UpdateMoStatus(c echo.Context) error {
 
 	// Call service to update the manufacture order status
 	updatedMo, err := h.moService.UpdateMoStatus(input.MoId)
+	var tankErr *service.TankUnavailableError
+	if errors.As(err, &tankErr) {
+		return c.JSON(http.StatusConflict, response.ErrorResponseWithData(http.StatusConflict, tankErr.Error(), tankErr.Suggestion))
+	}
+	var conflictErr *service.ScheduleConflictError
+	if errors.As(err, &conflictErr) {
+		return c.JSON(http.StatusConflict, response.ErrorResponseWithData(http.StatusConflict, conflictErr.Error(), conflictErr.Conflicts))
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
@@ -123,7 +133,7 @@ func (h *MoHandler) DownloadMOPDF(c echo.Context) error {
 	}
 
 	// Generate the PDF using the GenerateMOPDF method
-	pdfData, err := h.moService.GenerateMOPDF(mo)
+	pdfData, err := h.moService.GenerateMOPDF(mo, revealFormula(c))
 	if err != nil {
 		// If an error occurs while generating the PDF, return an internal server error
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
@@ -138,3 +148,368 @@ func (h *MoHandler) DownloadMOPDF(c echo.Context) error {
 
 	return nil
 }
+
+func (h *MoHandler) GetBatchRecord(c echo.Context) error {
+	record, err := h.moService.GetBatchRecord(c.Param("id_mo"), revealFormula(c))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "successfully displays batch record", record))
+}
+
+func (h *MoHandler) GetMoAvailability(c echo.Context) error {
+	moId := c.Param("id_mo")
+
+	availability, err := h.moService.GetMoAvailability(moId, revealFormula(c))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "successfully displays material availability", availability))
+}
+
+func (h *MoHandler) ShortageReport(c echo.Context) error {
+	report, err := h.moService.ShortageReport(revealFormula(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show material shortages", report))
+}
+
+func (h *MoHandler) CreateShortageRfq(c echo.Context) error {
+	var input binder.ShortageRfqRequest
+
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	rfq, err := h.moService.CreateShortageRfq(input.VendorId, input.OrderDate)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully created an RFQ from material shortages", rfq))
+}
+
+func (h *MoHandler) GenerateSubAssemblyMos(c echo.Context) error {
+	moId := c.Param("id_mo")
+
+	mos, err := h.moService.GenerateSubAssemblyMos(moId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully generated sub-assembly manufacture orders", mos))
+}
+
+func (h *MoHandler) GetMoWorkOrders(c echo.Context) error {
+	moId := c.Param("id_mo")
+
+	workOrders, err := h.moService.GetMoWorkOrders(moId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "successfully displays work orders", workOrders))
+}
+
+func (h *MoHandler) StartWorkOrder(c echo.Context) error {
+	var input binder.WorkOrderRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+	if input.Operator == "" {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "operator cannot be empty"))
+	}
+
+	workOrder, err := h.moService.StartWorkOrder(input.WorkOrderId, input.Operator)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully started work order", workOrder))
+}
+
+func (h *MoHandler) PauseWorkOrder(c echo.Context) error {
+	var input binder.WorkOrderRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	workOrder, err := h.moService.PauseWorkOrder(input.WorkOrderId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully paused work order", workOrder))
+}
+
+func (h *MoHandler) FinishWorkOrder(c echo.Context) error {
+	var input binder.WorkOrderRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	workOrder, err := h.moService.FinishWorkOrder(input.WorkOrderId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully finished work order", workOrder))
+}
+
+func (h *MoHandler) BookTank(c echo.Context) error {
+	var input binder.BookTankRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+	start, err := time.ParseInLocation("2006-01-02", input.Start, time.Local)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "start must use the YYYY-MM-DD format"))
+	}
+
+	booking, err := h.moService.BookTank(input.MoId, input.VesselId, start)
+	if err != nil {
+		return c.JSON(http.StatusConflict, response.ErrorResponse(http.StatusConflict, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully booked tank", booking))
+}
+
+func (h *MoHandler) ProduceMo(c echo.Context) error {
+	var input binder.ProduceMoRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	var consumptions []service.ActualConsumption
+	for _, consumption := range input.Consumptions {
+		if (consumption.IdMaterial == "") == (consumption.IdProduct == "") {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "each consumption needs either id_material or id_product"))
+		}
+		consumptions = append(consumptions, service.ActualConsumption{
+			MaterialId: consumption.IdMaterial,
+			ProductId:  consumption.IdProduct,
+			Quantity:   consumption.Quantity,
+			LotNumber:  consumption.LotNumber,
+		})
+	}
+
+	mo, err := h.moService.ProduceMo(input.MoId, input.Quantity, input.ProducedBy, input.Note, consumptions)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully recorded production", mo))
+}
+
+func (h *MoHandler) CloseMo(c echo.Context) error {
+	var input binder.CloseMoRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	result, err := h.moService.CloseMo(input.MoId, input.Backorder)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully closed manufacture order", result))
+}
+
+func (h *MoHandler) CancelMo(c echo.Context) error {
+	var input binder.CancelMoRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	reversal, err := h.moService.CancelMo(input.MoId, input.PerformedBy, input.Reason, revealFormula(c))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully cancelled manufacture order", reversal))
+}
+
+func (h *MoHandler) UnbuildMo(c echo.Context) error {
+	var input binder.UnbuildMoRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	reversal, err := h.moService.UnbuildMo(input.MoId, input.Quantity, input.PerformedBy, input.Reason, revealFormula(c))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully unbuilt manufacture order", reversal))
+}
+
+func (h *MoHandler) GetMoReversals(c echo.Context) error {
+	reversals, err := h.moService.GetMoReversals(c.Param("id_mo"), revealFormula(c))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "successfully displays cancellations and unbuilds", reversals))
+}
+
+func (h *MoHandler) GetMoProductions(c echo.Context) error {
+	productions, err := h.moService.GetMoProductions(c.Param("id_mo"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "successfully displays production records", productions))
+}
+
+// parsePlanTime reads a planned moment given as RFC 3339, as YYYY-MM-DD HH:MM
+// or as a bare date meaning its midnight.
+func parsePlanTime(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	if parsed, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local); err == nil {
+		return parsed, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+// scheduleError answers a failed (re)schedule, listing the conflicts when
+// the plan clashed with other work.
+func scheduleError(c echo.Context, err error) error {
+	var conflictErr *service.ScheduleConflictError
+	if errors.As(err, &conflictErr) {
+		return c.JSON(http.StatusConflict, response.ErrorResponseWithData(http.StatusConflict, conflictErr.Error(), conflictErr.Conflicts))
+	}
+	return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+}
+
+func (h *MoHandler) GetGantt(c echo.Context) error {
+	var input binder.GanttRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+
+	now := time.Now()
+	from, err := parseCalendarDate(input.From, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "from must use the YYYY-MM-DD format"))
+	}
+	to, err := parseCalendarDate(input.To, from.AddDate(0, 0, 13))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "to must use the YYYY-MM-DD format"))
+	}
+
+	gantt, err := h.moService.GetGantt(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully retrieved production plan", gantt))
+}
+
+func (h *MoHandler) ScheduleMo(c echo.Context) error {
+	var input binder.ScheduleMoRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	var start, requiredDate *time.Time
+	if input.Start != "" {
+		parsed, err := parsePlanTime(input.Start)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "start must use RFC 3339 or the YYYY-MM-DD HH:MM format"))
+		}
+		start = &parsed
+	}
+	if input.RequiredDate != "" {
+		parsed, err := parsePlanTime(input.RequiredDate)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "required_date must use RFC 3339 or the YYYY-MM-DD HH:MM format"))
+		}
+		requiredDate = &parsed
+	}
+
+	plan, err := h.moService.ScheduleMo(input.MoId, input.Direction, start, requiredDate)
+	if err != nil {
+		return scheduleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully scheduled manufacture order", plan))
+}
+
+func (h *MoHandler) RescheduleMo(c echo.Context) error {
+	var input binder.RescheduleMoRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+	start, err := parsePlanTime(input.PlannedStart)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "planned_start must use RFC 3339 or the YYYY-MM-DD HH:MM format"))
+	}
+
+	mo, err := h.moService.RescheduleMo(input.MoId, start)
+	if err != nil {
+		return scheduleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully rescheduled manufacture order", mo))
+}
+
+func (h *MoHandler) RescheduleWorkOrder(c echo.Context) error {
+	var input binder.RescheduleWorkOrderRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+	start, err := parsePlanTime(input.PlannedStart)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "planned_start must use RFC 3339 or the YYYY-MM-DD HH:MM format"))
+	}
+
+	workOrder, err := h.moService.RescheduleWorkOrder(input.WorkOrderId, start)
+	if err != nil {
+		return scheduleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully rescheduled work order", workOrder))
+}
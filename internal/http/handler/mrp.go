@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/service"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/response"
+	"github.com/labstack/echo/v4"
+)
+
+type MrpHandler struct {
+	mrpService service.MrpService
+}
+
+func NewMrpHandler(mrpService service.MrpService) MrpHandler {
+	return MrpHandler{mrpService: mrpService}
+}
+
+func (h *MrpHandler) RunMrp(c echo.Context) error {
+	run, err := h.mrpService.RunMrp()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully ran MRP", run))
+}
+
+func (h *MrpHandler) FindAllRuns(c echo.Context) error {
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	runs, err := h.mrpService.FindAllRuns(page)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show data MRP runs", runs))
+}
+
+func (h *MrpHandler) GetRun(c echo.Context) error {
+	var input binder.MrpRunRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+
+	run, err := h.mrpService.GetRunByID(input.RunId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "successfully displays MRP run", run))
+}
+
+func (h *MrpHandler) UpdateProposal(c echo.Context) error {
+	var input binder.MrpProposalUpdateRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	proposal := &entity.MrpProposal{
+		ProposalId: input.ProposalId,
+		Quantity:   input.Quantity,
+		VendorId:   input.VendorId,
+		Status:     input.Status,
+	}
+	if input.OrderDate != "" {
+		orderDate, err := time.ParseInLocation("2006-01-02", input.OrderDate, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "order_date must be a date in YYYY-MM-DD format"))
+		}
+		proposal.OrderDate = orderDate
+	}
+	if input.DueDate != "" {
+		dueDate, err := time.ParseInLocation("2006-01-02", input.DueDate, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "due_date must be a date in YYYY-MM-DD format"))
+		}
+		proposal.DueDate = dueDate
+	}
+
+	updated, err := h.mrpService.UpdateProposal(proposal)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success update MRP proposal", updated))
+}
+
+func (h *MrpHandler) ConfirmRun(c echo.Context) error {
+	var input binder.MrpConfirmRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	run, err := h.mrpService.ConfirmRun(input.RunId, input.ProposalIds)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully confirmed MRP proposals", run))
+}
+
+func (h *MrpHandler) CreateReorderRule(c echo.Context) error {
+	var input binder.ReorderRuleRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+
+	rule, err := h.mrpService.CreateReorderRule(&entity.ReorderRule{
+		MaterialId:   input.MaterialId,
+		ProductId:    input.ProductId,
+		MinQty:       input.MinQty,
+		MaxQty:       input.MaxQty,
+		LeadTimeDays: input.LeadTimeDays,
+		VendorId:     input.VendorId,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully input a new reorder rule", rule))
+}
+
+func (h *MrpHandler) UpdateReorderRule(c echo.Context) error {
+	var input binder.ReorderRuleRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+
+	rule, err := h.mrpService.UpdateReorderRule(&entity.ReorderRule{
+		RuleId:       input.RuleId,
+		MaterialId:   input.MaterialId,
+		ProductId:    input.ProductId,
+		MinQty:       input.MinQty,
+		MaxQty:       input.MaxQty,
+		LeadTimeDays: input.LeadTimeDays,
+		VendorId:     input.VendorId,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success update reorder rule", rule))
+}
+
+func (h *MrpHandler) DeleteReorderRule(c echo.Context) error {
+	var input binder.ReorderRuleDeleteRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+
+	isDeleted, err := h.mrpService.DeleteReorderRule(input.RuleId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success delete reorder rule", isDeleted))
+}
+
+func (h *MrpHandler) FindAllReorderRules(c echo.Context) error {
+	rules, err := h.mrpService.FindAllReorderRules()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show data reorder rules", rules))
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/service"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/response"
+	"github.com/labstack/echo/v4"
+)
+
+type PoApprovalHandler struct {
+	poApprovalService service.PoApprovalService
+}
+
+func NewPoApprovalHandler(poApprovalService service.PoApprovalService) PoApprovalHandler {
+	return PoApprovalHandler{poApprovalService: poApprovalService}
+}
+
+func approvalRuleFromRequest(input binder.PoApprovalRuleRequest) *entity.PoApprovalRule {
+	active := true
+	if input.Active != nil {
+		active = *input.Active
+	}
+	return &entity.PoApprovalRule{
+		ApprovalRuleId: input.ApprovalRuleId,
+		Name:           input.Name,
+		MinAmount:      input.MinAmount,
+		VendorId:       input.VendorId,
+		Category:       input.Category,
+		ApproverName:   input.ApproverName,
+		ApproverEmail:  input.ApproverEmail,
+		Active:         active,
+	}
+}
+
+func (h *PoApprovalHandler) CreateRule(c echo.Context) error {
+	var input binder.PoApprovalRuleRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	rule, err := h.poApprovalService.CreateRule(approvalRuleFromRequest(input))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully created approval rule", rule))
+}
+
+func (h *PoApprovalHandler) UpdateRule(c echo.Context) error {
+	var input binder.PoApprovalRuleRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	rule, err := h.poApprovalService.UpdateRule(approvalRuleFromRequest(input))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully updated approval rule", rule))
+}
+
+func (h *PoApprovalHandler) DeleteRule(c echo.Context) error {
+	isDeleted, err := h.poApprovalService.DeleteRule(c.Param("id_approvalrule"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully deleted approval rule", isDeleted))
+}
+
+func (h *PoApprovalHandler) FindAllRules(c echo.Context) error {
+	rules, err := h.poApprovalService.FindAllRules()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show approval rules", rules))
+}
+
+func (h *PoApprovalHandler) FindPendingApprovals(c echo.Context) error {
+	approvals, err := h.poApprovalService.FindPendingApprovals()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show pending approvals", approvals))
+}
+
+func (h *PoApprovalHandler) decide(c echo.Context, approved bool) error {
+	var input binder.PoApprovalDecisionRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	user := currentUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "sign in to decide on approvals"))
+	}
+
+	approval, err := h.poApprovalService.DecideApproval(input.RfqApprovalId, approved, user, input.Note)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully "+approval.Status+" purchase order", approval))
+}
+
+func (h *PoApprovalHandler) Approve(c echo.Context) error {
+	return h.decide(c, true)
+}
+
+func (h *PoApprovalHandler) Reject(c echo.Context) error {
+	return h.decide(c, false)
+}
+
+func (h *PoApprovalHandler) GetRfqApprovals(c echo.Context) error {
+	approvals, err := h.poApprovalService.GetRfqApprovals(c.Param("id_rfq"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show RFQ approvals", approvals))
+}
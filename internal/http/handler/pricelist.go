@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/service"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/response"
+	"github.com/labstack/echo/v4"
+)
+
+type PriceListHandler struct {
+	priceListService service.PriceListService
+}
+
+func NewPriceListHandler(priceListService service.PriceListService) PriceListHandler {
+	return PriceListHandler{priceListService: priceListService}
+}
+
+// optionalDate reads a YYYY-MM-DD date that may be left empty.
+func optionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func vendorPriceFromRequest(input binder.VendorPriceRequest) (*entity.VendorPrice, error) {
+	validFrom, err := optionalDate(input.ValidFrom)
+	if err != nil {
+		return nil, err
+	}
+	validTo, err := optionalDate(input.ValidTo)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.VendorPrice{
+		VendorPriceId: input.VendorPriceId,
+		VendorId:      input.VendorId,
+		ItemId:        input.ItemId,
+		MinQty:        input.MinQty,
+		UnitPrice:     input.UnitPrice,
+		Currency:      input.Currency,
+		ValidFrom:     validFrom,
+		ValidTo:       validTo,
+		LeadTimeDays:  input.LeadTimeDays,
+	}, nil
+}
+
+func (h *PriceListHandler) CreatePrice(c echo.Context) error {
+	var input binder.VendorPriceRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+	if input.VendorId == "" || input.ItemId == "" {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "id_vendor and id_item are required"))
+	}
+
+	price, err := vendorPriceFromRequest(input)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "dates must be formatted as YYYY-MM-DD"))
+	}
+	saved, err := h.priceListService.CreatePrice(price)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully created vendor price", saved))
+}
+
+func (h *PriceListHandler) UpdatePrice(c echo.Context) error {
+	var input binder.VendorPriceRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	price, err := vendorPriceFromRequest(input)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "dates must be formatted as YYYY-MM-DD"))
+	}
+	saved, err := h.priceListService.UpdatePrice(price)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully updated vendor price", saved))
+}
+
+func (h *PriceListHandler) DeletePrice(c echo.Context) error {
+	isDeleted, err := h.priceListService.DeletePrice(c.Param("id_vendorprice"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully deleted vendor price", isDeleted))
+}
+
+func (h *PriceListHandler) FindPrices(c echo.Context) error {
+	prices, err := h.priceListService.FindPrices(c.QueryParam("id_vendor"), c.QueryParam("id_item"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show vendor prices", prices))
+}
+
+func (h *PriceListHandler) GetPriceHistory(c echo.Context) error {
+	vendorId, itemId := c.QueryParam("id_vendor"), c.QueryParam("id_item")
+	if vendorId == "" || itemId == "" {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "id_vendor and id_item are required"))
+	}
+
+	history, err := h.priceListService.GetPriceHistory(vendorId, itemId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show vendor price history", history))
+}
+
+func (h *PriceListHandler) QuotePrice(c echo.Context) error {
+	var input binder.PriceQuoteRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+	day, err := parseCalendarDate(input.Date, time.Now())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "date must be formatted as YYYY-MM-DD"))
+	}
+
+	price, err := h.priceListService.QuotePrice(input.VendorId, input.ItemId, input.Quantity, day)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if price == nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, "vendor has no valid price for this item"))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success quote vendor price", map[string]interface{}{
+		"price":         price,
+		"quantity":      input.Quantity,
+		"total":         price.UnitPrice * input.Quantity,
+		"expected_date": day.AddDate(0, 0, price.LeadTimeDays).Format("2006-01-02"),
+	}))
+}
+
+func (h *PriceListHandler) UpdateFromPurchaseOrder(c echo.Context) error {
+	prices, err := h.priceListService.UpdateFromPurchaseOrder(c.Param("id_rfq"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully updated price list from purchase order", prices))
+}
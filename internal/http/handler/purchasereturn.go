@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/service"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/response"
+	"github.com/labstack/echo/v4"
+)
+
+type PurchaseReturnHandler struct {
+	returnService service.PurchaseReturnService
+}
+
+func NewPurchaseReturnHandler(returnService service.PurchaseReturnService) PurchaseReturnHandler {
+	return PurchaseReturnHandler{returnService: returnService}
+}
+
+func (h *PurchaseReturnHandler) CreateReturn(c echo.Context) error {
+	var input binder.PurchaseReturnRequest
+
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	lines := make([]service.ReturnLine, 0, len(input.Lines))
+	for _, line := range input.Lines {
+		lines = append(lines, service.ReturnLine{
+			ReceiptLineId: line.ReceiptLineId,
+			Quantity:      line.Quantity,
+			Reason:        line.Reason,
+			ReasonNote:    line.ReasonNote,
+		})
+	}
+
+	purchaseReturn, err := h.returnService.CreateReturn(input.ReceiptId, input.ReturnedBy, input.Note, lines)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusCreated, response.SuccessResponse(http.StatusCreated, "Successfully returned goods to vendor", purchaseReturn))
+}
+
+func (h *PurchaseReturnHandler) GetReturn(c echo.Context) error {
+	returnId := c.Param("id_return")
+
+	purchaseReturn, err := h.returnService.GetReturn(returnId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show purchase return", purchaseReturn))
+}
+
+func (h *PurchaseReturnHandler) FindReturns(c echo.Context) error {
+	returns, err := h.returnService.FindReturns(c.QueryParam("id_vendor"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show purchase returns", returns))
+}
+
+func (h *PurchaseReturnHandler) FindCreditNotes(c echo.Context) error {
+	openOnly, _ := strconv.ParseBool(c.QueryParam("open"))
+
+	creditNotes, err := h.returnService.FindCreditNotes(c.QueryParam("id_vendor"), openOnly)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show vendor credit notes", creditNotes))
+}
+
+func (h *PurchaseReturnHandler) ApplyCreditNote(c echo.Context) error {
+	creditNoteId := c.Param("id_creditnote")
+
+	creditNote, err := h.returnService.ApplyCreditNote(creditNoteId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully applied credit note", creditNote))
+}
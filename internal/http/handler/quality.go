@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/service"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/response"
+	"github.com/labstack/echo/v4"
+)
+
+type QualityHandler struct {
+	qualityService service.QualityService
+}
+
+func NewQualityHandler(qualityService service.QualityService) QualityHandler {
+	return QualityHandler{qualityService: qualityService}
+}
+
+func qualityCheckFromRequest(input binder.QualityCheckRequest) *entity.QualityCheck {
+	active := true
+	if input.Active != nil {
+		active = *input.Active
+	}
+	return &entity.QualityCheck{
+		QualityCheckId: input.QualityCheckId,
+		Name:           input.Name,
+		Trigger:        input.Trigger,
+		MaterialId:     input.MaterialId,
+		ProductId:      input.ProductId,
+		MeasureType:    input.MeasureType,
+		Unit:           input.Unit,
+		MinValue:       input.MinValue,
+		MaxValue:       input.MaxValue,
+		Active:         active,
+	}
+}
+
+func (h *QualityHandler) CreateCheck(c echo.Context) error {
+	var input binder.QualityCheckRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	check, err := h.qualityService.CreateCheck(qualityCheckFromRequest(input))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully input a new quality check", check))
+}
+
+func (h *QualityHandler) UpdateCheck(c echo.Context) error {
+	var input binder.QualityCheckRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	check, err := h.qualityService.UpdateCheck(qualityCheckFromRequest(input))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success update quality check", check))
+}
+
+func (h *QualityHandler) DeleteCheck(c echo.Context) error {
+	var input binder.QualityCheckIdRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+
+	isDeleted, err := h.qualityService.DeleteCheck(input.QualityCheckId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success delete quality check", isDeleted))
+}
+
+func (h *QualityHandler) FindAllChecks(c echo.Context) error {
+	checks, err := h.qualityService.FindAllChecks()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show data quality checks", checks))
+}
+
+func (h *QualityHandler) FindInspections(c echo.Context) error {
+	inspections, err := h.qualityService.FindInspections(c.QueryParam("status"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show data inspections", inspections))
+}
+
+func (h *QualityHandler) GetInspection(c echo.Context) error {
+	inspection, err := h.qualityService.GetInspectionByID(c.Param("id_inspection"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "successfully displays inspection", inspection))
+}
+
+func (h *QualityHandler) RecordResults(c echo.Context) error {
+	var input binder.QcResultsRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	results := make([]service.QcResultInput, 0, len(input.Results))
+	for _, result := range input.Results {
+		results = append(results, service.QcResultInput{
+			QualityCheckId: result.QualityCheckId,
+			Passed:         result.Passed,
+			MeasuredValue:  result.MeasuredValue,
+			Note:           result.Note,
+		})
+	}
+
+	inspection, err := h.qualityService.RecordResults(input.InspectionId, input.RecordedBy, results)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully recorded inspection results", inspection))
+}
+
+func (h *QualityHandler) ReleaseInspection(c echo.Context) error {
+	var input binder.QcDecisionRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	inspection, err := h.qualityService.ReleaseInspection(input.InspectionId, input.DecidedBy, input.Note)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully released quarantined stock", inspection))
+}
+
+func (h *QualityHandler) RejectInspection(c echo.Context) error {
+	var input binder.QcDecisionRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	inspection, err := h.qualityService.RejectInspection(input.InspectionId, input.DecidedBy, input.Note)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully rejected quarantined stock", inspection))
+}
+
+func (h *QualityHandler) FailureRateReport(c echo.Context) error {
+	var input binder.QcFailureReportRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if input.GroupBy == "" {
+		input.GroupBy = "vendor"
+	}
+
+	from, err := parseCalendarDate(input.From, time.Time{})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "from must use the YYYY-MM-DD format"))
+	}
+	to, err := parseCalendarDate(input.To, time.Now())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "to must use the YYYY-MM-DD format"))
+	}
+
+	report, err := h.qualityService.FailureRateReport(input.GroupBy, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "QC failure rates retrieved successfully", report))
+}
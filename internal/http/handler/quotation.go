@@ -65,6 +65,7 @@ func (h *QuoHandler) CreateRfq(c echo.Context) error {
 
 	// Buat RFQ baru
 	newRfq := entity.NewQuo("", input.OrderDate, input.Status, input.CostumerId, input.Payment)
+	newRfq.TaxRounding = input.TaxRounding
 
 	// Proses produk dan tambahkan VendorId dari input
 	var products []entity.QuotationsProduct
@@ -74,8 +75,7 @@ func (h *QuoHandler) CreateRfq(c echo.Context) error {
 			ProductName: product.ProductName,
 			Quantity:    product.Quantity,
 			UnitPrice:   product.UnitPrice,
-			Tax:         product.Tax,
-			Subtotal:    product.Subtotal,
+			LineAmounts: entity.LineAmounts{Discount: product.Discount, TaxId: product.TaxId, Tax: product.Tax},
 			CostumerId:  input.CostumerId,
 		})
 	}
@@ -127,6 +127,7 @@ func (h *QuoHandler) UpdateQuo(c echo.Context) error {
 		input.Payment,
 		existingRfq.Status, // Status lama
 	)
+	updatedRfq.TaxRounding = input.TaxRounding
 
 	// Tambahkan produk baru dengan VendorId yang sudah diset
 	var products []entity.QuotationsProduct
@@ -137,8 +138,7 @@ func (h *QuoHandler) UpdateQuo(c echo.Context) error {
 			ProductName: product.ProductName,
 			Quantity:    product.Quantity,
 			UnitPrice:   product.UnitPrice,
-			Tax:         product.Tax,
-			Subtotal:    product.Subtotal,
+			LineAmounts: entity.LineAmounts{Discount: product.Discount, TaxId: product.TaxId, Tax: product.Tax},
 			CostumerId:  product.CostumerId,
 		})
 	}
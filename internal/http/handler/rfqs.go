@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -24,10 +25,11 @@ func NewRfqHandler(rfqService service.RfqService) RfqHandler {
 func hasDuplicateProducts(products []binder.ProductRequest) bool {
 	seen := make(map[string]struct{})
 	for _, product := range products {
-		if _, exists := seen[product.ProductId]; exists {
+		key := product.LineType + ":" + product.MaterialId + product.ProductId
+		if _, exists := seen[key]; exists {
 			return true // Duplikasi ditemukan
 		}
-		seen[product.ProductId] = struct{}{}
+		seen[key] = struct{}{}
 	}
 	return false // Tidak ada duplikasi
 }
@@ -52,31 +54,27 @@ func (h *RfqHandler) CreateRfq(c echo.Context) error {
 		return c.JSON(http.StatusConflict, response.ErrorResponseBom(http.StatusConflict, "Duplicate product IDs found in the request"))
 	}
 
-	// Validasi material untuk setiap produk
-	for _, product := range input.Products {
-		materialCheck, err := h.rfqService.GetCheckIDMaterial(product.ProductId)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, response.ErrorResponseBom(http.StatusInternalServerError, err.Error()))
-		}
-		if !materialCheck {
-			return c.JSON(http.StatusNotFound, response.ErrorResponseBom(http.StatusNotFound, fmt.Sprintf("Product with id %s does not exist", product.ProductId)))
-		}
-	}
-
 	// Buat RFQ baru
 	newRfq := entity.NewRfqs("", input.OrderDate, input.Status, input.VendorId)
+	newRfq.TaxRounding = input.TaxRounding
 
 	// Proses produk dan tambahkan VendorId dari input
 	var products []entity.RfqsProduct
 	for _, product := range input.Products {
+		promised, err := optionalDate(product.PromisedDate)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "promised_date must be formatted as YYYY-MM-DD"))
+		}
 		products = append(products, entity.RfqsProduct{
-			ProductId:   product.ProductId,
-			ProductName: product.ProductName,
-			Quantity:    product.Quantity,
-			UnitPrice:   product.UnitPrice,
-			Tax:         product.Tax,
-			Subtotal:    product.Subtotal,
-			VendorId:    input.VendorId, // Tambahkan VendorId
+			LineType:     product.LineType,
+			ProductId:    product.ProductId,
+			MaterialId:   product.MaterialId,
+			ProductName:  product.ProductName,
+			Quantity:     product.Quantity,
+			UnitPrice:    product.UnitPrice,
+			LineAmounts:  entity.LineAmounts{Discount: product.Discount, TaxId: product.TaxId, Tax: product.Tax},
+			PromisedDate: promised,
+			VendorId:     input.VendorId, // Tambahkan VendorId
 		})
 	}
 
@@ -91,7 +89,7 @@ func (h *RfqHandler) CreateRfq(c echo.Context) error {
 	// Simpan RFQ ke database
 	bom, err := h.rfqService.CreateRfq(newRfq)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.ErrorResponseBom(http.StatusInternalServerError, "Failed to create RFQ"))
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponseBom(http.StatusInternalServerError, "Failed to create RFQ: "+err.Error()))
 	}
 
 	return c.JSON(http.StatusOK, response.BOMResponse{
@@ -108,6 +106,9 @@ func (h *RfqHandler) UpdateRfq(c echo.Context) error {
 	if err := c.Bind(&input); err != nil {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponseBom(http.StatusBadRequest, "Invalid input"))
 	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponseBom(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
 
 	// Ambil data RFQ lama untuk validasi
 	existingRfq, err := h.rfqService.FindRfqById(input.RfqId)
@@ -126,19 +127,26 @@ func (h *RfqHandler) UpdateRfq(c echo.Context) error {
 		input.VendorId,
 		existingRfq.Status, // Status lama
 	)
+	updatedRfq.TaxRounding = input.TaxRounding
 
 	// Tambahkan produk baru dengan VendorId yang sudah diset
 	var products []entity.RfqsProduct
 	for _, product := range input.Products {
+		promised, err := optionalDate(product.PromisedDate)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "promised_date must be formatted as YYYY-MM-DD"))
+		}
 		// Pastikan VendorId tetap diatur
 		products = append(products, entity.RfqsProduct{
-			ProductId:   product.ProductId,
-			ProductName: product.ProductName,
-			Quantity:    product.Quantity,
-			UnitPrice:   product.UnitPrice,
-			Tax:         product.Tax,
-			Subtotal:    product.Subtotal,
-			VendorId:    input.VendorId,
+			LineType:     product.LineType,
+			ProductId:    product.ProductId,
+			MaterialId:   product.MaterialId,
+			ProductName:  product.ProductName,
+			Quantity:     product.Quantity,
+			UnitPrice:    product.UnitPrice,
+			LineAmounts:  entity.LineAmounts{Discount: product.Discount, TaxId: product.TaxId, Tax: product.Tax},
+			PromisedDate: promised,
+			VendorId:     input.VendorId,
 		})
 	}
 	updatedRfq.Products = products
@@ -146,7 +154,7 @@ func (h *RfqHandler) UpdateRfq(c echo.Context) error {
 	// Perbarui data RFQ
 	result, err := h.rfqService.UpdateRfq(updatedRfq)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.ErrorResponseBom(http.StatusInternalServerError, "Failed to update RFQ"))
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponseBom(http.StatusInternalServerError, "Failed to update RFQ: "+err.Error()))
 	}
 
 	return c.JSON(http.StatusOK, response.BOMResponse{
@@ -174,6 +182,10 @@ func (h *RfqHandler) UpdateRfqStatus(c echo.Context) error {
 	// Call service to update the manufacture order status
 	updatedMo, err := h.rfqService.UpdateRfqStatus(input.RfqId)
 	if err != nil {
+		var approvalErr *service.PoApprovalRequiredError
+		if errors.As(err, &approvalErr) {
+			return c.JSON(http.StatusConflict, response.ErrorResponseWithData(http.StatusConflict, approvalErr.Error(), approvalErr.Approvals))
+		}
 		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
 
@@ -305,6 +317,9 @@ func (h *RfqHandler) UpdateRfqAll(c echo.Context) error {
 	if err := c.Bind(&input); err != nil {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponseBom(http.StatusBadRequest, "Invalid input"))
 	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponseBom(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
 
 	// Periksa apakah vendor ID valid
 	exists, err := h.rfqService.GetCheckIDProduct(input.VendorId)
@@ -315,31 +330,28 @@ func (h *RfqHandler) UpdateRfqAll(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, response.ErrorResponseBom(http.StatusNotFound, fmt.Sprintf("Vendor with id %s does not exist", input.VendorId)))
 	}
 
-	// Validasi material untuk setiap produk baru
-	for _, product := range input.Products {
-		materialCheck, err := h.rfqService.GetCheckIDMaterial(product.ProductId)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, response.ErrorResponseBom(http.StatusInternalServerError, err.Error()))
-		}
-		if !materialCheck {
-			return c.JSON(http.StatusNotFound, response.ErrorResponseBom(http.StatusNotFound, fmt.Sprintf("Product with id %s does not exist", product.ProductId)))
-		}
-	}
-
 	// Update RFQ
 	updatedRfq := entity.NewRfqs(rfqId, input.OrderDate, input.Status, input.VendorId)
+	updatedRfq.Status = input.Status // kosong jika tidak diubah
+	updatedRfq.TaxRounding = input.TaxRounding
 
 	// Proses produk baru
 	var products []entity.RfqsProduct
 	for _, product := range input.Products {
+		promised, err := optionalDate(product.PromisedDate)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "promised_date must be formatted as YYYY-MM-DD"))
+		}
 		products = append(products, entity.RfqsProduct{
-			ProductId:   product.ProductId,
-			ProductName: product.ProductName,
-			Quantity:    product.Quantity,
-			UnitPrice:   product.UnitPrice,
-			Tax:         product.Tax,
-			Subtotal:    product.Subtotal,
-			VendorId:    input.VendorId, // Tambahkan VendorId
+			LineType:     product.LineType,
+			ProductId:    product.ProductId,
+			MaterialId:   product.MaterialId,
+			ProductName:  product.ProductName,
+			Quantity:     product.Quantity,
+			UnitPrice:    product.UnitPrice,
+			LineAmounts:  entity.LineAmounts{Discount: product.Discount, TaxId: product.TaxId, Tax: product.Tax},
+			PromisedDate: promised,
+			VendorId:     input.VendorId, // Tambahkan VendorId
 		})
 	}
 	updatedRfq.Products = products
@@ -348,7 +360,7 @@ func (h *RfqHandler) UpdateRfqAll(c echo.Context) error {
 	// Simpan perubahan RFQ ke database
 	savedRfq, err := h.rfqService.UpdateRfqAll(rfqId, updatedRfq)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.ErrorResponseBom(http.StatusInternalServerError, "Failed to update RFQ"))
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponseBom(http.StatusInternalServerError, "Failed to update RFQ: "+err.Error()))
 	}
 
 	return c.JSON(http.StatusOK, response.BOMResponse{
@@ -359,3 +371,59 @@ func (h *RfqHandler) UpdateRfqAll(c echo.Context) error {
 		DataBom: *savedRfq,
 	})
 }
+
+func (h *RfqHandler) ReceiveRfq(c echo.Context) error {
+	var input binder.RfqReceiptRequest
+
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	lines := make([]service.ReceiptLine, 0, len(input.Lines))
+	for _, line := range input.Lines {
+		lines = append(lines, service.ReceiptLine{
+			RfqsProductId: line.RfqsProductId,
+			Quantity:      line.Quantity,
+			LotNumber:     line.LotNumber,
+		})
+	}
+
+	receipt, err := h.rfqService.ReceiveRfq(input.RfqId, input.ReceivedBy, input.Note, lines)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusCreated, response.SuccessResponse(http.StatusCreated, "Successfully received goods", receipt))
+}
+
+func (h *RfqHandler) CancelRfqBackorder(c echo.Context) error {
+	var input binder.CancelBackorderRequest
+
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Input binding error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	receipt, err := h.rfqService.CancelBackorder(input.RfqId, input.CancelledBy, input.Reason, input.Lines)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully cancelled backorder", receipt))
+}
+
+func (h *RfqHandler) GetRfqReceipts(c echo.Context) error {
+	rfqId := c.Param("id_rfq")
+
+	receipts, err := h.rfqService.GetRfqReceipts(rfqId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show RFQ receipts", receipts))
+}
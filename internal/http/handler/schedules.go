@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
@@ -28,6 +29,17 @@ func (h *SchedulesHandler) CreateSchedules(c echo.Context) error {
 	}
 
 	NewSchedules := entity.NewSchedules(input.Title, input.Qty_kolam, input.Date_schedules)
+	if input.VesselId != "" {
+		NewSchedules.VesselId = input.VesselId
+		NewSchedules.VolumeLitres = input.VolumeLitres
+		if input.DateEnd != "" {
+			dateEnd, err := time.ParseInLocation("2006-01-02", input.DateEnd, time.Local)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "date_end must use the YYYY-MM-DD format"))
+			}
+			NewSchedules.DateEnd = &dateEnd
+		}
+	}
 	schedule, err := h.schedulesService.CreateSchedules(NewSchedules)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/service"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/response"
+	"github.com/labstack/echo/v4"
+)
+
+type ScorecardHandler struct {
+	scorecardService service.ScorecardService
+}
+
+func NewScorecardHandler(scorecardService service.ScorecardService) ScorecardHandler {
+	return ScorecardHandler{scorecardService: scorecardService}
+}
+
+// scorecardPeriod turns the inclusive from and to dates of the request into
+// a [from, to) range, defaulting to the twelve months up to today.
+func scorecardPeriod(input binder.VendorScorecardRequest) (time.Time, time.Time, error) {
+	now := time.Now()
+	to, err := parseCalendarDate(input.To, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	from, err := parseCalendarDate(input.From, to.AddDate(-1, 0, 1))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+func (h *ScorecardHandler) GetVendorScorecard(c echo.Context) error {
+	var input binder.VendorScorecardRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	from, to, err := scorecardPeriod(input)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "from and to must be formatted as YYYY-MM-DD"))
+	}
+
+	scorecard, err := h.scorecardService.GetVendorScorecard(input.VendorId, from, to)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show vendor scorecard", scorecard))
+}
+
+func (h *ScorecardHandler) RankVendors(c echo.Context) error {
+	var input binder.VendorScorecardRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	from, to, err := scorecardPeriod(input)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "from and to must be formatted as YYYY-MM-DD"))
+	}
+
+	scorecards, err := h.scorecardService.RankVendors(from, to)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success rank vendors", scorecards))
+}
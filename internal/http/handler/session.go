@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// sessionUserKey is where Session leaves the authenticated user in the
+// request context.
+const sessionUserKey = "session_user"
+
+// Session resolves the bearer token of a request to the user it was issued
+// to. Requests without a valid session still go through, anonymously;
+// handlers that depend on who is asking look the user up with currentUser.
+func Session(userService service.UserService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			if accessToken, ok := strings.CutPrefix(header, "Bearer "); ok && accessToken != "" {
+				if user, err := userService.Authenticate(accessToken); err == nil {
+					c.Set(sessionUserKey, user)
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// revealFormula reports whether the authenticated caller may see formula
+// quantities.
+func revealFormula(c echo.Context) bool {
+	return service.CanViewFormula(currentUser(c))
+}
+
+// currentUser returns the authenticated user of the request, or nil.
+func currentUser(c echo.Context) *entity.User {
+	user, _ := c.Get(sessionUserKey).(*entity.User)
+	return user
+}
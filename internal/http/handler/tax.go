@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/service"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/response"
+	"github.com/labstack/echo/v4"
+)
+
+type TaxHandler struct {
+	taxService service.TaxService
+}
+
+func NewTaxHandler(taxService service.TaxService) TaxHandler {
+	return TaxHandler{taxService: taxService}
+}
+
+func taxFromRequest(input binder.TaxRequest) *entity.Tax {
+	active := true
+	if input.Active != nil {
+		active = *input.Active
+	}
+	return &entity.Tax{
+		TaxId:         input.TaxId,
+		Name:          input.Name,
+		Rate:          input.Rate,
+		PriceIncluded: input.PriceIncluded,
+		Active:        active,
+	}
+}
+
+func (h *TaxHandler) CreateTax(c echo.Context) error {
+	var input binder.TaxRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	tax, err := h.taxService.CreateTax(taxFromRequest(input))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully created tax", tax))
+}
+
+func (h *TaxHandler) UpdateTax(c echo.Context) error {
+	var input binder.TaxRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	tax, err := h.taxService.UpdateTax(taxFromRequest(input))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully updated tax", tax))
+}
+
+func (h *TaxHandler) DeleteTax(c echo.Context) error {
+	isDeleted, err := h.taxService.DeleteTax(c.Param("id_tax"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully deleted tax", isDeleted))
+}
+
+func (h *TaxHandler) FindAllTaxes(c echo.Context) error {
+	taxes, err := h.taxService.FindAllTaxes()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show taxes", taxes))
+}
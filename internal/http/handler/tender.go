@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/service"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/response"
+	"github.com/labstack/echo/v4"
+)
+
+type TenderHandler struct {
+	tenderService service.TenderService
+}
+
+func NewTenderHandler(tenderService service.TenderService) TenderHandler {
+	return TenderHandler{tenderService: tenderService}
+}
+
+func (h *TenderHandler) CreateTender(c echo.Context) error {
+	var input binder.TenderCreateRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+	deadline, err := optionalDate(input.Deadline)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "deadline must be formatted as YYYY-MM-DD"))
+	}
+
+	tender := &entity.Tender{
+		Title:     input.Title,
+		OrderDate: input.OrderDate,
+		Deadline:  deadline,
+	}
+	for _, line := range input.Lines {
+		tender.Lines = append(tender.Lines, entity.TenderLine{ItemId: line.ItemId, Quantity: line.Quantity})
+	}
+
+	saved, err := h.tenderService.CreateTender(tender, input.Vendors)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully created tender", saved))
+}
+
+func (h *TenderHandler) GetTender(c echo.Context) error {
+	tender, err := h.tenderService.GetTender(c.Param("id_tender"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show tender", tender))
+}
+
+func (h *TenderHandler) FindAllTenders(c echo.Context) error {
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	tenders, err := h.tenderService.FindAllTenders(page)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show tenders", tenders))
+}
+
+func (h *TenderHandler) SendTender(c echo.Context) error {
+	results, err := h.tenderService.SendTender(c.Param("id_tender"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Tender sent to vendors", results))
+}
+
+func (h *TenderHandler) RecordBids(c echo.Context) error {
+	var input binder.TenderBidRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	bids := make([]entity.TenderBid, 0, len(input.Bids))
+	for _, bid := range input.Bids {
+		bids = append(bids, entity.TenderBid{
+			TenderLineId: bid.TenderLineId,
+			UnitPrice:    bid.UnitPrice,
+			LeadTimeDays: bid.LeadTimeDays,
+			Note:         bid.Note,
+		})
+	}
+
+	saved, err := h.tenderService.RecordBids(input.TenderId, input.VendorId, bids)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully recorded bids", saved))
+}
+
+func (h *TenderHandler) CompareBids(c echo.Context) error {
+	comparison, err := h.tenderService.CompareBids(c.Param("id_tender"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success compare tender bids", comparison))
+}
+
+func (h *TenderHandler) AwardTender(c echo.Context) error {
+	var input binder.TenderAwardRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	tender, err := h.tenderService.AwardTender(input.TenderId, input.Awards)
+	if err != nil {
+		var approvalErr *service.PoApprovalRequiredError
+		if errors.As(err, &approvalErr) {
+			return c.JSON(http.StatusConflict, response.ErrorResponseWithData(http.StatusConflict, approvalErr.Error(), approvalErr.Approvals))
+		}
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully awarded tender", tender))
+}
+
+func (h *TenderHandler) CancelTender(c echo.Context) error {
+	tender, err := h.tenderService.CancelTender(c.Param("id_tender"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully cancelled tender", tender))
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/service"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/response"
+	"github.com/labstack/echo/v4"
+)
+
+type VarianceHandler struct {
+	varianceService service.VarianceService
+}
+
+func NewVarianceHandler(varianceService service.VarianceService) VarianceHandler {
+	return VarianceHandler{varianceService: varianceService}
+}
+
+func (h *VarianceHandler) GetMoVariance(c echo.Context) error {
+	variance, err := h.varianceService.GetMoVariance(c.Param("id_mo"), revealFormula(c))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "material variance retrieved successfully", variance))
+}
+
+func (h *VarianceHandler) VarianceReport(c echo.Context) error {
+	var input binder.VarianceReportRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if input.GroupBy == "" {
+		input.GroupBy = "product"
+	}
+
+	from, err := parseCalendarDate(input.From, time.Time{})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "from must use the YYYY-MM-DD format"))
+	}
+	to, err := parseCalendarDate(input.To, time.Now())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "to must use the YYYY-MM-DD format"))
+	}
+
+	report, err := h.varianceService.VarianceReport(input.GroupBy, input.Interval, from, to.AddDate(0, 0, 1), revealFormula(c))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "variance report retrieved successfully", report))
+}
@@ -80,6 +80,24 @@ func (h *VendorHandler) UpdateVendor(c echo.Context) error {
 	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success update vendor", updatedProduk))
 }
 
+func (h *VendorHandler) SetPaymentTerms(c echo.Context) error {
+	var input binder.VendorPaymentTermsRequest
+
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	vendor, err := h.vendorService.SetPaymentTerms(input.VendorId, input.PaymentTermDays, input.UpfrontPercent)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success update vendor payment terms", vendor))
+}
+
 func (h *VendorHandler) DeleteVendor(c echo.Context) error {
 	var input binder.VendorDeleteRequest
 
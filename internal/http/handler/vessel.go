@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/service"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/response"
+	"github.com/labstack/echo/v4"
+)
+
+type VesselHandler struct {
+	vesselService service.VesselService
+}
+
+func NewVesselHandler(vesselService service.VesselService) VesselHandler {
+	return VesselHandler{vesselService: vesselService}
+}
+
+// parseCalendarDate reads an optional YYYY-MM-DD query value.
+func parseCalendarDate(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+func (h *VesselHandler) CreateVessel(c echo.Context) error {
+	var input binder.VesselRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	vessel, err := h.vesselService.CreateVessel(&entity.Vessel{
+		Name:           input.Name,
+		CapacityLitres: input.CapacityLitres,
+		Description:    input.Description,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully input a new tank", vessel))
+}
+
+func (h *VesselHandler) UpdateVessel(c echo.Context) error {
+	var input binder.VesselRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	vessel, err := h.vesselService.UpdateVessel(&entity.Vessel{
+		VesselId:       input.VesselId,
+		Name:           input.Name,
+		CapacityLitres: input.CapacityLitres,
+		Description:    input.Description,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success update tank", vessel))
+}
+
+func (h *VesselHandler) DeleteVessel(c echo.Context) error {
+	var input binder.VesselIdRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+
+	isDeleted, err := h.vesselService.DeleteVessel(input.VesselId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success delete tank", isDeleted))
+}
+
+func (h *VesselHandler) GetVessel(c echo.Context) error {
+	var input binder.VesselIdRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+
+	vessel, err := h.vesselService.GetVesselByID(input.VesselId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "successfully displays tank", vessel))
+}
+
+func (h *VesselHandler) FindAllVessels(c echo.Context) error {
+	vessels, err := h.vesselService.FindAllVessels()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show data tanks", vessels))
+}
+
+func (h *VesselHandler) GetTankCalendar(c echo.Context) error {
+	var input binder.TankCalendarRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+
+	now := time.Now()
+	from, err := parseCalendarDate(input.From, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "from must use the YYYY-MM-DD format"))
+	}
+	to, err := parseCalendarDate(input.To, from.AddDate(0, 0, 30))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "to must use the YYYY-MM-DD format"))
+	}
+
+	calendar, err := h.vesselService.GetTankCalendar(from, to)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "successfully displays tank calendar", calendar))
+}
+
+func (h *VesselHandler) FindNextFreeSlot(c echo.Context) error {
+	var input binder.TankSlotRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	from, err := parseCalendarDate(input.From, time.Now())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "from must use the YYYY-MM-DD format"))
+	}
+
+	slot, err := h.vesselService.FindNextFreeSlot(input.Litres, input.Days, from)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "next free tank slot", slot))
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/http/binder"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/service"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/response"
+	"github.com/labstack/echo/v4"
+)
+
+type WorkCenterHandler struct {
+	workCenterService service.WorkCenterService
+}
+
+func NewWorkCenterHandler(workCenterService service.WorkCenterService) WorkCenterHandler {
+	return WorkCenterHandler{workCenterService: workCenterService}
+}
+
+func (h *WorkCenterHandler) CreateWorkCenter(c echo.Context) error {
+	var input binder.WorkCenterRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	workCenter, err := h.workCenterService.CreateWorkCenter(&entity.WorkCenter{
+		Name:        input.Name,
+		Capacity:    input.Capacity,
+		HourlyCost:  input.HourlyCost,
+		Description: input.Description,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully input a new work center", workCenter))
+}
+
+func (h *WorkCenterHandler) UpdateWorkCenter(c echo.Context) error {
+	var input binder.WorkCenterRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	workCenter, err := h.workCenterService.UpdateWorkCenter(&entity.WorkCenter{
+		WorkCenterId: input.WorkCenterId,
+		Name:         input.Name,
+		Capacity:     input.Capacity,
+		HourlyCost:   input.HourlyCost,
+		Description:  input.Description,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success update work center", workCenter))
+}
+
+func (h *WorkCenterHandler) DeleteWorkCenter(c echo.Context) error {
+	var input binder.WorkCenterIdRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+
+	isDeleted, err := h.workCenterService.DeleteWorkCenter(input.WorkCenterId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success delete work center", isDeleted))
+}
+
+func (h *WorkCenterHandler) GetWorkCenter(c echo.Context) error {
+	var input binder.WorkCenterIdRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+
+	workCenter, err := h.workCenterService.GetWorkCenterByID(input.WorkCenterId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "successfully displays work center", workCenter))
+}
+
+func (h *WorkCenterHandler) FindAllWorkCenters(c echo.Context) error {
+	workCenters, err := h.workCenterService.FindAllWorkCenters()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "success show data work centers", workCenters))
+}
+
+func (h *WorkCenterHandler) SetRouting(c echo.Context) error {
+	var input binder.RoutingRequest
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "there is an input error"))
+	}
+	if err := c.Validate(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Validation Error: "+err.Error()))
+	}
+
+	var operations []entity.RoutingOperation
+	for _, operation := range input.Operations {
+		operations = append(operations, entity.RoutingOperation{
+			Sequence:        operation.Sequence,
+			Name:            operation.Name,
+			WorkCenterId:    operation.WorkCenterId,
+			DurationMinutes: operation.DurationMinutes,
+			MinutesPerUnit:  operation.MinutesPerUnit,
+		})
+	}
+
+	routing, err := h.workCenterService.SetRouting(input.BomId, operations)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "Successfully saved BOM routing", routing))
+}
+
+func (h *WorkCenterHandler) GetRouting(c echo.Context) error {
+	bomId := c.Param("id_bom")
+
+	routing, err := h.workCenterService.GetRouting(bomId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.SuccessResponse(http.StatusOK, "BOM routing retrieved successfully", routing))
+}
@@ -8,12 +8,13 @@ import (
 )
 
 const (
-	Admin = "admin"
-	User  = "user"
+	Admin    = "admin"
+	User     = "user"
+	Perfumer = "perfumer"
 )
 
 var (
-	allRoles  = []string{Admin, User}
+	allRoles  = []string{Admin, User, Perfumer}
 	onlyAdmin = []string{Admin}
 	onlyUser  = []string{User}
 )
@@ -46,7 +47,13 @@ func PublicRoutes(userHandler handler.UserHandler, adminHandler handler.AdminHan
 func PrivateRoutes(userHandler handler.UserHandler, suggestionHandler handler.SuggestionHandler, adminHandler handler.AdminHandler,
 	schedulesHandler handler.SchedulesHandler, productHandler handler.ProductHandler, materialHandler handler.MaterialHandler,
 	bomHandler handler.BOMHandler, moHandler handler.MoHandler, vendorHandler handler.VendorHandler, rfqHandler handler.RfqHandler,
-	costumerHandler handler.CostumerHandler, quoHandler handler.QuoHandler, billrfqHandler handler.BillrfqHandler) []*route.Route {
+	costumerHandler handler.CostumerHandler, quoHandler handler.QuoHandler, billrfqHandler handler.BillrfqHandler,
+	mrpHandler handler.MrpHandler, workCenterHandler handler.WorkCenterHandler,
+	vesselHandler handler.VesselHandler, qualityHandler handler.QualityHandler,
+	varianceHandler handler.VarianceHandler, complianceHandler handler.ComplianceHandler,
+	priceListHandler handler.PriceListHandler, tenderHandler handler.TenderHandler,
+	poApprovalHandler handler.PoApprovalHandler, scorecardHandler handler.ScorecardHandler, taxHandler handler.TaxHandler,
+	purchaseReturnHandler handler.PurchaseReturnHandler) []*route.Route {
 	return []*route.Route{
 		//user
 		{
@@ -258,12 +265,252 @@ func PrivateRoutes(userHandler handler.UserHandler, suggestionHandler handler.Su
 			Handler: materialHandler.IncreaseMaterialQty,
 			Roles:   allRoles,
 		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/material/:id_material/constituents",
+			Handler: complianceHandler.SetMaterialConstituents,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/material/:id_material/constituents",
+			Handler: complianceHandler.GetMaterialConstituents,
+			Roles:   allRoles,
+		},
+		//compliance
+		{
+			Method:  http.MethodPost,
+			Path:    "/compliance/limit",
+			Handler: complianceHandler.CreateLimit,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/compliance/limit/:id_compliancelimit",
+			Handler: complianceHandler.UpdateLimit,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/compliance/limit/:id_compliancelimit",
+			Handler: complianceHandler.DeleteLimit,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/compliance/limit/all",
+			Handler: complianceHandler.FindAllLimits,
+			Roles:   allRoles,
+		},
+		//price list
+		{
+			Method:  http.MethodPost,
+			Path:    "/pricelist",
+			Handler: priceListHandler.CreatePrice,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/pricelist/:id_vendorprice",
+			Handler: priceListHandler.UpdatePrice,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/pricelist/:id_vendorprice",
+			Handler: priceListHandler.DeletePrice,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/pricelist",
+			Handler: priceListHandler.FindPrices,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/pricelist/history",
+			Handler: priceListHandler.GetPriceHistory,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/pricelist/quote",
+			Handler: priceListHandler.QuotePrice,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/rfq/:id_rfq/update-prices",
+			Handler: priceListHandler.UpdateFromPurchaseOrder,
+			Roles:   allRoles,
+		},
+		//tender
+		{
+			Method:  http.MethodPost,
+			Path:    "/tender",
+			Handler: tenderHandler.CreateTender,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/tender/all",
+			Handler: tenderHandler.FindAllTenders,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/tender/:id_tender",
+			Handler: tenderHandler.GetTender,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/tender/:id_tender/send",
+			Handler: tenderHandler.SendTender,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/tender/:id_tender/bid",
+			Handler: tenderHandler.RecordBids,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/tender/:id_tender/comparison",
+			Handler: tenderHandler.CompareBids,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/tender/:id_tender/award",
+			Handler: tenderHandler.AwardTender,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/tender/:id_tender/cancel",
+			Handler: tenderHandler.CancelTender,
+			Roles:   allRoles,
+		},
+		//purchase order approval
+		{
+			Method:  http.MethodGet,
+			Path:    "/po-approval/rules",
+			Handler: poApprovalHandler.FindAllRules,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/po-approval/rules",
+			Handler: poApprovalHandler.CreateRule,
+			Roles:   onlyAdmin,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/po-approval/rules/:id_approvalrule",
+			Handler: poApprovalHandler.UpdateRule,
+			Roles:   onlyAdmin,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/po-approval/rules/:id_approvalrule",
+			Handler: poApprovalHandler.DeleteRule,
+			Roles:   onlyAdmin,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/po-approval/pending",
+			Handler: poApprovalHandler.FindPendingApprovals,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/po-approval/:id_rfqapproval/approve",
+			Handler: poApprovalHandler.Approve,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/po-approval/:id_rfqapproval/reject",
+			Handler: poApprovalHandler.Reject,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/rfq/:id_rfq/approvals",
+			Handler: poApprovalHandler.GetRfqApprovals,
+			Roles:   allRoles,
+		},
+		//tax
+		{
+			Method:  http.MethodGet,
+			Path:    "/tax",
+			Handler: taxHandler.FindAllTaxes,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/tax",
+			Handler: taxHandler.CreateTax,
+			Roles:   onlyAdmin,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/tax/:id_tax",
+			Handler: taxHandler.UpdateTax,
+			Roles:   onlyAdmin,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/tax/:id_tax",
+			Handler: taxHandler.DeleteTax,
+			Roles:   onlyAdmin,
+		},
+		//purchase return
+		{
+			Method:  http.MethodPost,
+			Path:    "/purchasereturn",
+			Handler: purchaseReturnHandler.CreateReturn,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/purchasereturn",
+			Handler: purchaseReturnHandler.FindReturns,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/purchasereturn/creditnotes",
+			Handler: purchaseReturnHandler.FindCreditNotes,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/purchasereturn/creditnotes/:id_creditnote/apply",
+			Handler: purchaseReturnHandler.ApplyCreditNote,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/purchasereturn/:id_return",
+			Handler: purchaseReturnHandler.GetReturn,
+			Roles:   allRoles,
+		},
 		{
 			Method:  http.MethodPost,
 			Path:    "/bom",
 			Handler: bomHandler.CreateBOM,
 			Roles:   allRoles,
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/bom/simulate",
+			Handler: bomHandler.SimulateBOMCosts,
+			Roles:   allRoles,
+		},
 		{
 			Method:  http.MethodGet,
 			Path:    "/bom/all",
@@ -300,6 +547,42 @@ func PrivateRoutes(userHandler handler.UserHandler, suggestionHandler handler.Su
 			Handler: bomHandler.GetBOMPDF,
 			Roles:   allRoles,
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/bom/:id_bom/explode",
+			Handler: bomHandler.ExplodeBOM,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/bom/:id_bom/compliance",
+			Handler: bomHandler.GetBOMCompliance,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/bom/:id_bom/versions",
+			Handler: bomHandler.GetBOMVersions,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/bom/:id_bom/diff",
+			Handler: bomHandler.DiffBOMVersions,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/bom/:id_bom/routing",
+			Handler: workCenterHandler.SetRouting,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/bom/:id_bom/routing",
+			Handler: workCenterHandler.GetRouting,
+			Roles:   allRoles,
+		},
 		{
 			Method:  http.MethodPost,
 			Path:    "/mo",
@@ -318,6 +601,138 @@ func PrivateRoutes(userHandler handler.UserHandler, suggestionHandler handler.Su
 			Handler: moHandler.FindAllMos,
 			Roles:   allRoles,
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/mo/shortage",
+			Handler: moHandler.ShortageReport,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/mo/shortage/rfq",
+			Handler: moHandler.CreateShortageRfq,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/mo/:id_mo/availability",
+			Handler: moHandler.GetMoAvailability,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/mo/:id_mo/subassemblies",
+			Handler: moHandler.GenerateSubAssemblyMos,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/mo/:id_mo/workorders",
+			Handler: moHandler.GetMoWorkOrders,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/workorder/:id_workorder/start",
+			Handler: moHandler.StartWorkOrder,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/workorder/:id_workorder/pause",
+			Handler: moHandler.PauseWorkOrder,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/workorder/:id_workorder/finish",
+			Handler: moHandler.FinishWorkOrder,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/mo/:id_mo/tank",
+			Handler: moHandler.BookTank,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/mo/gantt",
+			Handler: moHandler.GetGantt,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/mo/:id_mo/schedule",
+			Handler: moHandler.ScheduleMo,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPatch,
+			Path:    "/mo/:id_mo/schedule",
+			Handler: moHandler.RescheduleMo,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPatch,
+			Path:    "/workorder/:id_workorder/schedule",
+			Handler: moHandler.RescheduleWorkOrder,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/mo/:id_mo/produce",
+			Handler: moHandler.ProduceMo,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/mo/:id_mo/productions",
+			Handler: moHandler.GetMoProductions,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/mo/:id_mo/close",
+			Handler: moHandler.CloseMo,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/mo/:id_mo/batch-record",
+			Handler: moHandler.GetBatchRecord,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/mo/:id_mo/cancel",
+			Handler: moHandler.CancelMo,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/mo/:id_mo/unbuild",
+			Handler: moHandler.UnbuildMo,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/mo/:id_mo/reversals",
+			Handler: moHandler.GetMoReversals,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/mo/:id_mo/variance",
+			Handler: varianceHandler.GetMoVariance,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/variance/report",
+			Handler: varianceHandler.VarianceReport,
+			Roles:   allRoles,
+		},
 		{
 			Method:  http.MethodGet,
 			Path:    "/mo/:id_mo",
@@ -349,6 +764,12 @@ func PrivateRoutes(userHandler handler.UserHandler, suggestionHandler handler.Su
 			Handler: vendorHandler.UpdateVendor,
 			Roles:   allRoles,
 		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/vendor/:id_vendor/payment-terms",
+			Handler: vendorHandler.SetPaymentTerms,
+			Roles:   allRoles,
+		},
 		{
 			Method:  http.MethodDelete,
 			Path:    "/vendor/:id_vendor",
@@ -379,6 +800,18 @@ func PrivateRoutes(userHandler handler.UserHandler, suggestionHandler handler.Su
 			Handler: vendorHandler.DownloadAllVendorsPDF,
 			Roles:   allRoles,
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/vendor/scorecard",
+			Handler: scorecardHandler.RankVendors,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/vendor/:id_vendor/scorecard",
+			Handler: scorecardHandler.GetVendorScorecard,
+			Roles:   allRoles,
+		},
 		//RFQ
 		{
 			Method:  http.MethodPost,
@@ -440,6 +873,24 @@ func PrivateRoutes(userHandler handler.UserHandler, suggestionHandler handler.Su
 			Handler: rfqHandler.HandleCreateRfqPDF,
 			Roles:   allRoles,
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/rfq/:id_rfq/receipt",
+			Handler: rfqHandler.ReceiveRfq,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/rfq/:id_rfq/receipts",
+			Handler: rfqHandler.GetRfqReceipts,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/rfq/:id_rfq/backorder/cancel",
+			Handler: rfqHandler.CancelRfqBackorder,
+			Roles:   allRoles,
+		},
 		//costumer
 		{
 			Method:  http.MethodPost,
@@ -544,5 +995,263 @@ func PrivateRoutes(userHandler handler.UserHandler, suggestionHandler handler.Su
 			Handler: billrfqHandler.CreateMo,
 			Roles:   allRoles,
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/billrfq/all",
+			Handler: billrfqHandler.FindAllBills,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/billrfq/payables",
+			Handler: billrfqHandler.GetOpenPayables,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/billrfq/tolerances",
+			Handler: billrfqHandler.FindAllTolerances,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/billrfq/tolerances",
+			Handler: billrfqHandler.SetTolerance,
+			Roles:   onlyAdmin,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/billrfq/:id_bill",
+			Handler: billrfqHandler.GetBill,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/billrfq/:id_bill/lines",
+			Handler: billrfqHandler.UpdateBillLines,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/billrfq/:id_bill/match",
+			Handler: billrfqHandler.MatchBill,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/billrfq/:id_bill/lines/:id_billline/resolve",
+			Handler: billrfqHandler.ResolveBillLine,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/billrfq/:id_bill/post",
+			Handler: billrfqHandler.PostBill,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/billrfq/:id_bill/cancel",
+			Handler: billrfqHandler.CancelBill,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/billrfq/:id_bill/payment",
+			Handler: billrfqHandler.RegisterPayment,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/billrfq/:id_bill/pdf",
+			Handler: billrfqHandler.DownloadBillPDF,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/mrp/run",
+			Handler: mrpHandler.RunMrp,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/mrp/all",
+			Handler: mrpHandler.FindAllRuns,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/mrp/:id_mrprun",
+			Handler: mrpHandler.GetRun,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/mrp/:id_mrprun/confirm",
+			Handler: mrpHandler.ConfirmRun,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/mrp/proposal/:id_mrpproposal",
+			Handler: mrpHandler.UpdateProposal,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/reorder-rule",
+			Handler: mrpHandler.CreateReorderRule,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/reorder-rule/all",
+			Handler: mrpHandler.FindAllReorderRules,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/reorder-rule/:id_reorderrule",
+			Handler: mrpHandler.UpdateReorderRule,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/reorder-rule/:id_reorderrule",
+			Handler: mrpHandler.DeleteReorderRule,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/workcenter",
+			Handler: workCenterHandler.CreateWorkCenter,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/workcenter/all",
+			Handler: workCenterHandler.FindAllWorkCenters,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/workcenter/:id_workcenter",
+			Handler: workCenterHandler.GetWorkCenter,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/workcenter/:id_workcenter",
+			Handler: workCenterHandler.UpdateWorkCenter,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/workcenter/:id_workcenter",
+			Handler: workCenterHandler.DeleteWorkCenter,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/vessel",
+			Handler: vesselHandler.CreateVessel,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/vessel/all",
+			Handler: vesselHandler.FindAllVessels,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/vessel/calendar",
+			Handler: vesselHandler.GetTankCalendar,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/vessel/next-slot",
+			Handler: vesselHandler.FindNextFreeSlot,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/vessel/:id_vessel",
+			Handler: vesselHandler.GetVessel,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/vessel/:id_vessel",
+			Handler: vesselHandler.UpdateVessel,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/vessel/:id_vessel",
+			Handler: vesselHandler.DeleteVessel,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/qc/check",
+			Handler: qualityHandler.CreateCheck,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/qc/check/all",
+			Handler: qualityHandler.FindAllChecks,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/qc/check/:id_qualitycheck",
+			Handler: qualityHandler.UpdateCheck,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/qc/check/:id_qualitycheck",
+			Handler: qualityHandler.DeleteCheck,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/qc/inspection/all",
+			Handler: qualityHandler.FindInspections,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/qc/inspection/:id_inspection",
+			Handler: qualityHandler.GetInspection,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/qc/inspection/:id_inspection/results",
+			Handler: qualityHandler.RecordResults,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/qc/inspection/:id_inspection/release",
+			Handler: qualityHandler.ReleaseInspection,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/qc/inspection/:id_inspection/reject",
+			Handler: qualityHandler.RejectInspection,
+			Roles:   allRoles,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/qc/report/failure-rate",
+			Handler: qualityHandler.FailureRateReport,
+			Roles:   allRoles,
+		},
 	}
 }
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -21,6 +22,7 @@ type AdminRepository interface {
 	DeleteAdmin(admin *entity.Admin) (bool, error)
 	SaveVerifCode(userID uuid.UUID, resetCode string) error
 	CheckUserExists(id uuid.UUID) (bool, error)
+	UpdateAdminJwtToken(userID uuid.UUID, token string, expiresAt time.Time) error
 }
 
 type adminRepository struct {
@@ -130,3 +132,19 @@ func (r *adminRepository) SaveVerifCode(id_user uuid.UUID, resetCode string) err
 		"verification_code": resetCode,
 	}).Error
 }
+
+func (r *adminRepository) UpdateAdminJwtToken(userID uuid.UUID, token string, expiresAt time.Time) error {
+	result := r.db.Model(&entity.Admin{}).
+		Where("id_user = ? AND deleted_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"jwt_token":            token,
+			"jwt_token_expires_at": expiresAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("admin not found or already deleted")
+	}
+	return nil
+}
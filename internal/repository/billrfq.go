@@ -11,6 +11,22 @@ import (
 type BillrfqRepository interface {
 	GetLastMo() (string, error)
 	CreateMo(mo *entity.Billrfq) (*entity.Billrfq, error)
+	FindBillByID(billId string) (*entity.Billrfq, error)
+	UpdateBill(bill *entity.Billrfq) (*entity.Billrfq, error)
+	GetLastBillLineId() (string, error)
+	CreateBillLine(line *entity.BillLine) (*entity.BillLine, error)
+	UpdateBillLine(line *entity.BillLine) (*entity.BillLine, error)
+	DeleteBillLines(billId string) error
+	SumBilledQty(rfqProductId, excludeBillId string) (float64, error)
+	GetLastToleranceId() (string, error)
+	FindToleranceByVendor(vendorId string) (*entity.BillMatchTolerance, error)
+	FindAllTolerances() ([]entity.BillMatchTolerance, error)
+	SaveTolerance(tolerance *entity.BillMatchTolerance) (*entity.BillMatchTolerance, error)
+	FindAllBills(page int, status string) ([]entity.Billrfq, error)
+	FindOpenBills(vendorId string) ([]entity.Billrfq, error)
+	GetLastPaymentId() (string, error)
+	CreatePayment(payment *entity.BillPayment) (*entity.BillPayment, error)
+	FindPostedLinesByRfqId(rfqId string) ([]entity.BillLine, error)
 }
 
 type billrfqRepository struct {
@@ -36,9 +52,166 @@ func (r *billrfqRepository) GetLastMo() (string, error) {
 }
 
 func (r *billrfqRepository) CreateMo(mo *entity.Billrfq) (*entity.Billrfq, error) {
-	if err := r.db.Create(&mo).Error; err != nil {
+	if err := r.db.Omit("Lines", "Payments").Create(&mo).Error; err != nil {
 		return mo, err
 	}
 	r.cacheable.Delete("FindAllMo_page_1")
 	return mo, nil
 }
+
+func (r *billrfqRepository) FindBillByID(billId string) (*entity.Billrfq, error) {
+	var bill entity.Billrfq
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_billline")
+	}).Preload("Payments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_payment")
+	}).Where("id_bill = ?", billId).First(&bill).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &bill, nil
+}
+
+func (r *billrfqRepository) UpdateBill(bill *entity.Billrfq) (*entity.Billrfq, error) {
+	if err := r.db.Omit("Lines", "Payments").Save(bill).Error; err != nil {
+		return nil, err
+	}
+	return bill, nil
+}
+
+func (r *billrfqRepository) GetLastBillLineId() (string, error) {
+	var lastLine entity.BillLine
+	err := r.db.Unscoped().Order("id_billline DESC").First(&lastLine).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastLine.BillLineId, nil
+}
+
+func (r *billrfqRepository) CreateBillLine(line *entity.BillLine) (*entity.BillLine, error) {
+	if err := r.db.Create(line).Error; err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func (r *billrfqRepository) UpdateBillLine(line *entity.BillLine) (*entity.BillLine, error) {
+	if err := r.db.Save(line).Error; err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func (r *billrfqRepository) DeleteBillLines(billId string) error {
+	return r.db.Where("id_bill = ?", billId).Delete(&entity.BillLine{}).Error
+}
+
+// SumBilledQty is how much of an RFQ line other bills, not cancelled, already
+// charge for.
+func (r *billrfqRepository) SumBilledQty(rfqProductId, excludeBillId string) (float64, error) {
+	var total float64
+	err := r.db.Model(&entity.BillLine{}).
+		Where("id_rfqproduct = ? AND id_bill <> ?", rfqProductId, excludeBillId).
+		Where("id_bill IN (?)", r.db.Model(&entity.Billrfq{}).Select("id_bill").Where("status <> ?", "Cancelled")).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *billrfqRepository) GetLastToleranceId() (string, error) {
+	var lastTolerance entity.BillMatchTolerance
+	err := r.db.Unscoped().Order("id_tolerance DESC").First(&lastTolerance).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastTolerance.ToleranceId, nil
+}
+
+func (r *billrfqRepository) FindToleranceByVendor(vendorId string) (*entity.BillMatchTolerance, error) {
+	var tolerance entity.BillMatchTolerance
+	if err := r.db.Where("id_vendor = ?", vendorId).First(&tolerance).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tolerance, nil
+}
+
+func (r *billrfqRepository) FindAllTolerances() ([]entity.BillMatchTolerance, error) {
+	var tolerances []entity.BillMatchTolerance
+	if err := r.db.Order("id_vendor").Find(&tolerances).Error; err != nil {
+		return nil, err
+	}
+	return tolerances, nil
+}
+
+func (r *billrfqRepository) SaveTolerance(tolerance *entity.BillMatchTolerance) (*entity.BillMatchTolerance, error) {
+	if err := r.db.Save(tolerance).Error; err != nil {
+		return nil, err
+	}
+	return tolerance, nil
+}
+
+func (r *billrfqRepository) FindAllBills(page int, status string) ([]entity.Billrfq, error) {
+	var bills []entity.Billrfq
+	const pageSize = 100
+
+	query := r.db.Model(&entity.Billrfq{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("id_bill DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&bills).Error; err != nil {
+		return nil, err
+	}
+	return bills, nil
+}
+
+// FindOpenBills returns the posted bills that are not fully paid, of one
+// vendor or of all when vendorId is empty.
+func (r *billrfqRepository) FindOpenBills(vendorId string) ([]entity.Billrfq, error) {
+	var bills []entity.Billrfq
+	query := r.db.Where("status IN ?", []string{"Posted", "Partially Paid"})
+	if vendorId != "" {
+		query = query.Where("id_vendor = ?", vendorId)
+	}
+	if err := query.Order("due_date, id_bill").Find(&bills).Error; err != nil {
+		return nil, err
+	}
+	return bills, nil
+}
+
+func (r *billrfqRepository) GetLastPaymentId() (string, error) {
+	var lastPayment entity.BillPayment
+	err := r.db.Unscoped().Order("id_payment DESC").First(&lastPayment).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastPayment.PaymentId, nil
+}
+
+func (r *billrfqRepository) CreatePayment(payment *entity.BillPayment) (*entity.BillPayment, error) {
+	if err := r.db.Create(payment).Error; err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// FindPostedLinesByRfqId returns the bill lines of an RFQ on bills that were
+// posted, paid or not, leaving out drafts, bills awaiting review and
+// cancelled bills.
+func (r *billrfqRepository) FindPostedLinesByRfqId(rfqId string) ([]entity.BillLine, error) {
+	var lines []entity.BillLine
+	err := r.db.Where("id_rfq = ?", rfqId).
+		Where("id_bill IN (?)", r.db.Model(&entity.Billrfq{}).Select("id_bill").Where("status NOT IN ?", []string{"Draft", "Needs Review", "Cancelled"})).
+		Order("id_billline").Find(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
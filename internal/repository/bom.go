@@ -18,10 +18,13 @@ type BOMRepository interface {
 	CheckProductExists(productId string) (bool, error)
 	FindAllBom(page int) ([]entity.Bom, error)
 	DeleteBom(bomId string) (bool, error)
+	CountMosByBomId(bomId string) (int64, error)
 	UpdateBOM(bom *entity.Bom) (*entity.Bom, error)
 	FindBOMByProductIDAndBOMID(productId string, bomId string) (*entity.Bom, error)
 	FindBOMByID(bomId string) (*entity.Bom, error)
 	GetProductDetails(productId string) (*entity.Products, error)
+	FindBOMByProductID(productId string) (*entity.Bom, error)
+	FindAllActiveBoms() ([]entity.Bom, error)
 }
 
 type bomRepository struct {
@@ -83,6 +86,16 @@ func (r *bomRepository) FindAllBom(page int) ([]entity.Bom, error) {
 	return Bom, nil
 }
 
+// CountMosByBomId counts the manufacture orders made from the BOM, deleted
+// ones included, since each keeps the formula version it was made with.
+func (r *bomRepository) CountMosByBomId(bomId string) (int64, error) {
+	var count int64
+	if err := r.db.Unscoped().Model(&entity.Mos{}).Where("id_bom = ?", bomId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (r *bomRepository) DeleteBom(bomId string) (bool, error) {
 
 	if err := r.db.Unscoped().Where("id_bom = ?", bomId).Delete(&entity.BomMaterial{}).Error; err != nil {
@@ -90,6 +103,11 @@ func (r *bomRepository) DeleteBom(bomId string) (bool, error) {
 		return false, err
 	}
 
+	if err := r.db.Unscoped().Where("id_bom = ?", bomId).Delete(&entity.BomVersion{}).Error; err != nil {
+		log.Printf("Error deleting versions for bom ID %s: %v", bomId, err)
+		return false, err
+	}
+
 	if err := r.db.Unscoped().Where("id_bom = ?", bomId).Delete(&entity.Bom{}).Error; err != nil {
 		log.Printf("Error deleting bom with ID %s: %v", bomId, err)
 		return false, err
@@ -122,14 +140,33 @@ func (r *bomRepository) FindBOMByProductIDAndBOMID(productId string, bomId strin
 }
 
 func (r *bomRepository) FindBOMByID(bomId string) (*entity.Bom, error) {
-    var bom entity.Bom
-    if err := r.db.Preload("Materials").Where("id_bom = ?", bomId).First(&bom).Error; err != nil {
-        if errors.Is(err, gorm.ErrRecordNotFound) {
-            return nil, nil 
-        }
-        return nil, err
-    }
-    return &bom, nil 
+	var bom entity.Bom
+	if err := r.db.Where("id_bom = ?", bomId).First(&bom).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := r.attachEffectiveVersion(&bom); err != nil {
+		return nil, err
+	}
+	return &bom, nil
+}
+
+// attachEffectiveVersion fills the BOM with the lines and output quantity of
+// the version in force today.
+func (r *bomRepository) attachEffectiveVersion(bom *entity.Bom) error {
+	version, err := findEffectiveBomVersion(r.db, bom.BomId, time.Now())
+	if err != nil || version == nil {
+		return err
+	}
+	bom.VersionId = version.BomVersionId
+	bom.Version = version.Version
+	bom.EffectiveFrom = version.EffectiveFrom
+	bom.ChangeNote = version.ChangeNote
+	bom.Quantity = version.Quantity
+	bom.Materials = version.Materials
+	return nil
 }
 
 func (r *bomRepository) GetProductDetails(productId string) (*entity.Products, error) {
@@ -140,4 +177,38 @@ func (r *bomRepository) GetProductDetails(productId string) (*entity.Products, e
     return &product, nil
 }
 
+// FindBOMByProductID returns the most recent BOM that produces the product,
+// or nil when the product is bought in rather than made.
+func (r *bomRepository) FindBOMByProductID(productId string) (*entity.Bom, error) {
+	var bom entity.Bom
+	if err := r.db.Where("id_product = ?", productId).Order("id_bom desc").First(&bom).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := r.attachEffectiveVersion(&bom); err != nil {
+		return nil, err
+	}
+	return &bom, nil
+}
+
+// FindAllActiveBoms loads every BOM with the lines of its current version,
+// skipping BOMs that have no version in effect yet.
+func (r *bomRepository) FindAllActiveBoms() ([]entity.Bom, error) {
+	var boms []entity.Bom
+	if err := r.db.Order("id_bom").Find(&boms).Error; err != nil {
+		return nil, err
+	}
 
+	active := make([]entity.Bom, 0, len(boms))
+	for i := range boms {
+		if err := r.attachEffectiveVersion(&boms[i]); err != nil {
+			return nil, err
+		}
+		if boms[i].VersionId != "" {
+			active = append(active, boms[i])
+		}
+	}
+	return active, nil
+}
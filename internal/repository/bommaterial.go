@@ -11,6 +11,7 @@ type BOMMaterialRepository interface {
 	CreateMaterial(material *entity.BomMaterial) (*entity.BomMaterial, error)
 	GetLastMaterialId() (string, error)
 	GetMaterialsByBomId(bomId string) ([]entity.BomMaterial, error)
+	GetMaterialsByVersionId(versionId string) ([]entity.BomMaterial, error)
 	CheckMaterialExists(materialId string) (bool, error)
 	DeleteMaterialsByBomId(bomId string) error
 	FindBOMByMaterialIDAndBOMID(materialId string, bomId string) (*entity.BomMaterial, error)
@@ -49,6 +50,14 @@ func (r *bomMaterialRepository) GetMaterialsByBomId(bomId string) ([]entity.BomM
 	return materials, nil
 }
 
+func (r *bomMaterialRepository) GetMaterialsByVersionId(versionId string) ([]entity.BomMaterial, error) {
+	var materials []entity.BomMaterial
+	if err := r.db.Where("id_bomversion = ?", versionId).Find(&materials).Error; err != nil {
+		return nil, err
+	}
+	return materials, nil
+}
+
 func (r *bomMaterialRepository) CheckMaterialExists(materialId string) (bool, error) {
 	var count int64
 	if err := r.db.Model(&entity.Materials{}).Where("id_material = ?", materialId).Count(&count).Error; err != nil {
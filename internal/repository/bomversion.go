@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type BOMVersionRepository interface {
+	GetLastBomVersionId() (string, error)
+	CreateBOMVersion(version *entity.BomVersion) (*entity.BomVersion, error)
+	UpdateBOMVersion(version *entity.BomVersion) (*entity.BomVersion, error)
+	FindVersionsByBomId(bomId string) ([]entity.BomVersion, error)
+	FindVersionByID(versionId string) (*entity.BomVersion, error)
+	FindVersionByNumber(bomId string, version int) (*entity.BomVersion, error)
+	FindEffectiveVersion(bomId string, at time.Time) (*entity.BomVersion, error)
+}
+
+type bomVersionRepository struct {
+	db *gorm.DB
+}
+
+func NewBOMVersionRepository(db *gorm.DB) BOMVersionRepository {
+	return &bomVersionRepository{db: db}
+}
+
+func (r *bomVersionRepository) GetLastBomVersionId() (string, error) {
+	var lastVersion entity.BomVersion
+	err := r.db.Order("id_bomversion DESC").First(&lastVersion).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastVersion.BomVersionId, nil
+}
+
+func (r *bomVersionRepository) CreateBOMVersion(version *entity.BomVersion) (*entity.BomVersion, error) {
+	if err := r.db.Omit("Materials").Create(version).Error; err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+func (r *bomVersionRepository) UpdateBOMVersion(version *entity.BomVersion) (*entity.BomVersion, error) {
+	if err := r.db.Omit("Materials").Save(version).Error; err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+func (r *bomVersionRepository) FindVersionsByBomId(bomId string) ([]entity.BomVersion, error) {
+	var versions []entity.BomVersion
+	if err := r.db.Preload("Materials").Where("id_bom = ?", bomId).Order("version").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (r *bomVersionRepository) FindVersionByID(versionId string) (*entity.BomVersion, error) {
+	var version entity.BomVersion
+	if err := r.db.Preload("Materials").Where("id_bomversion = ?", versionId).First(&version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &version, nil
+}
+
+func (r *bomVersionRepository) FindVersionByNumber(bomId string, number int) (*entity.BomVersion, error) {
+	var version entity.BomVersion
+	if err := r.db.Preload("Materials").Where("id_bom = ? AND version = ?", bomId, number).First(&version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &version, nil
+}
+
+func (r *bomVersionRepository) FindEffectiveVersion(bomId string, at time.Time) (*entity.BomVersion, error) {
+	return findEffectiveBomVersion(r.db, bomId, at)
+}
+
+// findEffectiveBomVersion returns the version of a BOM in force at the given
+// moment, or nil when none is (e.g. the only version starts in the future).
+func findEffectiveBomVersion(db *gorm.DB, bomId string, at time.Time) (*entity.BomVersion, error) {
+	var version entity.BomVersion
+	err := db.Preload("Materials").
+		Where("id_bom = ? AND effective_from <= ?", bomId, at).
+		Where("effective_to IS NULL OR effective_to > ?", at).
+		Order("version DESC").
+		First(&version).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &version, nil
+}
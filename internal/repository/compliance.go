@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type ComplianceRepository interface {
+	GetLastConstituentId() (string, error)
+	CreateConstituent(constituent *entity.MaterialConstituent) (*entity.MaterialConstituent, error)
+	DeleteConstituentsByMaterialId(materialId string) error
+	FindConstituentsByMaterialId(materialId string) ([]entity.MaterialConstituent, error)
+	FindConstituentsByMaterialIds(materialIds []string) ([]entity.MaterialConstituent, error)
+	GetLastLimitId() (string, error)
+	CreateLimit(limit *entity.ComplianceLimit) (*entity.ComplianceLimit, error)
+	UpdateLimit(limit *entity.ComplianceLimit) (*entity.ComplianceLimit, error)
+	DeleteLimit(limit *entity.ComplianceLimit) (bool, error)
+	FindLimitByID(limitId string) (*entity.ComplianceLimit, error)
+	FindAllLimits() ([]entity.ComplianceLimit, error)
+	FindLimitsByCategory(productCategory string) ([]entity.ComplianceLimit, error)
+}
+
+type complianceRepository struct {
+	db *gorm.DB
+}
+
+func NewComplianceRepository(db *gorm.DB) ComplianceRepository {
+	return &complianceRepository{db: db}
+}
+
+func (r *complianceRepository) GetLastConstituentId() (string, error) {
+	var lastConstituent entity.MaterialConstituent
+	err := r.db.Unscoped().Order("id_constituent DESC").First(&lastConstituent).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastConstituent.ConstituentId, nil
+}
+
+func (r *complianceRepository) CreateConstituent(constituent *entity.MaterialConstituent) (*entity.MaterialConstituent, error) {
+	if err := r.db.Create(constituent).Error; err != nil {
+		return nil, err
+	}
+	return constituent, nil
+}
+
+func (r *complianceRepository) DeleteConstituentsByMaterialId(materialId string) error {
+	return r.db.Unscoped().Where("id_material = ?", materialId).Delete(&entity.MaterialConstituent{}).Error
+}
+
+func (r *complianceRepository) FindConstituentsByMaterialId(materialId string) ([]entity.MaterialConstituent, error) {
+	var constituents []entity.MaterialConstituent
+	if err := r.db.Where("id_material = ?", materialId).Order("percentage DESC").Find(&constituents).Error; err != nil {
+		return nil, err
+	}
+	return constituents, nil
+}
+
+func (r *complianceRepository) FindConstituentsByMaterialIds(materialIds []string) ([]entity.MaterialConstituent, error) {
+	var constituents []entity.MaterialConstituent
+	if len(materialIds) == 0 {
+		return constituents, nil
+	}
+	if err := r.db.Where("id_material IN ?", materialIds).Order("id_constituent").Find(&constituents).Error; err != nil {
+		return nil, err
+	}
+	return constituents, nil
+}
+
+func (r *complianceRepository) GetLastLimitId() (string, error) {
+	var lastLimit entity.ComplianceLimit
+	err := r.db.Unscoped().Order("id_compliancelimit DESC").First(&lastLimit).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastLimit.ComplianceLimitId, nil
+}
+
+func (r *complianceRepository) CreateLimit(limit *entity.ComplianceLimit) (*entity.ComplianceLimit, error) {
+	if err := r.db.Create(limit).Error; err != nil {
+		return nil, err
+	}
+	return limit, nil
+}
+
+func (r *complianceRepository) UpdateLimit(limit *entity.ComplianceLimit) (*entity.ComplianceLimit, error) {
+	if err := r.db.Save(limit).Error; err != nil {
+		return nil, err
+	}
+	return limit, nil
+}
+
+func (r *complianceRepository) DeleteLimit(limit *entity.ComplianceLimit) (bool, error) {
+	if err := r.db.Delete(limit).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *complianceRepository) FindLimitByID(limitId string) (*entity.ComplianceLimit, error) {
+	var limit entity.ComplianceLimit
+	if err := r.db.Where("id_compliancelimit = ?", limitId).First(&limit).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &limit, nil
+}
+
+func (r *complianceRepository) FindAllLimits() ([]entity.ComplianceLimit, error) {
+	var limits []entity.ComplianceLimit
+	if err := r.db.Order("id_compliancelimit").Find(&limits).Error; err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// FindLimitsByCategory returns the limits of the category together with the
+// ones that apply to every category.
+func (r *complianceRepository) FindLimitsByCategory(productCategory string) ([]entity.ComplianceLimit, error) {
+	var limits []entity.ComplianceLimit
+	err := r.db.Where("LOWER(product_category) = LOWER(?) OR product_category = ''", productCategory).
+		Order("id_compliancelimit").Find(&limits).Error
+	if err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/encrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// encryptedPrefix marks values written by the formula serializer, so lines
+// stored before encryption was introduced can still be told apart.
+const encryptedPrefix = "enc:"
+
+// formulaSerializer keeps BOM line quantities, the quantities derived from
+// them on MOs and frozen batch records encrypted in the database. It is
+// registered under the name used in the "serializer:formula" tag, on string
+// and float fields alike.
+type formulaSerializer struct {
+	encryptTool encrypt.EncryptTool
+}
+
+// RegisterFormulaSerializer must run before the first BOM query.
+func RegisterFormulaSerializer(encryptTool encrypt.EncryptTool) {
+	schema.RegisterSerializer("formula", formulaSerializer{encryptTool: encryptTool})
+}
+
+func (s formulaSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		value = string(v)
+	case string:
+		value = v
+	default:
+		value = fmt.Sprint(v)
+	}
+
+	if strings.HasPrefix(value, encryptedPrefix) {
+		plain, err := s.encryptTool.Decrypt(strings.TrimPrefix(value, encryptedPrefix))
+		if err != nil {
+			return fmt.Errorf("cannot decrypt formula data: %v", err)
+		}
+		value = plain
+	}
+
+	target := field.ReflectValueOf(ctx, dst)
+	if target.Kind() == reflect.Float64 {
+		number := 0.0
+		if value != "" {
+			var err error
+			if number, err = strconv.ParseFloat(value, 64); err != nil {
+				return fmt.Errorf("invalid formula quantity %q: %v", value, err)
+			}
+		}
+		target.SetFloat(number)
+		return nil
+	}
+	target.SetString(value)
+	return nil
+}
+
+func (s formulaSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	var value string
+	switch v := fieldValue.(type) {
+	case string:
+		value = v
+	case float64:
+		value = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	encrypted, err := s.encryptTool.Encrypt(value)
+	if err != nil {
+		return nil, err
+	}
+	return encryptedPrefix + encrypted, nil
+}
+
+// EncryptStoredFormulas encrypts BOM lines, MO reservations and
+// consumptions, and batch records saved before they were encrypted. It
+// returns how many rows it rewrote.
+func EncryptStoredFormulas(db *gorm.DB) (int, error) {
+	var lines []entity.BomMaterial
+	if err := db.Where("quantity NOT LIKE ?", encryptedPrefix+"%").Find(&lines).Error; err != nil {
+		return 0, err
+	}
+	for _, line := range lines {
+		err := db.Model(&entity.BomMaterial{}).
+			Where("id_bommaterial = ?", line.IdBomMaterial).
+			Select("quantity").
+			Updates(&entity.BomMaterial{Quantity: line.Quantity}).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var reservations []entity.MoReservation
+	if err := db.Where("qty_required NOT LIKE ?", encryptedPrefix+"%").Find(&reservations).Error; err != nil {
+		return 0, err
+	}
+	for _, reservation := range reservations {
+		err := db.Model(&entity.MoReservation{}).
+			Where("id_reservation = ?", reservation.ReservationId).
+			Select("qty_required").
+			Updates(&entity.MoReservation{QtyRequired: reservation.QtyRequired}).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var consumptions []entity.MoConsumption
+	err := db.Where("qty_theoretical NOT LIKE ? OR qty_actual NOT LIKE ?", encryptedPrefix+"%", encryptedPrefix+"%").
+		Find(&consumptions).Error
+	if err != nil {
+		return 0, err
+	}
+	for _, consumption := range consumptions {
+		err := db.Model(&entity.MoConsumption{}).
+			Where("id_moconsumption = ?", consumption.ConsumptionId).
+			Select("qty_theoretical", "qty_actual").
+			Updates(&entity.MoConsumption{QtyTheoretical: consumption.QtyTheoretical, QtyActual: consumption.QtyActual}).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var records []entity.MoBatchRecord
+	if err := db.Where("record NOT LIKE ?", encryptedPrefix+"%").Find(&records).Error; err != nil {
+		return 0, err
+	}
+	for _, record := range records {
+		err := db.Model(&entity.MoBatchRecord{}).
+			Where("id_mo = ?", record.MoId).
+			Select("record").
+			Updates(&entity.MoBatchRecord{Record: record.Record}).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(lines) + len(reservations) + len(consumptions) + len(records), nil
+}
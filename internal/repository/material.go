@@ -23,6 +23,8 @@ type MaterialRepository interface {
 	SearchByName(name string) ([]entity.Materials, error)
 	Update(material *entity.Materials) error
 	FindMaterialByName(materialId string) (*entity.Materials, error)
+	AdjustQty(materialId string, delta float64) error
+	AdjustQuarantineQty(materialId string, delta float64) error
 }
 
 type materialRepository struct {
@@ -172,3 +174,26 @@ func (r *materialRepository) FindMaterialByName(materialId string) (*entity.Mate
 	log.Printf("material found: %v", material)
 	return material, nil
 }
+
+// AdjustQty moves the on-hand quantity by delta in a single statement so
+// concurrent stock movements do not overwrite each other.
+func (r *materialRepository) AdjustQty(materialId string, delta float64) error {
+	if err := r.db.Model(&entity.Materials{}).
+		Where("id_material = ?", materialId).
+		Update("qty", gorm.Expr("COALESCE(qty, 0) + ?", delta)).Error; err != nil {
+		return err
+	}
+	r.cacheable.Delete("FindAllMaterials_page_1")
+	return nil
+}
+
+// AdjustQuarantineQty moves the stock held for quality control by delta.
+func (r *materialRepository) AdjustQuarantineQty(materialId string, delta float64) error {
+	if err := r.db.Model(&entity.Materials{}).
+		Where("id_material = ?", materialId).
+		Update("quarantine_qty", gorm.Expr("COALESCE(quarantine_qty, 0) + ?", delta)).Error; err != nil {
+		return err
+	}
+	r.cacheable.Delete("FindAllMaterials_page_1")
+	return nil
+}
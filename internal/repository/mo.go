@@ -18,6 +18,11 @@ type MoRepository interface {
 	UpdateMoStatus(mo *entity.Mos) (*entity.Mos, error)
 	FindAllMos(page int) ([]entity.Mos, error)
 	DeleteMo(mo *entity.Mos) (bool, error)
+	FindMosByParentId(parentMoId string) ([]entity.Mos, error)
+	FindMosByStatus(statuses []string) ([]entity.Mos, error)
+	FindMosPlannedBetween(from, to time.Time) ([]entity.Mos, error)
+	FindBatchRecord(moId string) (*entity.MoBatchRecord, error)
+	CreateBatchRecord(record *entity.MoBatchRecord) (*entity.MoBatchRecord, error)
 }
 
 type moRepository struct {
@@ -98,3 +103,50 @@ func (r *moRepository) DeleteMo(mo *entity.Mos) (bool, error) {
 	r.cacheable.Delete("FindAllMo_page_1")
 	return true, nil
 }
+
+func (r *moRepository) FindMosByParentId(parentMoId string) ([]entity.Mos, error) {
+	var mos []entity.Mos
+	if err := r.db.Where("id_parent_mo = ?", parentMoId).Order("id_mo").Find(&mos).Error; err != nil {
+		return nil, err
+	}
+	return mos, nil
+}
+
+func (r *moRepository) FindMosByStatus(statuses []string) ([]entity.Mos, error) {
+	var mos []entity.Mos
+	if err := r.db.Where("status IN ?", statuses).Order("id_mo").Find(&mos).Error; err != nil {
+		return nil, err
+	}
+	return mos, nil
+}
+
+// FindMosPlannedBetween returns the MOs whose planned period overlaps
+// [from, to), ordered by planned start.
+func (r *moRepository) FindMosPlannedBetween(from, to time.Time) ([]entity.Mos, error) {
+	var mos []entity.Mos
+	err := r.db.Where("planned_start < ? AND planned_end > ?", to, from).
+		Order("planned_start").Order("id_mo").
+		Find(&mos).Error
+	if err != nil {
+		return nil, err
+	}
+	return mos, nil
+}
+
+func (r *moRepository) FindBatchRecord(moId string) (*entity.MoBatchRecord, error) {
+	var record entity.MoBatchRecord
+	if err := r.db.Where("id_mo = ?", moId).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *moRepository) CreateBatchRecord(record *entity.MoBatchRecord) (*entity.MoBatchRecord, error) {
+	if err := r.db.Create(record).Error; err != nil {
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type MoProductionRepository interface {
+	GetLastProductionId() (string, error)
+	CreateProduction(production *entity.MoProduction) (*entity.MoProduction, error)
+	GetProductionsByMoId(moId string) ([]entity.MoProduction, error)
+	DeleteProductionsByMoId(moId string) error
+	GetLastConsumptionId() (string, error)
+	CreateConsumption(consumption *entity.MoConsumption) (*entity.MoConsumption, error)
+	GetConsumptionsByMoId(moId string) ([]entity.MoConsumption, error)
+	FindConsumptionsBetween(from, to time.Time) ([]entity.MoConsumption, error)
+	DeleteConsumptionsByMoId(moId string) error
+}
+
+type moProductionRepository struct {
+	db *gorm.DB
+}
+
+func NewMoProductionRepository(db *gorm.DB) MoProductionRepository {
+	return &moProductionRepository{db: db}
+}
+
+func (r *moProductionRepository) GetLastProductionId() (string, error) {
+	var lastProduction entity.MoProduction
+	err := r.db.Unscoped().Order("id_moproduction DESC").First(&lastProduction).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastProduction.ProductionId, nil
+}
+
+func (r *moProductionRepository) CreateProduction(production *entity.MoProduction) (*entity.MoProduction, error) {
+	if err := r.db.Create(production).Error; err != nil {
+		return nil, err
+	}
+	return production, nil
+}
+
+func (r *moProductionRepository) GetProductionsByMoId(moId string) ([]entity.MoProduction, error) {
+	var productions []entity.MoProduction
+	if err := r.db.Where("id_mo = ?", moId).Order("id_moproduction").Find(&productions).Error; err != nil {
+		return nil, err
+	}
+	return productions, nil
+}
+
+func (r *moProductionRepository) DeleteProductionsByMoId(moId string) error {
+	return r.db.Unscoped().Where("id_mo = ?", moId).Delete(&entity.MoProduction{}).Error
+}
+
+func (r *moProductionRepository) GetLastConsumptionId() (string, error) {
+	var lastConsumption entity.MoConsumption
+	err := r.db.Unscoped().Order("id_moconsumption DESC").First(&lastConsumption).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastConsumption.ConsumptionId, nil
+}
+
+func (r *moProductionRepository) CreateConsumption(consumption *entity.MoConsumption) (*entity.MoConsumption, error) {
+	if err := r.db.Create(consumption).Error; err != nil {
+		return nil, err
+	}
+	return consumption, nil
+}
+
+func (r *moProductionRepository) GetConsumptionsByMoId(moId string) ([]entity.MoConsumption, error) {
+	var consumptions []entity.MoConsumption
+	if err := r.db.Where("id_mo = ?", moId).Order("id_moconsumption").Find(&consumptions).Error; err != nil {
+		return nil, err
+	}
+	return consumptions, nil
+}
+
+// FindConsumptionsBetween returns the consumption recorded within [from, to).
+func (r *moProductionRepository) FindConsumptionsBetween(from, to time.Time) ([]entity.MoConsumption, error) {
+	var consumptions []entity.MoConsumption
+	err := r.db.Where("created_at >= ? AND created_at < ?", from, to).Order("created_at, id_moconsumption").Find(&consumptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return consumptions, nil
+}
+
+func (r *moProductionRepository) DeleteConsumptionsByMoId(moId string) error {
+	return r.db.Unscoped().Where("id_mo = ?", moId).Delete(&entity.MoConsumption{}).Error
+}
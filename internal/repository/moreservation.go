@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type MoReservationRepository interface {
+	GetLastReservationId() (string, error)
+	CreateReservation(reservation *entity.MoReservation) (*entity.MoReservation, error)
+	UpdateReservation(reservation *entity.MoReservation) (*entity.MoReservation, error)
+	GetReservationsByMoId(moId string) ([]entity.MoReservation, error)
+	SumReservedByMaterial(materialId string, excludeMoId string) (float64, error)
+	SumReservedByProduct(productId string, excludeMoId string) (float64, error)
+	FindOpenShortages() ([]entity.MoReservation, error)
+	DeleteReservationsByMoId(moId string) error
+}
+
+type moReservationRepository struct {
+	db *gorm.DB
+}
+
+func NewMoReservationRepository(db *gorm.DB) MoReservationRepository {
+	return &moReservationRepository{db: db}
+}
+
+func (r *moReservationRepository) GetLastReservationId() (string, error) {
+	var lastReservation entity.MoReservation
+	err := r.db.Order("id_reservation DESC").First(&lastReservation).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastReservation.ReservationId, nil
+}
+
+func (r *moReservationRepository) CreateReservation(reservation *entity.MoReservation) (*entity.MoReservation, error) {
+	if err := r.db.Create(reservation).Error; err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+func (r *moReservationRepository) UpdateReservation(reservation *entity.MoReservation) (*entity.MoReservation, error) {
+	if err := r.db.Save(reservation).Error; err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+func (r *moReservationRepository) GetReservationsByMoId(moId string) ([]entity.MoReservation, error) {
+	var reservations []entity.MoReservation
+	if err := r.db.Where("id_mo = ?", moId).Order("id_reservation").Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// SumReservedByMaterial returns how much of a material is already held by
+// other manufacture orders, so it is not promised twice.
+func (r *moReservationRepository) SumReservedByMaterial(materialId string, excludeMoId string) (float64, error) {
+	var total float64
+	err := r.db.Model(&entity.MoReservation{}).
+		Where("id_material = ? AND id_mo <> ? AND status = ?", materialId, excludeMoId, "reserved").
+		Select("COALESCE(SUM(qty_reserved - qty_consumed), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// SumReservedByProduct is the sub-assembly counterpart of SumReservedByMaterial.
+func (r *moReservationRepository) SumReservedByProduct(productId string, excludeMoId string) (float64, error) {
+	var total float64
+	err := r.db.Model(&entity.MoReservation{}).
+		Where("id_product = ? AND id_mo <> ? AND status = ?", productId, excludeMoId, "reserved").
+		Select("COALESCE(SUM(qty_reserved - qty_consumed), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// FindOpenShortages lists material reservations that are not fully covered.
+// Sub-assembly shortfalls are left out: those are made in-house, not bought.
+// The required quantity is encrypted, so coverage is compared here rather
+// than in the query.
+func (r *moReservationRepository) FindOpenShortages() ([]entity.MoReservation, error) {
+	var reservations []entity.MoReservation
+	err := r.db.Table("mo_reservations").
+		Joins("JOIN mos ON mos.id_mo = mo_reservations.id_mo").
+		Where("mo_reservations.status = ?", "reserved").
+		Where("mos.status IN ? AND mos.deleted_at IS NULL", []string{"confirmed", "waiting for materials", "on progress"}).
+		Where("COALESCE(mo_reservations.id_material, '') <> ''").
+		Where("mo_reservations.deleted_at IS NULL").
+		Select("mo_reservations.*").
+		Order("mo_reservations.id_material, mo_reservations.id_mo").
+		Find(&reservations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	open := reservations[:0]
+	for _, reservation := range reservations {
+		if reservation.QtyReserved < reservation.QtyRequired {
+			open = append(open, reservation)
+		}
+	}
+	return open, nil
+}
+
+func (r *moReservationRepository) DeleteReservationsByMoId(moId string) error {
+	if err := r.db.Unscoped().Where("id_mo = ?", moId).Delete(&entity.MoReservation{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type MoReversalRepository interface {
+	GetLastReversalId() (string, error)
+	CreateReversal(reversal *entity.MoReversal) (*entity.MoReversal, error)
+	GetLastReversalLineId() (string, error)
+	CreateReversalLine(line *entity.MoReversalLine) (*entity.MoReversalLine, error)
+	GetReversalsByMoId(moId string) ([]entity.MoReversal, error)
+	SumUnbuiltQty(moId string) (float64, error)
+}
+
+type moReversalRepository struct {
+	db *gorm.DB
+}
+
+func NewMoReversalRepository(db *gorm.DB) MoReversalRepository {
+	return &moReversalRepository{db: db}
+}
+
+func (r *moReversalRepository) GetLastReversalId() (string, error) {
+	var lastReversal entity.MoReversal
+	err := r.db.Unscoped().Order("id_moreversal DESC").First(&lastReversal).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastReversal.ReversalId, nil
+}
+
+func (r *moReversalRepository) CreateReversal(reversal *entity.MoReversal) (*entity.MoReversal, error) {
+	if err := r.db.Omit("Lines").Create(reversal).Error; err != nil {
+		return nil, err
+	}
+	return reversal, nil
+}
+
+func (r *moReversalRepository) GetLastReversalLineId() (string, error) {
+	var lastLine entity.MoReversalLine
+	err := r.db.Unscoped().Order("id_moreversalline DESC").First(&lastLine).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastLine.LineId, nil
+}
+
+func (r *moReversalRepository) CreateReversalLine(line *entity.MoReversalLine) (*entity.MoReversalLine, error) {
+	if err := r.db.Create(line).Error; err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func (r *moReversalRepository) GetReversalsByMoId(moId string) ([]entity.MoReversal, error) {
+	var reversals []entity.MoReversal
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_moreversalline")
+	}).Where("id_mo = ?", moId).Order("id_moreversal").Find(&reversals).Error
+	if err != nil {
+		return nil, err
+	}
+	return reversals, nil
+}
+
+// SumUnbuiltQty is how much of the MO's output has already been unbuilt.
+func (r *moReversalRepository) SumUnbuiltQty(moId string) (float64, error) {
+	var total float64
+	err := r.db.Model(&entity.MoReversal{}).
+		Where("id_mo = ? AND type = ?", moId, "unbuild").
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
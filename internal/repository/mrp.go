@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type MrpRepository interface {
+	GetLastRunId() (string, error)
+	CreateRun(run *entity.MrpRun) (*entity.MrpRun, error)
+	UpdateRun(run *entity.MrpRun) (*entity.MrpRun, error)
+	FindRunByID(runId string) (*entity.MrpRun, error)
+	FindAllRuns(page int) ([]entity.MrpRun, error)
+	SupersedeOpenRuns(runId string) error
+	GetLastProposalId() (string, error)
+	CreateProposal(proposal *entity.MrpProposal) (*entity.MrpProposal, error)
+	UpdateProposal(proposal *entity.MrpProposal) (*entity.MrpProposal, error)
+	FindProposalByID(proposalId string) (*entity.MrpProposal, error)
+}
+
+type mrpRepository struct {
+	db *gorm.DB
+}
+
+func NewMrpRepository(db *gorm.DB) MrpRepository {
+	return &mrpRepository{db: db}
+}
+
+func (r *mrpRepository) GetLastRunId() (string, error) {
+	var lastRun entity.MrpRun
+	err := r.db.Order("id_mrprun DESC").First(&lastRun).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastRun.RunId, nil
+}
+
+func (r *mrpRepository) CreateRun(run *entity.MrpRun) (*entity.MrpRun, error) {
+	if err := r.db.Omit("Proposals").Create(run).Error; err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (r *mrpRepository) UpdateRun(run *entity.MrpRun) (*entity.MrpRun, error) {
+	if err := r.db.Omit("Proposals").Save(run).Error; err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (r *mrpRepository) FindRunByID(runId string) (*entity.MrpRun, error) {
+	var run entity.MrpRun
+	err := r.db.Preload("Proposals", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_mrpproposal")
+	}).Where("id_mrprun = ?", runId).First(&run).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *mrpRepository) FindAllRuns(page int) ([]entity.MrpRun, error) {
+	var runs []entity.MrpRun
+	const pageSize = 100
+
+	offset := (page - 1) * pageSize
+	if err := r.db.Order("id_mrprun DESC").Limit(pageSize).Offset(offset).Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// SupersedeOpenRuns marks the runs before the given one that still have
+// proposals to confirm as superseded.
+func (r *mrpRepository) SupersedeOpenRuns(runId string) error {
+	return r.db.Model(&entity.MrpRun{}).
+		Where("id_mrprun < ? AND status IN ?", runId, []string{"draft", "partially confirmed"}).
+		Updates(map[string]interface{}{"status": "superseded", "updated_at": time.Now()}).Error
+}
+
+func (r *mrpRepository) GetLastProposalId() (string, error) {
+	var lastProposal entity.MrpProposal
+	err := r.db.Order("id_mrpproposal DESC").First(&lastProposal).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastProposal.ProposalId, nil
+}
+
+func (r *mrpRepository) CreateProposal(proposal *entity.MrpProposal) (*entity.MrpProposal, error) {
+	if err := r.db.Create(proposal).Error; err != nil {
+		return nil, err
+	}
+	return proposal, nil
+}
+
+func (r *mrpRepository) UpdateProposal(proposal *entity.MrpProposal) (*entity.MrpProposal, error) {
+	if err := r.db.Save(proposal).Error; err != nil {
+		return nil, err
+	}
+	return proposal, nil
+}
+
+func (r *mrpRepository) FindProposalByID(proposalId string) (*entity.MrpProposal, error) {
+	var proposal entity.MrpProposal
+	if err := r.db.Where("id_mrpproposal = ?", proposalId).First(&proposal).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &proposal, nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type PoApprovalRepository interface {
+	GetLastRuleId() (string, error)
+	CreateRule(rule *entity.PoApprovalRule) (*entity.PoApprovalRule, error)
+	UpdateRule(rule *entity.PoApprovalRule) (*entity.PoApprovalRule, error)
+	DeleteRule(rule *entity.PoApprovalRule) (bool, error)
+	FindRuleByID(ruleId string) (*entity.PoApprovalRule, error)
+	FindAllRules() ([]entity.PoApprovalRule, error)
+	FindActiveRules() ([]entity.PoApprovalRule, error)
+	GetLastApprovalId() (string, error)
+	CreateApproval(approval *entity.RfqApproval) (*entity.RfqApproval, error)
+	UpdateApproval(approval *entity.RfqApproval) (*entity.RfqApproval, error)
+	FindApprovalByID(approvalId string) (*entity.RfqApproval, error)
+	FindApprovalsByRfqId(rfqId string) ([]entity.RfqApproval, error)
+	FindPendingApprovals() ([]entity.RfqApproval, error)
+}
+
+type poApprovalRepository struct {
+	db *gorm.DB
+}
+
+func NewPoApprovalRepository(db *gorm.DB) PoApprovalRepository {
+	return &poApprovalRepository{db: db}
+}
+
+func (r *poApprovalRepository) GetLastRuleId() (string, error) {
+	var lastRule entity.PoApprovalRule
+	err := r.db.Unscoped().Order("id_approvalrule DESC").First(&lastRule).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastRule.ApprovalRuleId, nil
+}
+
+func (r *poApprovalRepository) CreateRule(rule *entity.PoApprovalRule) (*entity.PoApprovalRule, error) {
+	if err := r.db.Create(rule).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *poApprovalRepository) UpdateRule(rule *entity.PoApprovalRule) (*entity.PoApprovalRule, error) {
+	if err := r.db.Save(rule).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *poApprovalRepository) DeleteRule(rule *entity.PoApprovalRule) (bool, error) {
+	if err := r.db.Delete(rule).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *poApprovalRepository) FindRuleByID(ruleId string) (*entity.PoApprovalRule, error) {
+	var rule entity.PoApprovalRule
+	if err := r.db.Where("id_approvalrule = ?", ruleId).First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *poApprovalRepository) FindAllRules() ([]entity.PoApprovalRule, error) {
+	var rules []entity.PoApprovalRule
+	if err := r.db.Order("min_amount, id_approvalrule").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *poApprovalRepository) FindActiveRules() ([]entity.PoApprovalRule, error) {
+	var rules []entity.PoApprovalRule
+	if err := r.db.Where("active = ?", true).Order("min_amount, id_approvalrule").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *poApprovalRepository) GetLastApprovalId() (string, error) {
+	var lastApproval entity.RfqApproval
+	err := r.db.Unscoped().Order("id_rfqapproval DESC").First(&lastApproval).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastApproval.RfqApprovalId, nil
+}
+
+func (r *poApprovalRepository) CreateApproval(approval *entity.RfqApproval) (*entity.RfqApproval, error) {
+	if err := r.db.Create(approval).Error; err != nil {
+		return nil, err
+	}
+	return approval, nil
+}
+
+func (r *poApprovalRepository) UpdateApproval(approval *entity.RfqApproval) (*entity.RfqApproval, error) {
+	if err := r.db.Save(approval).Error; err != nil {
+		return nil, err
+	}
+	return approval, nil
+}
+
+func (r *poApprovalRepository) FindApprovalByID(approvalId string) (*entity.RfqApproval, error) {
+	var approval entity.RfqApproval
+	if err := r.db.Where("id_rfqapproval = ?", approvalId).First(&approval).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &approval, nil
+}
+
+// FindApprovalsByRfqId returns the approval history of an RFQ, oldest first.
+func (r *poApprovalRepository) FindApprovalsByRfqId(rfqId string) ([]entity.RfqApproval, error) {
+	var approvals []entity.RfqApproval
+	if err := r.db.Where("id_rfq = ?", rfqId).Order("id_rfqapproval").Find(&approvals).Error; err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
+
+func (r *poApprovalRepository) FindPendingApprovals() ([]entity.RfqApproval, error) {
+	var approvals []entity.RfqApproval
+	if err := r.db.Where("status = ?", "pending").Order("id_rfqapproval").Find(&approvals).Error; err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type PriceListRepository interface {
+	GetLastVendorPriceId() (string, error)
+	CreateVendorPrice(price *entity.VendorPrice) (*entity.VendorPrice, error)
+	UpdateVendorPrice(price *entity.VendorPrice) (*entity.VendorPrice, error)
+	DeleteVendorPrice(price *entity.VendorPrice) (bool, error)
+	FindVendorPriceByID(priceId string) (*entity.VendorPrice, error)
+	FindCurrentPrices(vendorId, itemId string) ([]entity.VendorPrice, error)
+	FindValidPrices(vendorId, itemId string, day time.Time) ([]entity.VendorPrice, error)
+	FindPricesInEffect(vendorId, itemId string, at time.Time) ([]entity.VendorPrice, error)
+	FindPriceHistory(vendorId, itemId string) ([]entity.VendorPrice, error)
+}
+
+type priceListRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceListRepository(db *gorm.DB) PriceListRepository {
+	return &priceListRepository{db: db}
+}
+
+func (r *priceListRepository) GetLastVendorPriceId() (string, error) {
+	var lastPrice entity.VendorPrice
+	err := r.db.Unscoped().Order("id_vendorprice DESC").First(&lastPrice).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastPrice.VendorPriceId, nil
+}
+
+func (r *priceListRepository) CreateVendorPrice(price *entity.VendorPrice) (*entity.VendorPrice, error) {
+	if err := r.db.Create(price).Error; err != nil {
+		return nil, err
+	}
+	return price, nil
+}
+
+func (r *priceListRepository) UpdateVendorPrice(price *entity.VendorPrice) (*entity.VendorPrice, error) {
+	if err := r.db.Save(price).Error; err != nil {
+		return nil, err
+	}
+	return price, nil
+}
+
+func (r *priceListRepository) DeleteVendorPrice(price *entity.VendorPrice) (bool, error) {
+	if err := r.db.Delete(price).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *priceListRepository) FindVendorPriceByID(priceId string) (*entity.VendorPrice, error) {
+	var price entity.VendorPrice
+	if err := r.db.Where("id_vendorprice = ?", priceId).First(&price).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &price, nil
+}
+
+// FindCurrentPrices returns the price breaks not superseded by a newer price,
+// filtered by vendor and item when given.
+func (r *priceListRepository) FindCurrentPrices(vendorId, itemId string) ([]entity.VendorPrice, error) {
+	var prices []entity.VendorPrice
+	query := r.db.Where("superseded_by = ''")
+	if vendorId != "" {
+		query = query.Where("id_vendor = ?", vendorId)
+	}
+	if itemId != "" {
+		query = query.Where("id_item = ?", itemId)
+	}
+	if err := query.Order("id_vendor, id_item, min_qty").Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// FindValidPrices returns the current price breaks of a vendor for an item
+// that are valid on the given day.
+func (r *priceListRepository) FindValidPrices(vendorId, itemId string, day time.Time) ([]entity.VendorPrice, error) {
+	var prices []entity.VendorPrice
+	err := r.db.Where("id_vendor = ? AND id_item = ? AND superseded_by = ''", vendorId, itemId).
+		Where("valid_from IS NULL OR valid_from <= ?", day).
+		Where("valid_to IS NULL OR valid_to >= ?", day).
+		Order("min_qty").Find(&prices).Error
+	if err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// FindPricesInEffect returns the price breaks of a vendor for an item as the
+// price list stood at the given moment: entered by then, neither superseded
+// nor deleted yet, and valid on that day.
+func (r *priceListRepository) FindPricesInEffect(vendorId, itemId string, at time.Time) ([]entity.VendorPrice, error) {
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	var prices []entity.VendorPrice
+	err := r.db.Unscoped().Where("id_vendor = ? AND id_item = ?", vendorId, itemId).
+		Where("created_at <= ?", at).
+		Where("superseded_at IS NULL OR superseded_at > ?", at).
+		Where("deleted_at IS NULL OR deleted_at > ?", at).
+		Where("valid_from IS NULL OR valid_from <= ?", at).
+		Where("valid_to IS NULL OR valid_to >= ?", day).
+		Order("min_qty").Find(&prices).Error
+	if err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+func (r *priceListRepository) FindPriceHistory(vendorId, itemId string) ([]entity.VendorPrice, error) {
+	var prices []entity.VendorPrice
+	err := r.db.Where("id_vendor = ? AND id_item = ?", vendorId, itemId).
+		Order("min_qty, id_vendorprice").Find(&prices).Error
+	if err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
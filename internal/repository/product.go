@@ -23,6 +23,8 @@ type ProductRepository interface {
 	SearchByName(name string) ([]entity.Products, error)
 	FindAllProductVariant(page int) ([]entity.Products, error)
 	Update(product *entity.Products) error
+	AdjustQty(productId string, delta float64) error
+	AdjustQuarantineQty(productId string, delta float64) error
 }
 
 type productRepository struct {
@@ -180,3 +182,25 @@ func (r *productRepository) Update(product *entity.Products) error {
 	r.cacheable.Delete("FindAllProducts_page_1")
 	return r.db.Save(product).Error
 }
+
+// AdjustQty adds delta (negative to take stock out) to the product on hand.
+func (r *productRepository) AdjustQty(productId string, delta float64) error {
+	if err := r.db.Model(&entity.Products{}).
+		Where("id_product = ?", productId).
+		Update("qty", gorm.Expr("COALESCE(qty, 0) + ?", delta)).Error; err != nil {
+		return err
+	}
+	r.cacheable.Delete("FindAllProducts_page_1")
+	return nil
+}
+
+// AdjustQuarantineQty moves the stock held for quality control by delta.
+func (r *productRepository) AdjustQuarantineQty(productId string, delta float64) error {
+	if err := r.db.Model(&entity.Products{}).
+		Where("id_product = ?", productId).
+		Update("quarantine_qty", gorm.Expr("COALESCE(quarantine_qty, 0) + ?", delta)).Error; err != nil {
+		return err
+	}
+	r.cacheable.Delete("FindAllProducts_page_1")
+	return nil
+}
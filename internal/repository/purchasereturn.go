@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type PurchaseReturnRepository interface {
+	GetLastReturnId() (string, error)
+	CreateReturn(purchaseReturn *entity.PurchaseReturn) (*entity.PurchaseReturn, error)
+	GetLastReturnLineId() (string, error)
+	CreateReturnLine(line *entity.PurchaseReturnLine) (*entity.PurchaseReturnLine, error)
+	FindReturnByID(returnId string) (*entity.PurchaseReturn, error)
+	FindReturns(vendorId string) ([]entity.PurchaseReturn, error)
+	FindReturnLinesByRfqId(rfqId string) ([]entity.PurchaseReturnLine, error)
+	SumReturnedQty(receiptLineId string) (float64, error)
+	GetLastCreditNoteId() (string, error)
+	CreateCreditNote(creditNote *entity.VendorCreditNote) (*entity.VendorCreditNote, error)
+	UpdateCreditNote(creditNote *entity.VendorCreditNote) (*entity.VendorCreditNote, error)
+	FindCreditNoteByID(creditNoteId string) (*entity.VendorCreditNote, error)
+	FindCreditNotes(vendorId string, openOnly bool) ([]entity.VendorCreditNote, error)
+}
+
+type purchaseReturnRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseReturnRepository(db *gorm.DB) PurchaseReturnRepository {
+	return &purchaseReturnRepository{db: db}
+}
+
+func (r *purchaseReturnRepository) GetLastReturnId() (string, error) {
+	var lastReturn entity.PurchaseReturn
+	err := r.db.Unscoped().Order("id_return DESC").First(&lastReturn).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastReturn.ReturnId, nil
+}
+
+func (r *purchaseReturnRepository) CreateReturn(purchaseReturn *entity.PurchaseReturn) (*entity.PurchaseReturn, error) {
+	if err := r.db.Omit("Lines", "CreditNote").Create(purchaseReturn).Error; err != nil {
+		return nil, err
+	}
+	return purchaseReturn, nil
+}
+
+func (r *purchaseReturnRepository) GetLastReturnLineId() (string, error) {
+	var lastLine entity.PurchaseReturnLine
+	err := r.db.Unscoped().Order("id_returnline DESC").First(&lastLine).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastLine.ReturnLineId, nil
+}
+
+func (r *purchaseReturnRepository) CreateReturnLine(line *entity.PurchaseReturnLine) (*entity.PurchaseReturnLine, error) {
+	if err := r.db.Create(line).Error; err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func (r *purchaseReturnRepository) FindReturnByID(returnId string) (*entity.PurchaseReturn, error) {
+	var purchaseReturn entity.PurchaseReturn
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_returnline")
+	}).Preload("CreditNote.Applications", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_payment")
+	}).Where("id_return = ?", returnId).First(&purchaseReturn).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &purchaseReturn, nil
+}
+
+// FindReturns lists the returns to one vendor, or to all when vendorId is
+// empty, newest first.
+func (r *purchaseReturnRepository) FindReturns(vendorId string) ([]entity.PurchaseReturn, error) {
+	var returns []entity.PurchaseReturn
+	query := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_returnline")
+	}).Preload("CreditNote")
+	if vendorId != "" {
+		query = query.Where("id_vendor = ?", vendorId)
+	}
+	if err := query.Order("id_return DESC").Find(&returns).Error; err != nil {
+		return nil, err
+	}
+	return returns, nil
+}
+
+func (r *purchaseReturnRepository) FindReturnLinesByRfqId(rfqId string) ([]entity.PurchaseReturnLine, error) {
+	var lines []entity.PurchaseReturnLine
+	err := r.db.Where("id_return IN (?)", r.db.Model(&entity.PurchaseReturn{}).Select("id_return").Where("id_rfq = ?", rfqId)).
+		Order("id_returnline").Find(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// SumReturnedQty is how much of a receipt line earlier returns sent back.
+func (r *purchaseReturnRepository) SumReturnedQty(receiptLineId string) (float64, error) {
+	var total float64
+	err := r.db.Model(&entity.PurchaseReturnLine{}).Where("id_receiptline = ?", receiptLineId).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *purchaseReturnRepository) GetLastCreditNoteId() (string, error) {
+	var lastCreditNote entity.VendorCreditNote
+	err := r.db.Unscoped().Order("id_creditnote DESC").First(&lastCreditNote).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastCreditNote.CreditNoteId, nil
+}
+
+func (r *purchaseReturnRepository) CreateCreditNote(creditNote *entity.VendorCreditNote) (*entity.VendorCreditNote, error) {
+	if err := r.db.Omit("Applications").Create(creditNote).Error; err != nil {
+		return nil, err
+	}
+	return creditNote, nil
+}
+
+func (r *purchaseReturnRepository) UpdateCreditNote(creditNote *entity.VendorCreditNote) (*entity.VendorCreditNote, error) {
+	if err := r.db.Omit("Applications").Save(creditNote).Error; err != nil {
+		return nil, err
+	}
+	return creditNote, nil
+}
+
+func (r *purchaseReturnRepository) FindCreditNoteByID(creditNoteId string) (*entity.VendorCreditNote, error) {
+	var creditNote entity.VendorCreditNote
+	err := r.db.Preload("Applications", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_payment")
+	}).Where("id_creditnote = ?", creditNoteId).First(&creditNote).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &creditNote, nil
+}
+
+// FindCreditNotes lists the credit notes of one vendor, or of all when
+// vendorId is empty, oldest first. openOnly leaves out those fully applied.
+func (r *purchaseReturnRepository) FindCreditNotes(vendorId string, openOnly bool) ([]entity.VendorCreditNote, error) {
+	var creditNotes []entity.VendorCreditNote
+	query := r.db.Preload("Applications", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_payment")
+	})
+	if vendorId != "" {
+		query = query.Where("id_vendor = ?", vendorId)
+	}
+	if openOnly {
+		query = query.Where("status <> ?", "applied")
+	}
+	if err := query.Order("id_creditnote").Find(&creditNotes).Error; err != nil {
+		return nil, err
+	}
+	return creditNotes, nil
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type QualityRepository interface {
+	GetLastCheckId() (string, error)
+	CreateCheck(check *entity.QualityCheck) (*entity.QualityCheck, error)
+	UpdateCheck(check *entity.QualityCheck) (*entity.QualityCheck, error)
+	DeleteCheck(check *entity.QualityCheck) (bool, error)
+	FindCheckByID(checkId string) (*entity.QualityCheck, error)
+	FindAllChecks() ([]entity.QualityCheck, error)
+	FindApplicableChecks(trigger, materialId, productId string) ([]entity.QualityCheck, error)
+	GetLastInspectionId() (string, error)
+	CreateInspection(inspection *entity.QcInspection) (*entity.QcInspection, error)
+	UpdateInspection(inspection *entity.QcInspection) (*entity.QcInspection, error)
+	FindInspectionByID(inspectionId string) (*entity.QcInspection, error)
+	FindInspections(status string) ([]entity.QcInspection, error)
+	FindInspectionsByMoId(moId string) ([]entity.QcInspection, error)
+	FindDecidedInspections(from, to time.Time) ([]entity.QcInspection, error)
+	FindVendorInspections(vendorId string, from, to time.Time) ([]entity.QcInspection, error)
+	GetLastResultId() (string, error)
+	CreateResult(result *entity.QcResult) (*entity.QcResult, error)
+	UpdateResult(result *entity.QcResult) (*entity.QcResult, error)
+}
+
+type qualityRepository struct {
+	db *gorm.DB
+}
+
+func NewQualityRepository(db *gorm.DB) QualityRepository {
+	return &qualityRepository{db: db}
+}
+
+func (r *qualityRepository) GetLastCheckId() (string, error) {
+	var lastCheck entity.QualityCheck
+	err := r.db.Unscoped().Order("id_qualitycheck DESC").First(&lastCheck).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastCheck.QualityCheckId, nil
+}
+
+func (r *qualityRepository) CreateCheck(check *entity.QualityCheck) (*entity.QualityCheck, error) {
+	if err := r.db.Create(check).Error; err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+func (r *qualityRepository) UpdateCheck(check *entity.QualityCheck) (*entity.QualityCheck, error) {
+	if err := r.db.Save(check).Error; err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// DeleteCheck soft-deletes the check so results recorded against it keep
+// their reference.
+func (r *qualityRepository) DeleteCheck(check *entity.QualityCheck) (bool, error) {
+	if err := r.db.Delete(check).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *qualityRepository) FindCheckByID(checkId string) (*entity.QualityCheck, error) {
+	var check entity.QualityCheck
+	if err := r.db.Where("id_qualitycheck = ?", checkId).First(&check).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &check, nil
+}
+
+func (r *qualityRepository) FindAllChecks() ([]entity.QualityCheck, error) {
+	var checks []entity.QualityCheck
+	if err := r.db.Order("id_qualitycheck").Find(&checks).Error; err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+// FindApplicableChecks returns the active checks of the trigger that are
+// either generic or scoped to the given material or product.
+func (r *qualityRepository) FindApplicableChecks(trigger, materialId, productId string) ([]entity.QualityCheck, error) {
+	var checks []entity.QualityCheck
+	err := r.db.Where("trigger = ? AND active = TRUE", trigger).
+		Where("(id_material = '' AND id_product = '') OR (id_material <> '' AND id_material = ?) OR (id_product <> '' AND id_product = ?)", materialId, productId).
+		Order("id_qualitycheck").Find(&checks).Error
+	if err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+func (r *qualityRepository) GetLastInspectionId() (string, error) {
+	var lastInspection entity.QcInspection
+	err := r.db.Order("id_inspection DESC").First(&lastInspection).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastInspection.InspectionId, nil
+}
+
+func (r *qualityRepository) CreateInspection(inspection *entity.QcInspection) (*entity.QcInspection, error) {
+	if err := r.db.Omit("Results").Create(inspection).Error; err != nil {
+		return nil, err
+	}
+	return inspection, nil
+}
+
+func (r *qualityRepository) UpdateInspection(inspection *entity.QcInspection) (*entity.QcInspection, error) {
+	if err := r.db.Omit("Results").Save(inspection).Error; err != nil {
+		return nil, err
+	}
+	return inspection, nil
+}
+
+func (r *qualityRepository) FindInspectionByID(inspectionId string) (*entity.QcInspection, error) {
+	var inspection entity.QcInspection
+	err := r.db.Preload("Results", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_qcresult")
+	}).Where("id_inspection = ?", inspectionId).First(&inspection).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &inspection, nil
+}
+
+// FindInspections lists inspections, newest first, optionally filtered by
+// status.
+func (r *qualityRepository) FindInspections(status string) ([]entity.QcInspection, error) {
+	var inspections []entity.QcInspection
+	query := r.db.Preload("Results")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("id_inspection DESC").Find(&inspections).Error; err != nil {
+		return nil, err
+	}
+	return inspections, nil
+}
+
+func (r *qualityRepository) FindInspectionsByMoId(moId string) ([]entity.QcInspection, error) {
+	var inspections []entity.QcInspection
+	err := r.db.Preload("Results", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_qcresult")
+	}).Where("id_mo = ?", moId).Order("id_inspection").Find(&inspections).Error
+	if err != nil {
+		return nil, err
+	}
+	return inspections, nil
+}
+
+// FindDecidedInspections returns the inspections that reached a pass or fail
+// result within [from, to).
+func (r *qualityRepository) FindDecidedInspections(from, to time.Time) ([]entity.QcInspection, error) {
+	var inspections []entity.QcInspection
+	err := r.db.Where("result <> '' AND created_at >= ? AND created_at < ?", from, to).
+		Order("id_inspection").Find(&inspections).Error
+	if err != nil {
+		return nil, err
+	}
+	return inspections, nil
+}
+
+// FindVendorInspections returns the receipt inspections of a vendor's goods
+// created within [from, to).
+func (r *qualityRepository) FindVendorInspections(vendorId string, from, to time.Time) ([]entity.QcInspection, error) {
+	var inspections []entity.QcInspection
+	err := r.db.Where("trigger = ? AND id_vendor = ? AND created_at >= ? AND created_at < ?", "receipt", vendorId, from, to).
+		Order("id_inspection").Find(&inspections).Error
+	if err != nil {
+		return nil, err
+	}
+	return inspections, nil
+}
+
+func (r *qualityRepository) GetLastResultId() (string, error) {
+	var lastResult entity.QcResult
+	err := r.db.Order("id_qcresult DESC").First(&lastResult).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastResult.ResultId, nil
+}
+
+func (r *qualityRepository) CreateResult(result *entity.QcResult) (*entity.QcResult, error) {
+	if err := r.db.Create(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *qualityRepository) UpdateResult(result *entity.QcResult) (*entity.QcResult, error) {
+	if err := r.db.Save(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -21,7 +21,9 @@ type QuoRepository interface {
 	FindAllQuo(page int) ([]entity.Quotations, error)
 	FindAllQuoBill(page int) ([]entity.Quotations, error)
 	UpdateQuoStatus(rfq *entity.Quotations) (*entity.Quotations, error)
+	UpdateQuoTotals(quo *entity.Quotations) error
 	CheckEmailExistsByCostumerId(vendorId string) (string, error)
+	FindQuosByStatus(status string) ([]entity.Quotations, error)
 }
 
 type quoRepository struct {
@@ -172,6 +174,23 @@ func (r *quoRepository) UpdateQuoStatus(rfq *entity.Quotations) (*entity.Quotati
 	return rfq, nil
 }
 
+// UpdateQuoTotals saves the tax rounding and amounts of the quotation header.
+func (r *quoRepository) UpdateQuoTotals(quo *entity.Quotations) error {
+	if err := r.db.Model(&entity.Quotations{}).
+		Where("id_quotation = ?", quo.QuotationsId).
+		Updates(map[string]interface{}{
+			"tax_rounding":   quo.TaxRounding,
+			"amount_untaxed": quo.AmountUntaxed,
+			"amount_tax":     quo.AmountTax,
+			"amount_total":   quo.AmountTotal,
+		}).Error; err != nil {
+		return err
+	}
+	r.cacheable.Delete("FindAllQuo_page_1")
+	r.cacheable.Delete("FindAllQuoBill_page_1")
+	return nil
+}
+
 func (r *quoRepository) CheckEmailExistsByCostumerId(vendorId string) (string, error) {
 	var vendor entity.Costumers
 	err := r.db.Table("costumers").Where("id_costumer = ?", vendorId).Select("email").Scan(&vendor).Error
@@ -185,3 +204,11 @@ func (r *quoRepository) CheckEmailExistsByCostumerId(vendorId string) (string, e
 
 	return vendor.Email, nil
 }
+
+func (r *quoRepository) FindQuosByStatus(status string) ([]entity.Quotations, error) {
+	var quotations []entity.Quotations
+	if err := r.db.Where("status = ?", status).Preload("Products").Order("id_quotation").Find(&quotations).Error; err != nil {
+		return nil, err
+	}
+	return quotations, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type ReorderRuleRepository interface {
+	GetLastRuleId() (string, error)
+	CreateRule(rule *entity.ReorderRule) (*entity.ReorderRule, error)
+	UpdateRule(rule *entity.ReorderRule) (*entity.ReorderRule, error)
+	DeleteRule(rule *entity.ReorderRule) (bool, error)
+	FindRuleByID(ruleId string) (*entity.ReorderRule, error)
+	FindAllRules() ([]entity.ReorderRule, error)
+	CheckRuleExists(materialId, productId, excludeRuleId string) (bool, error)
+}
+
+type reorderRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewReorderRuleRepository(db *gorm.DB) ReorderRuleRepository {
+	return &reorderRuleRepository{db: db}
+}
+
+func (r *reorderRuleRepository) GetLastRuleId() (string, error) {
+	var lastRule entity.ReorderRule
+	err := r.db.Order("id_reorderrule DESC").First(&lastRule).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastRule.RuleId, nil
+}
+
+func (r *reorderRuleRepository) CreateRule(rule *entity.ReorderRule) (*entity.ReorderRule, error) {
+	if err := r.db.Create(rule).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *reorderRuleRepository) UpdateRule(rule *entity.ReorderRule) (*entity.ReorderRule, error) {
+	if err := r.db.Save(rule).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *reorderRuleRepository) DeleteRule(rule *entity.ReorderRule) (bool, error) {
+	if err := r.db.Unscoped().Delete(rule).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *reorderRuleRepository) FindRuleByID(ruleId string) (*entity.ReorderRule, error) {
+	var rule entity.ReorderRule
+	if err := r.db.Where("id_reorderrule = ?", ruleId).First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *reorderRuleRepository) FindAllRules() ([]entity.ReorderRule, error) {
+	var rules []entity.ReorderRule
+	if err := r.db.Order("id_reorderrule").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// CheckRuleExists reports whether the item already has a rule other than
+// excludeRuleId; each material or product gets at most one.
+func (r *reorderRuleRepository) CheckRuleExists(materialId, productId, excludeRuleId string) (bool, error) {
+	var count int64
+	query := r.db.Model(&entity.ReorderRule{}).Where("id_reorderrule <> ?", excludeRuleId)
+	if materialId != "" {
+		query = query.Where("id_material = ?", materialId)
+	} else {
+		query = query.Where("id_product = ?", productId)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
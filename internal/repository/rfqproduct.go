@@ -11,9 +11,13 @@ type RfqProductRepository interface {
 	GetProductsByRfqId(rfqId string) ([]entity.RfqsProduct, error)
 	CheckMaterialExists(materialId string) (bool, error)
 	UpdateProduct(product *entity.RfqsProduct) (*entity.RfqsProduct, error)
-	GetProductByRfqIdAndProductId(rfqId, productId string) (*entity.RfqsProduct, error)
-	GetProductDetails(materialId string) (*entity.Materials, error)
+	GetProductByRfqIdAndItemId(rfqId, itemId string) (*entity.RfqsProduct, error)
+	GetMaterialDetails(materialId string) (*entity.Materials, error)
+	GetProductDetails(productId string) (*entity.Products, error)
 	DeleteProductsByRfqId(rfqId string) error
+	DeleteProduct(product *entity.RfqsProduct) error
+	UpdateReceivedQty(product *entity.RfqsProduct) error
+	UpdateLineAmounts(product *entity.RfqsProduct) error
 }
 
 type rfqProductRepository struct {
@@ -66,9 +70,14 @@ func (r *rfqProductRepository) UpdateProduct(product *entity.RfqsProduct) (*enti
 	return product, nil
 }
 
-func (r *rfqProductRepository) GetProductByRfqIdAndProductId(rfqId, productId string) (*entity.RfqsProduct, error) {
+// GetProductByRfqIdAndItemId finds the line of an RFQ buying the given
+// material or product.
+func (r *rfqProductRepository) GetProductByRfqIdAndItemId(rfqId, itemId string) (*entity.RfqsProduct, error) {
 	var product entity.RfqsProduct
-	if err := r.db.Where("id_rfq = ? AND id_product = ?", rfqId, productId).First(&product).Error; err != nil {
+	err := r.db.Where("id_rfq = ?", rfqId).
+		Where("(line_type = 'material' AND id_material = ?) OR (line_type = 'product' AND id_product = ?)", itemId, itemId).
+		First(&product).Error
+	if err != nil {
 		// Jika tidak ditemukan, kembalikan nil dan error yang sesuai
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -78,9 +87,17 @@ func (r *rfqProductRepository) GetProductByRfqIdAndProductId(rfqId, productId st
 	return &product, nil
 }
 
-func (r *rfqProductRepository) GetProductDetails(materialId string) (*entity.Materials, error) {
-	var product entity.Materials
-	if err := r.db.Table("materials").Where("id_material = ?", materialId).First(&product).Error; err != nil {
+func (r *rfqProductRepository) GetMaterialDetails(materialId string) (*entity.Materials, error) {
+	var material entity.Materials
+	if err := r.db.Table("materials").Where("id_material = ?", materialId).First(&material).Error; err != nil {
+		return nil, err
+	}
+	return &material, nil
+}
+
+func (r *rfqProductRepository) GetProductDetails(productId string) (*entity.Products, error) {
+	var product entity.Products
+	if err := r.db.Table("products").Where("id_product = ?", productId).First(&product).Error; err != nil {
 		return nil, err
 	}
 	return &product, nil
@@ -93,3 +110,32 @@ func (s *rfqProductRepository) DeleteProductsByRfqId(rfqId string) error {
 	}
 	return nil
 }
+
+func (r *rfqProductRepository) DeleteProduct(product *entity.RfqsProduct) error {
+	return r.db.Unscoped().Where("id_rfqproduct = ?", product.RfqsProductId).Delete(&entity.RfqsProduct{}).Error
+}
+
+// UpdateReceivedQty saves how much of the line has arrived or been cancelled.
+func (r *rfqProductRepository) UpdateReceivedQty(product *entity.RfqsProduct) error {
+	return r.db.Model(&entity.RfqsProduct{}).
+		Where("id_rfqproduct = ?", product.RfqsProductId).
+		Updates(map[string]interface{}{
+			"qty_received":  product.QtyReceived,
+			"qty_cancelled": product.QtyCancelled,
+			"updated_at":    product.UpdatedAt,
+		}).Error
+}
+
+// UpdateLineAmounts saves the priced figures of the line, zeroes included.
+func (r *rfqProductRepository) UpdateLineAmounts(product *entity.RfqsProduct) error {
+	return r.db.Model(&entity.RfqsProduct{}).
+		Where("id_rfqproduct = ?", product.RfqsProductId).
+		Updates(map[string]interface{}{
+			"discount":   product.Discount,
+			"id_tax":     product.TaxId,
+			"tax":        product.Tax,
+			"subtotal":   product.Subtotal,
+			"tax_amount": product.TaxAmount,
+			"total":      product.Total,
+		}).Error
+}
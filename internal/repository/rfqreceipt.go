@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type RfqReceiptRepository interface {
+	GetLastReceiptId() (string, error)
+	CreateReceipt(receipt *entity.RfqReceipt) (*entity.RfqReceipt, error)
+	GetLastReceiptLineId() (string, error)
+	CreateReceiptLine(line *entity.RfqReceiptLine) (*entity.RfqReceiptLine, error)
+	FindReceiptsByRfqId(rfqId string) ([]entity.RfqReceipt, error)
+	FindReceiptByID(receiptId string) (*entity.RfqReceipt, error)
+}
+
+type rfqReceiptRepository struct {
+	db *gorm.DB
+}
+
+func NewRfqReceiptRepository(db *gorm.DB) RfqReceiptRepository {
+	return &rfqReceiptRepository{db: db}
+}
+
+func (r *rfqReceiptRepository) GetLastReceiptId() (string, error) {
+	var lastReceipt entity.RfqReceipt
+	err := r.db.Unscoped().Order("id_receipt DESC").First(&lastReceipt).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastReceipt.ReceiptId, nil
+}
+
+func (r *rfqReceiptRepository) CreateReceipt(receipt *entity.RfqReceipt) (*entity.RfqReceipt, error) {
+	if err := r.db.Omit("Lines").Create(receipt).Error; err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+func (r *rfqReceiptRepository) GetLastReceiptLineId() (string, error) {
+	var lastLine entity.RfqReceiptLine
+	err := r.db.Unscoped().Order("id_receiptline DESC").First(&lastLine).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastLine.ReceiptLineId, nil
+}
+
+func (r *rfqReceiptRepository) CreateReceiptLine(line *entity.RfqReceiptLine) (*entity.RfqReceiptLine, error) {
+	if err := r.db.Create(line).Error; err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func (r *rfqReceiptRepository) FindReceiptsByRfqId(rfqId string) ([]entity.RfqReceipt, error) {
+	var receipts []entity.RfqReceipt
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_receiptline")
+	}).Where("id_rfq = ?", rfqId).Order("id_receipt").Find(&receipts).Error
+	if err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+func (r *rfqReceiptRepository) FindReceiptByID(receiptId string) (*entity.RfqReceipt, error) {
+	var receipt entity.RfqReceipt
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_receiptline")
+	}).Where("id_receipt = ?", receiptId).First(&receipt).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &receipt, nil
+}
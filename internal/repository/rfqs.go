@@ -18,12 +18,15 @@ type RfqRepository interface {
 	UpdateRfq(rfq *entity.Rfqs) (*entity.Rfqs, error)
 	GetRfqById(rfqId string) (*entity.Rfqs, error)
 	UpdateRfqStatus(rfq *entity.Rfqs) (*entity.Rfqs, error)
+	UpdateRfqTotals(rfq *entity.Rfqs) error
 	FindAllRfq(page int) ([]entity.Rfqs, error)
 	FindAllRfqBill(page int) ([]entity.Rfqs, error)
 	GetVendorDetails(vendorId string) (*entity.Vendors, error)
 	CheckEmailExistsByVendorId(vendorId string) (string, error)
 	DeleteRfq(mo *entity.Rfqs) (bool, error)
 	UpdateRfqAll(rfq *entity.Rfqs) (*entity.Rfqs, error)
+	FindRfqsByStatus(statuses []string) ([]entity.Rfqs, error)
+	FindPurchaseOrders(vendorId string, from, to time.Time) ([]entity.Rfqs, error)
 }
 
 type rfqRepository struct {
@@ -90,6 +93,9 @@ func (r *rfqRepository) GetRfqById(rfqId string) (*entity.Rfqs, error) {
 	var rfq entity.Rfqs
 	if err := r.db.Where("id_rfq = ?", rfqId).
 		Preload("Products"). // Memuat data dari tabel rfqs_products
+		Preload("Approvals", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id_rfqapproval")
+		}).
 		First(&rfq).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -106,6 +112,7 @@ func (r *rfqRepository) UpdateRfqStatus(rfq *entity.Rfqs) (*entity.Rfqs, error)
 			"order_date": rfq.OrderDate,
 			"id_vendor":  rfq.VendorId,
 			"status":     rfq.Status,
+			"ordered_at": rfq.OrderedAt,
 			"updated_at": rfq.UpdatedAt,
 		}).Error; err != nil {
 		return nil, err
@@ -116,6 +123,23 @@ func (r *rfqRepository) UpdateRfqStatus(rfq *entity.Rfqs) (*entity.Rfqs, error)
 	return rfq, nil
 }
 
+// UpdateRfqTotals saves the tax rounding and amounts of the RFQ header.
+func (r *rfqRepository) UpdateRfqTotals(rfq *entity.Rfqs) error {
+	if err := r.db.Model(&entity.Rfqs{}).
+		Where("id_rfq = ?", rfq.RfqId).
+		Updates(map[string]interface{}{
+			"tax_rounding":   rfq.TaxRounding,
+			"amount_untaxed": rfq.AmountUntaxed,
+			"amount_tax":     rfq.AmountTax,
+			"amount_total":   rfq.AmountTotal,
+		}).Error; err != nil {
+		return err
+	}
+	r.cacheable.Delete("FindAllRfq_page_1")
+	r.cacheable.Delete("FindAllRfqBill_page_1")
+	return nil
+}
+
 func (r *rfqRepository) FindAllRfq(page int) ([]entity.Rfqs, error) {
 	var Rfq []entity.Rfqs
 	key := fmt.Sprintf("FindAllRfq_page_%d", page)
@@ -154,7 +178,7 @@ func (r *rfqRepository) FindAllRfqBill(page int) ([]entity.Rfqs, error) {
 	data, _ := r.cacheable.Get(key)
 	if data == "" {
 		offset := (page - 1) * pageSize
-		if err := r.db.Where("status IN ?", []string{"Recived", "Billed", "Done", "Purchase Order"}).
+		if err := r.db.Where("status IN ?", []string{"Partially Received", "Received", "Billed", "Done", "Purchase Order"}).
 			Limit(pageSize).
 			Offset(offset).
 			Find(&Rfq).Error; err != nil {
@@ -213,4 +237,26 @@ func (r *rfqRepository) UpdateRfqAll(rfq *entity.Rfqs) (*entity.Rfqs, error) {
 	r.cacheable.Delete("FindAllRfq_page_1")
 	r.cacheable.Delete("FindAllRfqBill_page_1")
 	return rfq, nil
-}
\ No newline at end of file
+}
+
+func (r *rfqRepository) FindRfqsByStatus(statuses []string) ([]entity.Rfqs, error) {
+	var rfqs []entity.Rfqs
+	if err := r.db.Where("status IN ?", statuses).Preload("Products").Order("id_rfq").Find(&rfqs).Error; err != nil {
+		return nil, err
+	}
+	return rfqs, nil
+}
+
+// FindPurchaseOrders returns the RFQs ordered within [from, to), of one vendor
+// or of all when vendorId is empty, with their lines.
+func (r *rfqRepository) FindPurchaseOrders(vendorId string, from, to time.Time) ([]entity.Rfqs, error) {
+	var rfqs []entity.Rfqs
+	query := r.db.Where("status NOT IN ? AND ordered_at >= ? AND ordered_at < ?", []string{"RFQ", "Cancelled"}, from, to)
+	if vendorId != "" {
+		query = query.Where("id_vendor = ?", vendorId)
+	}
+	if err := query.Preload("Products").Order("id_rfq").Find(&rfqs).Error; err != nil {
+		return nil, err
+	}
+	return rfqs, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type RoutingRepository interface {
+	GetLastOperationId() (string, error)
+	CreateOperation(operation *entity.RoutingOperation) (*entity.RoutingOperation, error)
+	GetOperationsByBomId(bomId string) ([]entity.RoutingOperation, error)
+	DeleteOperationsByBomId(bomId string) error
+}
+
+type routingRepository struct {
+	db *gorm.DB
+}
+
+func NewRoutingRepository(db *gorm.DB) RoutingRepository {
+	return &routingRepository{db: db}
+}
+
+func (r *routingRepository) GetLastOperationId() (string, error) {
+	var lastOperation entity.RoutingOperation
+	err := r.db.Order("id_operation DESC").First(&lastOperation).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastOperation.OperationId, nil
+}
+
+func (r *routingRepository) CreateOperation(operation *entity.RoutingOperation) (*entity.RoutingOperation, error) {
+	if err := r.db.Create(operation).Error; err != nil {
+		return nil, err
+	}
+	return operation, nil
+}
+
+func (r *routingRepository) GetOperationsByBomId(bomId string) ([]entity.RoutingOperation, error) {
+	var operations []entity.RoutingOperation
+	if err := r.db.Where("id_bom = ?", bomId).Order("sequence").Find(&operations).Error; err != nil {
+		return nil, err
+	}
+	return operations, nil
+}
+
+func (r *routingRepository) DeleteOperationsByBomId(bomId string) error {
+	if err := r.db.Unscoped().Where("id_bom = ?", bomId).Delete(&entity.RoutingOperation{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
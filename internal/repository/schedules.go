@@ -18,6 +18,10 @@ type SchedulesRepository interface {
 	CheckScheduleExists(id uuid.UUID) (bool, error)
 	FindScheduleByID(id_schedules uuid.UUID) (*entity.Schedules, error)
 	DeleteSchedule(schedule *entity.Schedules) (bool, error)
+	FindTankBookings(vesselId string, from, to time.Time) ([]entity.Schedules, error)
+	FindTankBookingsByMoId(moId string) ([]entity.Schedules, error)
+	DeleteTankBookingsByMoId(moId string) error
+	UpdateTankBookingEnd(id uuid.UUID, end time.Time) (bool, error)
 }
 
 type schedulesRepository struct {
@@ -109,3 +113,40 @@ func (r *schedulesRepository) DeleteSchedule(schedule *entity.Schedules) (bool,
 	r.cacheable.Delete("FindAllSchedule_page_1")
 	return true, nil
 }
+
+// FindTankBookings returns the bookings overlapping [from, to), ordered by
+// start date. An empty vesselId returns the bookings of every tank.
+func (r *schedulesRepository) FindTankBookings(vesselId string, from, to time.Time) ([]entity.Schedules, error) {
+	var bookings []entity.Schedules
+	query := r.db.Where("id_vessel <> '' AND date_schedules < ? AND COALESCE(date_end, date_schedules) > ?", to, from)
+	if vesselId != "" {
+		query = query.Where("id_vessel = ?", vesselId)
+	}
+	if err := query.Order("date_schedules").Find(&bookings).Error; err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+func (r *schedulesRepository) FindTankBookingsByMoId(moId string) ([]entity.Schedules, error) {
+	var bookings []entity.Schedules
+	if err := r.db.Where("id_mo = ? AND id_vessel <> ''", moId).Order("date_schedules").Find(&bookings).Error; err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+func (r *schedulesRepository) DeleteTankBookingsByMoId(moId string) error {
+	if err := r.db.Where("id_mo = ? AND id_vessel <> ''", moId).Delete(&entity.Schedules{}).Error; err != nil {
+		return err
+	}
+	r.cacheable.Delete("FindAllSchedule_page_1")
+	return nil
+}
+
+func (r *schedulesRepository) UpdateTankBookingEnd(id uuid.UUID, end time.Time) (bool, error) {
+	if err := r.db.Model(&entity.Schedules{}).Where("id_schedules = ?", id).Update("date_end", end).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type TaxRepository interface {
+	GetLastTaxId() (string, error)
+	CreateTax(tax *entity.Tax) (*entity.Tax, error)
+	UpdateTax(tax *entity.Tax) (*entity.Tax, error)
+	DeleteTax(tax *entity.Tax) (bool, error)
+	FindTaxByID(taxId string) (*entity.Tax, error)
+	FindAllTaxes() ([]entity.Tax, error)
+}
+
+type taxRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxRepository(db *gorm.DB) TaxRepository {
+	return &taxRepository{db: db}
+}
+
+func (r *taxRepository) GetLastTaxId() (string, error) {
+	var lastTax entity.Tax
+	err := r.db.Unscoped().Order("id_tax DESC").First(&lastTax).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastTax.TaxId, nil
+}
+
+func (r *taxRepository) CreateTax(tax *entity.Tax) (*entity.Tax, error) {
+	if err := r.db.Create(tax).Error; err != nil {
+		return nil, err
+	}
+	return tax, nil
+}
+
+func (r *taxRepository) UpdateTax(tax *entity.Tax) (*entity.Tax, error) {
+	if err := r.db.Save(tax).Error; err != nil {
+		return nil, err
+	}
+	return tax, nil
+}
+
+func (r *taxRepository) DeleteTax(tax *entity.Tax) (bool, error) {
+	if err := r.db.Delete(tax).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *taxRepository) FindTaxByID(taxId string) (*entity.Tax, error) {
+	var tax entity.Tax
+	if err := r.db.Where("id_tax = ?", taxId).First(&tax).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tax, nil
+}
+
+func (r *taxRepository) FindAllTaxes() ([]entity.Tax, error) {
+	var taxes []entity.Tax
+	if err := r.db.Order("id_tax").Find(&taxes).Error; err != nil {
+		return nil, err
+	}
+	return taxes, nil
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type TenderRepository interface {
+	GetLastTenderId() (string, error)
+	CreateTender(tender *entity.Tender) (*entity.Tender, error)
+	UpdateTender(tender *entity.Tender) (*entity.Tender, error)
+	FindTenderByID(tenderId string) (*entity.Tender, error)
+	FindAllTenders(page int) ([]entity.Tender, error)
+	GetLastTenderLineId() (string, error)
+	CreateTenderLine(line *entity.TenderLine) (*entity.TenderLine, error)
+	GetLastTenderVendorId() (string, error)
+	CreateTenderVendor(vendor *entity.TenderVendor) (*entity.TenderVendor, error)
+	UpdateTenderVendor(vendor *entity.TenderVendor) (*entity.TenderVendor, error)
+	GetLastBidId() (string, error)
+	FindBidsByTenderId(tenderId string) ([]entity.TenderBid, error)
+	SaveBid(bid *entity.TenderBid) (*entity.TenderBid, error)
+}
+
+type tenderRepository struct {
+	db *gorm.DB
+}
+
+func NewTenderRepository(db *gorm.DB) TenderRepository {
+	return &tenderRepository{db: db}
+}
+
+func (r *tenderRepository) GetLastTenderId() (string, error) {
+	var lastTender entity.Tender
+	err := r.db.Unscoped().Order("id_tender DESC").First(&lastTender).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastTender.TenderId, nil
+}
+
+func (r *tenderRepository) CreateTender(tender *entity.Tender) (*entity.Tender, error) {
+	if err := r.db.Omit("Lines", "Vendors").Create(tender).Error; err != nil {
+		return nil, err
+	}
+	return tender, nil
+}
+
+func (r *tenderRepository) UpdateTender(tender *entity.Tender) (*entity.Tender, error) {
+	if err := r.db.Omit("Lines", "Vendors").Save(tender).Error; err != nil {
+		return nil, err
+	}
+	return tender, nil
+}
+
+func (r *tenderRepository) FindTenderByID(tenderId string) (*entity.Tender, error) {
+	var tender entity.Tender
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_tenderline")
+	}).Preload("Vendors", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id_tendervendor")
+	}).Where("id_tender = ?", tenderId).First(&tender).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tender, nil
+}
+
+func (r *tenderRepository) FindAllTenders(page int) ([]entity.Tender, error) {
+	var tenders []entity.Tender
+	const pageSize = 100
+	if err := r.db.Order("id_tender DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&tenders).Error; err != nil {
+		return nil, err
+	}
+	return tenders, nil
+}
+
+func (r *tenderRepository) GetLastTenderLineId() (string, error) {
+	var lastLine entity.TenderLine
+	err := r.db.Unscoped().Order("id_tenderline DESC").First(&lastLine).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastLine.TenderLineId, nil
+}
+
+func (r *tenderRepository) CreateTenderLine(line *entity.TenderLine) (*entity.TenderLine, error) {
+	if err := r.db.Create(line).Error; err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func (r *tenderRepository) GetLastTenderVendorId() (string, error) {
+	var lastVendor entity.TenderVendor
+	err := r.db.Unscoped().Order("id_tendervendor DESC").First(&lastVendor).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastVendor.TenderVendorId, nil
+}
+
+func (r *tenderRepository) CreateTenderVendor(vendor *entity.TenderVendor) (*entity.TenderVendor, error) {
+	if err := r.db.Create(vendor).Error; err != nil {
+		return nil, err
+	}
+	return vendor, nil
+}
+
+func (r *tenderRepository) UpdateTenderVendor(vendor *entity.TenderVendor) (*entity.TenderVendor, error) {
+	if err := r.db.Save(vendor).Error; err != nil {
+		return nil, err
+	}
+	return vendor, nil
+}
+
+func (r *tenderRepository) GetLastBidId() (string, error) {
+	var lastBid entity.TenderBid
+	err := r.db.Unscoped().Order("id_bid DESC").First(&lastBid).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastBid.BidId, nil
+}
+
+func (r *tenderRepository) FindBidsByTenderId(tenderId string) ([]entity.TenderBid, error) {
+	var bids []entity.TenderBid
+	if err := r.db.Where("id_tender = ?", tenderId).Order("id_tenderline, id_vendor").Find(&bids).Error; err != nil {
+		return nil, err
+	}
+	return bids, nil
+}
+
+func (r *tenderRepository) SaveBid(bid *entity.TenderBid) (*entity.TenderBid, error) {
+	if err := r.db.Save(bid).Error; err != nil {
+		return nil, err
+	}
+	return bid, nil
+}
@@ -29,6 +29,7 @@ type UserRepository interface {
 	GetEventName(EventId uuid.UUID) (string, error)
 	GetAllUserIds() ([]uuid.UUID, error)
 	UpdateUserJwtToken(userID uuid.UUID, token string, expiresAt time.Time) error
+	FindUserBySession(userID uuid.UUID, token string) (*entity.User, error)
 	CheckUser(UserId uuid.UUID) (*entity.User, error)
 	CheckUserExists(id uuid.UUID) (bool, error)
 }
@@ -233,6 +234,21 @@ func (u *userRepository) UpdateUserJwtToken(userID uuid.UUID, token string, expi
 	return nil
 }
 
+// FindUserBySession returns the user when the token is the one stored at
+// their last login and it has not expired, and nil otherwise.
+func (r *userRepository) FindUserBySession(userID uuid.UUID, token string) (*entity.User, error) {
+	user := new(entity.User)
+	err := r.db.Where("id_user = ? AND jwt_token = ? AND jwt_token_expires_at > ?", userID, token, time.Now()).
+		Take(user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
 func (r *userRepository) GetAllUserIds() ([]uuid.UUID, error) {
 	var userIds []uuid.UUID
 	result := r.db.Table("users").Pluck("id_user", &userIds)
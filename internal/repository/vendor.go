@@ -20,6 +20,7 @@ type VendorRepository interface {
 	FindVendorByID(vendorId string) (*entity.Vendors, error)
 	DeleteVendor(vendor *entity.Vendors) (bool, error)
 	FindAllVendor(page int) ([]entity.Vendors, error)
+	UpdatePaymentTerms(vendor *entity.Vendors) (*entity.Vendors, error)
 }
 
 type vendorRepository struct {
@@ -91,6 +92,19 @@ func (r *vendorRepository) UpdateVendor(vendor *entity.Vendors) (*entity.Vendors
 	return vendor, nil
 }
 
+func (r *vendorRepository) UpdatePaymentTerms(vendor *entity.Vendors) (*entity.Vendors, error) {
+	err := r.db.Model(vendor).Where("id_vendor = ?", vendor.VendorId).Updates(map[string]interface{}{
+		"payment_term_days": vendor.PaymentTermDays,
+		"upfront_percent":   vendor.UpfrontPercent,
+		"updated_at":        vendor.UpdatedAt,
+	}).Error
+	if err != nil {
+		return vendor, err
+	}
+	r.cacheable.Delete("FindAllVendors_page_1")
+	return vendor, nil
+}
+
 func (r *vendorRepository) FindVendorByID(vendorId string) (*entity.Vendors, error) {
 	vendors := new(entity.Vendors)
 	if err := r.db.Where("id_vendor = ?", vendorId).First(vendors).Error; err != nil {
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type VesselRepository interface {
+	GetLastVesselId() (string, error)
+	CreateVessel(vessel *entity.Vessel) (*entity.Vessel, error)
+	UpdateVessel(vessel *entity.Vessel) (*entity.Vessel, error)
+	DeleteVessel(vessel *entity.Vessel) (bool, error)
+	FindVesselByID(vesselId string) (*entity.Vessel, error)
+	FindAllVessels() ([]entity.Vessel, error)
+	CheckVesselInUse(vesselId string) (bool, error)
+}
+
+type vesselRepository struct {
+	db *gorm.DB
+}
+
+func NewVesselRepository(db *gorm.DB) VesselRepository {
+	return &vesselRepository{db: db}
+}
+
+func (r *vesselRepository) GetLastVesselId() (string, error) {
+	var lastVessel entity.Vessel
+	err := r.db.Order("id_vessel DESC").First(&lastVessel).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastVessel.VesselId, nil
+}
+
+func (r *vesselRepository) CreateVessel(vessel *entity.Vessel) (*entity.Vessel, error) {
+	if err := r.db.Create(vessel).Error; err != nil {
+		return nil, err
+	}
+	return vessel, nil
+}
+
+func (r *vesselRepository) UpdateVessel(vessel *entity.Vessel) (*entity.Vessel, error) {
+	if err := r.db.Save(vessel).Error; err != nil {
+		return nil, err
+	}
+	return vessel, nil
+}
+
+func (r *vesselRepository) DeleteVessel(vessel *entity.Vessel) (bool, error) {
+	if err := r.db.Unscoped().Delete(vessel).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *vesselRepository) FindVesselByID(vesselId string) (*entity.Vessel, error) {
+	var vessel entity.Vessel
+	if err := r.db.Where("id_vessel = ?", vesselId).First(&vessel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &vessel, nil
+}
+
+func (r *vesselRepository) FindAllVessels() ([]entity.Vessel, error) {
+	var vessels []entity.Vessel
+	if err := r.db.Order("id_vessel").Find(&vessels).Error; err != nil {
+		return nil, err
+	}
+	return vessels, nil
+}
+
+// CheckVesselInUse reports whether the tank still has bookings on the
+// schedule.
+func (r *vesselRepository) CheckVesselInUse(vesselId string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&entity.Schedules{}).Where("id_vessel = ?", vesselId).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type WorkCenterRepository interface {
+	GetLastWorkCenterId() (string, error)
+	CreateWorkCenter(workCenter *entity.WorkCenter) (*entity.WorkCenter, error)
+	UpdateWorkCenter(workCenter *entity.WorkCenter) (*entity.WorkCenter, error)
+	DeleteWorkCenter(workCenter *entity.WorkCenter) (bool, error)
+	FindWorkCenterByID(workCenterId string) (*entity.WorkCenter, error)
+	FindAllWorkCenters() ([]entity.WorkCenter, error)
+	CheckWorkCenterInUse(workCenterId string) (bool, error)
+}
+
+type workCenterRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkCenterRepository(db *gorm.DB) WorkCenterRepository {
+	return &workCenterRepository{db: db}
+}
+
+func (r *workCenterRepository) GetLastWorkCenterId() (string, error) {
+	var lastWorkCenter entity.WorkCenter
+	err := r.db.Order("id_workcenter DESC").First(&lastWorkCenter).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastWorkCenter.WorkCenterId, nil
+}
+
+func (r *workCenterRepository) CreateWorkCenter(workCenter *entity.WorkCenter) (*entity.WorkCenter, error) {
+	if err := r.db.Create(workCenter).Error; err != nil {
+		return nil, err
+	}
+	return workCenter, nil
+}
+
+func (r *workCenterRepository) UpdateWorkCenter(workCenter *entity.WorkCenter) (*entity.WorkCenter, error) {
+	if err := r.db.Save(workCenter).Error; err != nil {
+		return nil, err
+	}
+	return workCenter, nil
+}
+
+func (r *workCenterRepository) DeleteWorkCenter(workCenter *entity.WorkCenter) (bool, error) {
+	if err := r.db.Unscoped().Delete(workCenter).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *workCenterRepository) FindWorkCenterByID(workCenterId string) (*entity.WorkCenter, error) {
+	var workCenter entity.WorkCenter
+	if err := r.db.Where("id_workcenter = ?", workCenterId).First(&workCenter).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &workCenter, nil
+}
+
+func (r *workCenterRepository) FindAllWorkCenters() ([]entity.WorkCenter, error) {
+	var workCenters []entity.WorkCenter
+	if err := r.db.Order("id_workcenter").Find(&workCenters).Error; err != nil {
+		return nil, err
+	}
+	return workCenters, nil
+}
+
+// CheckWorkCenterInUse reports whether a routing still sends work to the
+// work center.
+func (r *workCenterRepository) CheckWorkCenterInUse(workCenterId string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&entity.RoutingOperation{}).Where("id_workcenter = ?", workCenterId).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"gorm.io/gorm"
+)
+
+type WorkOrderRepository interface {
+	GetLastWorkOrderId() (string, error)
+	CreateWorkOrder(workOrder *entity.WorkOrder) (*entity.WorkOrder, error)
+	UpdateWorkOrder(workOrder *entity.WorkOrder) (*entity.WorkOrder, error)
+	FindWorkOrderByID(workOrderId string) (*entity.WorkOrder, error)
+	GetWorkOrdersByMoId(moId string) ([]entity.WorkOrder, error)
+	CountRunningByWorkCenter(workCenterId string) (int64, error)
+	FindPlannedByWorkCenter(workCenterId string, from, to time.Time) ([]entity.WorkOrder, error)
+	DeleteWorkOrdersByMoId(moId string) error
+	GetLastLogId() (string, error)
+	CreateLog(log *entity.WorkOrderLog) (*entity.WorkOrderLog, error)
+	UpdateLog(log *entity.WorkOrderLog) (*entity.WorkOrderLog, error)
+	FindOpenLog(workOrderId string) (*entity.WorkOrderLog, error)
+}
+
+type workOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkOrderRepository(db *gorm.DB) WorkOrderRepository {
+	return &workOrderRepository{db: db}
+}
+
+func (r *workOrderRepository) GetLastWorkOrderId() (string, error) {
+	var lastWorkOrder entity.WorkOrder
+	err := r.db.Order("id_workorder DESC").First(&lastWorkOrder).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastWorkOrder.WorkOrderId, nil
+}
+
+func (r *workOrderRepository) CreateWorkOrder(workOrder *entity.WorkOrder) (*entity.WorkOrder, error) {
+	if err := r.db.Omit("Logs").Create(workOrder).Error; err != nil {
+		return nil, err
+	}
+	return workOrder, nil
+}
+
+func (r *workOrderRepository) UpdateWorkOrder(workOrder *entity.WorkOrder) (*entity.WorkOrder, error) {
+	if err := r.db.Omit("Logs").Save(workOrder).Error; err != nil {
+		return nil, err
+	}
+	return workOrder, nil
+}
+
+func (r *workOrderRepository) FindWorkOrderByID(workOrderId string) (*entity.WorkOrder, error) {
+	var workOrder entity.WorkOrder
+	if err := r.db.Preload("Logs").Where("id_workorder = ?", workOrderId).First(&workOrder).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &workOrder, nil
+}
+
+func (r *workOrderRepository) GetWorkOrdersByMoId(moId string) ([]entity.WorkOrder, error) {
+	var workOrders []entity.WorkOrder
+	if err := r.db.Preload("Logs").Where("id_mo = ?", moId).Order("sequence").Find(&workOrders).Error; err != nil {
+		return nil, err
+	}
+	return workOrders, nil
+}
+
+func (r *workOrderRepository) CountRunningByWorkCenter(workCenterId string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&entity.WorkOrder{}).Where("id_workcenter = ? AND status = ?", workCenterId, "in progress").Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// FindPlannedByWorkCenter returns the open work orders of a work center
+// planned to overlap [from, to). An empty workCenterId returns those of every
+// work center.
+func (r *workOrderRepository) FindPlannedByWorkCenter(workCenterId string, from, to time.Time) ([]entity.WorkOrder, error) {
+	var workOrders []entity.WorkOrder
+	query := r.db.Where("status NOT IN ? AND planned_start < ? AND planned_end > ?", []string{"done", "cancelled"}, to, from)
+	if workCenterId != "" {
+		query = query.Where("id_workcenter = ?", workCenterId)
+	}
+	if err := query.Order("planned_start").Find(&workOrders).Error; err != nil {
+		return nil, err
+	}
+	return workOrders, nil
+}
+
+func (r *workOrderRepository) DeleteWorkOrdersByMoId(moId string) error {
+	if err := r.db.Unscoped().Where("id_workorder IN (?)", r.db.Model(&entity.WorkOrder{}).Select("id_workorder").Where("id_mo = ?", moId)).Delete(&entity.WorkOrderLog{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Unscoped().Where("id_mo = ?", moId).Delete(&entity.WorkOrder{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *workOrderRepository) GetLastLogId() (string, error) {
+	var lastLog entity.WorkOrderLog
+	err := r.db.Order("id_workorderlog DESC").First(&lastLog).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return lastLog.LogId, nil
+}
+
+func (r *workOrderRepository) CreateLog(log *entity.WorkOrderLog) (*entity.WorkOrderLog, error) {
+	if err := r.db.Create(log).Error; err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+func (r *workOrderRepository) UpdateLog(log *entity.WorkOrderLog) (*entity.WorkOrderLog, error) {
+	if err := r.db.Save(log).Error; err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// FindOpenLog returns the log of the stretch of work still running, if any.
+func (r *workOrderRepository) FindOpenLog(workOrderId string) (*entity.WorkOrderLog, error) {
+	var log entity.WorkOrderLog
+	if err := r.db.Where("id_workorder = ? AND ended_at IS NULL", workOrderId).First(&log).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &log, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
 	"github.com/Kevinmajesta/parfume-erp-backend/pkg/email"
 	"github.com/Kevinmajesta/parfume-erp-backend/pkg/encrypt"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/token"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -25,14 +26,16 @@ type adminService struct {
 	adminRepository repository.AdminRepository
 	encryptTool     encrypt.EncryptTool
 	emailSender     *email.EmailSender
+	tokenUseCase    token.TokenUseCase
 }
 
 func NewAdminService(adminRepository repository.AdminRepository,
-	encryptTool encrypt.EncryptTool, emailSender *email.EmailSender) *adminService {
+	encryptTool encrypt.EncryptTool, emailSender *email.EmailSender, tokenUseCase token.TokenUseCase) *adminService {
 	return &adminService{
 		adminRepository: adminRepository,
 		encryptTool:     encryptTool,
 		emailSender:     emailSender,
+		tokenUseCase:    tokenUseCase,
 	}
 }
 
@@ -51,7 +54,7 @@ func (s *adminService) LoginAdmin(email string, password string) (string, error)
 
 	// Dekripsi nomor telepon jika perlu
 	admin.Phone, _ = s.encryptTool.Decrypt(admin.Phone)
-	return "Login successful", nil
+	return issueSession(s.tokenUseCase, s.adminRepository.UpdateAdminJwtToken, admin.UserId, admin.Email, admin.Role)
 }
 
 func (s *adminService) FindAllUser(page int) ([]entity.User, error) {
@@ -0,0 +1,448 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// BatchRecord is the batch manufacturing record of an MO: what was made from
+// which formula, what went into it, who did the work and how it tested.
+type BatchRecord struct {
+	MoId           string            `json:"id_mo"`
+	ProductId      string            `json:"id_product"`
+	ProductName    string            `json:"product_name"`
+	BomId          string            `json:"id_bom"`
+	BomVersionId   string            `json:"id_bomversion"`
+	FormulaVersion int               `json:"formula_version"`
+	QtyPlanned     float64           `json:"qty_planned"`
+	QtyProduced    float64           `json:"qty_produced"`
+	YieldPercent   float64           `json:"yield_percent"`
+	Status         string            `json:"status"`
+	StartedAt      time.Time         `json:"started_at"`
+	ClosedAt       *time.Time        `json:"closed_at"`
+	Materials      []BatchMaterial   `json:"materials"`
+	Operations     []BatchOperation  `json:"operations"`
+	Productions    []BatchProduction `json:"productions"`
+	Inspections    []BatchInspection `json:"inspections"`
+	Final          bool              `json:"final"` // frozen; reprints are identical
+	Redacted       bool              `json:"redacted"`
+	GeneratedAt    time.Time         `json:"generated_at"`
+}
+
+type BatchMaterial struct {
+	MaterialId    string  `json:"id_material"`
+	ProductId     string  `json:"id_product"`
+	ComponentName string  `json:"component_name"`
+	Unit          string  `json:"unit"`
+	LotNumbers    string  `json:"lot_numbers"`
+	QtyPlanned    float64 `json:"qty_planned,omitempty"`
+	QtyActual     float64 `json:"qty_actual,omitempty"`
+}
+
+type BatchOperation struct {
+	Sequence        int        `json:"sequence"`
+	Name            string     `json:"name"`
+	WorkCenterId    string     `json:"id_workcenter"`
+	Status          string     `json:"status"`
+	PlannedMinutes  float64    `json:"planned_minutes"`
+	DurationMinutes float64    `json:"duration_minutes"`
+	Operators       []string   `json:"operators"`
+	StartedAt       *time.Time `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+}
+
+type BatchProduction struct {
+	ProducedAt time.Time `json:"produced_at"`
+	Quantity   float64   `json:"quantity"`
+	ProducedBy string    `json:"produced_by"`
+	Note       string    `json:"note"`
+}
+
+type BatchInspection struct {
+	InspectionId string            `json:"id_inspection"`
+	Quantity     float64           `json:"quantity"`
+	Result       string            `json:"result"`
+	Status       string            `json:"status"`
+	DecidedBy    string            `json:"decided_by"`
+	DecidedAt    *time.Time        `json:"decided_at"`
+	Results      []entity.QcResult `json:"results"`
+}
+
+// GetBatchRecord returns the stored record of a finished MO, freezing it on
+// first use. Records of MOs still running are built fresh and not final.
+// Material quantities are left out unless reveal is set; the stored record
+// always keeps them.
+func (s *moService) GetBatchRecord(moId string, reveal bool) (*BatchRecord, error) {
+	stored, err := s.moRepository.FindBatchRecord(moId)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil {
+		var record BatchRecord
+		if err := json.Unmarshal([]byte(stored.Record), &record); err != nil {
+			return nil, fmt.Errorf("stored batch record of %s is unreadable: %v", moId, err)
+		}
+		return record.redact(reveal), nil
+	}
+
+	mo, err := s.moRepository.FindMoByID(moId)
+	if err != nil {
+		return nil, errors.New("manufacture order not found")
+	}
+	record, err := s.buildBatchRecord(mo)
+	if err != nil {
+		return nil, err
+	}
+	if !record.Final {
+		return record.redact(reveal), nil
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.moRepository.CreateBatchRecord(&entity.MoBatchRecord{
+		MoId:      mo.MoId,
+		Record:    string(data),
+		Auditable: entity.NewAuditable(),
+	}); err != nil {
+		return nil, err
+	}
+	return record.redact(reveal), nil
+}
+
+// redact drops the material quantities, which add up to the formula, unless
+// reveal is set.
+func (record *BatchRecord) redact(reveal bool) *BatchRecord {
+	if reveal {
+		return record
+	}
+	record.Redacted = true
+	for i := range record.Materials {
+		record.Materials[i].QtyPlanned = 0
+		record.Materials[i].QtyActual = 0
+	}
+	return record
+}
+
+// buildBatchRecord gathers the record from the MO's stored production data.
+// It is final once the MO is finished and none of its output awaits QC.
+func (s *moService) buildBatchRecord(mo *entity.Mos) (*BatchRecord, error) {
+	bom, err := s.pinnedBOM(mo)
+	if err != nil {
+		return nil, err
+	}
+	planned, err := strconv.ParseFloat(mo.Qtytoproduce, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity to produce: %v", err)
+	}
+
+	record := &BatchRecord{
+		MoId:           mo.MoId,
+		ProductId:      mo.ProductId,
+		ProductName:    bom.ProductName,
+		BomId:          mo.BomId,
+		BomVersionId:   bom.VersionId,
+		FormulaVersion: bom.Version,
+		QtyPlanned:     planned,
+		QtyProduced:    mo.QtyProduced,
+		Status:         mo.Status,
+		StartedAt:      mo.CreatedAt,
+		GeneratedAt:    time.Now(),
+	}
+	if product, err := s.productRepo.FindProductByID(mo.ProductId); err == nil {
+		record.ProductName = product.Productname
+	}
+	if planned > 0 {
+		record.YieldPercent = math.Round(mo.QtyProduced/planned*10000) / 100
+	}
+
+	requirements, err := s.materialRequirements(mo)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int)
+	for _, requirement := range requirements {
+		index[requirement.componentId()] = len(record.Materials)
+		record.Materials = append(record.Materials, BatchMaterial{
+			MaterialId:    requirement.MaterialId,
+			ProductId:     requirement.ProductId,
+			ComponentName: requirement.MaterialName,
+			Unit:          requirement.Unit,
+			QtyPlanned:    roundCost(requirement.Quantity),
+		})
+	}
+	consumptions, err := s.productionRepo.GetConsumptionsByMoId(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	lots := make(map[string][]string)
+	for _, consumption := range consumptions {
+		key := consumption.MaterialId + consumption.ProductId
+		i, ok := index[key]
+		if !ok {
+			i = len(record.Materials)
+			index[key] = i
+			record.Materials = append(record.Materials, BatchMaterial{
+				MaterialId:    consumption.MaterialId,
+				ProductId:     consumption.ProductId,
+				ComponentName: consumption.ComponentName,
+				Unit:          consumption.Unit,
+			})
+		}
+		record.Materials[i].QtyActual = roundCost(record.Materials[i].QtyActual + consumption.QtyActual)
+		for _, lot := range strings.Split(consumption.LotNumber, ", ") {
+			if lot != "" && !containsString(lots[key], lot) {
+				lots[key] = append(lots[key], lot)
+			}
+		}
+	}
+	for key, i := range index {
+		record.Materials[i].LotNumbers = strings.Join(lots[key], ", ")
+	}
+
+	workOrders, err := s.workOrderRepo.GetWorkOrdersByMoId(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	for _, workOrder := range workOrders {
+		operation := BatchOperation{
+			Sequence:        workOrder.Sequence,
+			Name:            workOrder.Name,
+			WorkCenterId:    workOrder.WorkCenterId,
+			Status:          workOrder.Status,
+			PlannedMinutes:  workOrder.PlannedMinutes,
+			DurationMinutes: roundCost(workOrder.DurationMinutes),
+			StartedAt:       workOrder.StartedAt,
+			FinishedAt:      workOrder.FinishedAt,
+			Operators:       []string{},
+		}
+		sort.SliceStable(workOrder.Logs, func(i, j int) bool { return workOrder.Logs[i].StartedAt.Before(workOrder.Logs[j].StartedAt) })
+		for _, log := range workOrder.Logs {
+			if log.Operator != "" && !containsString(operation.Operators, log.Operator) {
+				operation.Operators = append(operation.Operators, log.Operator)
+			}
+		}
+		record.Operations = append(record.Operations, operation)
+	}
+
+	productions, err := s.productionRepo.GetProductionsByMoId(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	for _, production := range productions {
+		record.Productions = append(record.Productions, BatchProduction{
+			ProducedAt: production.CreatedAt,
+			Quantity:   production.Quantity,
+			ProducedBy: production.ProducedBy,
+			Note:       production.Note,
+		})
+	}
+
+	inspections, err := s.qualityService.FindMoInspections(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	awaitingQc := false
+	for _, inspection := range inspections {
+		if inspection.Status == "pending" || inspection.Status == "quarantined" {
+			awaitingQc = true
+		}
+		record.Inspections = append(record.Inspections, BatchInspection{
+			InspectionId: inspection.InspectionId,
+			Quantity:     inspection.Quantity,
+			Result:       inspection.Result,
+			Status:       inspection.Status,
+			DecidedBy:    inspection.DecidedBy,
+			DecidedAt:    inspection.DecidedAt,
+			Results:      inspection.Results,
+		})
+	}
+
+	if mo.Status == "done" || mo.Status == "cancelled" || mo.Status == "unbuilt" {
+		closedAt := mo.UpdatedAt
+		record.ClosedAt = &closedAt
+		record.Final = !awaitingQc
+	}
+	return record, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// formatRecordTime prints an optional timestamp of the batch record.
+func formatRecordTime(at *time.Time) string {
+	if at == nil {
+		return "-"
+	}
+	return at.Format("2006-01-02 15:04")
+}
+
+// renderBatchRecord lays out the batch record. The document dates are set
+// to the record's generation time so a frozen record prints byte for byte
+// the same.
+func renderBatchRecord(record *BatchRecord) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCreationDate(record.GeneratedAt)
+	pdf.SetModificationDate(record.GeneratedAt)
+	pdf.SetMargins(15, 20, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Arial", "I", 8)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Batch record %s - generated %s - page %d", record.MoId,
+			record.GeneratedAt.Format("2006-01-02 15:04"), pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 16)
+	pdf.CellFormat(0, 12, "Batch Manufacturing Record", "0", 1, "C", false, 0, "")
+	if !record.Final {
+		pdf.SetFont("Arial", "I", 10)
+		pdf.SetTextColor(200, 0, 0)
+		pdf.CellFormat(0, 6, "PROVISIONAL - batch not closed or QC not decided", "0", 1, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	}
+	pdf.Ln(4)
+
+	field := func(label, value string) {
+		pdf.SetFont("Arial", "B", 10)
+		pdf.Cell(45, 6, label)
+		pdf.SetFont("Arial", "", 10)
+		pdf.Cell(0, 6, value)
+		pdf.Ln(6)
+	}
+	field("Batch (MO) ID:", record.MoId)
+	field("Product:", fmt.Sprintf("%s - %s", record.ProductId, record.ProductName))
+	field("Formula:", fmt.Sprintf("%s version %d (%s)", record.BomId, record.FormulaVersion, record.BomVersionId))
+	field("Planned quantity:", strconv.FormatFloat(record.QtyPlanned, 'f', -1, 64))
+	field("Produced quantity:", strconv.FormatFloat(record.QtyProduced, 'f', -1, 64))
+	field("Yield:", fmt.Sprintf("%.2f%%", record.YieldPercent))
+	field("Status:", record.Status)
+	field("Opened:", formatRecordTime(&record.StartedAt))
+	field("Closed:", formatRecordTime(record.ClosedAt))
+	pdf.Ln(4)
+
+	table := func(title string, widths []float64, header []string, rows [][]string) {
+		pdf.SetFont("Arial", "B", 12)
+		pdf.Cell(0, 8, title)
+		pdf.Ln(8)
+		pdf.SetFont("Arial", "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		for i, heading := range header {
+			pdf.CellFormat(widths[i], 7, heading, "1", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Arial", "", 9)
+		if len(rows) == 0 {
+			pdf.CellFormat(sumWidths(widths), 7, "None recorded", "1", 1, "L", false, 0, "")
+		}
+		for _, row := range rows {
+			for i, cell := range row {
+				pdf.CellFormat(widths[i], 7, cell, "1", 0, "L", false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+		pdf.Ln(4)
+	}
+
+	var rows [][]string
+	for _, material := range record.Materials {
+		planned, actual, difference := "-", "-", "-"
+		if !record.Redacted {
+			planned = strconv.FormatFloat(material.QtyPlanned, 'f', -1, 64)
+			actual = strconv.FormatFloat(material.QtyActual, 'f', -1, 64)
+			difference = strconv.FormatFloat(roundCost(material.QtyActual-material.QtyPlanned), 'f', -1, 64)
+		}
+		rows = append(rows, []string{material.ComponentName, material.LotNumbers, material.Unit, planned, actual, difference})
+	}
+	table("Materials", []float64{50, 40, 15, 25, 25, 25}, []string{"Component", "Lot", "Unit", "Planned", "Actual", "Difference"}, rows)
+
+	rows = nil
+	for _, operation := range record.Operations {
+		rows = append(rows, []string{
+			strconv.Itoa(operation.Sequence),
+			operation.Name,
+			strings.Join(operation.Operators, ", "),
+			formatRecordTime(operation.StartedAt),
+			formatRecordTime(operation.FinishedAt),
+			fmt.Sprintf("%g / %g", operation.DurationMinutes, operation.PlannedMinutes),
+		})
+	}
+	table("Operations", []float64{10, 40, 40, 30, 30, 30}, []string{"#", "Operation", "Operators", "Started", "Finished", "Min (act/plan)"}, rows)
+
+	rows = nil
+	for _, production := range record.Productions {
+		rows = append(rows, []string{
+			formatRecordTime(&production.ProducedAt),
+			strconv.FormatFloat(production.Quantity, 'f', -1, 64),
+			production.ProducedBy,
+			production.Note,
+		})
+	}
+	table("Production", []float64{35, 25, 45, 75}, []string{"Date", "Quantity", "Produced by", "Note"}, rows)
+
+	rows = nil
+	for _, inspection := range record.Inspections {
+		decision := fmt.Sprintf("%s by %s on %s", inspection.Status, inspection.DecidedBy, formatRecordTime(inspection.DecidedAt))
+		if len(inspection.Results) == 0 {
+			rows = append(rows, []string{inspection.InspectionId, "-", "-", "-", decision})
+		}
+		for _, result := range inspection.Results {
+			value := "fail"
+			if result.Passed {
+				value = "pass"
+			}
+			if result.MeasuredValue != nil {
+				value = fmt.Sprintf("%g %s (%s)", *result.MeasuredValue, result.Unit, value)
+			}
+			rows = append(rows, []string{inspection.InspectionId, result.CheckName, value, result.RecordedBy, decision})
+		}
+	}
+	table("Quality control", []float64{25, 40, 35, 30, 50}, []string{"Inspection", "Check", "Result", "Recorded by", "Decision"}, rows)
+
+	if pdf.GetY() > 230 {
+		pdf.AddPage()
+	}
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(0, 8, "Signatures")
+	pdf.Ln(10)
+	pdf.SetFont("Arial", "", 9)
+	for _, role := range []string{"Produced by", "Checked by (QC)", "Released by (QA)"} {
+		pdf.Cell(40, 8, role)
+		pdf.Cell(60, 8, "Name: ____________________")
+		pdf.Cell(45, 8, "Signature: ____________")
+		pdf.Cell(0, 8, "Date: __________")
+		pdf.Ln(12)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func sumWidths(widths []float64) float64 {
+	total := 0.0
+	for _, width := range widths {
+		total += width
+	}
+	return total
+}
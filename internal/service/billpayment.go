@@ -0,0 +1,276 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// paymentTolerance absorbs rounding when comparing money amounts.
+const paymentTolerance = 0.005
+
+func billOutstanding(bill entity.Billrfq) float64 {
+	return math.Max(roundCost(bill.Total-bill.AmountPaid), 0)
+}
+
+// billDueBy is how much of a bill has fallen due before the given day: the
+// upfront part from the bill date, the rest from the due date.
+func billDueBy(bill entity.Billrfq, day time.Time) float64 {
+	due := 0.0
+	if bill.UpfrontDueDate != nil && bill.UpfrontDueDate.Before(day) {
+		due += bill.UpfrontAmount
+	}
+	if bill.DueDate != nil && bill.DueDate.Before(day) {
+		due += bill.Total - bill.UpfrontAmount
+	}
+	return roundCost(due)
+}
+
+func (s *billrfqService) FindAllBills(page int, status string) ([]entity.Billrfq, error) {
+	return s.billrfqRepository.FindAllBills(page, status)
+}
+
+// CancelBill voids a bill nothing has been paid on, releasing the quantities
+// it charged for to other bills.
+func (s *billrfqService) CancelBill(billId string) (*entity.Billrfq, error) {
+	bill, err := s.GetBill(billId)
+	if err != nil {
+		return nil, err
+	}
+	if !billEditable(bill.Status) && bill.Status != "Posted" {
+		return nil, fmt.Errorf("bill is %s and can no longer be cancelled", bill.Status)
+	}
+	if len(bill.Payments) > 0 {
+		return nil, errors.New("payments have been made on this bill")
+	}
+
+	bill.Status = "Cancelled"
+	bill.UpdatedAt = time.Now()
+	return s.billrfqRepository.UpdateBill(bill)
+}
+
+// RegisterPayment books a full or partial payment against a posted bill.
+func (s *billrfqService) RegisterPayment(billId string, amount float64, paymentDate, method, reference string) (*entity.Billrfq, error) {
+	bill, err := s.GetBill(billId)
+	if err != nil {
+		return nil, err
+	}
+	if bill.Status != "Posted" && bill.Status != "Partially Paid" {
+		return nil, fmt.Errorf("bill is %s, payments can only be made on a posted bill", bill.Status)
+	}
+	if amount <= 0 {
+		return nil, errors.New("payment amount must be greater than zero")
+	}
+	outstanding := billOutstanding(*bill)
+	if amount > outstanding+paymentTolerance {
+		return nil, fmt.Errorf("payment of %.2f exceeds the %.2f still open on the bill", amount, outstanding)
+	}
+
+	paidOn := time.Now()
+	if paymentDate != "" {
+		parsed, ok := parseDocumentDate(paymentDate)
+		if !ok {
+			return nil, fmt.Errorf("invalid payment date %q", paymentDate)
+		}
+		paidOn = parsed
+	}
+
+	return s.settle(bill, amount, paidOn, method, reference, "")
+}
+
+// ApplyCredit settles part of a posted bill with a vendor credit note. The
+// credit is booked as a payment so the bill's balance and status follow the
+// usual rules.
+func (s *billrfqService) ApplyCredit(billId string, amount float64, creditNoteId string) (*entity.Billrfq, error) {
+	bill, err := s.GetBill(billId)
+	if err != nil {
+		return nil, err
+	}
+	if bill.Status != "Posted" && bill.Status != "Partially Paid" {
+		return nil, fmt.Errorf("bill is %s, credit can only be applied to a posted bill", bill.Status)
+	}
+	if amount <= 0 {
+		return nil, errors.New("credit amount must be greater than zero")
+	}
+	outstanding := billOutstanding(*bill)
+	if amount > outstanding+paymentTolerance {
+		return nil, fmt.Errorf("credit of %.2f exceeds the %.2f still open on the bill", amount, outstanding)
+	}
+	return s.settle(bill, amount, time.Now(), "credit note", creditNoteId, creditNoteId)
+}
+
+// settle books a payment on the bill and moves it to partially paid or paid.
+func (s *billrfqService) settle(bill *entity.Billrfq, amount float64, paidOn time.Time, method, reference, creditNoteId string) (*entity.Billrfq, error) {
+	lastId, err := s.billrfqRepository.GetLastPaymentId()
+	if err != nil {
+		return nil, err
+	}
+	payment := entity.NewBillPayment(lastId, bill.BillrfqId, roundCost(amount), paidOn, method, reference)
+	payment.CreditNoteId = creditNoteId
+	if _, err := s.billrfqRepository.CreatePayment(payment); err != nil {
+		return nil, err
+	}
+
+	bill.Payments = append(bill.Payments, *payment)
+	bill.AmountPaid = roundCost(bill.AmountPaid + payment.Amount)
+	bill.Status = "Partially Paid"
+	if billOutstanding(*bill) < paymentTolerance {
+		bill.Status = "Paid"
+	}
+	bill.UpdatedAt = time.Now()
+	return s.billrfqRepository.UpdateBill(bill)
+}
+
+// GetOpenPayables lists what is still owed to vendors, oldest due date first,
+// with the part that is already overdue.
+func (s *billrfqService) GetOpenPayables(vendorId string, overdueOnly bool) (map[string]interface{}, error) {
+	bills, err := s.billrfqRepository.FindOpenBills(vendorId)
+	if err != nil {
+		return nil, err
+	}
+
+	today := startOfDay(time.Now())
+	payables := make([]map[string]interface{}, 0, len(bills))
+	totalOpen, totalOverdue := 0.0, 0.0
+	for _, bill := range bills {
+		outstanding := billOutstanding(bill)
+		overdue := math.Max(roundCost(billDueBy(bill, today)-bill.AmountPaid), 0)
+		if overdueOnly && overdue < paymentTolerance {
+			continue
+		}
+
+		daysOverdue := 0
+		if overdue >= paymentTolerance {
+			since := bill.DueDate
+			if bill.AmountPaid < bill.UpfrontAmount && bill.UpfrontDueDate != nil {
+				since = bill.UpfrontDueDate
+			}
+			if since != nil {
+				daysOverdue = int(today.Sub(startOfDay(*since)).Hours() / 24)
+			}
+		}
+
+		totalOpen += outstanding
+		totalOverdue += overdue
+		payables = append(payables, map[string]interface{}{
+			"id_bill":          bill.BillrfqId,
+			"id_vendor":        bill.VendorId,
+			"bill_date":        bill.Bill_date,
+			"status":           bill.Status,
+			"upfront_due_date": bill.UpfrontDueDate,
+			"due_date":         bill.DueDate,
+			"total":            bill.Total,
+			"amount_paid":      bill.AmountPaid,
+			"outstanding":      outstanding,
+			"overdue":          overdue,
+			"days_overdue":     daysOverdue,
+		})
+	}
+
+	return map[string]interface{}{
+		"as_of":         today.Format("2006-01-02"),
+		"bills":         payables,
+		"total_open":    roundCost(totalOpen),
+		"total_overdue": roundCost(totalOverdue),
+	}, nil
+}
+
+func formatBillDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
+
+func (s *billrfqService) CreateBillPDF(billId string) ([]byte, error) {
+	bill, err := s.GetBill(billId)
+	if err != nil {
+		return nil, err
+	}
+	vendorName := bill.VendorId
+	if vendor, err := s.vendorRepository.FindVendorByID(bill.VendorId); err == nil {
+		vendorName = fmt.Sprintf("%s (%s)", vendor.Vendorname, vendor.VendorId)
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 20, 15)
+	pdf.AddPage()
+
+	pdf.SetFillColor(0, 102, 204)
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Arial", "B", 20)
+	pdf.CellFormat(0, 15, "Vendor Bill | Bill ID: "+bill.BillrfqId, "0", 1, "C", true, 0, "")
+	pdf.Ln(10)
+
+	pdf.SetTextColor(0, 0, 0)
+	details := [][2]string{
+		{"Vendor:", vendorName},
+		{"Bill Date:", bill.Bill_date},
+		{"Status:", bill.Status},
+		{"Payment:", bill.Payment},
+		{"Upfront Due:", fmt.Sprintf("%.2f on %s", bill.UpfrontAmount, formatBillDate(bill.UpfrontDueDate))},
+		{"Due Date:", formatBillDate(bill.DueDate)},
+	}
+	for _, detail := range details {
+		pdf.SetFont("Arial", "B", 12)
+		pdf.Cell(40, 10, detail[0])
+		pdf.SetFont("Arial", "", 12)
+		pdf.Cell(0, 10, detail[1])
+		pdf.Ln(6)
+	}
+	pdf.Ln(6)
+
+	pdf.SetFont("Arial", "B", 11)
+	pdf.CellFormat(25, 8, "RFQ", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(65, 8, "Product", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(25, 8, "Quantity", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(30, 8, "Unit Price", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(35, 8, "Amount", "1", 1, "R", false, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	untaxed, tax := 0.0, 0.0
+	for _, line := range bill.Lines {
+		untaxed += line.Subtotal
+		tax += line.TaxAmount
+		pdf.CellFormat(25, 8, line.RfqId, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(65, 8, line.ProductName, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(25, 8, fmt.Sprintf("%g", line.Quantity), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(30, 8, fmt.Sprintf("%.2f", line.UnitPrice), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(35, 8, fmt.Sprintf("%.2f", line.Subtotal), "1", 1, "R", false, 0, "")
+	}
+
+	pdf.SetFont("Arial", "B", 11)
+	for _, total := range [][2]string{
+		{"Untaxed", fmt.Sprintf("%.2f", roundCost(untaxed))},
+		{"Tax", fmt.Sprintf("%.2f", roundCost(tax))},
+		{"Total", fmt.Sprintf("%.2f", bill.Total)},
+		{"Paid", fmt.Sprintf("%.2f", bill.AmountPaid)},
+		{"Outstanding", fmt.Sprintf("%.2f", billOutstanding(*bill))},
+	} {
+		pdf.CellFormat(145, 8, total[0], "1", 0, "R", false, 0, "")
+		pdf.CellFormat(35, 8, total[1], "1", 1, "R", false, 0, "")
+	}
+
+	if len(bill.Payments) > 0 {
+		pdf.Ln(6)
+		pdf.SetFont("Arial", "B", 12)
+		pdf.Cell(0, 10, "Payments:")
+		pdf.Ln(8)
+		pdf.SetFont("Arial", "", 11)
+		for _, payment := range bill.Payments {
+			pdf.Cell(0, 8, fmt.Sprintf("%s  %s  %.2f  %s %s", payment.PaymentId, payment.PaymentDate.Format("2006-01-02"),
+				payment.Amount, payment.Method, payment.Reference))
+			pdf.Ln(6)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -1,25 +1,78 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
 )
 
 type BillrfqService interface {
 	CreateBill(mo *entity.Billrfq) (*entity.Billrfq, error)
+	GetBill(billId string) (*entity.Billrfq, error)
+	ReplaceBillLines(billId string, lines []entity.BillLine) (*entity.Billrfq, error)
+	MatchBill(billId string) (*entity.Billrfq, error)
+	ResolveBillLine(billId, lineId, resolvedBy, note string) (*entity.Billrfq, error)
+	PostBill(billId string) (*entity.Billrfq, error)
+	CancelBill(billId string) (*entity.Billrfq, error)
+	FindAllBills(page int, status string) ([]entity.Billrfq, error)
+	RegisterPayment(billId string, amount float64, paymentDate, method, reference string) (*entity.Billrfq, error)
+	ApplyCredit(billId string, amount float64, creditNoteId string) (*entity.Billrfq, error)
+	GetOpenPayables(vendorId string, overdueOnly bool) (map[string]interface{}, error)
+	CreateBillPDF(billId string) ([]byte, error)
+	FindAllTolerances() ([]entity.BillMatchTolerance, error)
+	SetTolerance(vendorId string, qtyPercent, pricePercent float64) (*entity.BillMatchTolerance, error)
+}
+
+// billableRfqStatuses are the RFQ states a vendor can bill against: the order
+// has been placed. Moving into one of them is ordering, see isOrdering.
+var billableRfqStatuses = map[string]bool{
+	"Purchase Order":     true,
+	"Partially Received": true,
+	"Received":           true,
+	"Billed":             true,
+	"Done":               true,
+}
+
+// BillMismatchError is returned when a bill cannot be posted because some
+// of its lines do not match what was ordered and received.
+type BillMismatchError struct {
+	Lines []entity.BillLine
+}
+
+func (e *BillMismatchError) Error() string {
+	return fmt.Sprintf("%d bill line(s) do not match the order and receipts", len(e.Lines))
 }
 
 type billrfqService struct {
 	billrfqRepository repository.BillrfqRepository
+	rfqRepository     repository.RfqRepository
+	rfqProductRepo    repository.RfqProductRepository
+	vendorRepository  repository.VendorRepository
+	taxRepo           repository.TaxRepository
 }
 
-func NewBillrfqService(billrfqRepository repository.BillrfqRepository) *billrfqService {
+func NewBillrfqService(billrfqRepository repository.BillrfqRepository, rfqRepository repository.RfqRepository,
+	rfqProductRepo repository.RfqProductRepository, vendorRepository repository.VendorRepository,
+	taxRepo repository.TaxRepository) *billrfqService {
 	return &billrfqService{
 		billrfqRepository: billrfqRepository,
+		rfqRepository:     rfqRepository,
+		rfqProductRepo:    rfqProductRepo,
+		vendorRepository:  vendorRepository,
+		taxRepo:           taxRepo,
 	}
 }
 
 func (s *billrfqService) CreateBill(mo *entity.Billrfq) (*entity.Billrfq, error) {
+	if len(mo.Lines) == 0 {
+		return nil, errors.New("a bill needs at least one line")
+	}
 
 	lastId, err := s.billrfqRepository.GetLastMo()
 	if err != nil {
@@ -27,11 +80,391 @@ func (s *billrfqService) CreateBill(mo *entity.Billrfq) (*entity.Billrfq, error)
 	}
 
 	newMo := entity.NewBillrfq(lastId, mo.VendorId, mo.Bill_date, mo.Payment)
+	lines, err := s.resolveLines(newMo, mo.Lines)
+	if err != nil {
+		return nil, err
+	}
 
 	savedMo, err := s.billrfqRepository.CreateMo(newMo)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.saveLines(savedMo.BillrfqId, lines); err != nil {
+		return nil, err
+	}
+
+	return s.MatchBill(savedMo.BillrfqId)
+}
+
+func (s *billrfqService) GetBill(billId string) (*entity.Billrfq, error) {
+	bill, err := s.billrfqRepository.FindBillByID(billId)
+	if err != nil {
+		return nil, err
+	}
+	if bill == nil {
+		return nil, fmt.Errorf("bill with id %s not found", billId)
+	}
+	return bill, nil
+}
+
+// billEditable reports whether a bill's lines can still change: it is a
+// draft, or a legacy bill awaiting review.
+func billEditable(status string) bool {
+	return status == "Draft" || status == "Needs Review"
+}
+
+// ReplaceBillLines swaps the lines of a draft bill, typically to correct a
+// mismatch, and matches the bill again.
+func (s *billrfqService) ReplaceBillLines(billId string, lines []entity.BillLine) (*entity.Billrfq, error) {
+	bill, err := s.GetBill(billId)
+	if err != nil {
+		return nil, err
+	}
+	if !billEditable(bill.Status) {
+		return nil, fmt.Errorf("bill is %s, only draft bills can be changed", bill.Status)
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("a bill needs at least one line")
+	}
+
+	resolved, err := s.resolveLines(bill, lines)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.billrfqRepository.DeleteBillLines(billId); err != nil {
+		return nil, err
+	}
+	if err := s.saveLines(billId, resolved); err != nil {
+		return nil, err
+	}
+	return s.MatchBill(billId)
+}
+
+// resolveLines checks that every line points at an ordered RFQ line of the
+// bill's vendor and fills in the product from it.
+func (s *billrfqService) resolveLines(bill *entity.Billrfq, lines []entity.BillLine) ([]entity.BillLine, error) {
+	rfqs := make(map[string]*entity.Rfqs)
+	seen := make(map[string]bool)
+	resolved := make([]entity.BillLine, 0, len(lines))
+	for _, line := range lines {
+		rfq, ok := rfqs[line.RfqId]
+		if !ok {
+			found, err := s.rfqRepository.GetRfqById(line.RfqId)
+			if err != nil {
+				return nil, err
+			}
+			if found == nil {
+				return nil, fmt.Errorf("RFQ with id %s not found", line.RfqId)
+			}
+			if found.VendorId != bill.VendorId {
+				return nil, fmt.Errorf("RFQ %s was placed with another vendor", line.RfqId)
+			}
+			if !billableRfqStatuses[found.Status] {
+				return nil, fmt.Errorf("RFQ %s is %s and cannot be billed yet", line.RfqId, found.Status)
+			}
+			rfq, rfqs[line.RfqId] = found, found
+		}
+
+		var product *entity.RfqsProduct
+		for i := range rfq.Products {
+			if rfq.Products[i].RfqsProductId == line.RfqsProductId {
+				product = &rfq.Products[i]
+			}
+		}
+		if product == nil {
+			return nil, fmt.Errorf("line %s does not belong to RFQ %s", line.RfqsProductId, line.RfqId)
+		}
+		if seen[line.RfqsProductId] {
+			return nil, fmt.Errorf("line %s is billed more than once on this bill", line.RfqsProductId)
+		}
+		seen[line.RfqsProductId] = true
+		if line.Quantity <= 0 {
+			return nil, fmt.Errorf("billed quantity of %s must be greater than zero", product.ProductName)
+		}
+		if line.UnitPrice < 0 {
+			return nil, fmt.Errorf("billed price of %s cannot be negative", product.ProductName)
+		}
+
+		line.ProductId = product.ItemId()
+		line.ProductName = product.ProductName
+		resolved = append(resolved, line)
+	}
+	return resolved, nil
+}
+
+func (s *billrfqService) saveLines(billId string, lines []entity.BillLine) error {
+	for _, line := range lines {
+		lastId, err := s.billrfqRepository.GetLastBillLineId()
+		if err != nil {
+			return err
+		}
+		if _, err := s.billrfqRepository.CreateBillLine(entity.NewBillLine(lastId, billId, line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tolerance returns the vendor's own match tolerance, falling back to the
+// default and to an exact match when none is configured.
+func (s *billrfqService) tolerance(vendorId string) (entity.BillMatchTolerance, error) {
+	for _, id := range []string{vendorId, ""} {
+		tolerance, err := s.billrfqRepository.FindToleranceByVendor(id)
+		if err != nil {
+			return entity.BillMatchTolerance{}, err
+		}
+		if tolerance != nil {
+			return *tolerance, nil
+		}
+		if vendorId == "" {
+			break
+		}
+	}
+	return entity.BillMatchTolerance{}, nil
+}
+
+// MatchBill runs the three-way match: every line is compared with the
+// quantity received so far, less what other bills already charge for, and
+// with the price on the order. Lines accepted by hand stay resolved.
+func (s *billrfqService) MatchBill(billId string) (*entity.Billrfq, error) {
+	bill, err := s.GetBill(billId)
+	if err != nil {
+		return nil, err
+	}
+	tolerance, err := s.tolerance(bill.VendorId)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[string]entity.RfqsProduct)
+	bill.MatchStatus = "matched"
+	if len(bill.Lines) == 0 {
+		bill.MatchStatus = "unmatched"
+	}
+	for i := range bill.Lines {
+		line := &bill.Lines[i]
+		if _, ok := products[line.RfqsProductId]; !ok {
+			rfqLines, err := s.rfqProductRepo.GetProductsByRfqId(line.RfqId)
+			if err != nil {
+				return nil, err
+			}
+			for _, product := range rfqLines {
+				products[product.RfqsProductId] = product
+			}
+		}
+		product, ok := products[line.RfqsProductId]
+		if !ok {
+			return nil, fmt.Errorf("line %s of RFQ %s no longer exists", line.RfqsProductId, line.RfqId)
+		}
+		billedElsewhere, err := s.billrfqRepository.SumBilledQty(line.RfqsProductId, bill.BillrfqId)
+		if err != nil {
+			return nil, err
+		}
+
+		if billEditable(bill.Status) {
+			if line.TaxRate, line.TaxIncluded, err = s.orderedTax(product); err != nil {
+				return nil, err
+			}
+			priceBillLine(line)
+		}
+
+		problems := matchBillLine(*line, product, billedElsewhere, tolerance)
+		if line.MatchStatus != "resolved" {
+			line.MatchStatus = "matched"
+			if len(problems) > 0 {
+				line.MatchStatus = "mismatch"
+			}
+		}
+		line.MatchNote = strings.Join(problems, "; ")
+		line.UpdatedAt = time.Now()
+		if _, err := s.billrfqRepository.UpdateBillLine(line); err != nil {
+			return nil, err
+		}
+		if line.MatchStatus == "mismatch" {
+			bill.MatchStatus = "mismatch"
+		}
+	}
+
+	if billEditable(bill.Status) {
+		total := 0.0
+		for _, line := range bill.Lines {
+			total += line.Total
+		}
+		bill.Total = roundCost(total)
+	}
+	bill.UpdatedAt = time.Now()
+	if _, err := s.billrfqRepository.UpdateBill(bill); err != nil {
+		return nil, err
+	}
+	return bill, nil
+}
+
+// orderedTax returns the rate an RFQ line was ordered at and whether that
+// tax is included in its price.
+func (s *billrfqService) orderedTax(product entity.RfqsProduct) (float64, bool, error) {
+	rate := 0.0
+	if tax := strings.TrimSuffix(strings.TrimSpace(product.Tax), "%"); tax != "" {
+		var err error
+		if rate, err = strconv.ParseFloat(tax, 64); err != nil {
+			return 0, false, fmt.Errorf("invalid tax for %s: %v", product.ProductName, err)
+		}
+	}
+	if product.TaxId == "" {
+		return rate, false, nil
+	}
+	tax, err := s.taxRepo.FindTaxByID(product.TaxId)
+	if err != nil {
+		return 0, false, err
+	}
+	if tax == nil {
+		return 0, false, fmt.Errorf("tax with id %s does not exist", product.TaxId)
+	}
+	return rate, tax.PriceIncluded, nil
+}
+
+// priceBillLine works out the amounts of a bill line the way an RFQ line is
+// priced, from the billed quantity and unit price.
+func priceBillLine(line *entity.BillLine) {
+	_, _, subtotal, taxAmount := splitTax(line.Quantity*line.UnitPrice, line.TaxRate, line.TaxIncluded)
+	line.Subtotal = subtotal
+	line.TaxAmount = taxAmount
+	line.Total = roundCost(subtotal + taxAmount)
+}
+
+// matchBillLine lists what is wrong with a bill line, if anything.
+func matchBillLine(line entity.BillLine, product entity.RfqsProduct, billedElsewhere float64, tolerance entity.BillMatchTolerance) []string {
+	var problems []string
+
+	ordered, _ := strconv.ParseFloat(product.Quantity, 64)
+	billed := billedElsewhere + line.Quantity
+	if billed > ordered*(1+tolerance.QtyPercent/100)+receiptTolerance {
+		problems = append(problems, fmt.Sprintf("billed %g in total but only %g ordered", billed, ordered))
+	}
+	if billed > product.QtyReceived*(1+tolerance.QtyPercent/100)+receiptTolerance {
+		problems = append(problems, fmt.Sprintf("billed %g in total but only %g received", billed, product.QtyReceived))
+	}
+
+	orderedPrice, err := strconv.ParseFloat(product.UnitPrice, 64)
+	if err == nil {
+		// The vendor bills the price net of the discount agreed on the order.
+		orderedPrice *= 1 - product.Discount/100
+		deviation := math.Abs(line.UnitPrice - orderedPrice)
+		if (orderedPrice == 0 && deviation > 0) || (orderedPrice > 0 && deviation/orderedPrice*100 > tolerance.PricePercent+receiptTolerance) {
+			problems = append(problems, fmt.Sprintf("billed at %g but ordered at %g", line.UnitPrice, orderedPrice))
+		}
+	}
+	return problems
+}
+
+// ResolveBillLine accepts a mismatched line, for instance after agreeing a
+// price change with the vendor, so it no longer blocks approval.
+func (s *billrfqService) ResolveBillLine(billId, lineId, resolvedBy, note string) (*entity.Billrfq, error) {
+	bill, err := s.GetBill(billId)
+	if err != nil {
+		return nil, err
+	}
+	if !billEditable(bill.Status) {
+		return nil, fmt.Errorf("bill is %s, only draft bills can be changed", bill.Status)
+	}
+
+	var line *entity.BillLine
+	for i := range bill.Lines {
+		if bill.Lines[i].BillLineId == lineId {
+			line = &bill.Lines[i]
+		}
+	}
+	if line == nil {
+		return nil, fmt.Errorf("line %s does not belong to bill %s", lineId, billId)
+	}
+	if line.MatchStatus != "mismatch" {
+		return nil, fmt.Errorf("line %s is %s, only mismatched lines can be resolved", lineId, line.MatchStatus)
+	}
+
+	line.MatchStatus = "resolved"
+	line.ResolvedBy = resolvedBy
+	line.ResolutionNote = note
+	line.UpdatedAt = time.Now()
+	if _, err := s.billrfqRepository.UpdateBillLine(line); err != nil {
+		return nil, err
+	}
+	return s.MatchBill(billId)
+}
+
+// PostBill passes a bill for payment. The match is run again first, since
+// goods may have been received since the bill was entered. The total and due
+// dates are fixed from the vendor's payment terms at this point.
+func (s *billrfqService) PostBill(billId string) (*entity.Billrfq, error) {
+	bill, err := s.GetBill(billId)
+	if err != nil {
+		return nil, err
+	}
+	if !billEditable(bill.Status) {
+		return nil, fmt.Errorf("bill is already %s", bill.Status)
+	}
+	vendor, err := s.vendorRepository.FindVendorByID(bill.VendorId)
+	if err != nil {
+		return nil, fmt.Errorf("vendor %s not found", bill.VendorId)
+	}
+
+	bill, err = s.MatchBill(billId)
+	if err != nil {
+		return nil, err
+	}
+	if bill.MatchStatus != "matched" {
+		var mismatched []entity.BillLine
+		for _, line := range bill.Lines {
+			if line.MatchStatus == "mismatch" {
+				mismatched = append(mismatched, line)
+			}
+		}
+		return nil, &BillMismatchError{Lines: mismatched}
+	}
+
+	total := 0.0
+	for _, line := range bill.Lines {
+		total += line.Total
+	}
+	billDate, ok := parseDocumentDate(bill.Bill_date)
+	if !ok {
+		billDate = time.Now()
+	}
+	billDate = startOfDay(billDate)
+	dueDate := billDate.AddDate(0, 0, vendor.PaymentTermDays)
+
+	bill.Total = roundCost(total)
+	bill.UpfrontAmount = roundCost(total * vendor.UpfrontPercent / 100)
+	bill.UpfrontDueDate = &billDate
+	bill.DueDate = &dueDate
+	bill.Status = "Posted"
+	bill.UpdatedAt = time.Now()
+	return s.billrfqRepository.UpdateBill(bill)
+}
 
-	return savedMo, nil
+func (s *billrfqService) FindAllTolerances() ([]entity.BillMatchTolerance, error) {
+	return s.billrfqRepository.FindAllTolerances()
+}
+
+// SetTolerance sets the match tolerance of a vendor, or the default when no
+// vendor is given.
+func (s *billrfqService) SetTolerance(vendorId string, qtyPercent, pricePercent float64) (*entity.BillMatchTolerance, error) {
+	if qtyPercent < 0 || pricePercent < 0 {
+		return nil, errors.New("tolerances cannot be negative")
+	}
+
+	tolerance, err := s.billrfqRepository.FindToleranceByVendor(vendorId)
+	if err != nil {
+		return nil, err
+	}
+	if tolerance == nil {
+		lastId, err := s.billrfqRepository.GetLastToleranceId()
+		if err != nil {
+			return nil, err
+		}
+		tolerance = entity.NewBillMatchTolerance(lastId, vendorId, qtyPercent, pricePercent)
+	} else {
+		tolerance.QtyPercent = qtyPercent
+		tolerance.PricePercent = pricePercent
+		tolerance.UpdatedAt = time.Now()
+	}
+	return s.billrfqRepository.SaveTolerance(tolerance)
 }
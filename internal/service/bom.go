@@ -2,10 +2,13 @@ package service
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
 
 	"strconv"
+	"time"
 
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
@@ -21,20 +24,67 @@ type BOMService interface {
 	UpdateBOM(bom *entity.Bom) (*entity.Bom, error)
 	CheckDuplicateProductInBOM(productId string, bomId string) (bool, error)
 	CheckDuplicateMaterialInBOM(materialId string, bomId string) (bool, error)
-	GetBOMByID(bomId string) (*entity.Bom, error)
-	CalculateOverview(bomId string) (map[string]interface{}, error)
+	GetBOMByID(bomId string, reveal bool) (*entity.Bom, error)
+	CalculateOverview(bomId string, reveal bool) (map[string]interface{}, error)
 	GenerateBOMPDF(overview map[string]interface{}) ([]byte, error)
+	ValidateBOMComponents(productId string, materials []entity.BomMaterial) error
+	ExplodeBOM(bomId string, quantity float64, reveal bool) (map[string]interface{}, error)
+	GetBOMVersions(bomId string, reveal bool) ([]entity.BomVersion, error)
+	DiffBOMVersions(bomId string, from, to int, reveal bool) (map[string]interface{}, error)
+	SimulateBOMCosts(overrides []MaterialPriceOverride) (map[string]interface{}, error)
+	CheckCompliance(bomId string, reveal bool) (map[string]interface{}, error)
 }
 
+// ErrBOMCycle is returned when a sub-assembly ends up containing its own parent.
+var ErrBOMCycle = errors.New("BOM cycle detected")
+
+// ErrBOMEffectiveDate is returned when a new version would start before the
+// version it replaces.
+var ErrBOMEffectiveDate = errors.New("effective_from must not be before the current version")
+
 type bomService struct {
 	bomRepo         repository.BOMRepository
 	bomMaterialRepo repository.BOMMaterialRepository
+	bomVersionRepo  repository.BOMVersionRepository
+	complianceRepo  repository.ComplianceRepository
 }
 
-func NewBOMService(bomRepo repository.BOMRepository, bomMaterialRepo repository.BOMMaterialRepository) BOMService {
+func NewBOMService(bomRepo repository.BOMRepository, bomMaterialRepo repository.BOMMaterialRepository,
+	bomVersionRepo repository.BOMVersionRepository, complianceRepo repository.ComplianceRepository) BOMService {
 	return &bomService{
 		bomRepo:         bomRepo,
 		bomMaterialRepo: bomMaterialRepo,
+		bomVersionRepo:  bomVersionRepo,
+		complianceRepo:  complianceRepo,
+	}
+}
+
+// perfumerRole is the user role allowed to see formula quantities.
+const perfumerRole = "perfumer"
+
+// CanViewFormula reports whether the user is a perfumer. Requests without an
+// authenticated user get the redacted formula.
+func CanViewFormula(user *entity.User) bool {
+	return user != nil && user.Role == perfumerRole
+}
+
+// redactLines blanks the quantities of formula lines, leaving the list of
+// what goes into the product.
+func redactLines(lines []entity.BomMaterial) {
+	for i := range lines {
+		lines[i].Quantity = ""
+	}
+}
+
+// redactTree removes the quantities from an exploded BOM tree.
+func redactTree(node map[string]interface{}) {
+	delete(node, "quantity")
+	components, _ := node["components"].([]map[string]interface{})
+	for _, component := range components {
+		delete(component, "quantity")
+		if child, ok := component["bom"].(map[string]interface{}); ok {
+			redactTree(child)
+		}
 	}
 }
 
@@ -57,37 +107,73 @@ func (s *bomService) CreateBOM(bom *entity.Bom) (*entity.Bom, error) {
 	}
 
 	newBom := entity.NewBom(lastId, bom.ProductId, bom.ProductName, bom.ProductPreference, bom.Quantity)
+	newBom.MacerationDays = bom.MacerationDays
+	newBom.LitresPerUnit = bom.LitresPerUnit
 
 	savedBom, err := s.bomRepo.CreateBOM(newBom)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(bom.Materials) > 0 {
-		for _, material := range bom.Materials {
+	changeNote := bom.ChangeNote
+	if changeNote == "" {
+		changeNote = "Initial version"
+	}
+	version, err := s.createVersion(savedBom.BomId, 1, bom.Quantity, bom.EffectiveFrom, changeNote, bom.Materials)
+	if err != nil {
+		return nil, err
+	}
 
-			lastMaterialId, err := s.bomMaterialRepo.GetLastMaterialId()
-			if err != nil {
-				return nil, err
-			}
+	applyVersion(savedBom, version)
+	return savedBom, nil
+}
 
-			material.IdBomMaterial = generateBOMMaterialId(lastMaterialId)
-			material.BomId = savedBom.BomId
+// createVersion stores a new immutable version of the BOM together with its
+// lines. A zero effectiveFrom means the version applies from now on.
+func (s *bomService) createVersion(bomId string, number int, quantity string, effectiveFrom time.Time, changeNote string, materials []entity.BomMaterial) (*entity.BomVersion, error) {
+	if effectiveFrom.IsZero() {
+		effectiveFrom = time.Now()
+	}
 
-			_, err = s.bomMaterialRepo.CreateMaterial(&material)
-			if err != nil {
-				return nil, err
-			}
+	lastVersionId, err := s.bomVersionRepo.GetLastBomVersionId()
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.bomVersionRepo.CreateBOMVersion(entity.NewBomVersion(lastVersionId, bomId, number, quantity, effectiveFrom, changeNote))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, material := range materials {
+		lastMaterialId, err := s.bomMaterialRepo.GetLastMaterialId()
+		if err != nil {
+			return nil, err
+		}
+
+		material.IdBomMaterial = generateBOMMaterialId(lastMaterialId)
+		material.BomId = bomId
+		material.BomVersionId = version.BomVersionId
+		material.Auditable = entity.NewAuditable()
+
+		if _, err := s.bomMaterialRepo.CreateMaterial(&material); err != nil {
+			return nil, err
 		}
 	}
 
-	materials, err := s.bomMaterialRepo.GetMaterialsByBomId(savedBom.BomId)
+	version.Materials, err = s.bomMaterialRepo.GetMaterialsByVersionId(version.BomVersionId)
 	if err != nil {
 		return nil, err
 	}
-	savedBom.Materials = materials
+	return version, nil
+}
 
-	return savedBom, nil
+func applyVersion(bom *entity.Bom, version *entity.BomVersion) {
+	bom.VersionId = version.BomVersionId
+	bom.Version = version.Version
+	bom.EffectiveFrom = version.EffectiveFrom
+	bom.ChangeNote = version.ChangeNote
+	bom.Quantity = version.Quantity
+	bom.Materials = version.Materials
 }
 
 func (s *bomService) GetCheckIDProduct(productId string) (bool, error) {
@@ -102,51 +188,184 @@ func (s *bomService) FindAllBom(page int) ([]entity.Bom, error) {
 	return s.bomRepo.FindAllBom(page)
 }
 
+// DeleteBom removes a BOM with all its versions. A BOM manufacture orders
+// were made from is kept, as their batch records point at its versions.
 func (s *bomService) DeleteBom(bomId string) (bool, error) {
+	mos, err := s.bomRepo.CountMosByBomId(bomId)
+	if err != nil {
+		return false, err
+	}
+	if mos > 0 {
+		return false, fmt.Errorf("BOM %s is used by %d manufacture order(s) and cannot be deleted", bomId, mos)
+	}
 	return s.bomRepo.DeleteBom(bomId)
 }
 
+// UpdateBOM never touches the lines of earlier versions: it closes the open
+// version and records the new formula as the next version. When no lines are
+// sent the current ones are carried over, e.g. for a change of output
+// quantity only.
 func (s *bomService) UpdateBOM(bom *entity.Bom) (*entity.Bom, error) {
-	// Update BOM in database
-	updatedBom, err := s.bomRepo.UpdateBOM(bom)
+	versions, err := s.bomVersionRepo.FindVersionsByBomId(bom.BomId)
 	if err != nil {
 		return nil, err
 	}
 
-	// Handle materials update
-	if len(bom.Materials) > 0 {
-		// First, delete the old materials and add new ones
-		if err := s.bomMaterialRepo.DeleteMaterialsByBomId(bom.BomId); err != nil {
-			return nil, err
+	effectiveFrom := bom.EffectiveFrom
+	if effectiveFrom.IsZero() {
+		effectiveFrom = time.Now()
+	}
+
+	var latest *entity.BomVersion
+	if len(versions) > 0 {
+		latest = &versions[len(versions)-1]
+		if !effectiveFrom.After(latest.EffectiveFrom) {
+			return nil, fmt.Errorf("%w (version %d starts %s)", ErrBOMEffectiveDate, latest.Version, latest.EffectiveFrom.Format("2006-01-02"))
 		}
+	}
 
-		// Insert updated materials
-		for _, material := range bom.Materials {
-			lastMaterialId, err := s.bomMaterialRepo.GetLastMaterialId()
-			if err != nil {
-				return nil, err
-			}
+	materials := bom.Materials
+	if len(materials) == 0 && latest != nil {
+		for _, material := range latest.Materials {
+			materials = append(materials, entity.BomMaterial{
+				IdMaterial:   material.IdMaterial,
+				IdProduct:    material.IdProduct,
+				MaterialName: material.MaterialName,
+				Quantity:     material.Quantity,
+				Unit:         material.Unit,
+			})
+		}
+	}
 
-			material.IdBomMaterial = generateBOMMaterialId(lastMaterialId)
-			material.BomId = bom.BomId
+	// Update BOM header in database
+	updatedBom, err := s.bomRepo.UpdateBOM(bom)
+	if err != nil {
+		return nil, err
+	}
 
-			_, err = s.bomMaterialRepo.CreateMaterial(&material)
-			if err != nil {
+	number := 1
+	if latest != nil {
+		number = latest.Version + 1
+		if latest.EffectiveTo == nil || latest.EffectiveTo.After(effectiveFrom) {
+			latest.EffectiveTo = &effectiveFrom
+			if _, err := s.bomVersionRepo.UpdateBOMVersion(latest); err != nil {
 				return nil, err
 			}
 		}
 	}
 
-	// Fetch the updated materials to return along with the updated BOM
-	materials, err := s.bomMaterialRepo.GetMaterialsByBomId(bom.BomId)
+	version, err := s.createVersion(bom.BomId, number, bom.Quantity, effectiveFrom, bom.ChangeNote, materials)
 	if err != nil {
 		return nil, err
 	}
-	updatedBom.Materials = materials
 
+	applyVersion(updatedBom, version)
 	return updatedBom, nil
 }
 
+func (s *bomService) GetBOMVersions(bomId string, reveal bool) ([]entity.BomVersion, error) {
+	bom, err := s.bomRepo.FindBOMByID(bomId)
+	if err != nil {
+		return nil, err
+	}
+	if bom == nil {
+		return nil, errors.New("BOM not found")
+	}
+	versions, err := s.bomVersionRepo.FindVersionsByBomId(bomId)
+	if err != nil {
+		return nil, err
+	}
+	if !reveal {
+		for i := range versions {
+			redactLines(versions[i].Materials)
+		}
+	}
+	return versions, nil
+}
+
+// DiffBOMVersions compares two versions of a formula line by line, keyed on
+// the material or sub-assembly each line uses.
+func (s *bomService) DiffBOMVersions(bomId string, from, to int, reveal bool) (map[string]interface{}, error) {
+	fromVersion, err := s.bomVersionRepo.FindVersionByNumber(bomId, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.bomVersionRepo.FindVersionByNumber(bomId, to)
+	if err != nil {
+		return nil, err
+	}
+	if fromVersion == nil || toVersion == nil {
+		return nil, errors.New("BOM version not found")
+	}
+
+	oldLines := make(map[string]entity.BomMaterial)
+	for _, line := range fromVersion.Materials {
+		oldLines[line.IdMaterial+line.IdProduct] = line
+	}
+
+	added := []map[string]interface{}{}
+	changed := []map[string]interface{}{}
+	for _, line := range toVersion.Materials {
+		key := line.IdMaterial + line.IdProduct
+		old, found := oldLines[key]
+		delete(oldLines, key)
+		if !found {
+			added = append(added, diffLine(line))
+			continue
+		}
+		if old.Quantity != line.Quantity || old.Unit != line.Unit {
+			change := map[string]interface{}{
+				"id_material":   line.IdMaterial,
+				"id_product":    line.IdProduct,
+				"material_name": line.MaterialName,
+				"from_quantity": old.Quantity,
+				"to_quantity":   line.Quantity,
+				"from_unit":     old.Unit,
+				"to_unit":       line.Unit,
+			}
+			if !reveal {
+				delete(change, "from_quantity")
+				delete(change, "to_quantity")
+			}
+			changed = append(changed, change)
+		}
+	}
+
+	removed := []map[string]interface{}{}
+	for _, line := range fromVersion.Materials {
+		if _, stillThere := oldLines[line.IdMaterial+line.IdProduct]; stillThere {
+			removed = append(removed, diffLine(line))
+		}
+	}
+	if !reveal {
+		for _, line := range append(added, removed...) {
+			delete(line, "quantity")
+		}
+	}
+
+	return map[string]interface{}{
+		"bom_id":        bomId,
+		"from_version":  fromVersion.Version,
+		"to_version":    toVersion.Version,
+		"from_quantity": fromVersion.Quantity,
+		"to_quantity":   toVersion.Quantity,
+		"change_note":   toVersion.ChangeNote,
+		"added":         added,
+		"removed":       removed,
+		"changed":       changed,
+	}, nil
+}
+
+func diffLine(line entity.BomMaterial) map[string]interface{} {
+	return map[string]interface{}{
+		"id_material":   line.IdMaterial,
+		"id_product":    line.IdProduct,
+		"material_name": line.MaterialName,
+		"quantity":      line.Quantity,
+		"unit":          line.Unit,
+	}
+}
+
 func (s *bomService) CheckDuplicateProductInBOM(productId string, bomId string) (bool, error) {
 	existingBom, err := s.bomRepo.FindBOMByProductIDAndBOMID(productId, bomId)
 	if err != nil {
@@ -174,15 +393,27 @@ func (s *bomService) CheckDuplicateMaterialInBOM(materialId string, bomId string
 }
 
 // Service Layer
-func (s *bomService) GetBOMByID(bomId string) (*entity.Bom, error) {
-	return s.bomRepo.FindBOMByID(bomId)
+func (s *bomService) GetBOMByID(bomId string, reveal bool) (*entity.Bom, error) {
+	bom, err := s.bomRepo.FindBOMByID(bomId)
+	if err != nil || bom == nil {
+		return bom, err
+	}
+	if !reveal {
+		redactLines(bom.Materials)
+	}
+	return bom, nil
 }
 
-func (s *bomService) CalculateOverview(bomId string) (map[string]interface{}, error) {
+// CalculateOverview costs the BOM line by line. Without reveal the lines
+// carry neither quantity nor cost, so only the total is shown.
+func (s *bomService) CalculateOverview(bomId string, reveal bool) (map[string]interface{}, error) {
 	bom, err := s.bomRepo.FindBOMByID(bomId)
 	if err != nil {
 		return nil, err
 	}
+	if bom == nil {
+		return nil, errors.New("BOM not found")
+	}
 
 	overview := make(map[string]interface{})
 	overview["bom_id"] = bom.BomId
@@ -203,17 +434,41 @@ func (s *bomService) CalculateOverview(bomId string) (map[string]interface{}, er
 	}
 
 	for _, material := range bom.Materials {
-		// Fetch material price
-		materialDetails, err := s.bomMaterialRepo.GetMaterialDetails(material.IdMaterial)
+		quantity, err := strconv.ParseFloat(material.Quantity, 64)
 		if err != nil {
 			return nil, err
 		}
 
-		// Prepare material detail for overview
-		quantity, err := strconv.ParseFloat(material.Quantity, 64)
+		// Sub-assemblies are costed by rolling up their own BOM
+		if material.IdProduct != "" {
+			unitCost, err := s.componentUnitCost(material, []string{bom.ProductId}, s.materialMakePrice)
+			if err != nil {
+				return nil, err
+			}
+			subProduct, err := s.bomRepo.GetProductDetails(material.IdProduct)
+			if err != nil {
+				return nil, err
+			}
+
+			productCost := unitCost * quantity
+			totalCost += productCost
+
+			overview["materials"] = append(overview["materials"].([]map[string]interface{}), map[string]interface{}{
+				"type":         "product",
+				"material":     material.MaterialName,
+				"quantity":     material.Quantity,
+				"product_cost": fmt.Sprintf("%.2f", productCost),
+				"bom_cost":     subProduct.Sellprice,
+			})
+			continue
+		}
+
+		// Fetch material price
+		materialDetails, err := s.bomMaterialRepo.GetMaterialDetails(material.IdMaterial)
 		if err != nil {
 			return nil, err
 		}
+
 		makePrice, err := strconv.ParseFloat(materialDetails.Makeprice, 64)
 		if err != nil {
 			return nil, err
@@ -223,6 +478,7 @@ func (s *bomService) CalculateOverview(bomId string) (map[string]interface{}, er
 		productCost := makePrice * quantity
 
 		materialDetail := map[string]interface{}{
+			"type":         "material",
 			"material":     material.MaterialName,
 			"quantity":     material.Quantity,
 			"product_cost": fmt.Sprintf("%.2f", productCost), // Calculated value
@@ -236,9 +492,75 @@ func (s *bomService) CalculateOverview(bomId string) (map[string]interface{}, er
 	}
 
 	overview["total_cost"] = fmt.Sprintf("Rp %.2f", totalCost)
+	overview["redacted"] = !reveal
+	if !reveal {
+		for _, line := range overview["materials"].([]map[string]interface{}) {
+			delete(line, "quantity")
+			delete(line, "product_cost")
+		}
+	}
+
+	compliance, err := s.formulaCompliance(bom, productDetails.Productcategory, reveal)
+	if err != nil {
+		return nil, err
+	}
+	overview["compliance"] = compliance
 	return overview, nil
 }
 
+// CheckCompliance reports the concentration of every restricted substance
+// and allergen in the finished product of the BOM. Concentrations are left
+// out unless reveal is set, as they give the formula away.
+func (s *bomService) CheckCompliance(bomId string, reveal bool) (map[string]interface{}, error) {
+	bom, err := s.bomRepo.FindBOMByID(bomId)
+	if err != nil {
+		return nil, err
+	}
+	if bom == nil {
+		return nil, errors.New("BOM not found")
+	}
+	product, err := s.bomRepo.GetProductDetails(bom.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	compliance, err := s.formulaCompliance(bom, product.Productcategory, reveal)
+	if err != nil {
+		return nil, err
+	}
+	compliance["bom_id"] = bom.BomId
+	compliance["product_name"] = bom.ProductName
+	return compliance, nil
+}
+
+// formulaCompliance explodes the BOM down to raw materials, since
+// sub-assemblies carry their constituents into the finished product.
+func (s *bomService) formulaCompliance(bom *entity.Bom, category string, reveal bool) (map[string]interface{}, error) {
+	totals := make(map[string]*explodedComponent)
+	var order []string
+	if _, err := s.explode(bom, bomOutputQty(bom), nil, totals, &order); err != nil {
+		return nil, err
+	}
+
+	leaves := make([]explodedComponent, 0, len(order))
+	var materialIds []string
+	for _, key := range order {
+		leaves = append(leaves, *totals[key])
+		if totals[key].MaterialId != "" {
+			materialIds = append(materialIds, totals[key].MaterialId)
+		}
+	}
+	constituents, err := s.complianceRepo.FindConstituentsByMaterialIds(materialIds)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := s.complianceRepo.FindLimitsByCategory(category)
+	if err != nil {
+		return nil, err
+	}
+	return assessFormula(category, leaves, constituents, limits, reveal), nil
+}
+
 func (s *bomService) GenerateBOMPDF(overview map[string]interface{}) ([]byte, error) {
 	pdf := gofpdf.New("P", "mm", "A4", "")
 	pdf.SetMargins(15, 20, 15)
@@ -287,10 +609,17 @@ func (s *bomService) GenerateBOMPDF(overview map[string]interface{}) ([]byte, er
 	// Materials Table Rows
 	materials := overview["materials"].([]map[string]interface{})
 	for _, material := range materials {
+		quantity, productCost := "confidential", "confidential"
+		if value, ok := material["quantity"].(string); ok {
+			quantity = value
+		}
+		if value, ok := material["product_cost"].(string); ok {
+			productCost = value
+		}
 		pdf.SetFont("Arial", "", 12)
 		pdf.Cell(70, 10, material["material"].(string))
-		pdf.Cell(30, 10, material["quantity"].(string))
-		pdf.Cell(50, 10, material["product_cost"].(string))
+		pdf.Cell(30, 10, quantity)
+		pdf.Cell(50, 10, productCost)
 		pdf.Cell(50, 10, material["bom_cost"].(string))
 		pdf.Ln(10)
 	}
@@ -307,6 +636,28 @@ func (s *bomService) GenerateBOMPDF(overview map[string]interface{}) ([]byte, er
 	pdf.Cell(0, 10, overview["total_cost"].(string))
 	pdf.Ln(15)
 
+	// Compliance Section
+	if compliance, ok := overview["compliance"].(map[string]interface{}); ok {
+		pdf.SetFont("Arial", "B", 12)
+		pdf.Cell(0, 10, "Compliance ("+categoryName(compliance["product_category"].(string))+")")
+		pdf.Ln(10)
+		pdf.SetFont("Arial", "", 11)
+		violations := compliance["violations"].([]map[string]interface{})
+		if len(violations) == 0 {
+			pdf.Cell(0, 8, "No restricted substance above its limit.")
+			pdf.Ln(8)
+		}
+		for _, violation := range violations {
+			pdf.MultiCell(0, 6, "Violation: "+violation["message"].(string), "", "L", false)
+		}
+		allergens := compliance["label_allergens"].([]string)
+		label := "none"
+		if len(allergens) > 0 {
+			label = strings.Join(allergens, ", ")
+		}
+		pdf.MultiCell(0, 6, "Allergens to declare on the label: "+label, "", "L", false)
+	}
+
 	// Output PDF to buffer
 	var buf bytes.Buffer
 	err := pdf.Output(&buf)
@@ -316,6 +667,361 @@ func (s *bomService) GenerateBOMPDF(overview map[string]interface{}) ([]byte, er
 	return buf.Bytes(), nil
 }
 
+// ValidateBOMComponents rejects sub-assembly lines that would make the BOM of
+// productId contain itself, either directly or somewhere further down.
+func (s *bomService) ValidateBOMComponents(productId string, materials []entity.BomMaterial) error {
+	for _, material := range materials {
+		if material.IdProduct == "" {
+			continue
+		}
+		if err := s.checkCycle(material.IdProduct, []string{productId}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *bomService) checkCycle(productId string, path []string) error {
+	if err := checkBOMPath(path, productId); err != nil {
+		return err
+	}
+	bom, err := s.bomRepo.FindBOMByProductID(productId)
+	if err != nil || bom == nil {
+		return err
+	}
+	path = append(path, productId)
+	for _, material := range bom.Materials {
+		if material.IdProduct == "" {
+			continue
+		}
+		if err := s.checkCycle(material.IdProduct, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBOMPath fails when productId already appears on the branch being walked.
+func checkBOMPath(path []string, productId string) error {
+	for _, seen := range path {
+		if seen == productId {
+			chain := append(append([]string{}, path...), productId)
+			return fmt.Errorf("%w: %s", ErrBOMCycle, strings.Join(chain, " -> "))
+		}
+	}
+	return nil
+}
+
+type explodedComponent struct {
+	Type       string  `json:"type"`
+	MaterialId string  `json:"id_material,omitempty"`
+	ProductId  string  `json:"id_product,omitempty"`
+	Name       string  `json:"name"`
+	Unit       string  `json:"unit"`
+	Quantity   float64 `json:"quantity,omitempty"` // left out when redacted
+}
+
+// ExplodeBOM expands the BOM through every sub-assembly level for the given
+// output quantity. The result holds the nested tree and the flattened list of
+// leaf components (raw materials and bought-in products) with their totals.
+func (s *bomService) ExplodeBOM(bomId string, quantity float64, reveal bool) (map[string]interface{}, error) {
+	bom, err := s.bomRepo.FindBOMByID(bomId)
+	if err != nil {
+		return nil, err
+	}
+	if bom == nil {
+		return nil, errors.New("BOM not found")
+	}
+	if quantity <= 0 {
+		quantity = bomOutputQty(bom)
+	}
+
+	totals := make(map[string]*explodedComponent)
+	var order []string
+	tree, err := s.explode(bom, quantity, nil, totals, &order)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves := make([]explodedComponent, 0, len(order))
+	for _, key := range order {
+		leaf := *totals[key]
+		if !reveal {
+			leaf.Quantity = 0
+		}
+		leaves = append(leaves, leaf)
+	}
+	if !reveal {
+		redactTree(tree)
+	}
+
+	return map[string]interface{}{
+		"bom_id":     bom.BomId,
+		"id_product": bom.ProductId,
+		"quantity":   quantity,
+		"tree":       tree,
+		"materials":  leaves,
+	}, nil
+}
+
+func (s *bomService) explode(bom *entity.Bom, quantity float64, path []string, totals map[string]*explodedComponent, order *[]string) (map[string]interface{}, error) {
+	if err := checkBOMPath(path, bom.ProductId); err != nil {
+		return nil, err
+	}
+	path = append(path, bom.ProductId)
+	factor := quantity / bomOutputQty(bom)
+
+	addLeaf := func(leaf explodedComponent) {
+		key := leaf.MaterialId + leaf.ProductId
+		if existing, ok := totals[key]; ok {
+			existing.Quantity += leaf.Quantity
+			return
+		}
+		totals[key] = &leaf
+		*order = append(*order, key)
+	}
+
+	components := []map[string]interface{}{}
+	for _, line := range bom.Materials {
+		lineQty, err := strconv.ParseFloat(line.Quantity, 64)
+		if err != nil {
+			return nil, err
+		}
+		required := lineQty * factor
+
+		if line.IdProduct == "" {
+			components = append(components, map[string]interface{}{
+				"type":          "material",
+				"id_material":   line.IdMaterial,
+				"material_name": line.MaterialName,
+				"unit":          line.Unit,
+				"quantity":      required,
+			})
+			addLeaf(explodedComponent{Type: "material", MaterialId: line.IdMaterial, Name: line.MaterialName, Unit: line.Unit, Quantity: required})
+			continue
+		}
+
+		component := map[string]interface{}{
+			"type":         "product",
+			"id_product":   line.IdProduct,
+			"product_name": line.MaterialName,
+			"unit":         line.Unit,
+			"quantity":     required,
+		}
+		subBom, err := s.bomRepo.FindBOMByProductID(line.IdProduct)
+		if err != nil {
+			return nil, err
+		}
+		if subBom != nil {
+			child, err := s.explode(subBom, required, path, totals, order)
+			if err != nil {
+				return nil, err
+			}
+			component["bom"] = child
+		} else {
+			addLeaf(explodedComponent{Type: "product", ProductId: line.IdProduct, Name: line.MaterialName, Unit: line.Unit, Quantity: required})
+		}
+		components = append(components, component)
+	}
+
+	return map[string]interface{}{
+		"id_bom":       bom.BomId,
+		"id_product":   bom.ProductId,
+		"product_name": bom.ProductName,
+		"quantity":     quantity,
+		"components":   components,
+	}, nil
+}
+
+// materialPriceFunc resolves the make price of a material. Cost roll-ups take
+// one so a simulation can substitute hypothetical prices.
+type materialPriceFunc func(materialId string) (float64, error)
+
+func (s *bomService) materialMakePrice(materialId string) (float64, error) {
+	materialDetails, err := s.bomMaterialRepo.GetMaterialDetails(materialId)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(materialDetails.Makeprice, 64)
+}
+
+// componentUnitCost prices one unit of a BOM line. Materials use their make
+// price; sub-assemblies roll up the cost of their own BOM per unit produced,
+// and products without a BOM fall back to their make price.
+func (s *bomService) componentUnitCost(line entity.BomMaterial, path []string, price materialPriceFunc) (float64, error) {
+	if line.IdProduct == "" {
+		return price(line.IdMaterial)
+	}
+
+	subBom, err := s.bomRepo.FindBOMByProductID(line.IdProduct)
+	if err != nil {
+		return 0, err
+	}
+	if subBom == nil {
+		productDetails, err := s.bomRepo.GetProductDetails(line.IdProduct)
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseFloat(productDetails.Makeprice, 64)
+	}
+
+	total, err := s.bomCost(subBom, path, price)
+	if err != nil {
+		return 0, err
+	}
+	return total / bomOutputQty(subBom), nil
+}
+
+// bomCost is the cost of one full run of the BOM across all of its levels.
+func (s *bomService) bomCost(bom *entity.Bom, path []string, price materialPriceFunc) (float64, error) {
+	if err := checkBOMPath(path, bom.ProductId); err != nil {
+		return 0, err
+	}
+	path = append(path, bom.ProductId)
+
+	total := 0.0
+	for _, line := range bom.Materials {
+		quantity, err := strconv.ParseFloat(line.Quantity, 64)
+		if err != nil {
+			return 0, err
+		}
+		unitCost, err := s.componentUnitCost(line, path, price)
+		if err != nil {
+			return 0, err
+		}
+		total += unitCost * quantity
+	}
+	return total, nil
+}
+
+// MaterialPriceOverride is a hypothetical price for one material: either an
+// absolute make price or a percentage change on the current one.
+type MaterialPriceOverride struct {
+	MaterialId    string
+	Price         *float64
+	ChangePercent *float64
+}
+
+// SimulateBOMCosts recalculates every BOM with the given material prices
+// swapped in and reports the ones whose cost is affected. Nothing is saved.
+func (s *bomService) SimulateBOMCosts(overrides []MaterialPriceOverride) (map[string]interface{}, error) {
+	simulatedPrices := make(map[string]float64)
+	overrideLines := []map[string]interface{}{}
+	for _, override := range overrides {
+		if (override.Price == nil) == (override.ChangePercent == nil) {
+			return nil, fmt.Errorf("material %s needs either a price or a change_percent", override.MaterialId)
+		}
+		materialDetails, err := s.bomMaterialRepo.GetMaterialDetails(override.MaterialId)
+		if err != nil {
+			return nil, fmt.Errorf("material with id %s does not exist", override.MaterialId)
+		}
+		currentPrice, err := strconv.ParseFloat(materialDetails.Makeprice, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid make price for material %s: %v", override.MaterialId, err)
+		}
+
+		simulatedPrice := currentPrice
+		if override.Price != nil {
+			simulatedPrice = *override.Price
+		} else {
+			simulatedPrice = currentPrice * (1 + *override.ChangePercent/100)
+		}
+		if simulatedPrice < 0 {
+			return nil, fmt.Errorf("simulated price for material %s cannot be negative", override.MaterialId)
+		}
+		simulatedPrices[override.MaterialId] = simulatedPrice
+
+		overrideLines = append(overrideLines, map[string]interface{}{
+			"id_material":     materialDetails.MaterialId,
+			"material_name":   materialDetails.Materialname,
+			"current_price":   currentPrice,
+			"simulated_price": simulatedPrice,
+		})
+	}
+
+	boms, err := s.bomRepo.FindAllActiveBoms()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []map[string]interface{}{}
+	for i := range boms {
+		bom := &boms[i]
+
+		affected := false
+		simulatedPrice := func(materialId string) (float64, error) {
+			if price, ok := simulatedPrices[materialId]; ok {
+				affected = true
+				return price, nil
+			}
+			return s.materialMakePrice(materialId)
+		}
+
+		simulatedTotal, err := s.bomCost(bom, nil, simulatedPrice)
+		if err != nil {
+			return nil, fmt.Errorf("BOM %s: %w", bom.BomId, err)
+		}
+		if !affected {
+			continue
+		}
+		currentTotal, err := s.bomCost(bom, nil, s.materialMakePrice)
+		if err != nil {
+			return nil, fmt.Errorf("BOM %s: %w", bom.BomId, err)
+		}
+
+		outputQty := bomOutputQty(bom)
+		currentUnitCost := currentTotal / outputQty
+		simulatedUnitCost := simulatedTotal / outputQty
+
+		result := map[string]interface{}{
+			"bom_id":              bom.BomId,
+			"id_product":          bom.ProductId,
+			"product_name":        bom.ProductName,
+			"version":             bom.Version,
+			"current_unit_cost":   roundCost(currentUnitCost),
+			"simulated_unit_cost": roundCost(simulatedUnitCost),
+			"delta":               roundCost(simulatedUnitCost - currentUnitCost),
+		}
+		if currentUnitCost != 0 {
+			result["delta_percent"] = roundCost((simulatedUnitCost - currentUnitCost) / currentUnitCost * 100)
+		}
+
+		// Margins are left out when the product has no usable sell price
+		product, err := s.bomRepo.GetProductDetails(bom.ProductId)
+		if err != nil {
+			return nil, err
+		}
+		if sellPrice, err := strconv.ParseFloat(product.Sellprice, 64); err == nil && sellPrice != 0 {
+			result["sell_price"] = sellPrice
+			result["current_margin"] = roundCost(sellPrice - currentUnitCost)
+			result["simulated_margin"] = roundCost(sellPrice - simulatedUnitCost)
+			result["current_margin_percent"] = roundCost((sellPrice - currentUnitCost) / sellPrice * 100)
+			result["simulated_margin_percent"] = roundCost((sellPrice - simulatedUnitCost) / sellPrice * 100)
+		}
+
+		results = append(results, result)
+	}
+
+	return map[string]interface{}{
+		"overrides": overrideLines,
+		"boms":      results,
+	}, nil
+}
+
+func roundCost(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// bomOutputQty returns how many units one run of the BOM yields. Older BOMs
+// may carry an empty or zero quantity, which is treated as a single unit.
+func bomOutputQty(bom *entity.Bom) float64 {
+	quantity, err := strconv.ParseFloat(bom.Quantity, 64)
+	if err != nil || quantity <= 0 {
+		return 1
+	}
+	return quantity
+}
+
 // Fungsi untuk parsing biaya
 func parseCost(costStr string) (float64, error) {
 	// Hapus "Rp " dan konversi menjadi float64
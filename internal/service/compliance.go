@@ -0,0 +1,342 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
+)
+
+type ComplianceService interface {
+	SetMaterialConstituents(materialId string, constituents []entity.MaterialConstituent) ([]entity.MaterialConstituent, error)
+	GetMaterialConstituents(materialId string) ([]entity.MaterialConstituent, error)
+	CreateLimit(limit *entity.ComplianceLimit) (*entity.ComplianceLimit, error)
+	UpdateLimit(limit *entity.ComplianceLimit) (*entity.ComplianceLimit, error)
+	DeleteLimit(limitId string) (bool, error)
+	FindAllLimits() ([]entity.ComplianceLimit, error)
+}
+
+// defaultLabelThreshold is the allergen declaration threshold, in percent,
+// used when no label limit is configured for a product category. It is the
+// EU level for leave-on products.
+const defaultLabelThreshold = 0.001
+
+type complianceService struct {
+	complianceRepo repository.ComplianceRepository
+	materialRepo   repository.MaterialRepository
+}
+
+func NewComplianceService(complianceRepo repository.ComplianceRepository, materialRepo repository.MaterialRepository) *complianceService {
+	return &complianceService{
+		complianceRepo: complianceRepo,
+		materialRepo:   materialRepo,
+	}
+}
+
+// SetMaterialConstituents replaces the composition of a material.
+func (s *complianceService) SetMaterialConstituents(materialId string, constituents []entity.MaterialConstituent) ([]entity.MaterialConstituent, error) {
+	if _, err := s.materialRepo.FindMaterialByID(materialId); err != nil {
+		return nil, errors.New("material not found")
+	}
+
+	total := 0.0
+	for _, constituent := range constituents {
+		if constituent.Substance == "" {
+			return nil, errors.New("substance name cannot be empty")
+		}
+		if constituent.Percentage <= 0 || constituent.Percentage > 100 {
+			return nil, fmt.Errorf("percentage of %s must be above 0 and at most 100", constituent.Substance)
+		}
+		total += constituent.Percentage
+	}
+	if total > 100+1e-9 {
+		return nil, fmt.Errorf("constituents add up to %g%%, more than the whole material", total)
+	}
+
+	if err := s.complianceRepo.DeleteConstituentsByMaterialId(materialId); err != nil {
+		return nil, err
+	}
+	for _, constituent := range constituents {
+		lastId, err := s.complianceRepo.GetLastConstituentId()
+		if err != nil {
+			return nil, err
+		}
+		constituent.MaterialId = materialId
+		if _, err := s.complianceRepo.CreateConstituent(entity.NewMaterialConstituent(lastId, constituent)); err != nil {
+			return nil, err
+		}
+	}
+	return s.complianceRepo.FindConstituentsByMaterialId(materialId)
+}
+
+func (s *complianceService) GetMaterialConstituents(materialId string) ([]entity.MaterialConstituent, error) {
+	if _, err := s.materialRepo.FindMaterialByID(materialId); err != nil {
+		return nil, errors.New("material not found")
+	}
+	return s.complianceRepo.FindConstituentsByMaterialId(materialId)
+}
+
+func validateComplianceLimit(limit *entity.ComplianceLimit) error {
+	switch limit.Type {
+	case "restriction":
+		if limit.Substance == "" && limit.CasNumber == "" {
+			return errors.New("a restriction needs a substance or CAS number")
+		}
+	case "label":
+	default:
+		return errors.New("type must be restriction or label")
+	}
+	if limit.MaxPercent <= 0 || limit.MaxPercent > 100 {
+		return errors.New("max percent must be above 0 and at most 100")
+	}
+	return nil
+}
+
+func (s *complianceService) CreateLimit(limit *entity.ComplianceLimit) (*entity.ComplianceLimit, error) {
+	if err := validateComplianceLimit(limit); err != nil {
+		return nil, err
+	}
+
+	lastId, err := s.complianceRepo.GetLastLimitId()
+	if err != nil {
+		return nil, err
+	}
+	return s.complianceRepo.CreateLimit(entity.NewComplianceLimit(lastId, *limit))
+}
+
+func (s *complianceService) UpdateLimit(limit *entity.ComplianceLimit) (*entity.ComplianceLimit, error) {
+	existing, err := s.complianceRepo.FindLimitByID(limit.ComplianceLimitId)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, errors.New("compliance limit not found")
+	}
+	if err := validateComplianceLimit(limit); err != nil {
+		return nil, err
+	}
+
+	limit.CreatedAt = existing.CreatedAt
+	limit.UpdatedAt = time.Now()
+	return s.complianceRepo.UpdateLimit(limit)
+}
+
+func (s *complianceService) DeleteLimit(limitId string) (bool, error) {
+	limit, err := s.complianceRepo.FindLimitByID(limitId)
+	if err != nil {
+		return false, err
+	}
+	if limit == nil {
+		return false, errors.New("compliance limit not found")
+	}
+	return s.complianceRepo.DeleteLimit(limit)
+}
+
+func (s *complianceService) FindAllLimits() ([]entity.ComplianceLimit, error) {
+	return s.complianceRepo.FindAllLimits()
+}
+
+// gramsPerUnit converts BOM quantities to a common weight. Volumes are
+// taken at a density of 1, close enough for alcohol-based perfume.
+var gramsPerUnit = map[string]float64{
+	"kg":    1000,
+	"g":     1,
+	"gr":    1,
+	"gram":  1,
+	"mg":    0.001,
+	"l":     1000,
+	"liter": 1000,
+	"litre": 1000,
+	"ml":    1,
+}
+
+// unitGrams returns the grams in one unit, and false for units that are not
+// a weight or volume, such as pieces.
+func unitGrams(unit string) (float64, bool) {
+	grams, ok := gramsPerUnit[strings.ToLower(strings.TrimSpace(unit))]
+	return grams, ok
+}
+
+// substanceKey identifies a substance by CAS number, or by name when the CAS
+// number is unknown.
+func substanceKey(substance, casNumber string) string {
+	if casNumber != "" {
+		return casNumber
+	}
+	return strings.ToLower(strings.TrimSpace(substance))
+}
+
+func limitApplies(limit entity.ComplianceLimit, substance, casNumber string) bool {
+	if limit.CasNumber != "" && casNumber != "" {
+		return limit.CasNumber == casNumber
+	}
+	return strings.EqualFold(strings.TrimSpace(limit.Substance), strings.TrimSpace(substance))
+}
+
+// assessFormula works out the concentration of every constituent in the
+// finished product from the exploded formula and checks it against the
+// limits of the product's category. Category-specific limits win over the
+// general ones. Components counted in pieces or other units that are not a
+// weight or volume, like bottles and caps, are not part of the formula and
+// are listed as excluded. Unless reveal is set, concentrations and the batch
+// weight are left out; statuses and the label list still come through.
+func assessFormula(category string, leaves []explodedComponent, constituents []entity.MaterialConstituent,
+	limits []entity.ComplianceLimit, reveal bool) map[string]interface{} {
+	byMaterial := make(map[string][]entity.MaterialConstituent)
+	for _, constituent := range constituents {
+		byMaterial[constituent.MaterialId] = append(byMaterial[constituent.MaterialId], constituent)
+	}
+
+	type substanceTotal struct {
+		substance, casNumber string
+		allergen             bool
+		grams                float64
+	}
+	totals := make(map[string]*substanceTotal)
+	var order []string
+	totalGrams := 0.0
+	excluded := make([]string, 0)
+	for _, leaf := range leaves {
+		perUnit, ok := unitGrams(leaf.Unit)
+		if !ok {
+			excluded = append(excluded, fmt.Sprintf("%s (%s)", leaf.Name, leaf.Unit))
+			continue
+		}
+		grams := leaf.Quantity * perUnit
+		totalGrams += grams
+		for _, constituent := range byMaterial[leaf.MaterialId] {
+			key := substanceKey(constituent.Substance, constituent.CasNumber)
+			total, ok := totals[key]
+			if !ok {
+				total = &substanceTotal{substance: constituent.Substance, casNumber: constituent.CasNumber}
+				totals[key] = total
+				order = append(order, key)
+			}
+			total.allergen = total.allergen || constituent.Allergen
+			total.grams += grams * constituent.Percentage / 100
+		}
+	}
+
+	// specific prefers a limit for the category over one for every category.
+	specific := func(current *entity.ComplianceLimit, candidate entity.ComplianceLimit) bool {
+		return current == nil || (current.ProductCategory == "" && candidate.ProductCategory != "")
+	}
+	var defaultLabel *entity.ComplianceLimit
+	for i := range limits {
+		if limits[i].Type == "label" && limits[i].Substance == "" && limits[i].CasNumber == "" && specific(defaultLabel, limits[i]) {
+			defaultLabel = &limits[i]
+		}
+	}
+
+	substances := make([]map[string]interface{}, 0, len(order))
+	violations := make([]map[string]interface{}, 0)
+	type labelEntry struct {
+		name          string
+		concentration float64
+	}
+	var labelled []labelEntry
+	for _, key := range order {
+		total := totals[key]
+		concentration := 0.0
+		if totalGrams > 0 {
+			concentration = total.grams / totalGrams * 100
+		}
+
+		var restriction, label *entity.ComplianceLimit
+		for i := range limits {
+			if !limitApplies(limits[i], total.substance, total.casNumber) {
+				continue
+			}
+			if limits[i].Type == "restriction" && specific(restriction, limits[i]) {
+				restriction = &limits[i]
+			}
+			if limits[i].Type == "label" && specific(label, limits[i]) {
+				label = &limits[i]
+			}
+		}
+
+		entry := map[string]interface{}{
+			"substance":             total.substance,
+			"cas_number":            total.casNumber,
+			"allergen":              total.allergen,
+			"concentration_percent": math.Round(concentration*1e6) / 1e6,
+			"max_percent":           nil,
+			"status":                "ok",
+		}
+		if restriction != nil {
+			entry["max_percent"] = restriction.MaxPercent
+			entry["source"] = restriction.Source
+			if concentration > restriction.MaxPercent {
+				entry["status"] = "violation"
+				violation := map[string]interface{}{
+					"substance":             total.substance,
+					"cas_number":            total.casNumber,
+					"concentration_percent": entry["concentration_percent"],
+					"max_percent":           restriction.MaxPercent,
+					"source":                restriction.Source,
+					"message": fmt.Sprintf("%s at %.4f%% exceeds the %g%% limit for %s", total.substance, concentration,
+						restriction.MaxPercent, categoryName(restriction.ProductCategory)),
+				}
+				if !reveal {
+					delete(violation, "concentration_percent")
+					violation["message"] = fmt.Sprintf("%s exceeds the %g%% limit for %s", total.substance,
+						restriction.MaxPercent, categoryName(restriction.ProductCategory))
+				}
+				violations = append(violations, violation)
+			}
+		}
+
+		if total.allergen {
+			threshold := defaultLabelThreshold
+			if label == nil {
+				label = defaultLabel
+			}
+			if label != nil {
+				threshold = label.MaxPercent
+			}
+			entry["label_threshold_percent"] = threshold
+			declare := concentration > threshold
+			entry["declare_on_label"] = declare
+			if declare {
+				labelled = append(labelled, labelEntry{name: total.substance, concentration: concentration})
+			}
+		}
+		if !reveal {
+			delete(entry, "concentration_percent")
+		}
+		substances = append(substances, entry)
+	}
+
+	sort.SliceStable(labelled, func(i, j int) bool { return labelled[i].concentration > labelled[j].concentration })
+	labelAllergens := make([]string, 0, len(labelled))
+	for _, entry := range labelled {
+		labelAllergens = append(labelAllergens, entry.name)
+	}
+
+	result := map[string]interface{}{
+		"product_category": category,
+		"total_grams":      roundCost(totalGrams),
+		"substances":       substances,
+		"violations":       violations,
+		"compliant":        len(violations) == 0,
+		"label_allergens":  labelAllergens,
+		"excluded":         excluded,
+		"redacted":         !reveal,
+	}
+	if !reveal {
+		delete(result, "total_grams")
+	}
+	return result
+}
+
+func categoryName(category string) string {
+	if category == "" {
+		return "all products"
+	}
+	return category
+}
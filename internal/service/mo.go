@@ -1,12 +1,16 @@
 package service
 
 import (
-	"bytes"
 	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
-	"github.com/jung-kurt/gofpdf"
+	"github.com/google/uuid"
 )
 
 type MoService interface {
@@ -15,18 +19,109 @@ type MoService interface {
 	FindAllMos(page int) ([]entity.Mos, error)
 	GetMoByID(MoId string) (*entity.Mos, error)
 	DeleteMo(MoId string) (bool, error)
-	GenerateMOPDF(mo *entity.Mos) ([]byte, error)
+	GenerateMOPDF(mo *entity.Mos, reveal bool) ([]byte, error)
+	GetBatchRecord(moId string, reveal bool) (*BatchRecord, error)
+	GetMoAvailability(moId string, reveal bool) (map[string]interface{}, error)
+	ShortageReport(reveal bool) ([]map[string]interface{}, error)
+	CreateShortageRfq(vendorId, orderDate string) (*entity.Rfqs, error)
+	GenerateSubAssemblyMos(moId string) ([]entity.Mos, error)
+	GetMoWorkOrders(moId string) (map[string]interface{}, error)
+	StartWorkOrder(workOrderId, operator string) (*entity.WorkOrder, error)
+	PauseWorkOrder(workOrderId string) (*entity.WorkOrder, error)
+	FinishWorkOrder(workOrderId string) (*entity.WorkOrder, error)
+	ProduceMo(moId string, quantity float64, producedBy, note string, actual []ActualConsumption) (*entity.Mos, error)
+	CloseMo(moId string, backorder bool) (map[string]interface{}, error)
+	GetMoProductions(moId string) ([]entity.MoProduction, error)
+	BookTank(moId, vesselId string, start time.Time) (*entity.Schedules, error)
+	CancelMo(moId, performedBy, reason string, reveal bool) (*entity.MoReversal, error)
+	UnbuildMo(moId string, quantity float64, performedBy, reason string, reveal bool) (*entity.MoReversal, error)
+	GetMoReversals(moId string, reveal bool) ([]entity.MoReversal, error)
+	GetGantt(from, to time.Time) (map[string]interface{}, error)
+	ScheduleMo(moId, direction string, start, requiredDate *time.Time) (map[string]interface{}, error)
+	RescheduleMo(moId string, start time.Time) (*entity.Mos, error)
+	RescheduleWorkOrder(workOrderId string, start time.Time) (*entity.WorkOrder, error)
 }
 
 type moService struct {
-	moRepository repository.MoRepository
+	moRepository    repository.MoRepository
+	reservationRepo repository.MoReservationRepository
+	bomRepo         repository.BOMRepository
+	bomVersionRepo  repository.BOMVersionRepository
+	materialRepo    repository.MaterialRepository
+	productRepo     repository.ProductRepository
+	routingRepo     repository.RoutingRepository
+	workOrderRepo   repository.WorkOrderRepository
+	workCenterRepo  repository.WorkCenterRepository
+	productionRepo  repository.MoProductionRepository
+	reversalRepo    repository.MoReversalRepository
+	schedulesRepo   repository.SchedulesRepository
+	tanks           *tankScheduler
+	qualityService  QualityService
+	rfqService      RfqService
+	rfqRepo         repository.RfqRepository
 }
 
-func NewMoService(moRepository repository.MoRepository) *moService {
+func NewMoService(moRepository repository.MoRepository, reservationRepo repository.MoReservationRepository,
+	bomRepo repository.BOMRepository, bomVersionRepo repository.BOMVersionRepository, materialRepo repository.MaterialRepository,
+	productRepo repository.ProductRepository, routingRepo repository.RoutingRepository,
+	workOrderRepo repository.WorkOrderRepository, workCenterRepo repository.WorkCenterRepository,
+	productionRepo repository.MoProductionRepository, reversalRepo repository.MoReversalRepository, vesselRepo repository.VesselRepository, schedulesRepo repository.SchedulesRepository,
+	qualityService QualityService, rfqService RfqService, rfqRepo repository.RfqRepository) *moService {
 	return &moService{
-		moRepository: moRepository,
+		moRepository:    moRepository,
+		reservationRepo: reservationRepo,
+		bomRepo:         bomRepo,
+		bomVersionRepo:  bomVersionRepo,
+		materialRepo:    materialRepo,
+		productRepo:     productRepo,
+		routingRepo:     routingRepo,
+		workOrderRepo:   workOrderRepo,
+		workCenterRepo:  workCenterRepo,
+		productionRepo:  productionRepo,
+		reversalRepo:    reversalRepo,
+		schedulesRepo:   schedulesRepo,
+		tanks:           newTankScheduler(vesselRepo, schedulesRepo),
+		qualityService:  qualityService,
+		rfqService:      rfqService,
+		rfqRepo:         rfqRepo,
 	}
 }
+
+// materialRequirement is one BOM line scaled to the MO. ProductId is set
+// instead of MaterialId when the line is a sub-assembly.
+type materialRequirement struct {
+	MaterialId   string
+	ProductId    string
+	MaterialName string
+	Unit         string
+	Quantity     float64
+}
+
+func (r materialRequirement) componentId() string {
+	return r.MaterialId + r.ProductId
+}
+
+type materialShortage struct {
+	MaterialId   string
+	MaterialName string
+	Unit         string
+	MakePrice    string
+	OnHand       float64
+	Shortage     float64
+	MoIds        []string
+}
+
+func generateReservationId(lastId string) string {
+	var newNumber int
+	if lastId == "" {
+		newNumber = 1
+	} else {
+		fmt.Sscanf(lastId, "RSV-%d", &newNumber)
+		newNumber++
+	}
+	return fmt.Sprintf("RSV-%05d", newNumber)
+}
+
 func (s *moService) CreateMo(mo *entity.Mos) (*entity.Mos, error) {
 
 	lastId, err := s.moRepository.GetLastMo()
@@ -35,6 +130,9 @@ func (s *moService) CreateMo(mo *entity.Mos) (*entity.Mos, error) {
 	}
 
 	newMo := entity.NewMos(lastId, mo.ProductId, mo.BomId, mo.Qtytoproduce)
+	if err := s.pinBomVersion(newMo); err != nil {
+		return nil, err
+	}
 
 	savedMo, err := s.moRepository.CreateMo(newMo)
 	if err != nil {
@@ -53,11 +151,49 @@ func (s *moService) UpdateMoStatus(moId string) (*entity.Mos, error) {
 
 	// Cycle through statuses
 	switch mo.Status {
-	case "draft":
-		mo.Status = "confirmed"
+	case "draft", "waiting for materials":
+		booked := false
+		if mo.Status == "draft" {
+			if booked, err = s.ensureTankBooking(mo); err != nil {
+				return nil, err
+			}
+		}
+		fullyReserved, err := s.reserveMaterials(mo)
+		if err == nil && mo.Status == "draft" {
+			err = s.generateWorkOrders(mo)
+		}
+		if err != nil {
+			if booked {
+				if releaseErr := s.schedulesRepo.DeleteTankBookingsByMoId(mo.MoId); releaseErr != nil {
+					return nil, fmt.Errorf("%w (its tank booking could not be released: %v)", err, releaseErr)
+				}
+			}
+			return nil, err
+		}
+		if fullyReserved {
+			mo.Status = "confirmed"
+		} else {
+			mo.Status = "waiting for materials"
+		}
 	case "confirmed":
 		mo.Status = "on progress"
 	case "on progress":
+		workOrders, err := s.workOrderRepo.GetWorkOrdersByMoId(mo.MoId)
+		if err != nil {
+			return nil, err
+		}
+		for _, workOrder := range workOrders {
+			if workOrder.Status != "done" && workOrder.Status != "cancelled" {
+				return nil, fmt.Errorf("work order %s (%s) is not finished yet", workOrder.WorkOrderId, workOrder.Name)
+			}
+		}
+		remaining, err := remainingQty(mo)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.produce(mo, remaining, "", "", nil); err != nil {
+			return nil, err
+		}
 		mo.Status = "done"
 	default:
 		return nil, errors.New("invalid status transition")
@@ -90,62 +226,1397 @@ func (s *moService) DeleteMo(MoId string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	if material.Status != "draft" {
+		return false, fmt.Errorf("manufacture order is %s, cancel it instead of deleting it", material.Status)
+	}
+
+	if err := s.reservationRepo.DeleteReservationsByMoId(MoId); err != nil {
+		return false, err
+	}
+	if err := s.workOrderRepo.DeleteWorkOrdersByMoId(MoId); err != nil {
+		return false, err
+	}
+	if err := s.schedulesRepo.DeleteTankBookingsByMoId(MoId); err != nil {
+		return false, err
+	}
+	if err := s.productionRepo.DeleteProductionsByMoId(MoId); err != nil {
+		return false, err
+	}
+	if err := s.productionRepo.DeleteConsumptionsByMoId(MoId); err != nil {
+		return false, err
+	}
 
 	return s.moRepository.DeleteMo(material)
 }
 
-func (s *moService) GenerateMOPDF(mo *entity.Mos) ([]byte, error) {
-	// Create a new PDF document
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.SetMargins(15, 20, 15)
-	pdf.AddPage()
+// pinBomVersion records the BOM version in force when the MO is created, so
+// later formula changes do not alter what the MO consumes.
+func (s *moService) pinBomVersion(mo *entity.Mos) error {
+	bom, err := s.bomRepo.FindBOMByID(mo.BomId)
+	if err != nil {
+		return err
+	}
+	if bom == nil {
+		return fmt.Errorf("BOM with id %s not found", mo.BomId)
+	}
+	if bom.VersionId == "" {
+		return fmt.Errorf("BOM with id %s has no version in effect", mo.BomId)
+	}
+	mo.BomVersionId = bom.VersionId
+	return nil
+}
+
+// pinnedBOM returns the BOM with the lines of the version the MO was created
+// with. MOs from before versioning fall back to the current version.
+func (s *moService) pinnedBOM(mo *entity.Mos) (*entity.Bom, error) {
+	bom, err := s.bomRepo.FindBOMByID(mo.BomId)
+	if err != nil {
+		return nil, err
+	}
+	if bom == nil {
+		return nil, fmt.Errorf("BOM with id %s not found", mo.BomId)
+	}
+	if mo.BomVersionId == "" || mo.BomVersionId == bom.VersionId {
+		return bom, nil
+	}
+
+	version, err := s.bomVersionRepo.FindVersionByID(mo.BomVersionId)
+	if err != nil {
+		return nil, err
+	}
+	if version == nil {
+		return nil, fmt.Errorf("BOM version with id %s not found", mo.BomVersionId)
+	}
+	pinned := *bom
+	pinned.VersionId = version.BomVersionId
+	pinned.Version = version.Version
+	pinned.Quantity = version.Quantity
+	pinned.Materials = version.Materials
+	return &pinned, nil
+}
+
+// materialRequirements scales the lines of the MO's BOM version to the
+// quantity the MO has to produce.
+func (s *moService) materialRequirements(mo *entity.Mos) ([]materialRequirement, error) {
+	bom, err := s.pinnedBOM(mo)
+	if err != nil {
+		return nil, err
+	}
+
+	qtyToProduce, err := strconv.ParseFloat(mo.Qtytoproduce, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity to produce: %v", err)
+	}
+	factor := qtyToProduce / bomOutputQty(bom)
+
+	var requirements []materialRequirement
+	for _, material := range bom.Materials {
+		quantity, err := strconv.ParseFloat(material.Quantity, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity for component %s: %v", material.IdMaterial+material.IdProduct, err)
+		}
+		requirements = append(requirements, materialRequirement{
+			MaterialId:   material.IdMaterial,
+			ProductId:    material.IdProduct,
+			MaterialName: material.MaterialName,
+			Unit:         material.Unit,
+			Quantity:     quantity * factor,
+		})
+	}
+	return requirements, nil
+}
+
+// availableQty is the on-hand stock of a component minus what other MOs
+// already hold. It returns the on-hand quantity alongside.
+func (s *moService) availableQty(requirement materialRequirement, moId string) (float64, float64, error) {
+	if requirement.ProductId != "" {
+		product, err := s.productRepo.FindProductByID(requirement.ProductId)
+		if err != nil {
+			return 0, 0, fmt.Errorf("product with id %s not found", requirement.ProductId)
+		}
+		reservedElsewhere, err := s.reservationRepo.SumReservedByProduct(requirement.ProductId, moId)
+		if err != nil {
+			return 0, 0, err
+		}
+		return product.Qty - reservedElsewhere, product.Qty, nil
+	}
+
+	material, err := s.materialRepo.FindMaterialByID(requirement.MaterialId)
+	if err != nil {
+		return 0, 0, fmt.Errorf("material with id %s not found", requirement.MaterialId)
+	}
+	reservedElsewhere, err := s.reservationRepo.SumReservedByMaterial(requirement.MaterialId, moId)
+	if err != nil {
+		return 0, 0, err
+	}
+	return material.Qty - reservedElsewhere, material.Qty, nil
+}
+
+// reserveMaterials holds as much of every BOM component as is available and
+// reports whether the MO is fully covered. It can be called again while the
+// MO waits for materials to top up the existing reservations.
+func (s *moService) reserveMaterials(mo *entity.Mos) (bool, error) {
+	requirements, err := s.materialRequirements(mo)
+	if err != nil {
+		return false, err
+	}
+
+	existing, err := s.reservationRepo.GetReservationsByMoId(mo.MoId)
+	if err != nil {
+		return false, err
+	}
+	reservations := make(map[string]*entity.MoReservation)
+	for i := range existing {
+		reservations[existing[i].MaterialId+existing[i].ProductId] = &existing[i]
+	}
+
+	fullyReserved := true
+	for _, requirement := range requirements {
+		reservation, found := reservations[requirement.componentId()]
+		if !found {
+			reservation = &entity.MoReservation{
+				MoId:       mo.MoId,
+				MaterialId: requirement.MaterialId,
+				ProductId:  requirement.ProductId,
+				Status:     "reserved",
+				Auditable:  entity.NewAuditable(),
+			}
+		}
+		reservation.QtyRequired = requirement.Quantity
+
+		if reservation.QtyReserved < reservation.QtyRequired {
+			available, _, err := s.availableQty(requirement, mo.MoId)
+			if err != nil {
+				return false, err
+			}
+			take := math.Min(math.Max(available-reservation.QtyReserved, 0), reservation.QtyRequired-reservation.QtyReserved)
+			reservation.QtyReserved += take
+		}
+		if reservation.QtyReserved < reservation.QtyRequired {
+			fullyReserved = false
+		}
+
+		if found {
+			if _, err := s.reservationRepo.UpdateReservation(reservation); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		lastId, err := s.reservationRepo.GetLastReservationId()
+		if err != nil {
+			return false, err
+		}
+		reservation.ReservationId = generateReservationId(lastId)
+		if _, err := s.reservationRepo.CreateReservation(reservation); err != nil {
+			return false, err
+		}
+	}
+
+	return fullyReserved, nil
+}
+
+// ActualConsumption is the quantity of a component an operator reports as
+// really used for a production step, in the component's own unit, with the
+// lot it was taken from.
+type ActualConsumption struct {
+	MaterialId string
+	ProductId  string
+	Quantity   float64
+	LotNumber  string
+}
+
+// produce records finished output on the MO. Every reservation is released
+// in proportion to the share of the planned quantity produced; the step that
+// completes the MO releases whatever is left, so rounding never strands a
+// hold. Stock is taken out at the actual quantities reported, falling back to
+// the theoretical share, and both are kept for variance reporting.
+func (s *moService) produce(mo *entity.Mos, quantity float64, producedBy, note string, actual []ActualConsumption) error {
+	planned, err := strconv.ParseFloat(mo.Qtytoproduce, 64)
+	if err != nil {
+		return fmt.Errorf("invalid quantity to produce: %v", err)
+	}
+	if planned <= 0 {
+		return errors.New("quantity to produce must be greater than zero")
+	}
+	final := mo.QtyProduced+quantity >= planned-1e-9
+
+	reported := make(map[string]float64)
+	lots := make(map[string][]string)
+	for _, consumption := range actual {
+		if consumption.Quantity < 0 {
+			return errors.New("consumed quantity cannot be negative")
+		}
+		componentId := consumption.MaterialId + consumption.ProductId
+		reported[componentId] += consumption.Quantity
+		if consumption.LotNumber != "" {
+			lots[componentId] = append(lots[componentId], consumption.LotNumber)
+		}
+	}
+
+	productionId := ""
+	if quantity > 0 {
+		lastId, err := s.productionRepo.GetLastProductionId()
+		if err != nil {
+			return err
+		}
+		production, err := s.productionRepo.CreateProduction(entity.NewMoProduction(lastId, mo.MoId, quantity, producedBy, note))
+		if err != nil {
+			return err
+		}
+		productionId = production.ProductionId
+	}
+
+	reservations, err := s.reservationRepo.GetReservationsByMoId(mo.MoId)
+	if err != nil {
+		return err
+	}
+	for _, reservation := range reservations {
+		if reservation.Status != "reserved" {
+			continue
+		}
+		remaining := reservation.QtyReserved - reservation.QtyConsumed
+		share := reservation.QtyReserved * quantity / planned
+		if final || share > remaining {
+			share = remaining
+		}
+
+		used := share
+		componentId := reservation.MaterialId + reservation.ProductId
+		if qty, ok := reported[componentId]; ok {
+			used = qty
+			delete(reported, componentId)
+		}
+		lotNumber := strings.Join(lots[componentId], ", ")
+		if err := s.consume(mo.MoId, productionId, reservation.MaterialId, reservation.ProductId, lotNumber, share, used); err != nil {
+			return err
+		}
+
+		reservation.QtyConsumed += share
+		if final {
+			reservation.Status = "consumed"
+		}
+		if _, err := s.reservationRepo.UpdateReservation(&reservation); err != nil {
+			return err
+		}
+	}
+
+	// Components used on top of the BOM, e.g. a top-up of alcohol.
+	for _, consumption := range actual {
+		componentId := consumption.MaterialId + consumption.ProductId
+		used, ok := reported[componentId]
+		if !ok {
+			continue
+		}
+		delete(reported, componentId)
+		lotNumber := strings.Join(lots[componentId], ", ")
+		if err := s.consume(mo.MoId, productionId, consumption.MaterialId, consumption.ProductId, lotNumber, 0, used); err != nil {
+			return err
+		}
+	}
+
+	if quantity > 0 {
+		if err := s.qualityService.ReceiveProduction(mo, quantity); err != nil {
+			return err
+		}
+	}
+	mo.QtyProduced += quantity
+	return nil
+}
+
+// consume takes the used quantity of a component out of stock and records it
+// next to the theoretical quantity at the component's current cost price.
+func (s *moService) consume(moId, productionId, materialId, productId, lotNumber string, theoretical, used float64) error {
+	consumption := entity.MoConsumption{
+		MoId:           moId,
+		ProductionId:   productionId,
+		MaterialId:     materialId,
+		ProductId:      productId,
+		LotNumber:      lotNumber,
+		QtyTheoretical: theoretical,
+		QtyActual:      used,
+	}
+
+	if productId != "" {
+		product, err := s.productRepo.FindProductByID(productId)
+		if err != nil {
+			return fmt.Errorf("product with id %s not found", productId)
+		}
+		consumption.ComponentName = product.Productname
+		consumption.UnitCost, _ = strconv.ParseFloat(product.Makeprice, 64)
+		if err := s.productRepo.AdjustQty(productId, -used); err != nil {
+			return err
+		}
+	} else {
+		material, err := s.materialRepo.FindMaterialByID(materialId)
+		if err != nil {
+			return fmt.Errorf("material with id %s not found", materialId)
+		}
+		consumption.ComponentName = material.Materialname
+		consumption.Unit = material.Unit
+		consumption.UnitCost, _ = strconv.ParseFloat(material.Makeprice, 64)
+		if err := s.materialRepo.AdjustQty(materialId, -used); err != nil {
+			return err
+		}
+	}
+
+	if theoretical == 0 && used == 0 {
+		return nil
+	}
+	lastId, err := s.productionRepo.GetLastConsumptionId()
+	if err != nil {
+		return err
+	}
+	_, err = s.productionRepo.CreateConsumption(entity.NewMoConsumption(lastId, consumption))
+	return err
+}
+
+// remainingQty is what the MO still has to produce.
+func remainingQty(mo *entity.Mos) (float64, error) {
+	planned, err := strconv.ParseFloat(mo.Qtytoproduce, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity to produce: %v", err)
+	}
+	return math.Max(planned-mo.QtyProduced, 0), nil
+}
+
+// ProduceMo registers part of the MO's output. An MO that is still confirmed
+// is started; one whose full quantity is produced and whose work orders are
+// finished is marked done.
+func (s *moService) ProduceMo(moId string, quantity float64, producedBy, note string, actual []ActualConsumption) (*entity.Mos, error) {
+	mo, err := s.moRepository.FindMoByID(moId)
+	if err != nil {
+		return nil, errors.New("manufacture order not found")
+	}
+	if mo.Status != "confirmed" && mo.Status != "on progress" {
+		return nil, fmt.Errorf("manufacture order is %s, production can only be recorded once it is confirmed", mo.Status)
+	}
+	if quantity <= 0 {
+		return nil, errors.New("produced quantity must be greater than zero")
+	}
+	remaining, err := remainingQty(mo)
+	if err != nil {
+		return nil, err
+	}
+	if quantity > remaining+1e-9 {
+		return nil, fmt.Errorf("only %g left to produce on this manufacture order", remaining)
+	}
+
+	if err := s.produce(mo, quantity, producedBy, note, actual); err != nil {
+		return nil, err
+	}
+	mo.Status = "on progress"
+	if quantity >= remaining-1e-9 {
+		finished, err := s.workOrdersFinished(mo.MoId)
+		if err != nil {
+			return nil, err
+		}
+		if finished {
+			mo.Status = "done"
+		}
+	}
+
+	updatedMo, err := s.moRepository.UpdateMoStatus(mo)
+	if err != nil {
+		return nil, errors.New("failed to update manufacture order status")
+	}
+	return updatedMo, nil
+}
+
+func (s *moService) workOrdersFinished(moId string) (bool, error) {
+	workOrders, err := s.workOrderRepo.GetWorkOrdersByMoId(moId)
+	if err != nil {
+		return false, err
+	}
+	for _, workOrder := range workOrders {
+		if workOrder.Status != "done" && workOrder.Status != "cancelled" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
 
-	// Set Header
-	pdf.SetFont("Arial", "B", 16)
-	pdf.CellFormat(0, 12, "Manufacturing Order (MO)", "0", 1, "C", false, 0, "")
-	pdf.Ln(10)
+// CloseMo finishes an MO with less than planned. Unconsumed reservations go
+// back to stock, open work orders are cancelled, and with backorder set a new
+// draft MO is created for the remainder on the same formula version.
+func (s *moService) CloseMo(moId string, backorder bool) (map[string]interface{}, error) {
+	mo, err := s.moRepository.FindMoByID(moId)
+	if err != nil {
+		return nil, errors.New("manufacture order not found")
+	}
+	if mo.Status != "on progress" {
+		return nil, fmt.Errorf("manufacture order is %s, only an MO in progress can be closed", mo.Status)
+	}
+	if mo.QtyProduced <= 0 {
+		return nil, errors.New("nothing has been produced on this manufacture order yet")
+	}
+	remaining, err := remainingQty(mo)
+	if err != nil {
+		return nil, err
+	}
 
-	// Reset font to normal
-	pdf.SetFont("Arial", "", 12)
+	reservations, err := s.reservationRepo.GetReservationsByMoId(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	for _, reservation := range reservations {
+		if reservation.Status != "reserved" {
+			continue
+		}
+		reservation.Status = "released"
+		if _, err := s.reservationRepo.UpdateReservation(&reservation); err != nil {
+			return nil, err
+		}
+	}
 
-	// Add MO details
-	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(40, 10, "MO ID:")
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(0, 10, mo.MoId)
-	pdf.Ln(8)
+	workOrders, err := s.workOrderRepo.GetWorkOrdersByMoId(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range workOrders {
+		if workOrders[i].Status == "done" || workOrders[i].Status == "cancelled" {
+			continue
+		}
+		if _, err := s.stopWorkOrder(&workOrders[i], "cancelled"); err != nil {
+			return nil, err
+		}
+	}
 
-	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(40, 10, "Product ID:")
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(0, 10, mo.ProductId)
-	pdf.Ln(8)
+	mo.Status = "done"
+	updatedMo, err := s.moRepository.UpdateMoStatus(mo)
+	if err != nil {
+		return nil, errors.New("failed to update manufacture order status")
+	}
 
-	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(40, 10, "BOM ID:")
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(0, 10, mo.BomId)
-	pdf.Ln(8)
+	result := map[string]interface{}{
+		"mo":           updatedMo,
+		"qty_short":    remaining,
+		"id_backorder": "",
+	}
+	if backorder && remaining > 0 {
+		lastId, err := s.moRepository.GetLastMo()
+		if err != nil {
+			return nil, err
+		}
+		backorderMo := entity.NewMos(lastId, mo.ProductId, mo.BomId, strconv.FormatFloat(remaining, 'f', -1, 64))
+		backorderMo.BomVersionId = mo.BomVersionId
+		backorderMo.BackorderOf = mo.MoId
+		savedMo, err := s.moRepository.CreateMo(backorderMo)
+		if err != nil {
+			return nil, err
+		}
+		result["id_backorder"] = savedMo.MoId
+		result["backorder"] = savedMo
+	}
+	return result, nil
+}
+
+func (s *moService) GetMoProductions(moId string) ([]entity.MoProduction, error) {
+	if _, err := s.moRepository.FindMoByID(moId); err != nil {
+		return nil, errors.New("manufacture order not found")
+	}
+	return s.productionRepo.GetProductionsByMoId(moId)
+}
+
+// CancelMo stops an MO that is not done yet. Held materials are released,
+// everything consumed goes back to stock and output already produced is
+// taken out again; output still in QC is rejected. The reversal records who
+// cancelled it and why.
+func (s *moService) CancelMo(moId, performedBy, reason string, reveal bool) (*entity.MoReversal, error) {
+	mo, err := s.moRepository.FindMoByID(moId)
+	if err != nil {
+		return nil, errors.New("manufacture order not found")
+	}
+	if mo.Status != "draft" && mo.Status != "confirmed" && mo.Status != "waiting for materials" && mo.Status != "on progress" {
+		return nil, fmt.Errorf("manufacture order is %s and can no longer be cancelled", mo.Status)
+	}
+	if performedBy == "" || reason == "" {
+		return nil, errors.New("cancelling a manufacture order needs who did it and why")
+	}
 
-	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(40, 10, "Quantity to Produce:")
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(0, 10, mo.Qtytoproduce)
-	pdf.Ln(8)
+	// Output that passed QC is in stock and is taken back out, so it has to
+	// still be there.
+	if mo.QtyProduced > 0 {
+		inspections, err := s.qualityService.FindMoInspections(mo.MoId)
+		if err != nil {
+			return nil, err
+		}
+		released := mo.QtyProduced
+		for _, inspection := range inspections {
+			if inspection.Status == "pending" || inspection.Status == "quarantined" {
+				released -= inspection.Quantity
+			}
+		}
+		if released > 1e-9 {
+			product, err := s.productRepo.FindProductByID(mo.ProductId)
+			if err != nil {
+				return nil, fmt.Errorf("product with id %s not found", mo.ProductId)
+			}
+			if product.Qty < released-1e-9 {
+				return nil, fmt.Errorf("only %g of %s in stock, cannot take back the %g this manufacture order released",
+					product.Qty, product.Productname, released)
+			}
+		}
+	}
 
-	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(40, 10, "Status:")
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(0, 10, mo.Status)
-	pdf.Ln(10)
+	reservations, err := s.reservationRepo.GetReservationsByMoId(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	for _, reservation := range reservations {
+		if reservation.Status != "reserved" {
+			continue
+		}
+		reservation.Status = "released"
+		if _, err := s.reservationRepo.UpdateReservation(&reservation); err != nil {
+			return nil, err
+		}
+	}
 
-	// Output the PDF to a buffer
-	var buf bytes.Buffer
-	err := pdf.Output(&buf)
+	workOrders, err := s.workOrderRepo.GetWorkOrdersByMoId(mo.MoId)
 	if err != nil {
 		return nil, err
 	}
+	for i := range workOrders {
+		if workOrders[i].Status == "done" || workOrders[i].Status == "cancelled" {
+			continue
+		}
+		if _, err := s.stopWorkOrder(&workOrders[i], "cancelled"); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.schedulesRepo.DeleteTankBookingsByMoId(mo.MoId); err != nil {
+		return nil, err
+	}
 
-	// Return the PDF as a byte slice
-	return buf.Bytes(), nil
+	lastId, err := s.reversalRepo.GetLastReversalId()
+	if err != nil {
+		return nil, err
+	}
+	reversal, err := s.reversalRepo.CreateReversal(entity.NewMoReversal(lastId, mo.MoId, "cancel", mo.QtyProduced, reason, performedBy))
+	if err != nil {
+		return nil, err
+	}
+
+	if mo.QtyProduced > 0 {
+		rejected, err := s.qualityService.RejectMoInspections(mo.MoId, performedBy, "manufacture order cancelled: "+reason)
+		if err != nil {
+			return nil, err
+		}
+		if released := mo.QtyProduced - rejected; released > 1e-9 {
+			if err := s.reverse(reversal, "", mo.ProductId, -released); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	consumed, err := s.consumedComponents(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	for _, component := range consumed {
+		if err := s.reverse(reversal, component.MaterialId, component.ProductId, component.QtyActual); err != nil {
+			return nil, err
+		}
+	}
+
+	mo.Status = "cancelled"
+	if _, err := s.moRepository.UpdateMoStatus(mo); err != nil {
+		return nil, errors.New("failed to update manufacture order status")
+	}
+	return s.findReversal(mo.MoId, reversal.ReversalId, reveal)
+}
+
+// UnbuildMo takes finished goods of a done MO back apart. The components go
+// back to stock in proportion to what the MO actually consumed. Without a
+// quantity everything not yet unbuilt is; once all of it is the MO is
+// marked unbuilt.
+func (s *moService) UnbuildMo(moId string, quantity float64, performedBy, reason string, reveal bool) (*entity.MoReversal, error) {
+	mo, err := s.moRepository.FindMoByID(moId)
+	if err != nil {
+		return nil, errors.New("manufacture order not found")
+	}
+	if mo.Status != "done" {
+		return nil, fmt.Errorf("manufacture order is %s, only a done MO can be unbuilt", mo.Status)
+	}
+	if performedBy == "" || reason == "" {
+		return nil, errors.New("unbuilding a manufacture order needs who did it and why")
+	}
+	unbuilt, err := s.reversalRepo.SumUnbuiltQty(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	left := mo.QtyProduced - unbuilt
+	if quantity == 0 {
+		quantity = left
+	}
+	if quantity <= 0 {
+		return nil, errors.New("nothing left to unbuild on this manufacture order")
+	}
+	if quantity > left+1e-9 {
+		return nil, fmt.Errorf("only %g of this manufacture order's output is left to unbuild", left)
+	}
+
+	product, err := s.productRepo.FindProductByID(mo.ProductId)
+	if err != nil {
+		return nil, fmt.Errorf("product with id %s not found", mo.ProductId)
+	}
+	if product.Qty < quantity-1e-9 {
+		return nil, fmt.Errorf("only %g of %s in stock, cannot unbuild %g", product.Qty, product.Productname, quantity)
+	}
+
+	lastId, err := s.reversalRepo.GetLastReversalId()
+	if err != nil {
+		return nil, err
+	}
+	reversal, err := s.reversalRepo.CreateReversal(entity.NewMoReversal(lastId, mo.MoId, "unbuild", quantity, reason, performedBy))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reverse(reversal, "", mo.ProductId, -quantity); err != nil {
+		return nil, err
+	}
+
+	consumed, err := s.consumedComponents(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	for _, component := range consumed {
+		returned := roundCost(component.QtyActual * quantity / mo.QtyProduced)
+		if err := s.reverse(reversal, component.MaterialId, component.ProductId, returned); err != nil {
+			return nil, err
+		}
+	}
+
+	if quantity >= left-1e-9 {
+		mo.Status = "unbuilt"
+		if _, err := s.moRepository.UpdateMoStatus(mo); err != nil {
+			return nil, errors.New("failed to update manufacture order status")
+		}
+	}
+	return s.findReversal(mo.MoId, reversal.ReversalId, reveal)
+}
+
+// consumedComponents totals the MO's recorded consumption per component.
+func (s *moService) consumedComponents(moId string) ([]entity.MoConsumption, error) {
+	consumptions, err := s.productionRepo.GetConsumptionsByMoId(moId)
+	if err != nil {
+		return nil, err
+	}
+	var totals []entity.MoConsumption
+	index := make(map[string]int)
+	for _, consumption := range consumptions {
+		key := consumption.MaterialId + consumption.ProductId
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(totals)
+			totals = append(totals, consumption)
+			continue
+		}
+		totals[i].QtyActual += consumption.QtyActual
+	}
+	return totals, nil
+}
+
+// reverse moves quantity of a material or product back into stock (or out,
+// when negative) and records the movement on the reversal.
+func (s *moService) reverse(reversal *entity.MoReversal, materialId, productId string, quantity float64) error {
+	if quantity == 0 {
+		return nil
+	}
+	name := materialId
+	if productId != "" {
+		name = productId
+		if product, err := s.productRepo.FindProductByID(productId); err == nil {
+			name = product.Productname
+		}
+		if err := s.productRepo.AdjustQty(productId, quantity); err != nil {
+			return err
+		}
+	} else {
+		if material, err := s.materialRepo.FindMaterialByID(materialId); err == nil {
+			name = material.Materialname
+		}
+		if err := s.materialRepo.AdjustQty(materialId, quantity); err != nil {
+			return err
+		}
+	}
+
+	lastId, err := s.reversalRepo.GetLastReversalLineId()
+	if err != nil {
+		return err
+	}
+	_, err = s.reversalRepo.CreateReversalLine(entity.NewMoReversalLine(lastId, reversal.ReversalId, materialId, productId, name, quantity))
+	return err
+}
+
+func (s *moService) findReversal(moId, reversalId string, reveal bool) (*entity.MoReversal, error) {
+	reversals, err := s.reversalRepo.GetReversalsByMoId(moId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range reversals {
+		if reversals[i].ReversalId == reversalId {
+			redactReversal(&reversals[i], reveal)
+			return &reversals[i], nil
+		}
+	}
+	return nil, errors.New("reversal not found")
+}
+
+// redactReversal drops the component quantities moved by a reversal, which
+// are the formula scaled to the batch, unless reveal is set.
+func redactReversal(reversal *entity.MoReversal, reveal bool) {
+	if reveal {
+		return
+	}
+	reversal.Redacted = true
+	for i := range reversal.Lines {
+		reversal.Lines[i].Quantity = 0
+	}
+}
+
+func (s *moService) GetMoReversals(moId string, reveal bool) ([]entity.MoReversal, error) {
+	if _, err := s.moRepository.FindMoByID(moId); err != nil {
+		return nil, errors.New("manufacture order not found")
+	}
+	reversals, err := s.reversalRepo.GetReversalsByMoId(moId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range reversals {
+		redactReversal(&reversals[i], reveal)
+	}
+	return reversals, nil
+}
+
+// GetMoAvailability checks the stock of every component of the MO. Unless
+// reveal is set, the required, reserved and short quantities are left out
+// and each line only says whether it is short.
+func (s *moService) GetMoAvailability(moId string, reveal bool) (map[string]interface{}, error) {
+	mo, err := s.moRepository.FindMoByID(moId)
+	if err != nil {
+		return nil, errors.New("manufacture order not found")
+	}
+
+	requirements, err := s.materialRequirements(mo)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.reservationRepo.GetReservationsByMoId(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	reserved := make(map[string]float64)
+	for _, reservation := range existing {
+		if reservation.Status == "reserved" {
+			reserved[reservation.MaterialId+reservation.ProductId] = reservation.QtyReserved
+		}
+	}
+
+	fullyAvailable := true
+	lines := []map[string]interface{}{}
+	for _, requirement := range requirements {
+		available, onHand, err := s.availableQty(requirement, mo.MoId)
+		if err != nil {
+			return nil, err
+		}
+		shortage := math.Max(requirement.Quantity-math.Max(available, 0), 0)
+		if shortage > 0 {
+			fullyAvailable = false
+		}
+		line := map[string]interface{}{
+			"id_material":   requirement.MaterialId,
+			"id_product":    requirement.ProductId,
+			"material_name": requirement.MaterialName,
+			"unit":          requirement.Unit,
+			"required":      requirement.Quantity,
+			"on_hand":       onHand,
+			"available":     available,
+			"reserved":      reserved[requirement.componentId()],
+			"shortage":      shortage,
+			"short":         shortage > 0,
+		}
+		if !reveal {
+			delete(line, "required")
+			delete(line, "reserved")
+			delete(line, "shortage")
+		}
+		lines = append(lines, line)
+	}
+
+	return map[string]interface{}{
+		"id_mo":           mo.MoId,
+		"status":          mo.Status,
+		"fully_available": fullyAvailable,
+		"materials":       lines,
+		"redacted":        !reveal,
+	}, nil
+}
+
+// collectShortages adds up the unreserved quantities of all open MOs per
+// material.
+func (s *moService) collectShortages() ([]*materialShortage, error) {
+	reservations, err := s.reservationRepo.FindOpenShortages()
+	if err != nil {
+		return nil, err
+	}
+
+	var shortages []*materialShortage
+	byMaterial := make(map[string]*materialShortage)
+	for _, reservation := range reservations {
+		shortage, found := byMaterial[reservation.MaterialId]
+		if !found {
+			material, err := s.materialRepo.FindMaterialByID(reservation.MaterialId)
+			if err != nil {
+				return nil, fmt.Errorf("material with id %s not found", reservation.MaterialId)
+			}
+			shortage = &materialShortage{
+				MaterialId:   material.MaterialId,
+				MaterialName: material.Materialname,
+				Unit:         material.Unit,
+				MakePrice:    material.Makeprice,
+				OnHand:       material.Qty,
+			}
+			byMaterial[reservation.MaterialId] = shortage
+			shortages = append(shortages, shortage)
+		}
+		shortage.Shortage += reservation.QtyRequired - reservation.QtyReserved
+		shortage.MoIds = append(shortage.MoIds, reservation.MoId)
+	}
+	return shortages, nil
+}
+
+// ShortageReport lists the materials open MOs are short of. Unless reveal is
+// set, the quantities missing and the MOs they are missing for are left out,
+// as together they give the formulas away.
+func (s *moService) ShortageReport(reveal bool) ([]map[string]interface{}, error) {
+	shortages, err := s.collectShortages()
+	if err != nil {
+		return nil, err
+	}
+
+	report := []map[string]interface{}{}
+	for _, shortage := range shortages {
+		line := map[string]interface{}{
+			"id_material":   shortage.MaterialId,
+			"material_name": shortage.MaterialName,
+			"unit":          shortage.Unit,
+			"on_hand":       shortage.OnHand,
+			"shortage":      shortage.Shortage,
+			"make_price":    shortage.MakePrice,
+			"id_mos":        shortage.MoIds,
+			"redacted":      !reveal,
+		}
+		if !reveal {
+			delete(line, "shortage")
+			delete(line, "id_mos")
+		}
+		report = append(report, line)
+	}
+	return report, nil
+}
+
+// CreateShortageRfq turns the current shortage report into a single RFQ for
+// the given vendor, priced at each material's make price. What open RFQs
+// and purchase orders still have to deliver is not ordered again.
+func (s *moService) CreateShortageRfq(vendorId, orderDate string) (*entity.Rfqs, error) {
+	exists, err := s.rfqService.GetCheckIDProduct(vendorId)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("Vendor with id %s does not exist", vendorId)
+	}
+
+	shortages, err := s.collectShortages()
+	if err != nil {
+		return nil, err
+	}
+	onOrder, err := s.materialsOnOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	rfq := entity.NewRfqs("", orderDate, "", vendorId)
+	for _, shortage := range shortages {
+		missing := shortage.Shortage - onOrder[shortage.MaterialId]
+		if missing <= receiptTolerance {
+			continue
+		}
+		if _, err := strconv.ParseFloat(shortage.MakePrice, 64); err != nil {
+			return nil, fmt.Errorf("invalid make price for material %s: %v", shortage.MaterialId, err)
+		}
+		rfq.Products = append(rfq.Products, entity.RfqsProduct{
+			LineType:    "material",
+			MaterialId:  shortage.MaterialId,
+			ProductName: shortage.MaterialName,
+			Quantity:    strconv.FormatFloat(missing, 'f', -1, 64),
+			UnitPrice:   shortage.MakePrice,
+			VendorId:    vendorId,
+		})
+	}
+	if len(rfq.Products) == 0 {
+		return nil, errors.New("there are no material shortages to order")
+	}
+
+	return s.rfqService.CreateRfq(rfq)
+}
+
+// materialsOnOrder returns how much of each material open RFQs and purchase
+// orders have yet to deliver.
+func (s *moService) materialsOnOrder() (map[string]float64, error) {
+	rfqs, err := s.rfqRepo.FindRfqsByStatus(openRfqStatuses)
+	if err != nil {
+		return nil, err
+	}
+	onOrder := make(map[string]float64)
+	for _, rfq := range rfqs {
+		for _, line := range rfq.Products {
+			if line.LineType != "material" {
+				continue
+			}
+			qty, err := outstandingQty(line)
+			if err != nil {
+				continue
+			}
+			onOrder[line.MaterialId] += qty
+		}
+	}
+	return onOrder, nil
+}
+
+// GenerateSubAssemblyMos creates child MOs for every sub-assembly of the MO
+// that has a BOM of its own and is not covered by free stock. Children that
+// use sub-assemblies themselves get their own children in turn. Running it
+// again only adds what earlier children do not already cover.
+func (s *moService) GenerateSubAssemblyMos(moId string) ([]entity.Mos, error) {
+	mo, err := s.moRepository.FindMoByID(moId)
+	if err != nil {
+		return nil, errors.New("manufacture order not found")
+	}
+	if mo.Status == "done" || mo.Status == "cancelled" || mo.Status == "unbuilt" {
+		return nil, fmt.Errorf("manufacture order is already %s", mo.Status)
+	}
+	return s.generateSubAssemblyMos(mo, []string{mo.ProductId})
+}
+
+func (s *moService) generateSubAssemblyMos(mo *entity.Mos, path []string) ([]entity.Mos, error) {
+	requirements, err := s.materialRequirements(mo)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := s.moRepository.FindMosByParentId(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	planned := make(map[string]float64)
+	for _, child := range children {
+		qty, err := strconv.ParseFloat(child.Qtytoproduce, 64)
+		if err != nil {
+			continue
+		}
+		planned[child.ProductId] += qty
+	}
+
+	created := []entity.Mos{}
+	for _, requirement := range requirements {
+		if requirement.ProductId == "" {
+			continue
+		}
+		if err := checkBOMPath(path, requirement.ProductId); err != nil {
+			return nil, err
+		}
+
+		subBom, err := s.bomRepo.FindBOMByProductID(requirement.ProductId)
+		if err != nil {
+			return nil, err
+		}
+		if subBom == nil {
+			// Bought-in product, nothing to manufacture
+			continue
+		}
+
+		available, _, err := s.availableQty(requirement, mo.MoId)
+		if err != nil {
+			return nil, err
+		}
+		toProduce := requirement.Quantity - math.Max(available, 0) - planned[requirement.ProductId]
+		if toProduce <= 0 {
+			continue
+		}
+
+		lastId, err := s.moRepository.GetLastMo()
+		if err != nil {
+			return nil, err
+		}
+		child := entity.NewMos(lastId, requirement.ProductId, subBom.BomId, strconv.FormatFloat(toProduce, 'f', -1, 64))
+		child.ParentMoId = mo.MoId
+		child.BomVersionId = subBom.VersionId
+		savedChild, err := s.moRepository.CreateMo(child)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, *savedChild)
+
+		grandChildren, err := s.generateSubAssemblyMos(savedChild, append(path, requirement.ProductId))
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, grandChildren...)
+	}
+
+	return created, nil
+}
+
+// generateWorkOrders copies the routing of the MO's BOM into work orders,
+// with planned time scaled to the quantity to produce.
+func (s *moService) generateWorkOrders(mo *entity.Mos) error {
+	existing, err := s.workOrderRepo.GetWorkOrdersByMoId(mo.MoId)
+	if err != nil || len(existing) > 0 {
+		return err
+	}
+
+	operations, err := s.routingRepo.GetOperationsByBomId(mo.BomId)
+	if err != nil {
+		return err
+	}
+	qtyToProduce, err := strconv.ParseFloat(mo.Qtytoproduce, 64)
+	if err != nil {
+		return fmt.Errorf("invalid quantity to produce: %v", err)
+	}
+
+	// An MO planned before it was confirmed gets its work orders laid out
+	// from the planned start. Until now its operations were on no work
+	// center's schedule, so they are checked against it first.
+	var at time.Time
+	if mo.PlannedStart != nil {
+		at = *mo.PlannedStart
+		if err := s.checkPlannedOperations(mo); err != nil {
+			return err
+		}
+	}
+	for _, operation := range operations {
+		lastId, err := s.workOrderRepo.GetLastWorkOrderId()
+		if err != nil {
+			return err
+		}
+		planned := operation.DurationMinutes + operation.MinutesPerUnit*qtyToProduce
+		workOrder := entity.NewWorkOrder(lastId, mo.MoId, operation, planned)
+		if !at.IsZero() {
+			start, end := at, at.Add(time.Duration(planned*float64(time.Minute)))
+			workOrder.PlannedStart, workOrder.PlannedEnd = &start, &end
+			at = end
+		}
+		if _, err := s.workOrderRepo.CreateWorkOrder(workOrder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPlannedOperations checks that the work centers are free for the
+// operations of an MO laid out from its planned start.
+func (s *moService) checkPlannedOperations(mo *entity.Mos) error {
+	planner, err := s.newProductionPlanner([]string{mo.MoId})
+	if err != nil {
+		return err
+	}
+	plan, err := s.newMoPlan(mo)
+	if err != nil {
+		return err
+	}
+	plan.layout(*mo.PlannedStart)
+
+	var conflicts []ScheduleConflict
+	for _, operation := range plan.operations {
+		found, err := planner.workCenterConflicts(plan, operation)
+		if err != nil {
+			return err
+		}
+		conflicts = append(conflicts, found...)
+	}
+	if len(conflicts) > 0 {
+		return &ScheduleConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+func (s *moService) GetMoWorkOrders(moId string) (map[string]interface{}, error) {
+	mo, err := s.moRepository.FindMoByID(moId)
+	if err != nil {
+		return nil, errors.New("manufacture order not found")
+	}
+
+	workOrders, err := s.workOrderRepo.GetWorkOrdersByMoId(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+
+	var plannedMinutes, actualMinutes, cost float64
+	lines := []map[string]interface{}{}
+	for _, workOrder := range workOrders {
+		workCenter, err := s.workCenterRepo.FindWorkCenterByID(workOrder.WorkCenterId)
+		if err != nil {
+			return nil, err
+		}
+		hourlyCost := 0.0
+		workCenterName := ""
+		if workCenter != nil {
+			hourlyCost = workCenter.HourlyCost
+			workCenterName = workCenter.Name
+		}
+		workOrderCost := workOrder.DurationMinutes / 60 * hourlyCost
+
+		plannedMinutes += workOrder.PlannedMinutes
+		actualMinutes += workOrder.DurationMinutes
+		cost += workOrderCost
+
+		lines = append(lines, map[string]interface{}{
+			"work_order":     workOrder,
+			"workcenter":     workCenterName,
+			"hourly_cost":    hourlyCost,
+			"operation_cost": math.Round(workOrderCost*100) / 100,
+		})
+	}
+
+	return map[string]interface{}{
+		"id_mo":           mo.MoId,
+		"status":          mo.Status,
+		"work_orders":     lines,
+		"planned_minutes": plannedMinutes,
+		"actual_minutes":  actualMinutes,
+		"operation_cost":  math.Round(cost*100) / 100,
+	}, nil
+}
+
+func (s *moService) findWorkOrder(workOrderId string) (*entity.WorkOrder, *entity.Mos, error) {
+	workOrder, err := s.workOrderRepo.FindWorkOrderByID(workOrderId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if workOrder == nil {
+		return nil, nil, errors.New("work order not found")
+	}
+	mo, err := s.moRepository.FindMoByID(workOrder.MoId)
+	if err != nil {
+		return nil, nil, errors.New("manufacture order not found")
+	}
+	return workOrder, mo, nil
+}
+
+// StartWorkOrder starts or resumes a work order. Operations run in sequence,
+// a work center takes no more work orders than its capacity, and starting
+// the first one puts the MO in progress.
+func (s *moService) StartWorkOrder(workOrderId, operator string) (*entity.WorkOrder, error) {
+	workOrder, mo, err := s.findWorkOrder(workOrderId)
+	if err != nil {
+		return nil, err
+	}
+	if mo.Status != "confirmed" && mo.Status != "on progress" {
+		return nil, fmt.Errorf("manufacture order is %s, work can only start once it is confirmed", mo.Status)
+	}
+	if workOrder.Status != "pending" && workOrder.Status != "paused" {
+		return nil, fmt.Errorf("work order is already %s", workOrder.Status)
+	}
+
+	siblings, err := s.workOrderRepo.GetWorkOrdersByMoId(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	for _, sibling := range siblings {
+		if sibling.Sequence < workOrder.Sequence && sibling.Status != "done" {
+			return nil, fmt.Errorf("operation %s has to be finished first", sibling.Name)
+		}
+	}
+
+	workCenter, err := s.workCenterRepo.FindWorkCenterByID(workOrder.WorkCenterId)
+	if err != nil {
+		return nil, err
+	}
+	if workCenter != nil && workCenter.Capacity > 0 {
+		running, err := s.workOrderRepo.CountRunningByWorkCenter(workCenter.WorkCenterId)
+		if err != nil {
+			return nil, err
+		}
+		if float64(running) >= workCenter.Capacity {
+			return nil, fmt.Errorf("work center %s is at full capacity", workCenter.Name)
+		}
+	}
+
+	now := time.Now()
+	lastLogId, err := s.workOrderRepo.GetLastLogId()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.workOrderRepo.CreateLog(entity.NewWorkOrderLog(lastLogId, workOrder.WorkOrderId, operator, now)); err != nil {
+		return nil, err
+	}
+
+	if workOrder.StartedAt == nil {
+		workOrder.StartedAt = &now
+	}
+	workOrder.Status = "in progress"
+	workOrder.UpdatedAt = now
+	if _, err := s.workOrderRepo.UpdateWorkOrder(workOrder); err != nil {
+		return nil, err
+	}
+
+	if mo.Status == "confirmed" {
+		mo.Status = "on progress"
+		if _, err := s.moRepository.UpdateMoStatus(mo); err != nil {
+			return nil, errors.New("failed to update manufacture order status")
+		}
+	}
+
+	return s.workOrderRepo.FindWorkOrderByID(workOrder.WorkOrderId)
+}
+
+// stopWorkOrder closes the running log and adds its time to the work order.
+func (s *moService) stopWorkOrder(workOrder *entity.WorkOrder, status string) (*entity.WorkOrder, error) {
+	now := time.Now()
+	log, err := s.workOrderRepo.FindOpenLog(workOrder.WorkOrderId)
+	if err != nil {
+		return nil, err
+	}
+	if log != nil {
+		log.EndedAt = &now
+		log.Minutes = now.Sub(log.StartedAt).Minutes()
+		log.UpdatedAt = now
+		if _, err := s.workOrderRepo.UpdateLog(log); err != nil {
+			return nil, err
+		}
+		workOrder.DurationMinutes += log.Minutes
+	}
+
+	workOrder.Status = status
+	if status == "done" {
+		workOrder.FinishedAt = &now
+	}
+	workOrder.UpdatedAt = now
+	if _, err := s.workOrderRepo.UpdateWorkOrder(workOrder); err != nil {
+		return nil, err
+	}
+	return s.workOrderRepo.FindWorkOrderByID(workOrder.WorkOrderId)
+}
+
+func (s *moService) PauseWorkOrder(workOrderId string) (*entity.WorkOrder, error) {
+	workOrder, _, err := s.findWorkOrder(workOrderId)
+	if err != nil {
+		return nil, err
+	}
+	if workOrder.Status != "in progress" {
+		return nil, errors.New("only a work order in progress can be paused")
+	}
+	return s.stopWorkOrder(workOrder, "paused")
+}
+
+func (s *moService) FinishWorkOrder(workOrderId string) (*entity.WorkOrder, error) {
+	workOrder, _, err := s.findWorkOrder(workOrderId)
+	if err != nil {
+		return nil, err
+	}
+	if workOrder.Status != "in progress" && workOrder.Status != "paused" {
+		return nil, errors.New("only a started work order can be finished")
+	}
+	return s.stopWorkOrder(workOrder, "done")
+}
+
+// macerationNeeds returns the tank volume and the number of days the MO's
+// batch has to macerate. Zero days means the product needs no tank.
+func (s *moService) macerationNeeds(mo *entity.Mos) (float64, int, error) {
+	bom, err := s.pinnedBOM(mo)
+	if err != nil {
+		return 0, 0, err
+	}
+	qtyToProduce, err := strconv.ParseFloat(mo.Qtytoproduce, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid quantity to produce: %v", err)
+	}
+	return bom.LitresPerUnit * qtyToProduce, bom.MacerationDays, nil
+}
+
+// ensureTankBooking books a tank from today, or from the planned start when
+// that is later, for an MO that needs maceration and has not been booked
+// yet. It reports whether a booking was made, and fails with a
+// TankUnavailableError carrying the next free slot when every suitable tank
+// is taken.
+func (s *moService) ensureTankBooking(mo *entity.Mos) (bool, error) {
+	litres, days, err := s.macerationNeeds(mo)
+	if err != nil || days <= 0 {
+		return false, err
+	}
+	existing, err := s.schedulesRepo.FindTankBookingsByMoId(mo.MoId)
+	if err != nil || len(existing) > 0 {
+		return false, err
+	}
+
+	start := startOfDay(time.Now())
+	if mo.PlannedStart != nil && mo.PlannedStart.After(start) {
+		start = startOfDay(*mo.PlannedStart)
+	}
+	slot, err := s.tanks.nextFreeSlot(litres, days, start)
+	if err != nil {
+		return false, err
+	}
+	if slot == nil || slot.Start.After(start) {
+		return false, &TankUnavailableError{Litres: litres, Days: days, Start: start, Suggestion: slot}
+	}
+
+	booking := entity.NewTankBooking("Maceration "+mo.MoId, slot.VesselId, mo.MoId, slot.Start, slot.End, litres)
+	if _, err := s.schedulesRepo.CreateSchedules(booking); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// BookTank reserves a specific tank for the MO's maceration, replacing any
+// earlier booking. It is how a planner takes up a suggested slot before
+// confirming the MO.
+func (s *moService) BookTank(moId, vesselId string, start time.Time) (*entity.Schedules, error) {
+	mo, err := s.moRepository.FindMoByID(moId)
+	if err != nil {
+		return nil, errors.New("manufacture order not found")
+	}
+	if mo.Status != "draft" && mo.Status != "waiting for materials" && mo.Status != "confirmed" {
+		return nil, fmt.Errorf("manufacture order is %s, its tank can no longer be changed", mo.Status)
+	}
+
+	litres, days, err := s.macerationNeeds(mo)
+	if err != nil {
+		return nil, err
+	}
+	if days <= 0 {
+		return nil, errors.New("the BOM of this manufacture order has no maceration period")
+	}
+
+	start = startOfDay(start)
+	end := start.AddDate(0, 0, days)
+	existing, err := s.schedulesRepo.FindTankBookingsByMoId(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	skip := uuid.Nil
+	if len(existing) > 0 {
+		skip = existing[0].SchedulesId
+	}
+	if err := s.tanks.checkBooking(vesselId, start, end, litres, skip); err != nil {
+		return nil, err
+	}
+
+	if err := s.schedulesRepo.DeleteTankBookingsByMoId(mo.MoId); err != nil {
+		return nil, err
+	}
+	return s.schedulesRepo.CreateSchedules(entity.NewTankBooking("Maceration "+mo.MoId, vesselId, mo.MoId, start, end, litres))
+}
+
+// GenerateMOPDF prints the batch manufacturing record of the MO.
+func (s *moService) GenerateMOPDF(mo *entity.Mos, reveal bool) ([]byte, error) {
+	record, err := s.GetBatchRecord(mo.MoId, reveal)
+	if err != nil {
+		return nil, err
+	}
+	return renderBatchRecord(record)
 }
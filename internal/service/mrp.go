@@ -0,0 +1,699 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
+)
+
+type MrpService interface {
+	RunMrp() (*entity.MrpRun, error)
+	FindAllRuns(page int) ([]entity.MrpRun, error)
+	GetRunByID(runId string) (*entity.MrpRun, error)
+	UpdateProposal(proposal *entity.MrpProposal) (*entity.MrpProposal, error)
+	ConfirmRun(runId string, proposalIds []string) (*entity.MrpRun, error)
+	CreateReorderRule(rule *entity.ReorderRule) (*entity.ReorderRule, error)
+	UpdateReorderRule(rule *entity.ReorderRule) (*entity.ReorderRule, error)
+	DeleteReorderRule(ruleId string) (bool, error)
+	FindAllReorderRules() ([]entity.ReorderRule, error)
+}
+
+type mrpService struct {
+	mrpRepo         repository.MrpRepository
+	reorderRuleRepo repository.ReorderRuleRepository
+	quoRepo         repository.QuoRepository
+	rfqRepo         repository.RfqRepository
+	moRepo          repository.MoRepository
+	reservationRepo repository.MoReservationRepository
+	bomRepo         repository.BOMRepository
+	materialRepo    repository.MaterialRepository
+	productRepo     repository.ProductRepository
+	moService       MoService
+	rfqService      RfqService
+}
+
+func NewMrpService(mrpRepo repository.MrpRepository, reorderRuleRepo repository.ReorderRuleRepository,
+	quoRepo repository.QuoRepository, rfqRepo repository.RfqRepository, moRepo repository.MoRepository,
+	reservationRepo repository.MoReservationRepository, bomRepo repository.BOMRepository,
+	materialRepo repository.MaterialRepository, productRepo repository.ProductRepository,
+	moService MoService, rfqService RfqService) *mrpService {
+	return &mrpService{
+		mrpRepo:         mrpRepo,
+		reorderRuleRepo: reorderRuleRepo,
+		quoRepo:         quoRepo,
+		rfqRepo:         rfqRepo,
+		moRepo:          moRepo,
+		reservationRepo: reservationRepo,
+		bomRepo:         bomRepo,
+		materialRepo:    materialRepo,
+		productRepo:     productRepo,
+		moService:       moService,
+		rfqService:      rfqService,
+	}
+}
+
+// Statuses whose documents still count as open supply or demand.
+var (
+	openMoStatuses  = []string{"confirmed", "waiting for materials", "on progress"}
+	openRfqStatuses = []string{"RFQ", "Purchase Order", "Partially Received"}
+)
+
+// documentDateLayouts are the formats order dates have been entered in.
+var documentDateLayouts = []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05", "02-01-2006", "02/01/2006", "2006/01/02"}
+
+// parseDocumentDate reads the free-text order date of a quotation or RFQ.
+func parseDocumentDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range documentDateLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// mrpPlanner holds the state of a single MRP run. Projected availability per
+// item is consumed greedily as demand comes in; whatever cannot be covered
+// becomes (or is added to) a proposal.
+type mrpPlanner struct {
+	s         *mrpService
+	today     time.Time
+	rules     map[string]*entity.ReorderRule
+	incoming  map[string]float64
+	available map[string]float64
+	proposals map[string]*entity.MrpProposal
+	order     []string
+	warnings  []string
+}
+
+func (p *mrpPlanner) leadTime(itemId string) int {
+	if rule, ok := p.rules[itemId]; ok {
+		return rule.LeadTimeDays
+	}
+	return 0
+}
+
+// projected returns the stock left to plan with for an item: on hand, minus
+// what MOs have reserved, plus open purchases or production.
+func (p *mrpPlanner) projected(itemId string, isProduct bool) (float64, error) {
+	if available, ok := p.available[itemId]; ok {
+		return available, nil
+	}
+
+	var onHand, reserved float64
+	if isProduct {
+		product, err := p.s.productRepo.FindProductByID(itemId)
+		if err != nil {
+			return 0, fmt.Errorf("product with id %s not found", itemId)
+		}
+		onHand = product.Qty
+		if reserved, err = p.s.reservationRepo.SumReservedByProduct(itemId, ""); err != nil {
+			return 0, err
+		}
+	} else {
+		material, err := p.s.materialRepo.FindMaterialByID(itemId)
+		if err != nil {
+			return 0, fmt.Errorf("material with id %s not found", itemId)
+		}
+		onHand = material.Qty
+		if reserved, err = p.s.reservationRepo.SumReservedByMaterial(itemId, ""); err != nil {
+			return 0, err
+		}
+	}
+
+	available := onHand - reserved + p.incoming[itemId]
+	p.available[itemId] = available
+	return available, nil
+}
+
+// net takes qty out of the projected stock and returns the part not covered.
+func (p *mrpPlanner) net(itemId string, isProduct bool, qty float64) (float64, error) {
+	available, err := p.projected(itemId, isProduct)
+	if err != nil {
+		return 0, err
+	}
+	covered := math.Min(math.Max(available, 0), qty)
+	p.available[itemId] = available - covered
+	return qty - covered, nil
+}
+
+// propose adds qty to the item's proposal, keeping the earliest dates.
+func (p *mrpPlanner) propose(proposal entity.MrpProposal, itemId string, qty float64, due time.Time, source string) {
+	orderDate := startOfDay(due).AddDate(0, 0, -p.leadTime(itemId))
+	late := orderDate.Before(p.today)
+	if late {
+		orderDate = p.today
+	}
+
+	key := proposal.Type + ":" + itemId
+	existing, found := p.proposals[key]
+	if !found {
+		proposal.Quantity = qty
+		proposal.OrderDate = orderDate
+		proposal.DueDate = due
+		proposal.Late = late
+		proposal.Reason = source
+		p.proposals[key] = &proposal
+		p.order = append(p.order, key)
+		return
+	}
+
+	existing.Quantity += qty
+	if orderDate.Before(existing.OrderDate) {
+		existing.OrderDate = orderDate
+	}
+	if due.Before(existing.DueDate) {
+		existing.DueDate = due
+	}
+	existing.Late = existing.Late || late
+	if !strings.Contains(existing.Reason, source) {
+		existing.Reason += ", " + source
+	}
+}
+
+func (p *mrpPlanner) demandMaterial(materialId string, qty float64, due time.Time, source string) error {
+	shortfall, err := p.net(materialId, false, qty)
+	if err != nil || shortfall <= 0 {
+		return err
+	}
+	return p.planMaterial(materialId, shortfall, due, source)
+}
+
+func (p *mrpPlanner) planMaterial(materialId string, qty float64, due time.Time, source string) error {
+	material, err := p.s.materialRepo.FindMaterialByID(materialId)
+	if err != nil {
+		return fmt.Errorf("material with id %s not found", materialId)
+	}
+	proposal := entity.MrpProposal{
+		Type:       "rfq",
+		MaterialId: materialId,
+		Name:       material.Materialname,
+		Unit:       material.Unit,
+	}
+	if rule, ok := p.rules[materialId]; ok {
+		proposal.VendorId = rule.VendorId
+	}
+	p.propose(proposal, materialId, qty, due, source)
+	return nil
+}
+
+func (p *mrpPlanner) demandProduct(productId string, qty float64, due time.Time, source string, path []string) error {
+	shortfall, err := p.net(productId, true, qty)
+	if err != nil || shortfall <= 0 {
+		return err
+	}
+	return p.planProduct(productId, shortfall, due, source, path)
+}
+
+// planProduct proposes an MO for the product and passes the requirements of
+// its BOM down as demand, due when the MO has to start.
+func (p *mrpPlanner) planProduct(productId string, qty float64, due time.Time, source string, path []string) error {
+	if err := checkBOMPath(path, productId); err != nil {
+		return err
+	}
+	bom, err := p.s.bomRepo.FindBOMByProductID(productId)
+	if err != nil {
+		return err
+	}
+	if bom == nil {
+		p.warnings = append(p.warnings, fmt.Sprintf("product %s is short by %g but has no BOM to produce it", productId, qty))
+		return nil
+	}
+
+	qty = math.Ceil(qty)
+	p.propose(entity.MrpProposal{
+		Type:      "mo",
+		ProductId: productId,
+		BomId:     bom.BomId,
+		Name:      bom.ProductName,
+	}, productId, qty, due, source)
+
+	start := startOfDay(due).AddDate(0, 0, -p.leadTime(productId))
+	if start.Before(p.today) {
+		start = p.today
+	}
+	componentSource := "MO for " + productId
+	factor := qty / bomOutputQty(bom)
+	path = append(path, productId)
+	for _, line := range bom.Materials {
+		lineQty, err := strconv.ParseFloat(line.Quantity, 64)
+		if err != nil {
+			return fmt.Errorf("invalid quantity in BOM %s: %v", bom.BomId, err)
+		}
+		if line.IdProduct != "" {
+			if err := p.demandProduct(line.IdProduct, lineQty*factor, start, componentSource, path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := p.demandMaterial(line.IdMaterial, lineQty*factor, start, componentSource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyReorderRule tops the item up to its maximum when the projected stock,
+// including what is already proposed, falls below the minimum.
+func (p *mrpPlanner) applyReorderRule(rule *entity.ReorderRule) error {
+	isProduct := rule.ProductId != ""
+	itemId := rule.MaterialId
+	kind := "rfq"
+	if isProduct {
+		itemId = rule.ProductId
+		kind = "mo"
+	}
+
+	available, err := p.projected(itemId, isProduct)
+	if err != nil {
+		return err
+	}
+	projected := math.Max(available, 0)
+	if proposal, ok := p.proposals[kind+":"+itemId]; ok {
+		projected += proposal.Quantity
+	}
+	if projected >= rule.MinQty {
+		return nil
+	}
+
+	qty := math.Max(rule.MaxQty, rule.MinQty) - projected
+	due := p.today.AddDate(0, 0, rule.LeadTimeDays)
+	source := "reorder rule " + rule.RuleId
+	if isProduct {
+		return p.planProduct(itemId, qty, due, source, nil)
+	}
+	return p.planMaterial(itemId, qty, due, source)
+}
+
+// RunMrp plans against open sales orders, open MOs and reorder rules and
+// stores the resulting proposals on a new draft run.
+func (s *mrpService) RunMrp() (*entity.MrpRun, error) {
+	planner := &mrpPlanner{
+		s:         s,
+		today:     startOfDay(time.Now()),
+		rules:     make(map[string]*entity.ReorderRule),
+		incoming:  make(map[string]float64),
+		available: make(map[string]float64),
+		proposals: make(map[string]*entity.MrpProposal),
+	}
+
+	rules, err := s.reorderRuleRepo.FindAllRules()
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		planner.rules[rules[i].MaterialId+rules[i].ProductId] = &rules[i]
+	}
+
+	// Incoming supply: open purchases for materials, open MOs for products
+	rfqs, err := s.rfqRepo.FindRfqsByStatus(openRfqStatuses)
+	if err != nil {
+		return nil, err
+	}
+	for _, rfq := range rfqs {
+		for _, line := range rfq.Products {
+			qty, err := outstandingQty(line)
+			if err != nil {
+				continue
+			}
+			planner.incoming[line.ItemId()] += qty
+		}
+	}
+	openMos, err := s.moRepo.FindMosByStatus(openMoStatuses)
+	if err != nil {
+		return nil, err
+	}
+	for _, mo := range openMos {
+		qty, err := remainingQty(&mo)
+		if err != nil {
+			continue
+		}
+		planner.incoming[mo.ProductId] += qty
+	}
+
+	// Components open MOs still wait for
+	for _, mo := range openMos {
+		reservations, err := s.reservationRepo.GetReservationsByMoId(mo.MoId)
+		if err != nil {
+			return nil, err
+		}
+		for _, reservation := range reservations {
+			missing := reservation.QtyRequired - reservation.QtyReserved
+			if reservation.Status != "reserved" || missing <= 0 {
+				continue
+			}
+			source := "MO " + mo.MoId
+			if reservation.ProductId != "" {
+				err = planner.demandProduct(reservation.ProductId, missing, planner.today, source, nil)
+			} else {
+				err = planner.demandMaterial(reservation.MaterialId, missing, planner.today, source)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Open sales orders
+	salesOrders, err := s.quoRepo.FindQuosByStatus("Sales Order")
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range salesOrders {
+		due, ok := parseDocumentDate(order.OrderDate)
+		if !ok {
+			due = planner.today
+			planner.warnings = append(planner.warnings, fmt.Sprintf("sales order %s has an unreadable order date, planned for today", order.QuotationsId))
+		}
+		for _, line := range order.Products {
+			qty, err := strconv.ParseFloat(line.Quantity, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quantity on sales order %s: %v", order.QuotationsId, err)
+			}
+			if err := planner.demandProduct(line.ProductId, qty, due, "SO "+order.QuotationsId, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Products first, their top-ups add to material demand
+	for _, rule := range rules {
+		if rule.ProductId == "" {
+			continue
+		}
+		if err := planner.applyReorderRule(&rule); err != nil {
+			return nil, err
+		}
+	}
+	for _, rule := range rules {
+		if rule.MaterialId == "" {
+			continue
+		}
+		if err := planner.applyReorderRule(&rule); err != nil {
+			return nil, err
+		}
+	}
+
+	lastRunId, err := s.mrpRepo.GetLastRunId()
+	if err != nil {
+		return nil, err
+	}
+	run := entity.NewMrpRun(lastRunId)
+	run.Warnings = strings.Join(planner.warnings, "\n")
+	if _, err := s.mrpRepo.CreateRun(run); err != nil {
+		return nil, err
+	}
+	// The new run plans against the same demand, so what earlier runs
+	// proposed and was not confirmed is replaced by it.
+	if err := s.mrpRepo.SupersedeOpenRuns(run.RunId); err != nil {
+		return nil, err
+	}
+
+	for _, key := range planner.order {
+		lastProposalId, err := s.mrpRepo.GetLastProposalId()
+		if err != nil {
+			return nil, err
+		}
+		proposal := entity.NewMrpProposal(lastProposalId, run.RunId, *planner.proposals[key])
+		if _, err := s.mrpRepo.CreateProposal(proposal); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.mrpRepo.FindRunByID(run.RunId)
+}
+
+func (s *mrpService) FindAllRuns(page int) ([]entity.MrpRun, error) {
+	return s.mrpRepo.FindAllRuns(page)
+}
+
+func (s *mrpService) GetRunByID(runId string) (*entity.MrpRun, error) {
+	run, err := s.mrpRepo.FindRunByID(runId)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, errors.New("MRP run not found")
+	}
+	return run, nil
+}
+
+// UpdateProposal lets a planner adjust quantity, dates or vendor of a
+// proposal, or reject it, before the run is confirmed.
+func (s *mrpService) UpdateProposal(input *entity.MrpProposal) (*entity.MrpProposal, error) {
+	proposal, err := s.mrpRepo.FindProposalByID(input.ProposalId)
+	if err != nil {
+		return nil, err
+	}
+	if proposal == nil {
+		return nil, errors.New("MRP proposal not found")
+	}
+	if proposal.Status == "confirmed" {
+		return nil, errors.New("a confirmed proposal can no longer be changed")
+	}
+	run, err := s.GetRunByID(proposal.RunId)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status == "superseded" {
+		return nil, errors.New("the MRP run was superseded by a newer run")
+	}
+
+	if input.Quantity < 0 {
+		return nil, errors.New("quantity cannot be negative")
+	}
+	if input.Quantity > 0 {
+		proposal.Quantity = input.Quantity
+	}
+	if !input.OrderDate.IsZero() {
+		proposal.OrderDate = input.OrderDate
+	}
+	if !input.DueDate.IsZero() {
+		proposal.DueDate = input.DueDate
+	}
+	if input.VendorId != "" {
+		if proposal.Type != "rfq" {
+			return nil, errors.New("only purchase proposals have a vendor")
+		}
+		exists, err := s.rfqService.GetCheckIDProduct(input.VendorId)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("Vendor with id %s does not exist", input.VendorId)
+		}
+		proposal.VendorId = input.VendorId
+	}
+	switch input.Status {
+	case "":
+	case "proposed", "rejected":
+		proposal.Status = input.Status
+	default:
+		return nil, errors.New("status must be proposed or rejected")
+	}
+	proposal.UpdatedAt = time.Now()
+
+	return s.mrpRepo.UpdateProposal(proposal)
+}
+
+// ConfirmRun turns the selected proposals (all open ones when none are given)
+// into MOs and RFQs. Purchase proposals are grouped into one RFQ per vendor.
+// Only the latest run can be confirmed; a new run supersedes earlier ones.
+func (s *mrpService) ConfirmRun(runId string, proposalIds []string) (*entity.MrpRun, error) {
+	run, err := s.GetRunByID(runId)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status == "superseded" {
+		return nil, errors.New("the MRP run was superseded by a newer run, confirm that one instead")
+	}
+
+	selected := make(map[string]bool)
+	for _, id := range proposalIds {
+		selected[id] = true
+	}
+
+	var toConfirm []*entity.MrpProposal
+	for i := range run.Proposals {
+		proposal := &run.Proposals[i]
+		if proposal.Status != "proposed" || (len(selected) > 0 && !selected[proposal.ProposalId]) {
+			continue
+		}
+		if proposal.Type == "rfq" && proposal.VendorId == "" {
+			return nil, fmt.Errorf("proposal %s for %s has no vendor", proposal.ProposalId, proposal.Name)
+		}
+		toConfirm = append(toConfirm, proposal)
+	}
+	if len(toConfirm) == 0 {
+		return nil, errors.New("there are no open proposals to confirm")
+	}
+
+	rfqsByVendor := make(map[string]*entity.Rfqs)
+	rfqProposals := make(map[string][]*entity.MrpProposal)
+	var vendors []string
+	for _, proposal := range toConfirm {
+		if proposal.Type == "mo" {
+			mo, err := s.moService.CreateMo(entity.NewMos("", proposal.ProductId, proposal.BomId, strconv.FormatFloat(proposal.Quantity, 'f', -1, 64)))
+			if err != nil {
+				return nil, err
+			}
+			proposal.ReferenceId = mo.MoId
+			continue
+		}
+
+		material, err := s.materialRepo.FindMaterialByID(proposal.MaterialId)
+		if err != nil {
+			return nil, fmt.Errorf("material with id %s not found", proposal.MaterialId)
+		}
+		if _, err := strconv.ParseFloat(material.Makeprice, 64); err != nil {
+			return nil, fmt.Errorf("invalid make price for material %s: %v", material.MaterialId, err)
+		}
+
+		rfq, found := rfqsByVendor[proposal.VendorId]
+		if !found {
+			rfq = entity.NewRfqs("", proposal.OrderDate.Format("2006-01-02"), "", proposal.VendorId)
+			rfqsByVendor[proposal.VendorId] = rfq
+			vendors = append(vendors, proposal.VendorId)
+		} else if orderDate := proposal.OrderDate.Format("2006-01-02"); orderDate < rfq.OrderDate {
+			rfq.OrderDate = orderDate
+		}
+		rfq.Products = append(rfq.Products, entity.RfqsProduct{
+			LineType:    "material",
+			MaterialId:  material.MaterialId,
+			ProductName: material.Materialname,
+			Quantity:    strconv.FormatFloat(proposal.Quantity, 'f', -1, 64),
+			UnitPrice:   material.Makeprice,
+			VendorId:    proposal.VendorId,
+		})
+		rfqProposals[proposal.VendorId] = append(rfqProposals[proposal.VendorId], proposal)
+	}
+
+	for _, vendorId := range vendors {
+		savedRfq, err := s.rfqService.CreateRfq(rfqsByVendor[vendorId])
+		if err != nil {
+			return nil, err
+		}
+		for _, proposal := range rfqProposals[vendorId] {
+			proposal.ReferenceId = savedRfq.RfqId
+		}
+	}
+
+	for _, proposal := range toConfirm {
+		proposal.Status = "confirmed"
+		proposal.UpdatedAt = time.Now()
+		if _, err := s.mrpRepo.UpdateProposal(proposal); err != nil {
+			return nil, err
+		}
+	}
+
+	run.Status = "confirmed"
+	for _, proposal := range run.Proposals {
+		if proposal.Status == "proposed" {
+			run.Status = "partially confirmed"
+			break
+		}
+	}
+	run.UpdatedAt = time.Now()
+	if _, err := s.mrpRepo.UpdateRun(run); err != nil {
+		return nil, err
+	}
+
+	return s.mrpRepo.FindRunByID(runId)
+}
+
+func (s *mrpService) validateReorderRule(rule *entity.ReorderRule) error {
+	if (rule.MaterialId == "") == (rule.ProductId == "") {
+		return errors.New("a reorder rule needs either id_material or id_product")
+	}
+	if rule.MinQty < 0 || rule.MaxQty < 0 || rule.LeadTimeDays < 0 {
+		return errors.New("quantities and lead time cannot be negative")
+	}
+	if rule.MaxQty != 0 && rule.MaxQty < rule.MinQty {
+		return errors.New("max_qty cannot be lower than min_qty")
+	}
+
+	if rule.MaterialId != "" {
+		if _, err := s.materialRepo.FindMaterialByID(rule.MaterialId); err != nil {
+			return fmt.Errorf("Material with id %s does not exist", rule.MaterialId)
+		}
+	} else if exists, err := s.productRepo.CheckProductExists(rule.ProductId); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("Product with id %s does not exist", rule.ProductId)
+	}
+
+	if rule.VendorId != "" {
+		exists, err := s.rfqService.GetCheckIDProduct(rule.VendorId)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("Vendor with id %s does not exist", rule.VendorId)
+		}
+	}
+
+	duplicate, err := s.reorderRuleRepo.CheckRuleExists(rule.MaterialId, rule.ProductId, rule.RuleId)
+	if err != nil {
+		return err
+	}
+	if duplicate {
+		return errors.New("this item already has a reorder rule")
+	}
+	return nil
+}
+
+func (s *mrpService) CreateReorderRule(rule *entity.ReorderRule) (*entity.ReorderRule, error) {
+	if err := s.validateReorderRule(rule); err != nil {
+		return nil, err
+	}
+
+	lastId, err := s.reorderRuleRepo.GetLastRuleId()
+	if err != nil {
+		return nil, err
+	}
+	newRule := entity.NewReorderRule(lastId, rule.MaterialId, rule.ProductId, rule.MinQty, rule.MaxQty, rule.LeadTimeDays, rule.VendorId)
+	return s.reorderRuleRepo.CreateRule(newRule)
+}
+
+func (s *mrpService) UpdateReorderRule(rule *entity.ReorderRule) (*entity.ReorderRule, error) {
+	existing, err := s.reorderRuleRepo.FindRuleByID(rule.RuleId)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, errors.New("reorder rule not found")
+	}
+	if err := s.validateReorderRule(rule); err != nil {
+		return nil, err
+	}
+
+	rule.CreatedAt = existing.CreatedAt
+	rule.UpdatedAt = time.Now()
+	return s.reorderRuleRepo.UpdateRule(rule)
+}
+
+func (s *mrpService) DeleteReorderRule(ruleId string) (bool, error) {
+	rule, err := s.reorderRuleRepo.FindRuleByID(ruleId)
+	if err != nil {
+		return false, err
+	}
+	if rule == nil {
+		return false, errors.New("reorder rule not found")
+	}
+	return s.reorderRuleRepo.DeleteRule(rule)
+}
+
+func (s *mrpService) FindAllReorderRules() ([]entity.ReorderRule, error) {
+	return s.reorderRuleRepo.FindAllRules()
+}
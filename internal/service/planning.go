@@ -0,0 +1,1012 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+)
+
+// Production planning gives MOs and their work orders a planned period.
+// Operations run back to back in routing sequence, around the clock, and a
+// maceration tank is booked by the day from the day the MO starts. An MO
+// that uses sub-assemblies can only start once their MOs are finished.
+
+// ScheduleConflict is one reason a planned period cannot be used. From and
+// To are the period of whatever is in the way.
+type ScheduleConflict struct {
+	Type    string    `json:"type"` // workcenter, tank, sequence or dependency
+	Message string    `json:"message"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+
+	// itemStart and itemEnd are the period of the operation or booking that
+	// clashes, so the scheduler knows how far to move it.
+	itemStart, itemEnd time.Time
+}
+
+// ScheduleConflictError is returned when a new plan clashes with work
+// already on the schedule.
+type ScheduleConflictError struct {
+	Conflicts []ScheduleConflict
+}
+
+func (e *ScheduleConflictError) Error() string {
+	messages := make([]string, 0, len(e.Conflicts))
+	for _, conflict := range e.Conflicts {
+		messages = append(messages, conflict.Message)
+	}
+	return "schedule conflict: " + strings.Join(messages, "; ")
+}
+
+// maxScheduleAttempts bounds the search for a free slot.
+const maxScheduleAttempts = 1000
+
+const planTimeLayout = "2006-01-02 15:04"
+
+// reschedulable reports whether the whole plan of an MO can still move. Once
+// work has started only the remaining work orders can.
+func reschedulable(status string) bool {
+	return status == "draft" || status == "waiting for materials" || status == "confirmed"
+}
+
+func moClosed(status string) bool {
+	return status == "done" || status == "cancelled" || status == "unbuilt"
+}
+
+type plannedOperation struct {
+	workOrder    *entity.WorkOrder // nil while the MO has no work orders yet
+	name         string
+	sequence     int
+	workCenterId string
+	minutes      float64
+	start, end   time.Time
+}
+
+type plannedTank struct {
+	vesselId   string // tank already booked, empty to pick any that fits
+	chosen     string // tank the plan uses, set once it is checked
+	litres     float64
+	days       int
+	start, end time.Time
+}
+
+type moPlan struct {
+	mo         *entity.Mos
+	operations []*plannedOperation
+	tank       *plannedTank
+	start, end time.Time
+}
+
+// layout places the operations back to back from start, with the tank
+// booked from the start day.
+func (p *moPlan) layout(start time.Time) {
+	at := start
+	for _, operation := range p.operations {
+		operation.start = at
+		at = at.Add(time.Duration(operation.minutes * float64(time.Minute)))
+		operation.end = at
+	}
+	if p.tank != nil {
+		p.tank.start = startOfDay(start)
+		p.tank.end = p.tank.start.AddDate(0, 0, p.tank.days)
+	}
+	p.span()
+	if p.start.IsZero() {
+		p.start, p.end = start, start
+	}
+}
+
+// span sets the planned period of the MO from its operations and tank.
+func (p *moPlan) span() {
+	p.start, p.end = time.Time{}, time.Time{}
+	extend := func(start, end time.Time) {
+		if start.IsZero() {
+			return
+		}
+		if p.start.IsZero() || start.Before(p.start) {
+			p.start = start
+		}
+		if end.After(p.end) {
+			p.end = end
+		}
+	}
+	for _, operation := range p.operations {
+		extend(operation.start, operation.end)
+	}
+	if p.tank != nil {
+		if len(p.operations) == 0 {
+			extend(p.tank.start, p.tank.end)
+		} else if p.tank.end.After(p.end) {
+			p.end = p.tank.end
+		}
+	}
+}
+
+// newMoPlan reads the current plan of an MO. MOs that are not confirmed yet
+// have no work orders, so their operations come from the routing.
+func (s *moService) newMoPlan(mo *entity.Mos) (*moPlan, error) {
+	plan := &moPlan{mo: mo}
+
+	workOrders, err := s.workOrderRepo.GetWorkOrdersByMoId(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	if len(workOrders) > 0 {
+		for i := range workOrders {
+			workOrder := &workOrders[i]
+			if workOrder.Status == "cancelled" {
+				continue
+			}
+			operation := &plannedOperation{
+				workOrder:    workOrder,
+				name:         workOrder.Name,
+				sequence:     workOrder.Sequence,
+				workCenterId: workOrder.WorkCenterId,
+				minutes:      workOrder.PlannedMinutes,
+			}
+			if workOrder.PlannedStart != nil && workOrder.PlannedEnd != nil {
+				operation.start, operation.end = *workOrder.PlannedStart, *workOrder.PlannedEnd
+			}
+			plan.operations = append(plan.operations, operation)
+		}
+	} else {
+		operations, err := s.routingRepo.GetOperationsByBomId(mo.BomId)
+		if err != nil {
+			return nil, err
+		}
+		qtyToProduce, err := strconv.ParseFloat(mo.Qtytoproduce, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity to produce: %v", err)
+		}
+		for _, operation := range operations {
+			plan.operations = append(plan.operations, &plannedOperation{
+				name:         operation.Name,
+				sequence:     operation.Sequence,
+				workCenterId: operation.WorkCenterId,
+				minutes:      operation.DurationMinutes + operation.MinutesPerUnit*qtyToProduce,
+			})
+		}
+	}
+
+	litres, days, err := s.macerationNeeds(mo)
+	if err != nil {
+		return nil, err
+	}
+	if days > 0 {
+		plan.tank = &plannedTank{litres: litres, days: days}
+		bookings, err := s.schedulesRepo.FindTankBookingsByMoId(mo.MoId)
+		if err != nil {
+			return nil, err
+		}
+		if len(bookings) > 0 {
+			plan.tank.vesselId = bookings[0].VesselId
+			if start, end, ok := bookingPeriod(bookings[0]); ok {
+				plan.tank.start, plan.tank.end = start, end
+			}
+		}
+	}
+
+	plan.span()
+	return plan, nil
+}
+
+// productionPlanner holds the plans of one scheduling run. MOs being
+// replanned are checked against the rest of the schedule and against each
+// other, and nothing is saved until the whole run has worked out.
+type productionPlanner struct {
+	s           *moService
+	replanning  map[string]bool
+	plans       []*moPlan
+	workCenters map[string]entity.WorkCenter
+	vessels     []entity.Vessel
+}
+
+func (s *moService) newProductionPlanner(moIds []string) (*productionPlanner, error) {
+	workCenters, err := s.workCenterRepo.FindAllWorkCenters()
+	if err != nil {
+		return nil, err
+	}
+	vessels, err := s.tanks.vesselRepo.FindAllVessels()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(vessels, func(i, j int) bool { return vessels[i].CapacityLitres < vessels[j].CapacityLitres })
+
+	p := &productionPlanner{
+		s:           s,
+		replanning:  make(map[string]bool),
+		workCenters: make(map[string]entity.WorkCenter),
+		vessels:     vessels,
+	}
+	for _, moId := range moIds {
+		p.replanning[moId] = true
+	}
+	for _, workCenter := range workCenters {
+		p.workCenters[workCenter.WorkCenterId] = workCenter
+	}
+	return p, nil
+}
+
+func (p *productionPlanner) vesselName(vesselId string) string {
+	for _, vessel := range p.vessels {
+		if vessel.VesselId == vesselId {
+			return vessel.Name
+		}
+	}
+	return vesselId
+}
+
+// busyPeriod is work already planned on a work center or tank.
+type busyPeriod struct {
+	label    string
+	from, to time.Time
+}
+
+// peakLoad is the largest number of periods running at the same moment
+// within [from, to).
+func peakLoad(periods []busyPeriod, from, to time.Time) int {
+	type event struct {
+		at    time.Time
+		delta int
+	}
+	events := make([]event, 0, 2*len(periods))
+	for _, period := range periods {
+		start, end := period.from, period.to
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			events = append(events, event{start, 1}, event{end, -1})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].at.Equal(events[j].at) {
+			return events[i].delta < events[j].delta
+		}
+		return events[i].at.Before(events[j].at)
+	})
+
+	load, peak := 0, 0
+	for _, e := range events {
+		load += e.delta
+		if load > peak {
+			peak = load
+		}
+	}
+	return peak
+}
+
+// workCenterConflicts checks that the work center of the operation has room
+// for it next to the other work orders planned on it.
+func (p *productionPlanner) workCenterConflicts(plan *moPlan, operation *plannedOperation) ([]ScheduleConflict, error) {
+	if operation.workCenterId == "" || !operation.end.After(operation.start) {
+		return nil, nil
+	}
+	capacity, name := 1, operation.workCenterId
+	if workCenter, ok := p.workCenters[operation.workCenterId]; ok {
+		name = workCenter.Name
+		if workCenter.Capacity > 1 {
+			capacity = int(workCenter.Capacity)
+		}
+	}
+
+	others, err := p.s.workOrderRepo.FindPlannedByWorkCenter(operation.workCenterId, operation.start, operation.end)
+	if err != nil {
+		return nil, err
+	}
+	var periods []busyPeriod
+	for _, other := range others {
+		if other.MoId == plan.mo.MoId || p.replanning[other.MoId] {
+			continue
+		}
+		periods = append(periods, busyPeriod{fmt.Sprintf("%s of %s", other.Name, other.MoId), *other.PlannedStart, *other.PlannedEnd})
+	}
+	for _, otherPlan := range p.plans {
+		if otherPlan.mo.MoId == plan.mo.MoId {
+			continue
+		}
+		for _, other := range otherPlan.operations {
+			if other.workCenterId == operation.workCenterId && other.start.Before(operation.end) && other.end.After(operation.start) {
+				periods = append(periods, busyPeriod{fmt.Sprintf("%s of %s", other.name, otherPlan.mo.MoId), other.start, other.end})
+			}
+		}
+	}
+	if peakLoad(periods, operation.start, operation.end) < capacity {
+		return nil, nil
+	}
+
+	conflicts := make([]ScheduleConflict, 0, len(periods))
+	for _, period := range periods {
+		conflicts = append(conflicts, ScheduleConflict{
+			Type: "workcenter",
+			Message: fmt.Sprintf("%s: work center %s is busy with %s from %s to %s", operation.name, name, period.label,
+				period.from.Format(planTimeLayout), period.to.Format(planTimeLayout)),
+			From:      period.from,
+			To:        period.to,
+			itemStart: operation.start,
+			itemEnd:   operation.end,
+		})
+	}
+	return conflicts, nil
+}
+
+// tankConflicts checks the maceration booking of the plan. An MO without a
+// tank yet gets the smallest one that is free; the conflicts are only
+// returned when no tank is.
+func (p *productionPlanner) tankConflicts(plan *moPlan) ([]ScheduleConflict, error) {
+	tank := plan.tank
+	tank.chosen = ""
+	candidates := []string{tank.vesselId}
+	if tank.vesselId == "" {
+		candidates = nil
+		for _, vessel := range p.vessels {
+			if tank.litres <= vessel.CapacityLitres {
+				candidates = append(candidates, vessel.VesselId)
+			}
+		}
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("no tank can hold %g litres", tank.litres)
+		}
+	}
+
+	var conflicts []ScheduleConflict
+	for _, vesselId := range candidates {
+		bookings, err := p.s.schedulesRepo.FindTankBookings(vesselId, tank.start, tank.end)
+		if err != nil {
+			return nil, err
+		}
+		var periods []busyPeriod
+		for _, booking := range bookings {
+			if booking.MoId != "" && (booking.MoId == plan.mo.MoId || p.replanning[booking.MoId]) {
+				continue
+			}
+			if from, to, ok := bookingPeriod(booking); ok {
+				periods = append(periods, busyPeriod{fmt.Sprintf("%q", booking.Title), from, to})
+			}
+		}
+		for _, otherPlan := range p.plans {
+			other := otherPlan.tank
+			if otherPlan.mo.MoId != plan.mo.MoId && other != nil && other.chosen == vesselId &&
+				other.start.Before(tank.end) && other.end.After(tank.start) {
+				periods = append(periods, busyPeriod{"maceration of " + otherPlan.mo.MoId, other.start, other.end})
+			}
+		}
+		if len(periods) == 0 {
+			tank.chosen = vesselId
+			return nil, nil
+		}
+
+		for _, period := range periods {
+			conflicts = append(conflicts, ScheduleConflict{
+				Type: "tank",
+				Message: fmt.Sprintf("tank %s is booked for %s from %s to %s", p.vesselName(vesselId), period.label,
+					period.from.Format("2006-01-02"), period.to.Format("2006-01-02")),
+				From:      period.from,
+				To:        period.to,
+				itemStart: tank.start,
+				itemEnd:   tank.end,
+			})
+		}
+	}
+	return conflicts, nil
+}
+
+// conflicts checks every operation and the tank booking of the plan.
+func (p *productionPlanner) conflicts(plan *moPlan) ([]ScheduleConflict, error) {
+	var conflicts []ScheduleConflict
+	for _, operation := range plan.operations {
+		found, err := p.workCenterConflicts(plan, operation)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, found...)
+	}
+	if plan.tank != nil {
+		found, err := p.tankConflicts(plan)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, found...)
+	}
+	return conflicts, nil
+}
+
+// dependencyConflicts checks that the sub-assemblies of the MO finish before
+// it starts, and that it finishes before the MO using it starts.
+func (p *productionPlanner) dependencyConflicts(plan *moPlan) ([]ScheduleConflict, error) {
+	var conflicts []ScheduleConflict
+	children, err := p.s.moRepository.FindMosByParentId(plan.mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		if moClosed(child.Status) || child.PlannedEnd == nil || !child.PlannedEnd.After(plan.start) {
+			continue
+		}
+		conflicts = append(conflicts, ScheduleConflict{
+			Type: "dependency",
+			Message: fmt.Sprintf("sub-assembly %s is only planned to finish at %s", child.MoId,
+				child.PlannedEnd.Format(planTimeLayout)),
+			From:      *child.PlannedStart,
+			To:        *child.PlannedEnd,
+			itemStart: plan.start,
+			itemEnd:   plan.end,
+		})
+	}
+
+	if plan.mo.ParentMoId != "" {
+		parent, err := p.s.moRepository.FindMoByID(plan.mo.ParentMoId)
+		if err == nil && !moClosed(parent.Status) && parent.PlannedStart != nil && parent.PlannedStart.Before(plan.end) {
+			conflicts = append(conflicts, ScheduleConflict{
+				Type: "dependency",
+				Message: fmt.Sprintf("%s needs this sub-assembly when it starts at %s", parent.MoId,
+					parent.PlannedStart.Format(planTimeLayout)),
+				From:      *parent.PlannedStart,
+				To:        *parent.PlannedEnd,
+				itemStart: plan.start,
+				itemEnd:   plan.end,
+			})
+		}
+	}
+	return conflicts, nil
+}
+
+// place finds the nearest period for the plan without conflicts: starting
+// at or after at when forward, ending at or before at when backward.
+func (p *productionPlanner) place(plan *moPlan, at time.Time, forward bool) error {
+	var minutes float64
+	for _, operation := range plan.operations {
+		minutes += operation.minutes
+	}
+	duration := time.Duration(minutes * float64(time.Minute))
+
+	for attempt := 0; attempt < maxScheduleAttempts; attempt++ {
+		if forward {
+			plan.layout(at)
+		} else {
+			start := at.Add(-duration)
+			plan.layout(start)
+			// The tank is booked by the day, so it may run past the end.
+			for plan.end.After(at) {
+				start = start.Add(-plan.end.Sub(at))
+				plan.layout(start)
+			}
+		}
+
+		conflicts, err := p.conflicts(plan)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) == 0 {
+			p.plans = append(p.plans, plan)
+			return nil
+		}
+
+		// Move by the smallest step that clears one of the conflicts, so no
+		// free slot is skipped.
+		var shift time.Duration
+		for _, conflict := range conflicts {
+			needed := conflict.To.Sub(conflict.itemStart)
+			if !forward {
+				needed = conflict.itemEnd.Sub(conflict.From)
+			}
+			if needed > 0 && (shift == 0 || needed < shift) {
+				shift = needed
+			}
+		}
+		if shift == 0 {
+			shift = time.Minute
+		}
+		if forward {
+			at = at.Add(shift)
+		} else {
+			at = at.Add(-shift)
+		}
+	}
+	return fmt.Errorf("no free slot found for %s", plan.mo.MoId)
+}
+
+// forward plans the MO as early as possible from at, after its
+// sub-assemblies, and returns when it is planned to finish.
+func (p *productionPlanner) forward(mo *entity.Mos, at time.Time) (time.Time, error) {
+	ready := at
+	children, err := p.s.moRepository.FindMosByParentId(mo.MoId)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for i := range children {
+		child := &children[i]
+		if !p.replanning[child.MoId] {
+			if !moClosed(child.Status) && child.PlannedEnd != nil && child.PlannedEnd.After(ready) {
+				ready = *child.PlannedEnd
+			}
+			continue
+		}
+		end, err := p.forward(child, at)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if end.After(ready) {
+			ready = end
+		}
+	}
+
+	plan, err := p.s.newMoPlan(mo)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := p.place(plan, ready, true); err != nil {
+		return time.Time{}, err
+	}
+	return plan.end, nil
+}
+
+// backward plans the MO to finish by due, as late as possible, and then its
+// sub-assemblies to finish before it starts. It returns the earliest start
+// of the tree.
+func (p *productionPlanner) backward(mo *entity.Mos, due time.Time) (time.Time, error) {
+	plan, err := p.s.newMoPlan(mo)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := p.place(plan, due, false); err != nil {
+		return time.Time{}, err
+	}
+
+	earliest := plan.start
+	children, err := p.s.moRepository.FindMosByParentId(mo.MoId)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for i := range children {
+		if !p.replanning[children[i].MoId] {
+			continue
+		}
+		start, err := p.backward(&children[i], plan.start)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if start.Before(earliest) {
+			earliest = start
+		}
+	}
+	return earliest, nil
+}
+
+// save writes the plans of the run: the planned periods of the MOs and their
+// work orders, and the tank bookings that moved.
+func (p *productionPlanner) save() error {
+	now := time.Now()
+	for _, plan := range p.plans {
+		start, end := plan.start, plan.end
+		plan.mo.PlannedStart, plan.mo.PlannedEnd = &start, &end
+		plan.mo.UpdatedAt = now
+		if _, err := p.s.moRepository.UpdateMoStatus(plan.mo); err != nil {
+			return errors.New("failed to update manufacture order schedule")
+		}
+
+		for _, operation := range plan.operations {
+			if operation.workOrder == nil || operation.start.IsZero() {
+				continue
+			}
+			operationStart, operationEnd := operation.start, operation.end
+			operation.workOrder.PlannedStart, operation.workOrder.PlannedEnd = &operationStart, &operationEnd
+			operation.workOrder.UpdatedAt = now
+			if _, err := p.s.workOrderRepo.UpdateWorkOrder(operation.workOrder); err != nil {
+				return err
+			}
+		}
+
+		if plan.tank != nil && plan.tank.chosen != "" {
+			if err := p.s.schedulesRepo.DeleteTankBookingsByMoId(plan.mo.MoId); err != nil {
+				return err
+			}
+			booking := entity.NewTankBooking("Maceration "+plan.mo.MoId, plan.tank.chosen, plan.mo.MoId,
+				plan.tank.start, plan.tank.end, plan.tank.litres)
+			if _, err := p.s.schedulesRepo.CreateSchedules(booking); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// openSubAssemblies returns the ids of the MO's sub-assembly MOs, at any
+// depth, whose plan can still move.
+func (s *moService) openSubAssemblies(moId string) ([]string, error) {
+	children, err := s.moRepository.FindMosByParentId(moId)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, child := range children {
+		if !reschedulable(child.Status) {
+			continue
+		}
+		ids = append(ids, child.MoId)
+		grandChildren, err := s.openSubAssemblies(child.MoId)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, grandChildren...)
+	}
+	return ids, nil
+}
+
+// ScheduleMo plans the MO together with its open sub-assembly MOs. Forward
+// scheduling starts as soon as possible from start. Backward scheduling
+// finishes the MO on the required date and starts everything as late as
+// possible; when that would mean starting in the past it plans forward from
+// now instead, and the result is flagged late.
+func (s *moService) ScheduleMo(moId, direction string, start, requiredDate *time.Time) (map[string]interface{}, error) {
+	mo, err := s.moRepository.FindMoByID(moId)
+	if err != nil {
+		return nil, errors.New("manufacture order not found")
+	}
+	if !reschedulable(mo.Status) {
+		return nil, fmt.Errorf("manufacture order is %s, it can no longer be scheduled", mo.Status)
+	}
+	if requiredDate != nil {
+		mo.RequiredDate = requiredDate
+	}
+	switch direction {
+	case "forward":
+	case "backward":
+		if mo.RequiredDate == nil {
+			return nil, errors.New("backward scheduling needs a required date")
+		}
+	default:
+		return nil, errors.New("direction must be forward or backward")
+	}
+
+	subAssemblies, err := s.openSubAssemblies(mo.MoId)
+	if err != nil {
+		return nil, err
+	}
+	moIds := append([]string{mo.MoId}, subAssemblies...)
+
+	now := time.Now()
+	from := now
+	if start != nil && start.After(now) {
+		from = *start
+	}
+	planner, err := s.newProductionPlanner(moIds)
+	if err != nil {
+		return nil, err
+	}
+	scheduled := direction
+	if direction == "backward" {
+		earliest, err := planner.backward(mo, *mo.RequiredDate)
+		if err != nil {
+			return nil, err
+		}
+		if earliest.Before(now) {
+			if planner, err = s.newProductionPlanner(moIds); err != nil {
+				return nil, err
+			}
+			scheduled = "forward"
+		}
+	}
+	if scheduled == "forward" {
+		if _, err := planner.forward(mo, from); err != nil {
+			return nil, err
+		}
+	}
+	if err := planner.save(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(planner.plans, func(i, j int) bool { return planner.plans[i].start.Before(planner.plans[j].start) })
+	mos := make([]map[string]interface{}, 0, len(planner.plans))
+	for _, plan := range planner.plans {
+		entry := map[string]interface{}{
+			"id_mo":         plan.mo.MoId,
+			"id_product":    plan.mo.ProductId,
+			"id_parent_mo":  plan.mo.ParentMoId,
+			"planned_start": plan.start,
+			"planned_end":   plan.end,
+		}
+		if plan.tank != nil {
+			entry["id_vessel"] = plan.tank.chosen
+		}
+		mos = append(mos, entry)
+	}
+
+	return map[string]interface{}{
+		"id_mo":         mo.MoId,
+		"direction":     scheduled,
+		"planned_start": mo.PlannedStart,
+		"planned_end":   mo.PlannedEnd,
+		"required_date": mo.RequiredDate,
+		"late":          mo.RequiredDate != nil && mo.PlannedEnd.After(*mo.RequiredDate),
+		"mos":           mos,
+	}, nil
+}
+
+// RescheduleMo moves the whole plan of the MO to a new start, as when its bar
+// is dragged on the Gantt chart. Operations keep their order and length and
+// the tank booking moves along. Nothing is saved when the new period clashes
+// with other work or with the MO's sub-assembly dependencies.
+func (s *moService) RescheduleMo(moId string, start time.Time) (*entity.Mos, error) {
+	mo, err := s.moRepository.FindMoByID(moId)
+	if err != nil {
+		return nil, errors.New("manufacture order not found")
+	}
+	if !reschedulable(mo.Status) {
+		return nil, fmt.Errorf("manufacture order is %s, only its remaining work orders can be moved", mo.Status)
+	}
+
+	planner, err := s.newProductionPlanner([]string{mo.MoId})
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.newMoPlan(mo)
+	if err != nil {
+		return nil, err
+	}
+	plan.layout(start)
+
+	conflicts, err := planner.conflicts(plan)
+	if err != nil {
+		return nil, err
+	}
+	dependencies, err := planner.dependencyConflicts(plan)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, dependencies...)
+	if len(conflicts) > 0 {
+		return nil, &ScheduleConflictError{Conflicts: conflicts}
+	}
+
+	planner.plans = append(planner.plans, plan)
+	if err := planner.save(); err != nil {
+		return nil, err
+	}
+	return s.moRepository.FindMoByID(mo.MoId)
+}
+
+// RescheduleWorkOrder moves a single operation. It has to stay after the
+// operation before it and before the one after it; the planned period of the
+// MO follows.
+func (s *moService) RescheduleWorkOrder(workOrderId string, start time.Time) (*entity.WorkOrder, error) {
+	workOrder, mo, err := s.findWorkOrder(workOrderId)
+	if err != nil {
+		return nil, err
+	}
+	if moClosed(mo.Status) {
+		return nil, fmt.Errorf("manufacture order is already %s", mo.Status)
+	}
+	if workOrder.Status != "pending" {
+		return nil, fmt.Errorf("work order is already %s, only pending work orders can be moved", workOrder.Status)
+	}
+
+	plan, err := s.newMoPlan(mo)
+	if err != nil {
+		return nil, err
+	}
+	oldStart, oldEnd := plan.start, plan.end
+	var moved *plannedOperation
+	for _, operation := range plan.operations {
+		if operation.workOrder != nil && operation.workOrder.WorkOrderId == workOrderId {
+			moved = operation
+		}
+	}
+	moved.start = start
+	moved.end = start.Add(time.Duration(moved.minutes * float64(time.Minute)))
+	plan.span()
+
+	var conflicts []ScheduleConflict
+	for _, other := range plan.operations {
+		if other == moved || other.start.IsZero() {
+			continue
+		}
+		if other.sequence < moved.sequence && other.end.After(moved.start) {
+			conflicts = append(conflicts, ScheduleConflict{
+				Type:    "sequence",
+				Message: fmt.Sprintf("operation %s is planned to finish at %s", other.name, other.end.Format(planTimeLayout)),
+				From:    other.start,
+				To:      other.end,
+			})
+		}
+		if other.sequence > moved.sequence && other.start.Before(moved.end) {
+			conflicts = append(conflicts, ScheduleConflict{
+				Type:    "sequence",
+				Message: fmt.Sprintf("operation %s is planned to start at %s", other.name, other.start.Format(planTimeLayout)),
+				From:    other.start,
+				To:      other.end,
+			})
+		}
+	}
+
+	planner, err := s.newProductionPlanner([]string{mo.MoId})
+	if err != nil {
+		return nil, err
+	}
+	found, err := planner.workCenterConflicts(plan, moved)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, found...)
+	if plan.start.Before(oldStart) || plan.end.After(oldEnd) {
+		dependencies, err := planner.dependencyConflicts(plan)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, dependencies...)
+	}
+	if len(conflicts) > 0 {
+		return nil, &ScheduleConflictError{Conflicts: conflicts}
+	}
+
+	planner.plans = append(planner.plans, plan)
+	if err := planner.save(); err != nil {
+		return nil, err
+	}
+	return s.workOrderRepo.FindWorkOrderByID(workOrderId)
+}
+
+// GetGantt lays out the MOs planned in [from, to): per MO with its
+// operations, tank booking and sub-assembly dependencies, and per work center
+// and tank. Late MOs and sub-assemblies planned to finish after the MO that
+// needs them are flagged.
+func (s *moService) GetGantt(from, to time.Time) (map[string]interface{}, error) {
+	if !to.After(from) {
+		return nil, errors.New("the end of the period must be after its start")
+	}
+	mos, err := s.moRepository.FindMosPlannedBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	workCenters, err := s.workCenterRepo.FindAllWorkCenters()
+	if err != nil {
+		return nil, err
+	}
+	vessels, err := s.tanks.vesselRepo.FindAllVessels()
+	if err != nil {
+		return nil, err
+	}
+
+	centerBars := make(map[string][]map[string]interface{})
+	bars := make([]map[string]interface{}, 0, len(mos))
+	for _, mo := range mos {
+		if mo.Status == "cancelled" {
+			continue
+		}
+
+		workOrders, err := s.workOrderRepo.GetWorkOrdersByMoId(mo.MoId)
+		if err != nil {
+			return nil, err
+		}
+		operations := make([]map[string]interface{}, 0, len(workOrders))
+		for _, workOrder := range workOrders {
+			if workOrder.Status == "cancelled" || workOrder.PlannedStart == nil || workOrder.PlannedEnd == nil {
+				continue
+			}
+			operations = append(operations, map[string]interface{}{
+				"id_workorder":  workOrder.WorkOrderId,
+				"name":          workOrder.Name,
+				"sequence":      workOrder.Sequence,
+				"id_workcenter": workOrder.WorkCenterId,
+				"status":        workOrder.Status,
+				"start":         *workOrder.PlannedStart,
+				"end":           *workOrder.PlannedEnd,
+			})
+			centerBars[workOrder.WorkCenterId] = append(centerBars[workOrder.WorkCenterId], map[string]interface{}{
+				"id_mo":        mo.MoId,
+				"id_workorder": workOrder.WorkOrderId,
+				"name":         workOrder.Name,
+				"status":       workOrder.Status,
+				"start":        *workOrder.PlannedStart,
+				"end":          *workOrder.PlannedEnd,
+			})
+		}
+
+		var tank map[string]interface{}
+		bookings, err := s.schedulesRepo.FindTankBookingsByMoId(mo.MoId)
+		if err != nil {
+			return nil, err
+		}
+		if len(bookings) > 0 {
+			if start, end, ok := bookingPeriod(bookings[0]); ok {
+				tank = map[string]interface{}{"id_vessel": bookings[0].VesselId, "start": start, "end": end}
+			}
+		}
+
+		children, err := s.moRepository.FindMosByParentId(mo.MoId)
+		if err != nil {
+			return nil, err
+		}
+		dependsOn := make([]string, 0, len(children))
+		issues := make([]string, 0)
+		for _, child := range children {
+			if child.Status == "cancelled" {
+				continue
+			}
+			dependsOn = append(dependsOn, child.MoId)
+			if moClosed(child.Status) {
+				continue
+			}
+			if child.PlannedEnd == nil {
+				issues = append(issues, fmt.Sprintf("sub-assembly %s is not planned yet", child.MoId))
+			} else if child.PlannedEnd.After(*mo.PlannedStart) {
+				issues = append(issues, fmt.Sprintf("sub-assembly %s is only planned to finish at %s", child.MoId,
+					child.PlannedEnd.Format(planTimeLayout)))
+			}
+		}
+
+		bars = append(bars, map[string]interface{}{
+			"id_mo":             mo.MoId,
+			"id_product":        mo.ProductId,
+			"qtytoproduce":      mo.Qtytoproduce,
+			"status":            mo.Status,
+			"id_parent_mo":      mo.ParentMoId,
+			"planned_start":     mo.PlannedStart,
+			"planned_end":       mo.PlannedEnd,
+			"required_date":     mo.RequiredDate,
+			"late":              mo.RequiredDate != nil && mo.PlannedEnd.After(*mo.RequiredDate),
+			"depends_on":        dependsOn,
+			"dependency_issues": issues,
+			"operations":        operations,
+			"tank":              tank,
+		})
+	}
+
+	centerLanes := make([]map[string]interface{}, 0, len(workCenters))
+	for _, workCenter := range workCenters {
+		lane := centerBars[workCenter.WorkCenterId]
+		if lane == nil {
+			lane = []map[string]interface{}{}
+		}
+		centerLanes = append(centerLanes, map[string]interface{}{
+			"id_workcenter": workCenter.WorkCenterId,
+			"name":          workCenter.Name,
+			"capacity":      workCenter.Capacity,
+			"bars":          lane,
+		})
+	}
+
+	tankLanes := make([]map[string]interface{}, 0, len(vessels))
+	for _, vessel := range vessels {
+		bookings, err := s.schedulesRepo.FindTankBookings(vessel.VesselId, from, to)
+		if err != nil {
+			return nil, err
+		}
+		lane := make([]map[string]interface{}, 0, len(bookings))
+		for _, booking := range bookings {
+			start, end, ok := bookingPeriod(booking)
+			if !ok {
+				continue
+			}
+			lane = append(lane, map[string]interface{}{
+				"id_schedules": booking.SchedulesId,
+				"title":        booking.Title,
+				"id_mo":        booking.MoId,
+				"start":        start,
+				"end":          end,
+			})
+		}
+		tankLanes = append(tankLanes, map[string]interface{}{
+			"id_vessel":       vessel.VesselId,
+			"name":            vessel.Name,
+			"capacity_litres": vessel.CapacityLitres,
+			"bars":            lane,
+		})
+	}
+
+	return map[string]interface{}{
+		"from":         from,
+		"to":           to,
+		"mos":          bars,
+		"work_centers": centerLanes,
+		"tanks":        tankLanes,
+	}, nil
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/email"
+)
+
+// approvalTolerance is how far an RFQ amount may drift, in currency units,
+// and still be the amount that was approved.
+const approvalTolerance = 0.005
+
+// PoApprovalRequiredError is returned when an RFQ cannot become a purchase
+// order yet. Approvals holds the requests still waiting for a decision.
+type PoApprovalRequiredError struct {
+	RfqId     string
+	Approvals []entity.RfqApproval
+}
+
+func (e *PoApprovalRequiredError) Error() string {
+	approvers := make([]string, 0, len(e.Approvals))
+	for _, approval := range e.Approvals {
+		approvers = append(approvers, fmt.Sprintf("%s (%s)", approval.ApproverName, approval.RfqApprovalId))
+	}
+	return fmt.Sprintf("RFQ %s awaits approval from %s", e.RfqId, strings.Join(approvers, ", "))
+}
+
+type PoApprovalService interface {
+	CreateRule(rule *entity.PoApprovalRule) (*entity.PoApprovalRule, error)
+	UpdateRule(rule *entity.PoApprovalRule) (*entity.PoApprovalRule, error)
+	DeleteRule(ruleId string) (bool, error)
+	FindAllRules() ([]entity.PoApprovalRule, error)
+	RequireApproval(rfq *entity.Rfqs) error
+	DecideApproval(approvalId string, approved bool, approver *entity.User, note string) (*entity.RfqApproval, error)
+	GetRfqApprovals(rfqId string) (map[string]interface{}, error)
+	FindPendingApprovals() ([]entity.RfqApproval, error)
+}
+
+type poApprovalService struct {
+	approvalRepo  repository.PoApprovalRepository
+	rfqRepository repository.RfqRepository
+	materialRepo  repository.MaterialRepository
+	productRepo   repository.ProductRepository
+	taxes         TaxService
+	emailSender   *email.EmailSender
+}
+
+func NewPoApprovalService(approvalRepo repository.PoApprovalRepository, rfqRepository repository.RfqRepository,
+	materialRepo repository.MaterialRepository, productRepo repository.ProductRepository, taxes TaxService,
+	emailSender *email.EmailSender) *poApprovalService {
+	return &poApprovalService{
+		approvalRepo:  approvalRepo,
+		rfqRepository: rfqRepository,
+		materialRepo:  materialRepo,
+		productRepo:   productRepo,
+		taxes:         taxes,
+		emailSender:   emailSender,
+	}
+}
+
+// rfqAmount is what an RFQ commits to spend before tax, as the pricing engine
+// works it out from the lines given, which need not be saved yet.
+func (s *poApprovalService) rfqAmount(rfq *entity.Rfqs) (float64, error) {
+	priced := *rfq
+	priced.Products = append([]entity.RfqsProduct(nil), rfq.Products...)
+	if err := s.taxes.PriceRfq(&priced); err != nil {
+		return 0, err
+	}
+	return priced.AmountUntaxed, nil
+}
+
+func validateRule(rule *entity.PoApprovalRule) error {
+	if rule.Name == "" || rule.ApproverName == "" || rule.ApproverEmail == "" {
+		return errors.New("a rule needs a name, an approver name and an approver email")
+	}
+	if rule.MinAmount < 0 {
+		return errors.New("minimum amount cannot be negative")
+	}
+	return nil
+}
+
+func (s *poApprovalService) CreateRule(rule *entity.PoApprovalRule) (*entity.PoApprovalRule, error) {
+	if err := validateRule(rule); err != nil {
+		return nil, err
+	}
+	lastId, err := s.approvalRepo.GetLastRuleId()
+	if err != nil {
+		return nil, err
+	}
+	return s.approvalRepo.CreateRule(entity.NewPoApprovalRule(lastId, *rule))
+}
+
+func (s *poApprovalService) UpdateRule(rule *entity.PoApprovalRule) (*entity.PoApprovalRule, error) {
+	existing, err := s.approvalRepo.FindRuleByID(rule.ApprovalRuleId)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("approval rule with id %s not found", rule.ApprovalRuleId)
+	}
+	if err := validateRule(rule); err != nil {
+		return nil, err
+	}
+
+	existing.Name = rule.Name
+	existing.MinAmount = rule.MinAmount
+	existing.VendorId = rule.VendorId
+	existing.Category = rule.Category
+	existing.ApproverName = rule.ApproverName
+	existing.ApproverEmail = rule.ApproverEmail
+	existing.Active = rule.Active
+	existing.UpdatedAt = time.Now()
+	return s.approvalRepo.UpdateRule(existing)
+}
+
+func (s *poApprovalService) DeleteRule(ruleId string) (bool, error) {
+	rule, err := s.approvalRepo.FindRuleByID(ruleId)
+	if err != nil {
+		return false, err
+	}
+	if rule == nil {
+		return false, fmt.Errorf("approval rule with id %s not found", ruleId)
+	}
+	return s.approvalRepo.DeleteRule(rule)
+}
+
+func (s *poApprovalService) FindAllRules() ([]entity.PoApprovalRule, error) {
+	return s.approvalRepo.FindAllRules()
+}
+
+func (s *poApprovalService) FindPendingApprovals() ([]entity.RfqApproval, error) {
+	return s.approvalRepo.FindPendingApprovals()
+}
+
+// applicableRules returns the active rules an RFQ of the given amount falls
+// under.
+func (s *poApprovalService) applicableRules(rfq *entity.Rfqs, amount float64) ([]entity.PoApprovalRule, error) {
+	rules, err := s.approvalRepo.FindActiveRules()
+	if err != nil {
+		return nil, err
+	}
+
+	var categories map[string]bool
+	var applicable []entity.PoApprovalRule
+	for _, rule := range rules {
+		if amount < rule.MinAmount || (rule.VendorId != "" && rule.VendorId != rfq.VendorId) {
+			continue
+		}
+		if rule.Category != "" {
+			if categories == nil {
+				categories = make(map[string]bool)
+				for _, line := range rfq.Products {
+					if line.LineType == "material" {
+						if material, err := s.materialRepo.FindMaterialByID(line.MaterialId); err == nil {
+							categories[strings.ToLower(material.Materialcategory)] = true
+						}
+					} else if product, err := s.productRepo.FindProductByID(line.ProductId); err == nil {
+						categories[strings.ToLower(product.Productcategory)] = true
+					}
+				}
+			}
+			if !categories[strings.ToLower(rule.Category)] {
+				continue
+			}
+		}
+		applicable = append(applicable, rule)
+	}
+	return applicable, nil
+}
+
+// RequireApproval checks that every rule the RFQ falls under has approved its
+// current amount. Missing approvals are requested from the approvers, and the
+// RFQ is held with a PoApprovalRequiredError until they decide. An approval
+// covers the amount approved or less; anything more is asked again.
+func (s *poApprovalService) RequireApproval(rfq *entity.Rfqs) error {
+	amount, err := s.rfqAmount(rfq)
+	if err != nil {
+		return err
+	}
+	rules, err := s.applicableRules(rfq, amount)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	history, err := s.approvalRepo.FindApprovalsByRfqId(rfq.RfqId)
+	if err != nil {
+		return err
+	}
+	latest := make(map[string]*entity.RfqApproval)
+	for i := range history {
+		if history[i].Status != "superseded" {
+			latest[history[i].ApprovalRuleId] = &history[i]
+		}
+	}
+
+	var waiting []entity.RfqApproval
+	for _, rule := range rules {
+		if last := latest[rule.ApprovalRuleId]; last != nil {
+			sameAmount := math.Abs(last.Amount-amount) <= approvalTolerance
+			switch last.Status {
+			case "approved":
+				if amount <= last.Amount+approvalTolerance {
+					continue
+				}
+			case "rejected":
+				if sameAmount {
+					return fmt.Errorf("%s rejected RFQ %s: %s", last.DecidedBy, rfq.RfqId, last.Note)
+				}
+			case "pending":
+				if sameAmount {
+					waiting = append(waiting, *last)
+					continue
+				}
+				last.Status = "superseded"
+				last.UpdatedAt = time.Now()
+				if _, err := s.approvalRepo.UpdateApproval(last); err != nil {
+					return err
+				}
+			}
+		}
+
+		lastId, err := s.approvalRepo.GetLastApprovalId()
+		if err != nil {
+			return err
+		}
+		approval, err := s.approvalRepo.CreateApproval(entity.NewRfqApproval(lastId, rfq.RfqId, rule, amount))
+		if err != nil {
+			return err
+		}
+		// The request stands even when the mail does not go out; the
+		// approver can still find it among the pending approvals.
+		if err := s.emailSender.SendPoApprovalEmail(rule.ApproverEmail, rule.ApproverName, approval.RfqApprovalId,
+			rfq.RfqId, rfq.VendorId, rule.Name, amount); err != nil {
+			log.Printf("Error sending approval request %s: %v", approval.RfqApprovalId, err)
+		}
+		waiting = append(waiting, *approval)
+	}
+
+	if len(waiting) > 0 {
+		return &PoApprovalRequiredError{RfqId: rfq.RfqId, Approvals: waiting}
+	}
+	return nil
+}
+
+// DecideApproval approves or rejects a pending request on behalf of the
+// signed-in approver, who must be the one the rule names. Approving does not
+// move the RFQ; that is still done through the status change, which now
+// goes through.
+func (s *poApprovalService) DecideApproval(approvalId string, approved bool, approver *entity.User, note string) (*entity.RfqApproval, error) {
+	if approver == nil {
+		return nil, errors.New("sign in to decide on approvals")
+	}
+	approval, err := s.approvalRepo.FindApprovalByID(approvalId)
+	if err != nil {
+		return nil, err
+	}
+	if approval == nil {
+		return nil, fmt.Errorf("approval with id %s not found", approvalId)
+	}
+	if approval.Status != "pending" {
+		return nil, fmt.Errorf("approval is already %s", approval.Status)
+	}
+	if !strings.EqualFold(strings.TrimSpace(approver.Email), strings.TrimSpace(approval.ApproverEmail)) {
+		return nil, fmt.Errorf("approval %s is for %s to decide", approval.RfqApprovalId, approval.ApproverName)
+	}
+	if !approved && note == "" {
+		return nil, errors.New("give a reason for the rejection")
+	}
+
+	now := time.Now()
+	approval.Status = "rejected"
+	if approved {
+		approval.Status = "approved"
+	}
+	approval.DecidedBy = approver.Email
+	approval.Note = note
+	approval.DecidedAt = &now
+	approval.UpdatedAt = now
+	return s.approvalRepo.UpdateApproval(approval)
+}
+
+// GetRfqApprovals shows the rules an RFQ currently falls under next to its
+// full approval history.
+func (s *poApprovalService) GetRfqApprovals(rfqId string) (map[string]interface{}, error) {
+	rfq, err := s.rfqRepository.GetRfqById(rfqId)
+	if err != nil {
+		return nil, err
+	}
+	if rfq == nil {
+		return nil, fmt.Errorf("RFQ with id %s not found", rfqId)
+	}
+	amount, err := s.rfqAmount(rfq)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.applicableRules(rfq, amount)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.approvalRepo.FindApprovalsByRfqId(rfqId)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"id_rfq":         rfq.RfqId,
+		"status":         rfq.Status,
+		"amount":         amount,
+		"required_rules": rules,
+		"history":        history,
+	}, nil
+}
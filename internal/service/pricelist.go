@@ -0,0 +1,344 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
+)
+
+type PriceListService interface {
+	CreatePrice(price *entity.VendorPrice) (*entity.VendorPrice, error)
+	UpdatePrice(price *entity.VendorPrice) (*entity.VendorPrice, error)
+	DeletePrice(priceId string) (bool, error)
+	FindPrices(vendorId, itemId string) ([]entity.VendorPrice, error)
+	GetPriceHistory(vendorId, itemId string) ([]entity.VendorPrice, error)
+	QuotePrice(vendorId, itemId string, qty float64, day time.Time) (*entity.VendorPrice, error)
+	PriceInEffect(vendorId, itemId string, qty float64, at time.Time) (*entity.VendorPrice, error)
+	PriceRfqLines(rfq *entity.Rfqs) ([]string, error)
+	UpdateFromPurchaseOrder(rfqId string) ([]entity.VendorPrice, error)
+}
+
+const defaultCurrency = "IDR"
+
+type priceListService struct {
+	priceListRepo     repository.PriceListRepository
+	vendorRepository  repository.VendorRepository
+	materialRepo      repository.MaterialRepository
+	productRepository repository.ProductRepository
+	rfqRepository     repository.RfqRepository
+}
+
+func NewPriceListService(priceListRepo repository.PriceListRepository, vendorRepository repository.VendorRepository,
+	materialRepo repository.MaterialRepository, productRepository repository.ProductRepository,
+	rfqRepository repository.RfqRepository) *priceListService {
+	return &priceListService{
+		priceListRepo:     priceListRepo,
+		vendorRepository:  vendorRepository,
+		materialRepo:      materialRepo,
+		productRepository: productRepository,
+		rfqRepository:     rfqRepository,
+	}
+}
+
+// itemName looks the item up as a material first and then as a product.
+func (s *priceListService) itemName(itemId string) (string, error) {
+	if material, err := s.materialRepo.FindMaterialByID(itemId); err == nil && material != nil {
+		return material.Materialname, nil
+	}
+	if product, err := s.productRepository.FindProductByID(itemId); err == nil && product != nil {
+		return product.Productname, nil
+	}
+	return "", fmt.Errorf("no material or product with id %s", itemId)
+}
+
+func validateVendorPrice(price *entity.VendorPrice) error {
+	if price.MinQty < 0 {
+		return errors.New("minimum quantity cannot be negative")
+	}
+	if price.UnitPrice < 0 {
+		return errors.New("unit price cannot be negative")
+	}
+	if price.LeadTimeDays < 0 {
+		return errors.New("lead time cannot be negative")
+	}
+	if price.ValidFrom != nil && price.ValidTo != nil && price.ValidTo.Before(*price.ValidFrom) {
+		return errors.New("valid to cannot be before valid from")
+	}
+	price.Currency = strings.ToUpper(strings.TrimSpace(price.Currency))
+	if price.Currency == "" {
+		price.Currency = defaultCurrency
+	}
+	if len(price.Currency) != 3 {
+		return fmt.Errorf("currency %s is not a three-letter code", price.Currency)
+	}
+	return nil
+}
+
+func (s *priceListService) CreatePrice(price *entity.VendorPrice) (*entity.VendorPrice, error) {
+	if _, err := s.vendorRepository.FindVendorByID(price.VendorId); err != nil {
+		return nil, fmt.Errorf("vendor with id %s not found", price.VendorId)
+	}
+	name, err := s.itemName(price.ItemId)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateVendorPrice(price); err != nil {
+		return nil, err
+	}
+
+	current, err := s.priceListRepo.FindCurrentPrices(price.VendorId, price.ItemId)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range current {
+		if existing.MinQty == price.MinQty {
+			return nil, fmt.Errorf("vendor already has a price from %g for this item (%s), update that one instead",
+				existing.MinQty, existing.VendorPriceId)
+		}
+	}
+
+	lastId, err := s.priceListRepo.GetLastVendorPriceId()
+	if err != nil {
+		return nil, err
+	}
+	price.ItemName = name
+	price.Source = "manual"
+	price.RfqId = ""
+	return s.priceListRepo.CreateVendorPrice(entity.NewVendorPrice(lastId, *price))
+}
+
+// supersede replaces a current price with a new one and links the two, so
+// the old price stays in the history.
+func (s *priceListService) supersede(existing *entity.VendorPrice, replacement entity.VendorPrice) (*entity.VendorPrice, error) {
+	lastId, err := s.priceListRepo.GetLastVendorPriceId()
+	if err != nil {
+		return nil, err
+	}
+	replacement.VendorId = existing.VendorId
+	replacement.ItemId = existing.ItemId
+	replacement.ItemName = existing.ItemName
+	saved, err := s.priceListRepo.CreateVendorPrice(entity.NewVendorPrice(lastId, replacement))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	existing.SupersededBy = saved.VendorPriceId
+	existing.SupersededAt = &now
+	existing.UpdatedAt = now
+	if _, err := s.priceListRepo.UpdateVendorPrice(existing); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// UpdatePrice changes a price break. The old entry is kept as history.
+func (s *priceListService) UpdatePrice(price *entity.VendorPrice) (*entity.VendorPrice, error) {
+	existing, err := s.priceListRepo.FindVendorPriceByID(price.VendorPriceId)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, errors.New("vendor price not found")
+	}
+	if existing.SupersededBy != "" {
+		return nil, fmt.Errorf("price was already replaced by %s", existing.SupersededBy)
+	}
+	if err := validateVendorPrice(price); err != nil {
+		return nil, err
+	}
+
+	price.Source = "manual"
+	price.RfqId = ""
+	return s.supersede(existing, *price)
+}
+
+func (s *priceListService) DeletePrice(priceId string) (bool, error) {
+	price, err := s.priceListRepo.FindVendorPriceByID(priceId)
+	if err != nil {
+		return false, err
+	}
+	if price == nil {
+		return false, errors.New("vendor price not found")
+	}
+	return s.priceListRepo.DeleteVendorPrice(price)
+}
+
+func (s *priceListService) FindPrices(vendorId, itemId string) ([]entity.VendorPrice, error) {
+	return s.priceListRepo.FindCurrentPrices(vendorId, itemId)
+}
+
+func (s *priceListService) GetPriceHistory(vendorId, itemId string) ([]entity.VendorPrice, error) {
+	return s.priceListRepo.FindPriceHistory(vendorId, itemId)
+}
+
+// applicableBreak returns the price break for qty from breaks sorted by
+// minimum quantity, or nil when qty is below the smallest one.
+func applicableBreak(breaks []entity.VendorPrice, qty float64) *entity.VendorPrice {
+	var applicable *entity.VendorPrice
+	for i := range breaks {
+		if breaks[i].MinQty <= qty+receiptTolerance {
+			applicable = &breaks[i]
+		}
+	}
+	return applicable
+}
+
+// QuotePrice returns the vendor's price for qty of the item on the given day,
+// or nil when the vendor has no valid price for it. Ordering less than the
+// vendor's minimum quantity is an error.
+func (s *priceListService) QuotePrice(vendorId, itemId string, qty float64, day time.Time) (*entity.VendorPrice, error) {
+	breaks, err := s.priceListRepo.FindValidPrices(vendorId, itemId, day)
+	if err != nil {
+		return nil, err
+	}
+	if len(breaks) == 0 {
+		return nil, nil
+	}
+	price := applicableBreak(breaks, qty)
+	if price == nil {
+		return nil, fmt.Errorf("%s has a minimum order quantity of %g from vendor %s", breaks[0].ItemName, breaks[0].MinQty, vendorId)
+	}
+	return price, nil
+}
+
+// PriceInEffect returns the vendor's price for qty of the item as the price
+// list stood at the given moment, superseded prices included, or nil when
+// there was none.
+func (s *priceListService) PriceInEffect(vendorId, itemId string, qty float64, at time.Time) (*entity.VendorPrice, error) {
+	breaks, err := s.priceListRepo.FindPricesInEffect(vendorId, itemId, at)
+	if err != nil {
+		return nil, err
+	}
+	return applicableBreak(breaks, qty), nil
+}
+
+// PriceRfqLines fills in the unit price of RFQ lines that have none from the
+// vendor's price list, as of the order date; prices already on a line are
+// kept. RFQs are in the default currency, so list prices in any other are
+// not used. Lines the list cannot price, such as those below the vendor's
+// minimum quantity, are left for the buyer and come back as warnings.
+func (s *priceListService) PriceRfqLines(rfq *entity.Rfqs) ([]string, error) {
+	day, ok := parseDocumentDate(rfq.OrderDate)
+	if !ok {
+		day = time.Now()
+	}
+	var warnings []string
+	for i := range rfq.Products {
+		line := &rfq.Products[i]
+		if strings.TrimSpace(line.UnitPrice) != "" {
+			continue
+		}
+		qty, err := strconv.ParseFloat(line.Quantity, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity for %s: %v", line.ProductName, err)
+		}
+		breaks, err := s.priceListRepo.FindValidPrices(rfq.VendorId, line.ItemId(), day)
+		if err != nil {
+			return nil, err
+		}
+		if len(breaks) == 0 {
+			continue
+		}
+
+		var usable []entity.VendorPrice
+		for _, price := range breaks {
+			if price.Currency == defaultCurrency {
+				usable = append(usable, price)
+			}
+		}
+		if len(usable) == 0 {
+			warnings = append(warnings, fmt.Sprintf("%s: vendor %s lists it in %s, not %s; enter the price",
+				line.ProductName, rfq.VendorId, breaks[0].Currency, defaultCurrency))
+			continue
+		}
+		price := applicableBreak(usable, qty)
+		if price == nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %g is below the minimum order quantity of %g from vendor %s; enter the price",
+				line.ProductName, qty, usable[0].MinQty, rfq.VendorId))
+			continue
+		}
+		line.UnitPrice = strconv.FormatFloat(price.UnitPrice, 'f', -1, 64)
+	}
+	return warnings, nil
+}
+
+// UpdateFromPurchaseOrder writes the prices agreed on a confirmed purchase
+// order back into the vendor's price list. Breaks whose price changed are
+// superseded; items without a price list entry get one from zero quantity.
+func (s *priceListService) UpdateFromPurchaseOrder(rfqId string) ([]entity.VendorPrice, error) {
+	rfq, err := s.rfqRepository.GetRfqById(rfqId)
+	if err != nil {
+		return nil, err
+	}
+	if rfq == nil {
+		return nil, fmt.Errorf("RFQ with id %s not found", rfqId)
+	}
+	if !billableRfqStatuses[rfq.Status] {
+		return nil, fmt.Errorf("RFQ is %s, only confirmed purchase orders update the price list", rfq.Status)
+	}
+
+	today := startOfDay(time.Now())
+	updated := make([]entity.VendorPrice, 0)
+	for _, line := range rfq.Products {
+		unitPrice, err := strconv.ParseFloat(line.UnitPrice, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unit price for %s: %v", line.ProductName, err)
+		}
+		// The list holds what the vendor actually charges, after the
+		// discount agreed on the order.
+		unitPrice *= 1 - line.Discount/100
+		qty, err := strconv.ParseFloat(line.Quantity, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity for %s: %v", line.ProductName, err)
+		}
+
+		breaks, err := s.priceListRepo.FindValidPrices(rfq.VendorId, line.ItemId(), today)
+		if err != nil {
+			return nil, err
+		}
+		existing := applicableBreak(breaks, qty)
+		if existing != nil && math.Abs(existing.UnitPrice-unitPrice) < receiptTolerance {
+			continue
+		}
+
+		var saved *entity.VendorPrice
+		if existing != nil {
+			// The new price applies from today; the old one covers the
+			// days before.
+			replacement := *existing
+			replacement.UnitPrice = unitPrice
+			replacement.ValidFrom = &today
+			replacement.Source = "purchase order"
+			replacement.RfqId = rfq.RfqId
+			saved, err = s.supersede(existing, replacement)
+		} else {
+			var lastId string
+			lastId, err = s.priceListRepo.GetLastVendorPriceId()
+			if err != nil {
+				return nil, err
+			}
+			saved, err = s.priceListRepo.CreateVendorPrice(entity.NewVendorPrice(lastId, entity.VendorPrice{
+				VendorId:  rfq.VendorId,
+				ItemId:    line.ItemId(),
+				ItemName:  line.ProductName,
+				UnitPrice: unitPrice,
+				Currency:  defaultCurrency,
+				ValidFrom: &today,
+				Source:    "purchase order",
+				RfqId:     rfq.RfqId,
+			}))
+		}
+		if err != nil {
+			return nil, err
+		}
+		updated = append(updated, *saved)
+	}
+	return updated, nil
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
+)
+
+// ReturnLine is the quantity of one receipt line sent back, and why.
+type ReturnLine struct {
+	ReceiptLineId string
+	Quantity      float64
+	Reason        string
+	ReasonNote    string
+}
+
+// returnReasons are why goods go back to a vendor. They are kept apart on
+// the vendor scorecard.
+var returnReasons = map[string]bool{
+	"quality_failure": true,
+	"damaged":         true,
+	"wrong_item":      true,
+	"over_delivery":   true,
+	"expired":         true,
+	"other":           true,
+}
+
+type PurchaseReturnService interface {
+	CreateReturn(receiptId, returnedBy, note string, lines []ReturnLine) (*entity.PurchaseReturn, error)
+	GetReturn(returnId string) (*entity.PurchaseReturn, error)
+	FindReturns(vendorId string) ([]entity.PurchaseReturn, error)
+	FindCreditNotes(vendorId string, openOnly bool) ([]entity.VendorCreditNote, error)
+	ApplyCreditNote(creditNoteId string) (*entity.VendorCreditNote, error)
+}
+
+type purchaseReturnService struct {
+	returnRepo        repository.PurchaseReturnRepository
+	receiptRepo       repository.RfqReceiptRepository
+	rfqRepository     repository.RfqRepository
+	billrfqRepository repository.BillrfqRepository
+	billrfqService    BillrfqService
+	qualityService    QualityService
+}
+
+func NewPurchaseReturnService(returnRepo repository.PurchaseReturnRepository, receiptRepo repository.RfqReceiptRepository,
+	rfqRepository repository.RfqRepository, billrfqRepository repository.BillrfqRepository,
+	billrfqService BillrfqService, qualityService QualityService) *purchaseReturnService {
+	return &purchaseReturnService{
+		returnRepo:        returnRepo,
+		receiptRepo:       receiptRepo,
+		rfqRepository:     rfqRepository,
+		billrfqRepository: billrfqRepository,
+		billrfqService:    billrfqService,
+		qualityService:    qualityService,
+	}
+}
+
+// returnUnitPrice is what one unit of an RFQ line cost before tax, after its
+// discount, and the tax paid on it. The vendor credits both.
+func returnUnitPrice(line entity.RfqsProduct) (float64, float64, error) {
+	ordered, err := strconv.ParseFloat(line.Quantity, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid quantity for %s: %v", line.ProductName, err)
+	}
+	if ordered <= 0 || line.Subtotal == "" {
+		return 0, 0, nil
+	}
+	subtotal, err := strconv.ParseFloat(line.Subtotal, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid subtotal for %s: %v", line.ProductName, err)
+	}
+	return subtotal / ordered, line.TaxAmount / ordered, nil
+}
+
+// CreateReturn sends goods of a receipt back to the vendor. Each line comes
+// out of quarantine or stock, and the value returned becomes a credit note
+// that is applied straight away to the vendor's open bills. The RFQ keeps
+// its received quantities: the goods did arrive, and what was billed for
+// them is settled by the credit.
+func (s *purchaseReturnService) CreateReturn(receiptId, returnedBy, note string, lines []ReturnLine) (*entity.PurchaseReturn, error) {
+	receipt, err := s.receiptRepo.FindReceiptByID(receiptId)
+	if err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, fmt.Errorf("receipt with id %s not found", receiptId)
+	}
+	if receipt.Type != "receipt" {
+		return nil, errors.New("only goods that were received can be returned")
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("a return needs at least one line")
+	}
+	rfq, err := s.rfqRepository.GetRfqById(receipt.RfqId)
+	if err != nil {
+		return nil, err
+	}
+	if rfq == nil {
+		return nil, fmt.Errorf("RFQ with id %s not found", receipt.RfqId)
+	}
+
+	receiptLines := make(map[string]entity.RfqReceiptLine)
+	for _, line := range receipt.Lines {
+		receiptLines[line.ReceiptLineId] = line
+	}
+	products := make(map[string]entity.RfqsProduct)
+	for _, product := range rfq.Products {
+		products[product.RfqsProductId] = product
+	}
+
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		receiptLine, ok := receiptLines[line.ReceiptLineId]
+		if !ok {
+			return nil, fmt.Errorf("line %s does not belong to receipt %s", line.ReceiptLineId, receiptId)
+		}
+		if seen[line.ReceiptLineId] {
+			return nil, fmt.Errorf("line %s is returned more than once", line.ReceiptLineId)
+		}
+		seen[line.ReceiptLineId] = true
+		if _, ok := products[receiptLine.RfqsProductId]; !ok {
+			return nil, fmt.Errorf("RFQ line of %s no longer exists", receiptLine.ProductName)
+		}
+		if !returnReasons[line.Reason] {
+			return nil, fmt.Errorf("unknown return reason %q", line.Reason)
+		}
+		if line.Quantity <= 0 {
+			return nil, fmt.Errorf("returned quantity of %s must be greater than zero", receiptLine.ProductName)
+		}
+		returned, err := s.returnRepo.SumReturnedQty(line.ReceiptLineId)
+		if err != nil {
+			return nil, err
+		}
+		if left := receiptLine.Quantity - returned; line.Quantity > left+receiptTolerance {
+			return nil, fmt.Errorf("only %g of %s received on this line can still be returned", math.Max(left, 0), receiptLine.ProductName)
+		}
+	}
+
+	lastId, err := s.returnRepo.GetLastReturnId()
+	if err != nil {
+		return nil, err
+	}
+	purchaseReturn, err := s.returnRepo.CreateReturn(entity.NewPurchaseReturn(lastId, *receipt, returnedBy, note))
+	if err != nil {
+		return nil, err
+	}
+
+	credit := 0.0
+	for _, line := range lines {
+		receiptLine := receiptLines[line.ReceiptLineId]
+		product := products[receiptLine.RfqsProductId]
+		unitPrice, unitTax, err := returnUnitPrice(product)
+		if err != nil {
+			return nil, err
+		}
+		subtotal, tax := roundCost(unitPrice*line.Quantity), roundCost(unitTax*line.Quantity)
+		source, err := s.qualityService.ReturnReceiptLine(receiptLine, product.LineType, line.Quantity, returnedBy,
+			fmt.Sprintf("returned to vendor on %s", purchaseReturn.ReturnId))
+		if err != nil {
+			return nil, err
+		}
+
+		lastLineId, err := s.returnRepo.GetLastReturnLineId()
+		if err != nil {
+			return nil, err
+		}
+		returnLine, err := s.returnRepo.CreateReturnLine(entity.NewPurchaseReturnLine(lastLineId, purchaseReturn.ReturnId, entity.PurchaseReturnLine{
+			ReceiptLineId: receiptLine.ReceiptLineId,
+			RfqsProductId: receiptLine.RfqsProductId,
+			ProductId:     receiptLine.ProductId,
+			ProductName:   receiptLine.ProductName,
+			Quantity:      line.Quantity,
+			UnitPrice:     unitPrice,
+			TaxAmount:     tax,
+			Amount:        roundCost(subtotal + tax),
+			Reason:        line.Reason,
+			ReasonNote:    line.ReasonNote,
+			StockSource:   source,
+			InspectionId:  receiptLine.InspectionId,
+		}))
+		if err != nil {
+			return nil, err
+		}
+		credit += returnLine.Amount
+		purchaseReturn.Lines = append(purchaseReturn.Lines, *returnLine)
+	}
+
+	if credit = roundCost(credit); credit > 0 {
+		lastCreditId, err := s.returnRepo.GetLastCreditNoteId()
+		if err != nil {
+			return nil, err
+		}
+		creditNote, err := s.returnRepo.CreateCreditNote(entity.NewVendorCreditNote(lastCreditId, purchaseReturn.VendorId, purchaseReturn.ReturnId, credit))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.applyCredit(creditNote); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetReturn(purchaseReturn.ReturnId)
+}
+
+func (s *purchaseReturnService) GetReturn(returnId string) (*entity.PurchaseReturn, error) {
+	purchaseReturn, err := s.returnRepo.FindReturnByID(returnId)
+	if err != nil {
+		return nil, err
+	}
+	if purchaseReturn == nil {
+		return nil, fmt.Errorf("return with id %s not found", returnId)
+	}
+	return purchaseReturn, nil
+}
+
+func (s *purchaseReturnService) FindReturns(vendorId string) ([]entity.PurchaseReturn, error) {
+	return s.returnRepo.FindReturns(vendorId)
+}
+
+func (s *purchaseReturnService) FindCreditNotes(vendorId string, openOnly bool) ([]entity.VendorCreditNote, error) {
+	return s.returnRepo.FindCreditNotes(vendorId, openOnly)
+}
+
+// ApplyCreditNote offsets what is left of a credit note against the vendor's
+// open bills, for credit that found no bill when it was issued.
+func (s *purchaseReturnService) ApplyCreditNote(creditNoteId string) (*entity.VendorCreditNote, error) {
+	creditNote, err := s.returnRepo.FindCreditNoteByID(creditNoteId)
+	if err != nil {
+		return nil, err
+	}
+	if creditNote == nil {
+		return nil, fmt.Errorf("credit note with id %s not found", creditNoteId)
+	}
+	if creditNote.Status == "applied" {
+		return nil, errors.New("credit note has already been applied in full")
+	}
+	if _, err := s.applyCredit(creditNote); err != nil {
+		return nil, err
+	}
+	return s.returnRepo.FindCreditNoteByID(creditNoteId)
+}
+
+// applyCredit spends the credit note's balance on the vendor's open bills,
+// the one due first first. Credit left over stays open for later bills.
+func (s *purchaseReturnService) applyCredit(creditNote *entity.VendorCreditNote) (*entity.VendorCreditNote, error) {
+	bills, err := s.billrfqRepository.FindOpenBills(creditNote.VendorId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, bill := range bills {
+		remaining := roundCost(creditNote.Amount - creditNote.AmountApplied)
+		if remaining < paymentTolerance {
+			break
+		}
+		amount := math.Min(remaining, billOutstanding(bill))
+		if amount < paymentTolerance {
+			continue
+		}
+		if _, err := s.billrfqService.ApplyCredit(bill.BillrfqId, amount, creditNote.CreditNoteId); err != nil {
+			return nil, err
+		}
+		creditNote.AmountApplied = roundCost(creditNote.AmountApplied + amount)
+	}
+
+	switch {
+	case creditNote.Amount-creditNote.AmountApplied < paymentTolerance:
+		creditNote.Status = "applied"
+	case creditNote.AmountApplied > 0:
+		creditNote.Status = "partially applied"
+	}
+	creditNote.UpdatedAt = time.Now()
+	return s.returnRepo.UpdateCreditNote(creditNote)
+}
@@ -0,0 +1,529 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
+)
+
+type QualityService interface {
+	CreateCheck(check *entity.QualityCheck) (*entity.QualityCheck, error)
+	UpdateCheck(check *entity.QualityCheck) (*entity.QualityCheck, error)
+	DeleteCheck(checkId string) (bool, error)
+	FindAllChecks() ([]entity.QualityCheck, error)
+	ReceiveRfqLine(rfq *entity.Rfqs, line entity.RfqsProduct, quantity float64) (*entity.QcInspection, error)
+	ReceiveProduction(mo *entity.Mos, quantity float64) error
+	FindInspections(status string) ([]entity.QcInspection, error)
+	GetInspectionByID(inspectionId string) (map[string]interface{}, error)
+	RecordResults(inspectionId, recordedBy string, results []QcResultInput) (*entity.QcInspection, error)
+	ReleaseInspection(inspectionId, decidedBy, note string) (*entity.QcInspection, error)
+	RejectInspection(inspectionId, decidedBy, note string) (*entity.QcInspection, error)
+	FailureRateReport(groupBy string, from, to time.Time) ([]map[string]interface{}, error)
+	RejectMoInspections(moId, decidedBy, note string) (float64, error)
+	FindMoInspections(moId string) ([]entity.QcInspection, error)
+	ReturnReceiptLine(line entity.RfqReceiptLine, lineType string, quantity float64, returnedBy, note string) (string, error)
+}
+
+// QcResultInput is the outcome of one check. Pass/fail checks need Passed;
+// measured checks need MeasuredValue and pass when it is within range.
+type QcResultInput struct {
+	QualityCheckId string
+	Passed         *bool
+	MeasuredValue  *float64
+	Note           string
+}
+
+var qcTriggers = map[string]bool{"receipt": true, "production": true}
+
+type qualityService struct {
+	qualityRepo  repository.QualityRepository
+	materialRepo repository.MaterialRepository
+	productRepo  repository.ProductRepository
+	vendorRepo   repository.VendorRepository
+}
+
+func NewQualityService(qualityRepo repository.QualityRepository, materialRepo repository.MaterialRepository,
+	productRepo repository.ProductRepository, vendorRepo repository.VendorRepository) *qualityService {
+	return &qualityService{
+		qualityRepo:  qualityRepo,
+		materialRepo: materialRepo,
+		productRepo:  productRepo,
+		vendorRepo:   vendorRepo,
+	}
+}
+
+func validateQualityCheck(check *entity.QualityCheck) error {
+	if check.Name == "" {
+		return errors.New("check name cannot be empty")
+	}
+	if !qcTriggers[check.Trigger] {
+		return errors.New("trigger must be receipt or production")
+	}
+	if check.MaterialId != "" && check.ProductId != "" {
+		return errors.New("a check applies to a material or a product, not both")
+	}
+	switch check.MeasureType {
+	case "", "pass_fail":
+		check.MeasureType = "pass_fail"
+	case "measure":
+		if check.MinValue == nil && check.MaxValue == nil {
+			return errors.New("a measured check needs a minimum or maximum value")
+		}
+		if check.MinValue != nil && check.MaxValue != nil && *check.MinValue > *check.MaxValue {
+			return errors.New("minimum value cannot be above maximum value")
+		}
+	default:
+		return errors.New("measure type must be pass_fail or measure")
+	}
+	return nil
+}
+
+func (s *qualityService) CreateCheck(check *entity.QualityCheck) (*entity.QualityCheck, error) {
+	if err := validateQualityCheck(check); err != nil {
+		return nil, err
+	}
+
+	lastId, err := s.qualityRepo.GetLastCheckId()
+	if err != nil {
+		return nil, err
+	}
+	return s.qualityRepo.CreateCheck(entity.NewQualityCheck(lastId, *check))
+}
+
+func (s *qualityService) UpdateCheck(check *entity.QualityCheck) (*entity.QualityCheck, error) {
+	existing, err := s.qualityRepo.FindCheckByID(check.QualityCheckId)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, errors.New("quality check not found")
+	}
+	if err := validateQualityCheck(check); err != nil {
+		return nil, err
+	}
+
+	check.CreatedAt = existing.CreatedAt
+	check.UpdatedAt = time.Now()
+	return s.qualityRepo.UpdateCheck(check)
+}
+
+func (s *qualityService) DeleteCheck(checkId string) (bool, error) {
+	check, err := s.qualityRepo.FindCheckByID(checkId)
+	if err != nil {
+		return false, err
+	}
+	if check == nil {
+		return false, errors.New("quality check not found")
+	}
+	return s.qualityRepo.DeleteCheck(check)
+}
+
+func (s *qualityService) FindAllChecks() ([]entity.QualityCheck, error) {
+	return s.qualityRepo.FindAllChecks()
+}
+
+// ReceiveRfqLine books a delivered quantity of an RFQ line into stock. When
+// receipt checks apply it goes to quarantine under a new inspection instead,
+// which is returned.
+func (s *qualityService) ReceiveRfqLine(rfq *entity.Rfqs, line entity.RfqsProduct, quantity float64) (*entity.QcInspection, error) {
+	materialId, productId := line.MaterialId, ""
+	if line.LineType != "material" {
+		materialId, productId = "", line.ProductId
+	}
+
+	inspection, err := s.receive("receipt", materialId, productId, line.ProductName, quantity)
+	if err != nil || inspection == nil {
+		return nil, err
+	}
+	inspection.RfqId = rfq.RfqId
+	inspection.VendorId = rfq.VendorId
+	return s.qualityRepo.UpdateInspection(inspection)
+}
+
+// ReceiveProduction books the output of a finished MO into stock, or into
+// quarantine when production checks apply to the product.
+func (s *qualityService) ReceiveProduction(mo *entity.Mos, quantity float64) error {
+	itemName := mo.ProductId
+	if product, err := s.productRepo.FindProductByID(mo.ProductId); err == nil {
+		itemName = product.Productname
+	}
+
+	inspection, err := s.receive("production", "", mo.ProductId, itemName, quantity)
+	if err != nil || inspection == nil {
+		return err
+	}
+	inspection.MoId = mo.MoId
+	_, err = s.qualityRepo.UpdateInspection(inspection)
+	return err
+}
+
+// receive adds the quantity to stock when no check applies, otherwise to
+// quarantine, returning the inspection that now holds it.
+func (s *qualityService) receive(trigger, materialId, productId, itemName string, quantity float64) (*entity.QcInspection, error) {
+	checks, err := s.qualityRepo.FindApplicableChecks(trigger, materialId, productId)
+	if err != nil {
+		return nil, err
+	}
+	if len(checks) == 0 {
+		return nil, s.adjustStock(materialId, productId, quantity, 0)
+	}
+
+	if err := s.adjustStock(materialId, productId, 0, quantity); err != nil {
+		return nil, err
+	}
+	lastId, err := s.qualityRepo.GetLastInspectionId()
+	if err != nil {
+		return nil, err
+	}
+	return s.qualityRepo.CreateInspection(entity.NewQcInspection(lastId, trigger, materialId, productId, itemName, quantity))
+}
+
+// adjustStock moves the available and quarantined quantity of the item.
+func (s *qualityService) adjustStock(materialId, productId string, available, quarantined float64) error {
+	adjust, adjustQuarantine := s.materialRepo.AdjustQty, s.materialRepo.AdjustQuarantineQty
+	itemId := materialId
+	if materialId == "" {
+		adjust, adjustQuarantine = s.productRepo.AdjustQty, s.productRepo.AdjustQuarantineQty
+		itemId = productId
+	}
+
+	if available != 0 {
+		if err := adjust(itemId, available); err != nil {
+			return err
+		}
+	}
+	if quarantined != 0 {
+		return adjustQuarantine(itemId, quarantined)
+	}
+	return nil
+}
+
+func (s *qualityService) FindInspections(status string) ([]entity.QcInspection, error) {
+	return s.qualityRepo.FindInspections(status)
+}
+
+func (s *qualityService) findInspection(inspectionId string) (*entity.QcInspection, error) {
+	inspection, err := s.qualityRepo.FindInspectionByID(inspectionId)
+	if err != nil {
+		return nil, err
+	}
+	if inspection == nil {
+		return nil, errors.New("inspection not found")
+	}
+	return inspection, nil
+}
+
+// GetInspectionByID returns the inspection with the checks that still await
+// a result.
+func (s *qualityService) GetInspectionByID(inspectionId string) (map[string]interface{}, error) {
+	inspection, err := s.findInspection(inspectionId)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := s.qualityRepo.FindApplicableChecks(inspection.Trigger, inspection.MaterialId, inspection.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := make(map[string]bool)
+	for _, result := range inspection.Results {
+		recorded[result.QualityCheckId] = true
+	}
+	open := make([]entity.QualityCheck, 0)
+	for _, check := range checks {
+		if !recorded[check.QualityCheckId] {
+			open = append(open, check)
+		}
+	}
+
+	return map[string]interface{}{
+		"inspection":     inspection,
+		"pending_checks": open,
+	}, nil
+}
+
+// RecordResults stores check outcomes on a pending inspection. Once every
+// applicable check has a result the inspection passes and its stock is
+// released, or fails and stays quarantined.
+func (s *qualityService) RecordResults(inspectionId, recordedBy string, results []QcResultInput) (*entity.QcInspection, error) {
+	inspection, err := s.findInspection(inspectionId)
+	if err != nil {
+		return nil, err
+	}
+	if inspection.Status != "pending" {
+		return nil, fmt.Errorf("inspection is %s, results can no longer be recorded", inspection.Status)
+	}
+
+	existing := make(map[string]*entity.QcResult)
+	for i := range inspection.Results {
+		existing[inspection.Results[i].QualityCheckId] = &inspection.Results[i]
+	}
+
+	for _, input := range results {
+		check, err := s.qualityRepo.FindCheckByID(input.QualityCheckId)
+		if err != nil {
+			return nil, err
+		}
+		if check == nil || check.Trigger != inspection.Trigger {
+			return nil, fmt.Errorf("quality check %s does not apply to this inspection", input.QualityCheckId)
+		}
+
+		passed, err := evaluateCheck(check, input)
+		if err != nil {
+			return nil, err
+		}
+
+		if result, ok := existing[check.QualityCheckId]; ok {
+			result.Passed = passed
+			result.MeasuredValue = input.MeasuredValue
+			result.Note = input.Note
+			result.RecordedBy = recordedBy
+			result.UpdatedAt = time.Now()
+			if _, err := s.qualityRepo.UpdateResult(result); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		lastId, err := s.qualityRepo.GetLastResultId()
+		if err != nil {
+			return nil, err
+		}
+		result, err := s.qualityRepo.CreateResult(entity.NewQcResult(lastId, inspection.InspectionId, *check, passed, input.MeasuredValue, input.Note, recordedBy))
+		if err != nil {
+			return nil, err
+		}
+		existing[check.QualityCheckId] = result
+	}
+
+	checks, err := s.qualityRepo.FindApplicableChecks(inspection.Trigger, inspection.MaterialId, inspection.ProductId)
+	if err != nil {
+		return nil, err
+	}
+	allPassed := true
+	for _, check := range checks {
+		result, ok := existing[check.QualityCheckId]
+		if !ok {
+			return s.findInspection(inspectionId)
+		}
+		allPassed = allPassed && result.Passed
+	}
+
+	if allPassed {
+		inspection.Result = "pass"
+		if _, err := s.decide(inspection, "released", recordedBy, "all checks passed"); err != nil {
+			return nil, err
+		}
+	} else {
+		inspection.Result = "fail"
+		inspection.Status = "quarantined"
+		inspection.UpdatedAt = time.Now()
+		if _, err := s.qualityRepo.UpdateInspection(inspection); err != nil {
+			return nil, err
+		}
+	}
+	return s.findInspection(inspectionId)
+}
+
+func evaluateCheck(check *entity.QualityCheck, input QcResultInput) (bool, error) {
+	if check.MeasureType != "measure" {
+		if input.Passed == nil {
+			return false, fmt.Errorf("check %s needs a pass or fail result", check.Name)
+		}
+		return *input.Passed, nil
+	}
+
+	if input.MeasuredValue == nil {
+		return false, fmt.Errorf("check %s needs a measured value", check.Name)
+	}
+	value := *input.MeasuredValue
+	if check.MinValue != nil && value < *check.MinValue {
+		return false, nil
+	}
+	if check.MaxValue != nil && value > *check.MaxValue {
+		return false, nil
+	}
+	return true, nil
+}
+
+// decide closes the inspection, moving its quarantined quantity into stock
+// when released and writing it off when rejected.
+func (s *qualityService) decide(inspection *entity.QcInspection, status, decidedBy, note string) (*entity.QcInspection, error) {
+	available := 0.0
+	if status == "released" {
+		available = inspection.Quantity
+	}
+	if err := s.adjustStock(inspection.MaterialId, inspection.ProductId, available, -inspection.Quantity); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	inspection.Status = status
+	inspection.DecidedBy = decidedBy
+	inspection.DecidedAt = &now
+	inspection.Note = note
+	inspection.UpdatedAt = now
+	return s.qualityRepo.UpdateInspection(inspection)
+}
+
+// ReleaseInspection lets quarantined stock into use, e.g. after a concession
+// on a failed lot. Pending inspections need their results first.
+func (s *qualityService) ReleaseInspection(inspectionId, decidedBy, note string) (*entity.QcInspection, error) {
+	inspection, err := s.findInspection(inspectionId)
+	if err != nil {
+		return nil, err
+	}
+	if inspection.Status != "quarantined" {
+		return nil, fmt.Errorf("inspection is %s, only quarantined stock can be released", inspection.Status)
+	}
+	return s.decide(inspection, "released", decidedBy, note)
+}
+
+func (s *qualityService) RejectInspection(inspectionId, decidedBy, note string) (*entity.QcInspection, error) {
+	inspection, err := s.findInspection(inspectionId)
+	if err != nil {
+		return nil, err
+	}
+	if inspection.Status != "pending" && inspection.Status != "quarantined" {
+		return nil, fmt.Errorf("inspection is already %s", inspection.Status)
+	}
+	if inspection.Result == "" {
+		inspection.Result = "fail"
+	}
+	return s.decide(inspection, "rejected", decidedBy, note)
+}
+
+// ReturnReceiptLine takes goods sent back to the vendor out of the
+// warehouse and says where from. A lot QC still holds leaves quarantine as a
+// whole, closing its inspection as rejected; a lot QC already rejected was
+// written off then, so nothing moves; anything else comes out of stock.
+func (s *qualityService) ReturnReceiptLine(line entity.RfqReceiptLine, lineType string, quantity float64, returnedBy, note string) (string, error) {
+	materialId, productId := line.ProductId, ""
+	if lineType != "material" {
+		materialId, productId = "", line.ProductId
+	}
+
+	if line.InspectionId != "" {
+		inspection, err := s.findInspection(line.InspectionId)
+		if err != nil {
+			return "", err
+		}
+		switch inspection.Status {
+		case "pending", "quarantined":
+			if math.Abs(quantity-inspection.Quantity) > 1e-9 {
+				return "", fmt.Errorf("%s is held by QC under %s, the whole lot of %g must be returned",
+					line.ProductName, inspection.InspectionId, inspection.Quantity)
+			}
+			if inspection.Result == "" {
+				inspection.Result = "fail"
+			}
+			if _, err := s.decide(inspection, "rejected", returnedBy, note); err != nil {
+				return "", err
+			}
+			return "quarantine", nil
+		case "rejected":
+			return "written_off", nil
+		}
+	}
+
+	if err := s.adjustStock(materialId, productId, -quantity, 0); err != nil {
+		return "", err
+	}
+	return "stock", nil
+}
+
+// FindMoInspections returns the production inspections of an MO with their
+// results.
+func (s *qualityService) FindMoInspections(moId string) ([]entity.QcInspection, error) {
+	return s.qualityRepo.FindInspectionsByMoId(moId)
+}
+
+// RejectMoInspections writes off the MO's output that is still waiting for
+// QC, for when the MO is cancelled. It returns the quantity taken out of
+// quarantine.
+func (s *qualityService) RejectMoInspections(moId, decidedBy, note string) (float64, error) {
+	inspections, err := s.qualityRepo.FindInspectionsByMoId(moId)
+	if err != nil {
+		return 0, err
+	}
+
+	rejected := 0.0
+	for i := range inspections {
+		if inspections[i].Status != "pending" && inspections[i].Status != "quarantined" {
+			continue
+		}
+		if _, err := s.decide(&inspections[i], "rejected", decidedBy, note); err != nil {
+			return 0, err
+		}
+		rejected += inspections[i].Quantity
+	}
+	return rejected, nil
+}
+
+// FailureRateReport groups inspections that reached a result by vendor or by
+// inspected item and reports the share that failed.
+func (s *qualityService) FailureRateReport(groupBy string, from, to time.Time) ([]map[string]interface{}, error) {
+	if groupBy != "vendor" && groupBy != "product" {
+		return nil, errors.New("group_by must be vendor or product")
+	}
+	inspections, err := s.qualityRepo.FindDecidedInspections(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	type failureGroup struct {
+		key, name      string
+		total, failed  int
+		failedQuantity float64
+	}
+	groups := make(map[string]*failureGroup)
+	var order []string
+	for _, inspection := range inspections {
+		key, name := inspection.MaterialId+inspection.ProductId, inspection.ItemName
+		if groupBy == "vendor" {
+			if inspection.VendorId == "" {
+				continue
+			}
+			key, name = inspection.VendorId, inspection.VendorId
+		}
+
+		group, ok := groups[key]
+		if !ok {
+			if groupBy == "vendor" {
+				if vendor, err := s.vendorRepo.FindVendorByID(key); err == nil {
+					name = vendor.Vendorname
+				}
+			}
+			group = &failureGroup{key: key, name: name}
+			groups[key] = group
+			order = append(order, key)
+		}
+		group.total++
+		if inspection.Result == "fail" {
+			group.failed++
+			group.failedQuantity += inspection.Quantity
+		}
+	}
+
+	report := make([]map[string]interface{}, 0, len(order))
+	for _, key := range order {
+		group := groups[key]
+		report = append(report, map[string]interface{}{
+			"id":                   group.key,
+			"name":                 group.name,
+			"inspections":          group.total,
+			"failed":               group.failed,
+			"failed_quantity":      group.failedQuantity,
+			"failure_rate_percent": math.Round(float64(group.failed)/float64(group.total)*10000) / 100,
+		})
+	}
+	sort.SliceStable(report, func(i, j int) bool {
+		return report[i]["failure_rate_percent"].(float64) > report[j]["failure_rate_percent"].(float64)
+	})
+	return report, nil
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"strconv"
 
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
@@ -32,13 +31,16 @@ type quoService struct {
 	quoRepository  repository.QuoRepository
 	quoProductRepo repository.QuoProductRepository
 	emailSender    *email.EmailSender
+	taxes          TaxService
 }
 
-func NewQuoService(quoRepository repository.QuoRepository, quoProductRepo repository.QuoProductRepository, emailSender *email.EmailSender) *quoService {
+func NewQuoService(quoRepository repository.QuoRepository, quoProductRepo repository.QuoProductRepository, emailSender *email.EmailSender,
+	taxes TaxService) *quoService {
 	return &quoService{
 		quoRepository:  quoRepository,
 		quoProductRepo: quoProductRepo,
 		emailSender:    emailSender,
+		taxes:          taxes,
 	}
 }
 
@@ -67,6 +69,9 @@ func generateQuosProductId(lastId string) string {
 }
 
 func (s *quoService) CreateQuo(rfq *entity.Quotations) (*entity.Quotations, error) {
+	if err := s.taxes.PriceQuotation(rfq); err != nil {
+		return nil, err
+	}
 
 	lastId, err := s.quoRepository.GetLastQuo()
 	if err != nil {
@@ -74,6 +79,7 @@ func (s *quoService) CreateQuo(rfq *entity.Quotations) (*entity.Quotations, erro
 	}
 
 	newRfq := entity.NewQuo(lastId, rfq.OrderDate, rfq.Status, rfq.CostumerId, rfq.Payment)
+	newRfq.DocumentTotals = rfq.DocumentTotals
 
 	savedRfq, err := s.quoRepository.CreateQuo(newRfq)
 	if err != nil {
@@ -125,6 +131,12 @@ func (s *quoService) UpdateQuo(rfq *entity.Quotations) (*entity.Quotations, erro
 	if existingRfq == nil {
 		return nil, fmt.Errorf("Quo with id %s not found", rfq.QuotationsId)
 	}
+	if rfq.TaxRounding == "" {
+		rfq.TaxRounding = existingRfq.TaxRounding
+	}
+	if err := s.taxes.PriceQuotation(rfq); err != nil {
+		return nil, err
+	}
 
 	// Update hanya data RFQ tanpa menyentuh produk
 	updatedRfq := entity.UpdateQuo(
@@ -136,11 +148,16 @@ func (s *quoService) UpdateQuo(rfq *entity.Quotations) (*entity.Quotations, erro
 		existingRfq.Status,
 	)
 
+	updatedRfq.DocumentTotals = rfq.DocumentTotals
+
 	// Perbarui data RFQ
 	result, err := s.quoRepository.UpdateQuo(updatedRfq)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.quoRepository.UpdateQuoTotals(updatedRfq); err != nil {
+		return nil, err
+	}
 
 	// Hapus produk lama sebelum menambah produk baru
 	err = s.quoProductRepo.DeleteProductsByQuoId(updatedRfq.QuotationsId)
@@ -293,46 +310,36 @@ func (s *quoService) CalculateOverview(rfqId string) (map[string]interface{}, er
 	overview["created_at"] = rfq.CreatedAt
 	overview["updated_at"] = rfq.UpdatedAt
 	overview["deleted_at"] = rfq.DeletedAt
+	overview["tax_rounding"] = rfq.TaxRounding
 	overview["products"] = []map[string]interface{}{}
-	totalCost := 0.0
 
-	// Iterasi produk pada RFQ untuk menghitung detail
+	// Iterasi produk pada RFQ untuk menampilkan detail
 	for _, product := range rfq.Products {
 		productDetails, err := s.quoProductRepo.GetProductDetails(product.ProductId)
 		if err != nil {
 			return nil, err
 		}
 
-		// Kalkulasi harga subtotal
-		subtotal, err := strconv.ParseFloat(product.Subtotal, 64)
-		if err != nil {
-			return nil, err
-		}
-
-		quantity, err := strconv.ParseFloat(product.Quantity, 64)
-		if err != nil {
-			return nil, err
-		}
-		productCost := subtotal * quantity
-
 		productDetail := map[string]interface{}{
 			"product_id":   product.ProductId,
 			"product_name": product.ProductName,
 			"quantity":     product.Quantity,
 			"unit_price":   product.UnitPrice,
+			"discount":     product.Discount,
+			"id_tax":       product.TaxId,
 			"tax":          product.Tax,
 			"subtotal":     product.Subtotal,
-			"total_cost":   fmt.Sprintf("Rp %.2f", productCost),
+			"tax_amount":   product.TaxAmount,
+			"total_cost":   fmt.Sprintf("Rp %.2f", product.Total),
 			"vendor_price": productDetails.Sellprice, // Jika ingin menggunakan harga dari vendor
 		}
 
-		// Hitung total biaya
-		totalCost += productCost
-
 		overview["products"] = append(overview["products"].([]map[string]interface{}), productDetail)
 	}
 
-	overview["total_cost"] = fmt.Sprintf("Rp %.2f", totalCost)
+	overview["amount_untaxed"] = fmt.Sprintf("Rp %.2f", rfq.AmountUntaxed)
+	overview["amount_tax"] = fmt.Sprintf("Rp %.2f", rfq.AmountTax)
+	overview["total_cost"] = fmt.Sprintf("Rp %.2f", rfq.AmountTotal)
 	return overview, nil
 }
 
@@ -350,12 +357,13 @@ func (s *quoService) SendQuoEmail(rfqId string, recipientEmail string) error {
 
 	// Kirim email menggunakan service email (pastikan sudah diinisialisasi)
 	err = s.emailSender.SendQuoEmail(
-		recipientEmail,   // Pass the email recipient as string
-		rfq.QuotationsId, // RFQ ID
-		rfq.CostumerId,   // Vendor ID
-		rfq.OrderDate,    // Order Date
-		rfq.Status,       // Status
-		rfq.Products,     // List of products
+		recipientEmail,     // Pass the email recipient as string
+		rfq.QuotationsId,   // RFQ ID
+		rfq.CostumerId,     // Vendor ID
+		rfq.OrderDate,      // Order Date
+		rfq.Status,         // Status
+		rfq.Products,       // List of products
+		rfq.DocumentTotals, // Totals
 	)
 	if err != nil {
 		return fmt.Errorf("failed to send RFQ email: %v", err)
@@ -459,6 +467,7 @@ func (s *quoService) CreateQuoPDF(quoId string, recipientEmail string) ([]byte,
 		pdf.Cell(40, 10, fmt.Sprintf("%s", product.Subtotal))
 		pdf.Ln(6)
 	}
+	addPdfTotals(pdf, quo.DocumentTotals)
 
 	// Footer
 	pdf.SetY(-30)
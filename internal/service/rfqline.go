@@ -0,0 +1,85 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+)
+
+// productUnit is the unit bought-in products are counted in; products carry
+// no unit of their own.
+const productUnit = "pcs"
+
+// resolveRfqLines settles what each line buys. Lines say so through LineType,
+// with the item in MaterialId or ProductId; a line without a type is taken
+// as a material when its id is one, as RFQs have always been keyed. The name
+// and unit of the line come from the material or product itself.
+func (s *rfqService) resolveRfqLines(lines []entity.RfqsProduct) error {
+	seen := make(map[string]bool)
+	for i := range lines {
+		line := &lines[i]
+		switch line.LineType {
+		case "":
+			if line.MaterialId == "" {
+				exists, err := s.rfqProductRepo.CheckMaterialExists(line.ProductId)
+				if err != nil {
+					return err
+				}
+				if !exists {
+					line.LineType = "product"
+					break
+				}
+				line.MaterialId = line.ProductId
+			}
+			line.LineType, line.ProductId = "material", ""
+		case "material":
+			if line.MaterialId == "" {
+				line.MaterialId = line.ProductId
+			}
+			line.ProductId = ""
+		case "product":
+			line.MaterialId = ""
+		default:
+			return fmt.Errorf("line type must be material or product, not %q", line.LineType)
+		}
+
+		if line.LineType == "material" {
+			material, err := s.rfqProductRepo.GetMaterialDetails(line.MaterialId)
+			if err != nil {
+				return fmt.Errorf("material with id %s does not exist", line.MaterialId)
+			}
+			line.ProductName = material.Materialname
+			line.Unit = material.Unit
+		} else {
+			product, err := s.rfqProductRepo.GetProductDetails(line.ProductId)
+			if err != nil {
+				return fmt.Errorf("product with id %s does not exist", line.ProductId)
+			}
+			line.ProductName = product.Productname
+			line.Unit = productUnit
+		}
+
+		key := line.LineType + ":" + line.ItemId()
+		if seen[key] {
+			return fmt.Errorf("%s is on the RFQ more than once", line.ProductName)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// itemListPrice is the sell price kept on the material or product of a line.
+func (s *rfqService) itemListPrice(line entity.RfqsProduct) (string, error) {
+	if line.LineType == "material" {
+		material, err := s.rfqProductRepo.GetMaterialDetails(line.MaterialId)
+		if err != nil {
+			return "", err
+		}
+		return material.Sellprice, nil
+	}
+	product, err := s.rfqProductRepo.GetProductDetails(line.ProductId)
+	if err != nil {
+		return "", err
+	}
+	return product.Sellprice, nil
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+)
+
+// ReceiptLine is the quantity of one RFQ line that arrived in a delivery.
+type ReceiptLine struct {
+	RfqsProductId string
+	Quantity      float64
+	LotNumber     string
+}
+
+// receiptTolerance absorbs rounding when comparing delivered quantities.
+const receiptTolerance = 1e-9
+
+// outstandingQty is what is still to arrive on an RFQ line: the backorder.
+func outstandingQty(line entity.RfqsProduct) (float64, error) {
+	ordered, err := strconv.ParseFloat(line.Quantity, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity for %s: %v", line.ProductName, err)
+	}
+	return math.Max(ordered-line.QtyReceived-line.QtyCancelled, 0), nil
+}
+
+// checkLinesEditable refuses to replace the lines of an RFQ once goods have
+// been received against them.
+func (s *rfqService) checkLinesEditable(rfqId string) error {
+	receipts, err := s.receiptRepo.FindReceiptsByRfqId(rfqId)
+	if err != nil {
+		return err
+	}
+	if len(receipts) > 0 {
+		return errors.New("goods have already been received for this RFQ, its lines can no longer change")
+	}
+	return nil
+}
+
+// editableRfqStatuses are the statuses an RFQ can be given by editing it.
+// Anything further is reached through ReceiveRfq, CancelBackorder and
+// settleRfq.
+var editableRfqStatuses = map[string]bool{
+	"RFQ":            true,
+	"Purchase Order": true,
+	"Cancelled":      true,
+}
+
+// checkStatusEditable refuses status changes that belong to receiving: an
+// edit cannot move an RFQ past "Purchase Order", nor move one that receipts
+// already took past it.
+func checkStatusEditable(from, to string) error {
+	if from == to {
+		return nil
+	}
+	if !editableRfqStatuses[to] {
+		return fmt.Errorf("status %s is reached by receiving the goods, not by editing the RFQ", to)
+	}
+	if !editableRfqStatuses[from] {
+		return fmt.Errorf("RFQ is %s, its status follows its receipts", from)
+	}
+	return nil
+}
+
+// settleRfq moves the RFQ to "Received" once nothing is outstanding, and to
+// "Partially Received" while a backorder remains.
+func (s *rfqService) settleRfq(rfq *entity.Rfqs) error {
+	products, err := s.rfqProductRepo.GetProductsByRfqId(rfq.RfqId)
+	if err != nil {
+		return err
+	}
+	rfq.Status = "Received"
+	for _, line := range products {
+		outstanding, err := outstandingQty(line)
+		if err != nil {
+			return err
+		}
+		if outstanding > receiptTolerance {
+			rfq.Status = "Partially Received"
+			break
+		}
+	}
+	rfq.UpdatedAt = time.Now()
+	if _, err := s.rfqRepository.UpdateRfqStatus(rfq); err != nil {
+		return errors.New("failed to update RFQ status")
+	}
+	return nil
+}
+
+// ReceiveRfq records a delivery against a purchase order and books it into
+// stock, or into quarantine where receipt checks apply. Whatever is not
+// delivered stays open as a backorder.
+func (s *rfqService) ReceiveRfq(rfqId, receivedBy, note string, lines []ReceiptLine) (*entity.RfqReceipt, error) {
+	rfq, err := s.rfqRepository.GetRfqById(rfqId)
+	if err != nil {
+		return nil, err
+	}
+	if rfq == nil {
+		return nil, fmt.Errorf("RFQ with id %s not found", rfqId)
+	}
+	if rfq.Status != "Purchase Order" && rfq.Status != "Partially Received" {
+		return nil, fmt.Errorf("RFQ is %s, goods can only be received against a purchase order", rfq.Status)
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("a receipt needs at least one line")
+	}
+
+	products := make(map[string]*entity.RfqsProduct)
+	for i := range rfq.Products {
+		products[rfq.Products[i].RfqsProductId] = &rfq.Products[i]
+	}
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		product, ok := products[line.RfqsProductId]
+		if !ok {
+			return nil, fmt.Errorf("line %s does not belong to RFQ %s", line.RfqsProductId, rfqId)
+		}
+		if seen[line.RfqsProductId] {
+			return nil, fmt.Errorf("line %s is received more than once", line.RfqsProductId)
+		}
+		seen[line.RfqsProductId] = true
+		if line.Quantity <= 0 {
+			return nil, fmt.Errorf("received quantity of %s must be greater than zero", product.ProductName)
+		}
+		outstanding, err := outstandingQty(*product)
+		if err != nil {
+			return nil, err
+		}
+		if line.Quantity > outstanding+receiptTolerance {
+			return nil, fmt.Errorf("only %g of %s is still outstanding", outstanding, product.ProductName)
+		}
+	}
+
+	lastId, err := s.receiptRepo.GetLastReceiptId()
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := s.receiptRepo.CreateReceipt(entity.NewRfqReceipt(lastId, rfq.RfqId, rfq.VendorId, "receipt", receivedBy, note))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		product := products[line.RfqsProductId]
+		inspection, err := s.qualityService.ReceiveRfqLine(rfq, *product, line.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		lastLineId, err := s.receiptRepo.GetLastReceiptLineId()
+		if err != nil {
+			return nil, err
+		}
+		receiptLine := entity.NewRfqReceiptLine(lastLineId, receipt.ReceiptId, *product, line.Quantity, line.LotNumber)
+		if inspection != nil {
+			receiptLine.InspectionId = inspection.InspectionId
+		}
+		if _, err := s.receiptRepo.CreateReceiptLine(receiptLine); err != nil {
+			return nil, err
+		}
+
+		product.QtyReceived += line.Quantity
+		product.UpdatedAt = time.Now()
+		if err := s.rfqProductRepo.UpdateReceivedQty(product); err != nil {
+			return nil, err
+		}
+		receipt.Lines = append(receipt.Lines, *receiptLine)
+	}
+
+	if err := s.settleRfq(rfq); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// CancelBackorder closes what is still outstanding on the given lines, or on
+// every line when none are given, so a short delivery can complete the RFQ.
+func (s *rfqService) CancelBackorder(rfqId, cancelledBy, reason string, lineIds []string) (*entity.RfqReceipt, error) {
+	rfq, err := s.rfqRepository.GetRfqById(rfqId)
+	if err != nil {
+		return nil, err
+	}
+	if rfq == nil {
+		return nil, fmt.Errorf("RFQ with id %s not found", rfqId)
+	}
+	if rfq.Status != "Partially Received" {
+		return nil, fmt.Errorf("RFQ is %s, only the backorder of a partially received RFQ can be cancelled", rfq.Status)
+	}
+
+	selected := make(map[string]bool)
+	for _, lineId := range lineIds {
+		selected[lineId] = true
+	}
+	var cancelled []*entity.RfqsProduct
+	for i := range rfq.Products {
+		product := &rfq.Products[i]
+		if len(selected) > 0 && !selected[product.RfqsProductId] {
+			continue
+		}
+		delete(selected, product.RfqsProductId)
+		outstanding, err := outstandingQty(*product)
+		if err != nil {
+			return nil, err
+		}
+		if outstanding > receiptTolerance {
+			cancelled = append(cancelled, product)
+		}
+	}
+	for lineId := range selected {
+		return nil, fmt.Errorf("line %s does not belong to RFQ %s", lineId, rfqId)
+	}
+	if len(cancelled) == 0 {
+		return nil, errors.New("nothing is outstanding on the selected lines")
+	}
+
+	lastId, err := s.receiptRepo.GetLastReceiptId()
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := s.receiptRepo.CreateReceipt(entity.NewRfqReceipt(lastId, rfq.RfqId, rfq.VendorId, "cancellation", cancelledBy, reason))
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range cancelled {
+		outstanding, _ := outstandingQty(*product)
+		lastLineId, err := s.receiptRepo.GetLastReceiptLineId()
+		if err != nil {
+			return nil, err
+		}
+		receiptLine := entity.NewRfqReceiptLine(lastLineId, receipt.ReceiptId, *product, outstanding, "")
+		if _, err := s.receiptRepo.CreateReceiptLine(receiptLine); err != nil {
+			return nil, err
+		}
+
+		product.QtyCancelled += outstanding
+		product.UpdatedAt = time.Now()
+		if err := s.rfqProductRepo.UpdateReceivedQty(product); err != nil {
+			return nil, err
+		}
+		receipt.Lines = append(receipt.Lines, *receiptLine)
+	}
+
+	if err := s.settleRfq(rfq); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// GetRfqReceipts lists the receipts of an RFQ with, per line, what was
+// ordered, received and cancelled and what is still on backorder.
+func (s *rfqService) GetRfqReceipts(rfqId string) (map[string]interface{}, error) {
+	rfq, err := s.rfqRepository.GetRfqById(rfqId)
+	if err != nil {
+		return nil, err
+	}
+	if rfq == nil {
+		return nil, fmt.Errorf("RFQ with id %s not found", rfqId)
+	}
+	receipts, err := s.receiptRepo.FindReceiptsByRfqId(rfqId)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]map[string]interface{}, 0, len(rfq.Products))
+	for _, product := range rfq.Products {
+		outstanding, err := outstandingQty(product)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, map[string]interface{}{
+			"id_rfqproduct": product.RfqsProductId,
+			"line_type":     product.LineType,
+			"id_product":    product.ProductId,
+			"id_material":   product.MaterialId,
+			"productname":   product.ProductName,
+			"unit":          product.Unit,
+			"qty_ordered":   product.Quantity,
+			"qty_received":  product.QtyReceived,
+			"qty_cancelled": product.QtyCancelled,
+			"qty_backorder": outstanding,
+		})
+	}
+
+	return map[string]interface{}{
+		"id_rfq":   rfq.RfqId,
+		"status":   rfq.Status,
+		"lines":    lines,
+		"receipts": receipts,
+	}, nil
+}
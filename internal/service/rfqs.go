@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
@@ -29,19 +30,35 @@ type RfqService interface {
 	CreateRfqPDF(rfqId string, recipientEmail string) ([]byte, error)
 	UpdateRfqAll(rfqId string, updatedRfq *entity.Rfqs) (*entity.Rfqs, error)
 	DeleteProductsByRfqId(rfqId string) error
+	ReceiveRfq(rfqId, receivedBy, note string, lines []ReceiptLine) (*entity.RfqReceipt, error)
+	CancelBackorder(rfqId, cancelledBy, reason string, lineIds []string) (*entity.RfqReceipt, error)
+	GetRfqReceipts(rfqId string) (map[string]interface{}, error)
+	RecalculateRfq(rfqId string) (*entity.Rfqs, error)
 }
 
 type rfqService struct {
 	rfqRepository  repository.RfqRepository
 	rfqProductRepo repository.RfqProductRepository
+	receiptRepo    repository.RfqReceiptRepository
 	emailSender    *email.EmailSender
+	qualityService QualityService
+	priceList      PriceListService
+	approvals      PoApprovalService
+	taxes          TaxService
 }
 
-func NewRfqService(rfqRepository repository.RfqRepository, rfqProductRepo repository.RfqProductRepository, emailSender *email.EmailSender) *rfqService {
+func NewRfqService(rfqRepository repository.RfqRepository, rfqProductRepo repository.RfqProductRepository,
+	receiptRepo repository.RfqReceiptRepository, emailSender *email.EmailSender, qualityService QualityService,
+	priceList PriceListService, approvals PoApprovalService, taxes TaxService) *rfqService {
 	return &rfqService{
 		rfqRepository:  rfqRepository,
 		rfqProductRepo: rfqProductRepo,
+		receiptRepo:    receiptRepo,
 		emailSender:    emailSender,
+		qualityService: qualityService,
+		priceList:      priceList,
+		approvals:      approvals,
+		taxes:          taxes,
 	}
 }
 
@@ -70,6 +87,17 @@ func generateRfqsProductId(lastId string) string {
 }
 
 func (s *rfqService) CreateRfq(rfq *entity.Rfqs) (*entity.Rfqs, error) {
+	if err := s.resolveRfqLines(rfq.Products); err != nil {
+		return nil, err
+	}
+	// Lines without a price take the vendor's list price where one applies.
+	warnings, err := s.priceList.PriceRfqLines(rfq)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.taxes.PriceRfq(rfq); err != nil {
+		return nil, err
+	}
 
 	lastId, err := s.rfqRepository.GetLastRfq()
 	if err != nil {
@@ -77,6 +105,8 @@ func (s *rfqService) CreateRfq(rfq *entity.Rfqs) (*entity.Rfqs, error) {
 	}
 
 	newRfq := entity.NewRfqs(lastId, rfq.OrderDate, rfq.Status, rfq.VendorId)
+	newRfq.TenderId = rfq.TenderId
+	newRfq.DocumentTotals = rfq.DocumentTotals
 
 	savedRfq, err := s.rfqRepository.CreateRfq(newRfq)
 	if err != nil {
@@ -106,6 +136,7 @@ func (s *rfqService) CreateRfq(rfq *entity.Rfqs) (*entity.Rfqs, error) {
 		return nil, err
 	}
 	savedRfq.Products = products
+	savedRfq.Warnings = warnings
 
 	return savedRfq, nil
 }
@@ -127,11 +158,41 @@ func (s *rfqService) UpdateRfq(updatedRfq *entity.Rfqs) (*entity.Rfqs, error) {
 	if existingRfq == nil {
 		return nil, fmt.Errorf("RFQ with id %s not found", updatedRfq.RfqId)
 	}
+	if err := checkStatusEditable(existingRfq.Status, updatedRfq.Status); err != nil {
+		return nil, err
+	}
+	if err := s.checkLinesEditable(updatedRfq.RfqId); err != nil {
+		return nil, err
+	}
+	if err := s.resolveRfqLines(updatedRfq.Products); err != nil {
+		return nil, err
+	}
+	// Prices typed by the buyer are kept; only empty ones are looked up.
+	warnings, err := s.priceList.PriceRfqLines(updatedRfq)
+	if err != nil {
+		return nil, err
+	}
+	if updatedRfq.TaxRounding == "" {
+		updatedRfq.TaxRounding = existingRfq.TaxRounding
+	}
+	if err := s.taxes.PriceRfq(updatedRfq); err != nil {
+		return nil, err
+	}
+	if err := s.checkPoApproval(existingRfq, updatedRfq.Status, updatedRfq.Products); err != nil {
+		return nil, err
+	}
+
+	ordered := isOrdering(existingRfq.Status, updatedRfq.Status)
+	if ordered {
+		now := time.Now()
+		existingRfq.OrderedAt = &now
+	}
 
 	// Update detail RFQ
 	existingRfq.OrderDate = updatedRfq.OrderDate
 	existingRfq.Status = updatedRfq.Status
 	existingRfq.VendorId = updatedRfq.VendorId
+	existingRfq.DocumentTotals = updatedRfq.DocumentTotals
 	existingRfq.UpdatedAt = time.Now()
 
 	// Simpan perubahan RFQ
@@ -139,6 +200,9 @@ func (s *rfqService) UpdateRfq(updatedRfq *entity.Rfqs) (*entity.Rfqs, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := s.rfqRepository.UpdateRfqTotals(existingRfq); err != nil {
+		return nil, err
+	}
 
 	// Hapus produk lama
 	err = s.rfqProductRepo.DeleteProductsByRfqId(updatedRfq.RfqId)
@@ -168,6 +232,12 @@ func (s *rfqService) UpdateRfq(updatedRfq *entity.Rfqs) (*entity.Rfqs, error) {
 		return nil, err
 	}
 	updatedRfqResult.Products = products
+	updatedRfqResult.Warnings = warnings
+	if ordered {
+		if err := s.promiseLines(updatedRfqResult); err != nil {
+			return nil, err
+		}
+	}
 
 	return updatedRfqResult, nil
 }
@@ -196,6 +266,86 @@ func (s *rfqService) FindRfqById(rfqId string) (*entity.Rfqs, error) {
 	return rfq, nil
 }
 
+// isOrdering reports whether moving from one status to the other places the
+// order: an RFQ entering an ordered status from one that is not, whether it
+// comes from "RFQ" or is revived after a cancellation.
+func isOrdering(from, to string) bool {
+	return billableRfqStatuses[to] && !billableRfqStatuses[from]
+}
+
+// promiseLines gives the lines of a freshly placed order that have no
+// promised date one from the vendor's quoted lead time. Lines the price list
+// has no lead time for stay unpromised.
+func (s *rfqService) promiseLines(rfq *entity.Rfqs) error {
+	for i := range rfq.Products {
+		line := &rfq.Products[i]
+		if line.PromisedDate != nil {
+			continue
+		}
+		qty, err := strconv.ParseFloat(line.Quantity, 64)
+		if err != nil {
+			return fmt.Errorf("invalid quantity for %s: %v", line.ProductName, err)
+		}
+		price, err := s.priceList.QuotePrice(rfq.VendorId, line.ItemId(), qty, *rfq.OrderedAt)
+		if err != nil || price == nil {
+			continue
+		}
+		promised := startOfDay(*rfq.OrderedAt).AddDate(0, 0, price.LeadTimeDays)
+		line.PromisedDate = &promised
+		if _, err := s.rfqProductRepo.UpdateProduct(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// repriceRfq prices the stored lines of an RFQ again and saves their amounts
+// together with the new totals.
+func (s *rfqService) repriceRfq(rfq *entity.Rfqs) error {
+	if err := s.taxes.PriceRfq(rfq); err != nil {
+		return err
+	}
+	for i := range rfq.Products {
+		if err := s.rfqProductRepo.UpdateLineAmounts(&rfq.Products[i]); err != nil {
+			return err
+		}
+	}
+	return s.rfqRepository.UpdateRfqTotals(rfq)
+}
+
+// RecalculateRfq brings line amounts and totals up to date after lines were
+// changed outside the RFQ screens, such as by tender bids.
+func (s *rfqService) RecalculateRfq(rfqId string) (*entity.Rfqs, error) {
+	rfq, err := s.FindRfqById(rfqId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repriceRfq(rfq); err != nil {
+		return nil, err
+	}
+	return rfq, nil
+}
+
+// checkPoApproval guards every way an RFQ can be ordered. Entering an
+// ordered status needs the approvals its rules ask for, and so does
+// repricing a purchase order through new lines. A tender bid is only
+// ordered by awarding the tender.
+func (s *rfqService) checkPoApproval(existing *entity.Rfqs, status string, lines []entity.RfqsProduct) error {
+	ordering := isOrdering(existing.Status, status)
+	repricing := existing.Status == "Purchase Order" && status == "Purchase Order" && len(lines) > 0
+	if !ordering && !repricing {
+		return nil
+	}
+	if ordering && existing.TenderId != "" {
+		return fmt.Errorf("RFQ is a bid in tender %s, award the tender instead", existing.TenderId)
+	}
+	candidate := *existing
+	if len(lines) > 0 {
+		candidate.Products = lines
+	}
+	return s.approvals.RequireApproval(&candidate)
+}
+
 func (s *rfqService) UpdateRfqStatus(rfqId string) (*entity.Rfqs, error) {
 	// Fetch the existing Manufacture Order
 	mo, err := s.rfqRepository.GetRfqById(rfqId)
@@ -206,10 +356,35 @@ func (s *rfqService) UpdateRfqStatus(rfqId string) (*entity.Rfqs, error) {
 	// Cycle through statuses
 	switch mo.Status {
 	case "RFQ":
+		if err := s.checkPoApproval(mo, "Purchase Order", nil); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		mo.OrderedAt = &now
+		if err := s.promiseLines(mo); err != nil {
+			return nil, err
+		}
 		mo.Status = "Purchase Order"
-	case "Purchase Order":
-		mo.Status = "Recived"
-	case "Recived":
+	case "Purchase Order", "Partially Received":
+		// Advancing the status receives everything still outstanding.
+		var lines []ReceiptLine
+		for _, line := range mo.Products {
+			outstanding, err := outstandingQty(line)
+			if err != nil {
+				return nil, err
+			}
+			if outstanding > 0 {
+				lines = append(lines, ReceiptLine{RfqsProductId: line.RfqsProductId, Quantity: outstanding})
+			}
+		}
+		if len(lines) > 0 {
+			if _, err := s.ReceiveRfq(mo.RfqId, "", "", lines); err != nil {
+				return nil, err
+			}
+			return s.rfqRepository.GetRfqById(mo.RfqId)
+		}
+		mo.Status = "Received"
+	case "Received":
 		mo.Status = "Done"
 	default:
 		return nil, errors.New("invalid status transition")
@@ -247,46 +422,39 @@ func (s *rfqService) CalculateOverview(rfqId string) (map[string]interface{}, er
 	overview["created_at"] = rfq.CreatedAt
 	overview["updated_at"] = rfq.UpdatedAt
 	overview["deleted_at"] = rfq.DeletedAt
+	overview["tax_rounding"] = rfq.TaxRounding
 	overview["products"] = []map[string]interface{}{}
-	totalCost := 0.0
 
-	// Iterasi produk pada RFQ untuk menghitung detail
+	// Iterasi produk pada RFQ untuk menampilkan detail
 	for _, product := range rfq.Products {
-		productDetails, err := s.rfqProductRepo.GetProductDetails(product.ProductId)
-		if err != nil {
-			return nil, err
-		}
-
-		// Kalkulasi harga subtotal
-		subtotal, err := strconv.ParseFloat(product.Subtotal, 64)
+		vendorPrice, err := s.itemListPrice(product)
 		if err != nil {
 			return nil, err
 		}
 
-		quantity, err := strconv.ParseFloat(product.Quantity, 64)
-		if err != nil {
-			return nil, err
-		}
-		productCost := subtotal * quantity
-
 		productDetail := map[string]interface{}{
+			"line_type":    product.LineType,
 			"product_id":   product.ProductId,
+			"material_id":  product.MaterialId,
 			"product_name": product.ProductName,
 			"quantity":     product.Quantity,
+			"unit":         product.Unit,
 			"unit_price":   product.UnitPrice,
+			"discount":     product.Discount,
+			"id_tax":       product.TaxId,
 			"tax":          product.Tax,
 			"subtotal":     product.Subtotal,
-			"total_cost":   fmt.Sprintf("Rp %.2f", productCost),
-			"vendor_price": productDetails.Sellprice, // Jika ingin menggunakan harga dari vendor
+			"tax_amount":   product.TaxAmount,
+			"total_cost":   fmt.Sprintf("Rp %.2f", product.Total),
+			"vendor_price": vendorPrice, // Jika ingin menggunakan harga dari vendor
 		}
 
-		// Hitung total biaya
-		totalCost += productCost
-
 		overview["products"] = append(overview["products"].([]map[string]interface{}), productDetail)
 	}
 
-	overview["total_cost"] = fmt.Sprintf("Rp %.2f", totalCost)
+	overview["amount_untaxed"] = fmt.Sprintf("Rp %.2f", rfq.AmountUntaxed)
+	overview["amount_tax"] = fmt.Sprintf("Rp %.2f", rfq.AmountTax)
+	overview["total_cost"] = fmt.Sprintf("Rp %.2f", rfq.AmountTotal)
 	return overview, nil
 }
 
@@ -313,12 +481,13 @@ func (s *rfqService) SendRfqEmail(rfqId string, recipientEmail string) error {
 
 	// Kirim email menggunakan service email (pastikan sudah diinisialisasi)
 	err = s.emailSender.SendRfqEmail(
-		recipientEmail, // Pass the email recipient as string
-		rfq.RfqId,      // RFQ ID
-		rfq.VendorId,   // Vendor ID
-		rfq.OrderDate,  // Order Date
-		rfq.Status,     // Status
-		rfq.Products,   // List of products
+		recipientEmail,     // Pass the email recipient as string
+		rfq.RfqId,          // RFQ ID
+		rfq.VendorId,       // Vendor ID
+		rfq.OrderDate,      // Order Date
+		rfq.Status,         // Status
+		rfq.Products,       // List of products
+		rfq.DocumentTotals, // Totals
 	)
 	if err != nil {
 		return fmt.Errorf("failed to send RFQ email: %v", err)
@@ -402,12 +571,12 @@ func (s *rfqService) CreateRfqPDF(rfqId string, recipientEmail string) ([]byte,
 
 	// Products Table
 	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(40, 10, "Products:")
+	pdf.Cell(40, 10, "Items:")
 	pdf.Ln(6)
 
 	// Table Header
 	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(50, 10, "Product Name")
+	pdf.Cell(50, 10, "Item")
 	pdf.Cell(30, 10, "Quantity")
 	pdf.Cell(40, 10, "Unit Price")
 	pdf.Cell(40, 10, "Subtotal")
@@ -417,11 +586,12 @@ func (s *rfqService) CreateRfqPDF(rfqId string, recipientEmail string) ([]byte,
 	pdf.SetFont("Arial", "", 12)
 	for _, product := range rfq.Products {
 		pdf.Cell(50, 10, product.ProductName)
-		pdf.Cell(30, 10, fmt.Sprintf("%s", product.Quantity))
+		pdf.Cell(30, 10, strings.TrimSpace(product.Quantity+" "+product.Unit))
 		pdf.Cell(40, 10, fmt.Sprintf("%s", product.UnitPrice))
 		pdf.Cell(40, 10, fmt.Sprintf("%s", product.Subtotal))
 		pdf.Ln(6)
 	}
+	addPdfTotals(pdf, rfq.DocumentTotals)
 
 	// Footer
 	pdf.SetY(-30)
@@ -450,6 +620,41 @@ func (s *rfqService) UpdateRfqAll(rfqId string, updatedRfq *entity.Rfqs) (*entit
 	if existingRfq == nil {
 		return nil, fmt.Errorf("RFQ with id %s not found", rfqId)
 	}
+	if updatedRfq.Status == "" {
+		updatedRfq.Status = existingRfq.Status
+	}
+	if err := checkStatusEditable(existingRfq.Status, updatedRfq.Status); err != nil {
+		return nil, err
+	}
+	if updatedRfq.TaxRounding == "" {
+		updatedRfq.TaxRounding = existingRfq.TaxRounding
+	}
+	roundingChanged := updatedRfq.TaxRounding != existingRfq.TaxRounding
+	var warnings []string
+	if len(updatedRfq.Products) > 0 {
+		if err := s.checkLinesEditable(rfqId); err != nil {
+			return nil, err
+		}
+		if err := s.resolveRfqLines(updatedRfq.Products); err != nil {
+			return nil, err
+		}
+		// Prices typed by the buyer are kept; only empty ones are looked up.
+		if warnings, err = s.priceList.PriceRfqLines(updatedRfq); err != nil {
+			return nil, err
+		}
+		if err := s.taxes.PriceRfq(updatedRfq); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkPoApproval(existingRfq, updatedRfq.Status, updatedRfq.Products); err != nil {
+		return nil, err
+	}
+
+	ordered := isOrdering(existingRfq.Status, updatedRfq.Status)
+	if ordered {
+		now := time.Now()
+		existingRfq.OrderedAt = &now
+	}
 
 	// Update informasi RFQ
 	existingRfq.OrderDate = updatedRfq.OrderDate
@@ -493,6 +698,23 @@ func (s *rfqService) UpdateRfqAll(rfqId string, updatedRfq *entity.Rfqs) (*entit
 		return nil, err
 	}
 	savedRfq.Products = products
+	savedRfq.Warnings = warnings
+	if len(updatedRfq.Products) > 0 {
+		savedRfq.DocumentTotals = updatedRfq.DocumentTotals
+		if err := s.rfqRepository.UpdateRfqTotals(savedRfq); err != nil {
+			return nil, err
+		}
+	} else if roundingChanged {
+		savedRfq.TaxRounding = updatedRfq.TaxRounding
+		if err := s.repriceRfq(savedRfq); err != nil {
+			return nil, err
+		}
+	}
+	if ordered {
+		if err := s.promiseLines(savedRfq); err != nil {
+			return nil, err
+		}
+	}
 
 	return savedRfq, nil
 }
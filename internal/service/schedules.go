@@ -20,15 +20,26 @@ type SchedulesService interface {
 
 type schedulesService struct {
 	schedulesRepository repository.SchedulesRepository
+	tanks               *tankScheduler
 }
 
-func NewSchedulesService(schedulesRepository repository.SchedulesRepository) *schedulesService {
+func NewSchedulesService(schedulesRepository repository.SchedulesRepository, vesselRepo repository.VesselRepository) *schedulesService {
 	return &schedulesService{
 		schedulesRepository: schedulesRepository,
+		tanks:               newTankScheduler(vesselRepo, schedulesRepository),
 	}
 }
 
 func (s *schedulesService) CreateSchedules(schedules *entity.Schedules) (*entity.Schedules, error) {
+	if schedules.VesselId != "" {
+		start, end, ok := bookingPeriod(*schedules)
+		if !ok {
+			return nil, errors.New("invalid schedule date")
+		}
+		if err := s.tanks.checkBooking(schedules.VesselId, start, end, schedules.VolumeLitres, schedules.SchedulesId); err != nil {
+			return nil, err
+		}
+	}
 	return s.schedulesRepository.CreateSchedules(schedules)
 }
 
@@ -57,6 +68,27 @@ func (s *schedulesService) UpdateSchedule(schedule *entity.Schedules) (*entity.S
 		return nil, errors.New("date cannot be empty")
 	}
 
+	// Moving a tank booking keeps its length and must not clash with other
+	// bookings of the tank.
+	existing, err := s.schedulesRepository.FindScheduleByID(schedule.SchedulesId)
+	if err != nil {
+		return nil, err
+	}
+	if existing.VesselId != "" {
+		oldStart, oldEnd, _ := bookingPeriod(*existing)
+		start, ok := parseDocumentDate(schedule.Date_schedules)
+		if !ok {
+			return nil, errors.New("invalid schedule date")
+		}
+		end := start.Add(oldEnd.Sub(oldStart))
+		if err := s.tanks.checkBooking(existing.VesselId, start, end, existing.VolumeLitres, existing.SchedulesId); err != nil {
+			return nil, err
+		}
+		if _, err := s.schedulesRepository.UpdateTankBookingEnd(existing.SchedulesId, end); err != nil {
+			return nil, err
+		}
+	}
+
 	return s.schedulesRepository.UpdateSchedule(schedule)
 }
 
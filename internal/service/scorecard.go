@@ -0,0 +1,306 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
+)
+
+// VendorScorecard is how a vendor performed on the orders placed with them
+// in a period. Rates are percentages and stay nil when the period holds
+// nothing to judge them on.
+type VendorScorecard struct {
+	VendorId   string    `json:"id_vendor"`
+	VendorName string    `json:"vendorname"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	Orders     int       `json:"orders"`
+
+	LinesJudged   int      `json:"lines_judged"` // lines due or delivered in full against a promised date
+	LinesOnTime   int      `json:"lines_on_time"`
+	OnTimeRate    *float64 `json:"on_time_rate"`
+	QtyOrdered    float64  `json:"qty_ordered"` // over lines with nothing outstanding
+	QtyReceived   float64  `json:"qty_received"`
+	FillRate      *float64 `json:"fill_rate"`
+	ListValue     float64  `json:"list_value"` // what the lines cost at list price
+	PaidValue     float64  `json:"paid_value"` // what they were billed, or ordered at when not billed yet
+	PriceVariance *float64 `json:"price_variance"`
+	QtyInspected  float64  `json:"qty_inspected"`
+	QtyRejected   float64  `json:"qty_rejected"`
+	RejectRate    *float64 `json:"qc_rejection_rate"`
+	AvgLeadDays   *float64 `json:"avg_lead_time_days"`
+	Score         *float64 `json:"score"`
+
+	QtyReturned   float64            `json:"qty_returned"`
+	ReturnRate    *float64           `json:"return_rate"` // of the quantity delivered
+	ReturnReasons map[string]float64 `json:"returns_by_reason"`
+}
+
+// scoreWeights weigh the rates into the overall score. Components without
+// data are left out and the remaining weights scaled up.
+var scoreWeights = struct{ onTime, fill, quality, price float64 }{0.35, 0.25, 0.3, 0.1}
+
+type ScorecardService interface {
+	GetVendorScorecard(vendorId string, from, to time.Time) (*VendorScorecard, error)
+	RankVendors(from, to time.Time) ([]VendorScorecard, error)
+}
+
+type scorecardService struct {
+	vendorRepository  repository.VendorRepository
+	rfqRepository     repository.RfqRepository
+	receiptRepo       repository.RfqReceiptRepository
+	qualityRepo       repository.QualityRepository
+	billrfqRepository repository.BillrfqRepository
+	returnRepo        repository.PurchaseReturnRepository
+	priceList         PriceListService
+}
+
+func NewScorecardService(vendorRepository repository.VendorRepository, rfqRepository repository.RfqRepository,
+	receiptRepo repository.RfqReceiptRepository, qualityRepo repository.QualityRepository,
+	billrfqRepository repository.BillrfqRepository, returnRepo repository.PurchaseReturnRepository,
+	priceList PriceListService) *scorecardService {
+	return &scorecardService{
+		vendorRepository:  vendorRepository,
+		rfqRepository:     rfqRepository,
+		receiptRepo:       receiptRepo,
+		qualityRepo:       qualityRepo,
+		billrfqRepository: billrfqRepository,
+		returnRepo:        returnRepo,
+		priceList:         priceList,
+	}
+}
+
+func percentOf(part, whole float64) *float64 {
+	if whole <= 0 {
+		return nil
+	}
+	rate := roundCost(part / whole * 100)
+	return &rate
+}
+
+func (s *scorecardService) GetVendorScorecard(vendorId string, from, to time.Time) (*VendorScorecard, error) {
+	vendor, err := s.vendorRepository.FindVendorByID(vendorId)
+	if err != nil {
+		return nil, fmt.Errorf("vendor with id %s not found", vendorId)
+	}
+	orders, err := s.rfqRepository.FindPurchaseOrders(vendorId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return s.score(vendor, orders, from, to)
+}
+
+// RankVendors scores every vendor ordered from in the period, best first.
+// Vendors without a score, having nothing to judge yet, come last.
+func (s *scorecardService) RankVendors(from, to time.Time) ([]VendorScorecard, error) {
+	orders, err := s.rfqRepository.FindPurchaseOrders("", from, to)
+	if err != nil {
+		return nil, err
+	}
+	byVendor := make(map[string][]entity.Rfqs)
+	var vendorIds []string
+	for _, order := range orders {
+		if _, ok := byVendor[order.VendorId]; !ok {
+			vendorIds = append(vendorIds, order.VendorId)
+		}
+		byVendor[order.VendorId] = append(byVendor[order.VendorId], order)
+	}
+
+	scorecards := make([]VendorScorecard, 0, len(vendorIds))
+	for _, vendorId := range vendorIds {
+		vendor, err := s.vendorRepository.FindVendorByID(vendorId)
+		if err != nil {
+			vendor = &entity.Vendors{VendorId: vendorId}
+		}
+		scorecard, err := s.score(vendor, byVendor[vendorId], from, to)
+		if err != nil {
+			return nil, err
+		}
+		scorecards = append(scorecards, *scorecard)
+	}
+	sort.SliceStable(scorecards, func(i, j int) bool {
+		a, b := scorecards[i].Score, scorecards[j].Score
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return *a > *b
+	})
+	return scorecards, nil
+}
+
+func (s *scorecardService) score(vendor *entity.Vendors, orders []entity.Rfqs, from, to time.Time) (*VendorScorecard, error) {
+	card := &VendorScorecard{
+		VendorId:      vendor.VendorId,
+		VendorName:    vendor.Vendorname,
+		From:          from,
+		To:            to,
+		Orders:        len(orders),
+		ReturnReasons: make(map[string]float64),
+	}
+	now := time.Now()
+	leadDays, leadQty := 0.0, 0.0
+
+	for _, order := range orders {
+		orderedAt := order.CreatedAt
+		if order.OrderedAt != nil {
+			orderedAt = *order.OrderedAt
+		}
+
+		receipts, err := s.receiptRepo.FindReceiptsByRfqId(order.RfqId)
+		if err != nil {
+			return nil, err
+		}
+		deliveries := make(map[string][]entity.RfqReceiptLine)
+		deliveredAt := make(map[string]time.Time)
+		for _, receipt := range receipts {
+			if receipt.Type != "receipt" {
+				continue
+			}
+			for _, line := range receipt.Lines {
+				deliveries[line.RfqsProductId] = append(deliveries[line.RfqsProductId], line)
+				deliveredAt[line.ReceiptLineId] = receipt.CreatedAt
+				leadDays += receipt.CreatedAt.Sub(orderedAt).Hours() / 24 * line.Quantity
+				leadQty += line.Quantity
+			}
+		}
+
+		returns, err := s.returnRepo.FindReturnLinesByRfqId(order.RfqId)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range returns {
+			card.QtyReturned += line.Quantity
+			card.ReturnReasons[line.Reason] += line.Quantity
+		}
+
+		billed, err := s.billrfqRepository.FindPostedLinesByRfqId(order.RfqId)
+		if err != nil {
+			return nil, err
+		}
+		billedValue := make(map[string]float64)
+		billedQty := make(map[string]float64)
+		for _, line := range billed {
+			billedValue[line.RfqsProductId] += line.UnitPrice * line.Quantity
+			billedQty[line.RfqsProductId] += line.Quantity
+		}
+
+		for _, line := range order.Products {
+			ordered, err := strconv.ParseFloat(line.Quantity, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quantity for %s: %v", line.ProductName, err)
+			}
+			outstanding, _ := outstandingQty(line)
+			if outstanding <= receiptTolerance {
+				card.QtyOrdered += ordered
+				card.QtyReceived += line.QtyReceived
+			}
+
+			// A line is on time when everything still wanted of it arrived
+			// by the end of the promised day. Lines not yet due and not yet
+			// delivered are not judged.
+			wanted := ordered - line.QtyCancelled
+			if line.PromisedDate != nil && wanted > receiptTolerance {
+				due := startOfDay(*line.PromisedDate).AddDate(0, 0, 1)
+				byDue := 0.0
+				for _, delivery := range deliveries[line.RfqsProductId] {
+					if deliveredAt[delivery.ReceiptLineId].Before(due) {
+						byDue += delivery.Quantity
+					}
+				}
+				onTime := byDue >= wanted-receiptTolerance
+				if onTime || now.After(due) {
+					card.LinesJudged++
+					if onTime {
+						card.LinesOnTime++
+					}
+				}
+			}
+
+			listed, err := s.priceList.PriceInEffect(order.VendorId, line.ItemId(), ordered, orderedAt)
+			if err != nil || listed == nil || listed.UnitPrice <= 0 {
+				continue
+			}
+			paid := 0.0
+			if billedQty[line.RfqsProductId] > 0 {
+				paid = billedValue[line.RfqsProductId] / billedQty[line.RfqsProductId]
+			} else if paid, err = strconv.ParseFloat(line.UnitPrice, 64); err != nil {
+				continue
+			} else {
+				paid *= 1 - line.Discount/100
+			}
+			card.ListValue += listed.UnitPrice * ordered
+			card.PaidValue += paid * ordered
+		}
+	}
+
+	inspections, err := s.qualityRepo.FindVendorInspections(vendor.VendorId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, inspection := range inspections {
+		switch inspection.Status {
+		case "released":
+			card.QtyInspected += inspection.Quantity
+		case "rejected":
+			card.QtyInspected += inspection.Quantity
+			card.QtyRejected += inspection.Quantity
+		}
+	}
+
+	card.ListValue = roundCost(card.ListValue)
+	card.PaidValue = roundCost(card.PaidValue)
+	card.OnTimeRate = percentOf(float64(card.LinesOnTime), float64(card.LinesJudged))
+	card.FillRate = percentOf(card.QtyReceived, card.QtyOrdered)
+	card.RejectRate = percentOf(card.QtyRejected, card.QtyInspected)
+	card.ReturnRate = percentOf(card.QtyReturned, leadQty)
+	if card.ListValue > 0 {
+		variance := roundCost((card.PaidValue - card.ListValue) / card.ListValue * 100)
+		card.PriceVariance = &variance
+	}
+	if leadQty > 0 {
+		days := roundCost(leadDays / leadQty)
+		card.AvgLeadDays = &days
+	}
+	card.Score = overallScore(card)
+	return card, nil
+}
+
+// overallScore blends the rates into one figure out of 100. Quality is judged
+// on the worse of the QC rejection and return rates, since a lot failing QC
+// is usually also sent back. Paying above list costs points, paying below it
+// earns none.
+func overallScore(card *VendorScorecard) *float64 {
+	total, weight := 0.0, 0.0
+	if card.OnTimeRate != nil {
+		total += *card.OnTimeRate * scoreWeights.onTime
+		weight += scoreWeights.onTime
+	}
+	if card.FillRate != nil {
+		total += math.Min(*card.FillRate, 100) * scoreWeights.fill
+		weight += scoreWeights.fill
+	}
+	if card.RejectRate != nil || card.ReturnRate != nil {
+		worst := 0.0
+		for _, rate := range []*float64{card.RejectRate, card.ReturnRate} {
+			if rate != nil {
+				worst = math.Max(worst, *rate)
+			}
+		}
+		total += (100 - math.Min(worst, 100)) * scoreWeights.quality
+		weight += scoreWeights.quality
+	}
+	if card.PriceVariance != nil {
+		total += math.Max(100-math.Max(*card.PriceVariance, 0), 0) * scoreWeights.price
+		weight += scoreWeights.price
+	}
+	if weight == 0 {
+		return nil
+	}
+	score := roundCost(total / weight)
+	return &score
+}
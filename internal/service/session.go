@@ -0,0 +1,27 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/token"
+	"github.com/google/uuid"
+)
+
+// issueSession signs an access token for a login and stores it with the
+// user, so requests can be traced back to a current session and a new login
+// ends the previous one.
+func issueSession(tokenUseCase token.TokenUseCase, save func(uuid.UUID, string, time.Time) error,
+	userId uuid.UUID, email, role string) (string, error) {
+	accessToken, expiresAt, err := tokenUseCase.GenerateAccessToken(token.JwtCustomClaims{
+		UserId: userId.String(),
+		Email:  email,
+		Role:   role,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := save(userId, accessToken, expiresAt); err != nil {
+		return "", err
+	}
+	return accessToken, nil
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// taxRoundings are the ways a document can round its tax.
+var taxRoundings = map[string]bool{"line": true, "document": true}
+
+// pricedLine is a document line as the pricing engine sees it. Amounts points
+// into the line so the engine can write its figures back.
+type pricedLine struct {
+	name      string
+	quantity  string
+	unitPrice string
+	amounts   *entity.LineAmounts
+}
+
+type TaxService interface {
+	CreateTax(tax *entity.Tax) (*entity.Tax, error)
+	UpdateTax(tax *entity.Tax) (*entity.Tax, error)
+	DeleteTax(taxId string) (bool, error)
+	FindAllTaxes() ([]entity.Tax, error)
+	PriceRfq(rfq *entity.Rfqs) error
+	PriceQuotation(quo *entity.Quotations) error
+}
+
+type taxService struct {
+	taxRepo repository.TaxRepository
+}
+
+func NewTaxService(taxRepo repository.TaxRepository) *taxService {
+	return &taxService{taxRepo: taxRepo}
+}
+
+func validateTax(tax *entity.Tax) error {
+	if strings.TrimSpace(tax.Name) == "" {
+		return errors.New("a tax needs a name")
+	}
+	if tax.Rate < 0 {
+		return errors.New("tax rate cannot be negative")
+	}
+	return nil
+}
+
+func (s *taxService) CreateTax(tax *entity.Tax) (*entity.Tax, error) {
+	if err := validateTax(tax); err != nil {
+		return nil, err
+	}
+	lastId, err := s.taxRepo.GetLastTaxId()
+	if err != nil {
+		return nil, err
+	}
+	return s.taxRepo.CreateTax(entity.NewTax(lastId, *tax))
+}
+
+// UpdateTax changes a tax rule. Lines already priced keep their figures until
+// their document is priced again.
+func (s *taxService) UpdateTax(tax *entity.Tax) (*entity.Tax, error) {
+	existing, err := s.taxRepo.FindTaxByID(tax.TaxId)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("tax with id %s not found", tax.TaxId)
+	}
+	if err := validateTax(tax); err != nil {
+		return nil, err
+	}
+
+	existing.Name = tax.Name
+	existing.Rate = tax.Rate
+	existing.PriceIncluded = tax.PriceIncluded
+	existing.Active = tax.Active
+	existing.UpdatedAt = time.Now()
+	return s.taxRepo.UpdateTax(existing)
+}
+
+func (s *taxService) DeleteTax(taxId string) (bool, error) {
+	tax, err := s.taxRepo.FindTaxByID(taxId)
+	if err != nil {
+		return false, err
+	}
+	if tax == nil {
+		return false, fmt.Errorf("tax with id %s not found", taxId)
+	}
+	return s.taxRepo.DeleteTax(tax)
+}
+
+func (s *taxService) FindAllTaxes() ([]entity.Tax, error) {
+	return s.taxRepo.FindAllTaxes()
+}
+
+// PriceRfq works out the amounts of every line of the RFQ and its totals.
+func (s *taxService) PriceRfq(rfq *entity.Rfqs) error {
+	lines := make([]pricedLine, 0, len(rfq.Products))
+	for i := range rfq.Products {
+		line := &rfq.Products[i]
+		lines = append(lines, pricedLine{line.ProductName, line.Quantity, line.UnitPrice, &line.LineAmounts})
+	}
+	return s.priceDocument(lines, &rfq.DocumentTotals)
+}
+
+// PriceQuotation works out the amounts of every line of the quotation and its
+// totals.
+func (s *taxService) PriceQuotation(quo *entity.Quotations) error {
+	lines := make([]pricedLine, 0, len(quo.Products))
+	for i := range quo.Products {
+		line := &quo.Products[i]
+		lines = append(lines, pricedLine{line.ProductName, line.Quantity, line.UnitPrice, &line.LineAmounts})
+	}
+	return s.priceDocument(lines, &quo.DocumentTotals)
+}
+
+// priceDocument prices each line from quantity, unit price, discount and tax,
+// then totals the document. Line figures are always rounded to the cent;
+// whether the totals add those up or round the exact sums once is up to the
+// document's tax rounding.
+func (s *taxService) priceDocument(lines []pricedLine, totals *entity.DocumentTotals) error {
+	if totals.TaxRounding == "" {
+		totals.TaxRounding = "line"
+	}
+	if !taxRoundings[totals.TaxRounding] {
+		return fmt.Errorf("tax rounding must be line or document, not %q", totals.TaxRounding)
+	}
+
+	taxes := make(map[string]*entity.Tax)
+	var exactUntaxed, exactTax, lineUntaxed, lineTax float64
+	for _, line := range lines {
+		qty, err := strconv.ParseFloat(line.quantity, 64)
+		if err != nil {
+			return fmt.Errorf("invalid quantity for %s: %v", line.name, err)
+		}
+		price := 0.0
+		if strings.TrimSpace(line.unitPrice) != "" {
+			if price, err = strconv.ParseFloat(line.unitPrice, 64); err != nil {
+				return fmt.Errorf("invalid unit price for %s: %v", line.name, err)
+			}
+		}
+		if qty < 0 || price < 0 {
+			return fmt.Errorf("quantity and unit price of %s cannot be negative", line.name)
+		}
+		if line.amounts.Discount < 0 || line.amounts.Discount > 100 {
+			return fmt.Errorf("discount on %s must be between 0 and 100 percent", line.name)
+		}
+		rate, included, err := s.lineTax(line, taxes)
+		if err != nil {
+			return err
+		}
+
+		gross := qty * price * (1 - line.amounts.Discount/100)
+		untaxed, tax, subtotal, taxAmount := splitTax(gross, rate, included)
+
+		line.amounts.Subtotal = fmt.Sprintf("%.2f", subtotal)
+		line.amounts.TaxAmount = taxAmount
+		line.amounts.Total = roundCost(subtotal + taxAmount)
+		exactUntaxed += untaxed
+		exactTax += tax
+		lineUntaxed += subtotal
+		lineTax += taxAmount
+	}
+
+	if totals.TaxRounding == "document" {
+		totals.AmountUntaxed = roundCost(exactUntaxed)
+		totals.AmountTax = roundCost(exactTax)
+	} else {
+		totals.AmountUntaxed = roundCost(lineUntaxed)
+		totals.AmountTax = roundCost(lineTax)
+	}
+	totals.AmountTotal = roundCost(totals.AmountUntaxed + totals.AmountTax)
+	return nil
+}
+
+// splitTax splits what a line comes to after its discount into the untaxed
+// amount and the tax on it, both exact and rounded to the cent.
+func splitTax(gross, rate float64, included bool) (untaxed, tax, subtotal, taxAmount float64) {
+	if !included {
+		untaxed, tax = gross, gross*rate/100
+		return untaxed, tax, roundCost(untaxed), roundCost(tax)
+	}
+	untaxed = gross / (1 + rate/100)
+	tax = gross - untaxed
+	// The price the vendor asked for is the line total; tax is what is left
+	// of it once the untaxed amount is rounded.
+	subtotal = roundCost(untaxed)
+	return untaxed, tax, subtotal, roundCost(roundCost(gross) - subtotal)
+}
+
+// lineTax returns the rate, in percent, a line is taxed at and whether it is
+// included in the unit price. A line names a tax rule through TaxId; without
+// one, its Tax is read as a rate added on top of the price.
+func (s *taxService) lineTax(line pricedLine, taxes map[string]*entity.Tax) (float64, bool, error) {
+	amounts := line.amounts
+	if amounts.TaxId == "" {
+		if strings.TrimSpace(amounts.Tax) == "" {
+			amounts.Tax = "0"
+			return 0, false, nil
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(amounts.Tax), "%"), 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid tax for %s: %v", line.name, err)
+		}
+		if rate < 0 {
+			return 0, false, fmt.Errorf("tax on %s cannot be negative", line.name)
+		}
+		return rate, false, nil
+	}
+
+	tax, ok := taxes[amounts.TaxId]
+	if !ok {
+		var err error
+		if tax, err = s.taxRepo.FindTaxByID(amounts.TaxId); err != nil {
+			return 0, false, err
+		}
+		taxes[amounts.TaxId] = tax
+	}
+	if tax == nil {
+		return 0, false, fmt.Errorf("tax with id %s does not exist", amounts.TaxId)
+	}
+	if !tax.Active {
+		return 0, false, fmt.Errorf("tax %s is no longer active", tax.Name)
+	}
+	amounts.Tax = strconv.FormatFloat(tax.Rate, 'f', -1, 64)
+	return tax.Rate, tax.PriceIncluded, nil
+}
+
+// addPdfTotals writes the untaxed, tax and grand totals of a document under
+// its line table, aligned with the subtotal column.
+func addPdfTotals(pdf *gofpdf.Fpdf, totals entity.DocumentTotals) {
+	pdf.Ln(4)
+	rows := []struct {
+		label  string
+		amount float64
+	}{
+		{"Untaxed", totals.AmountUntaxed},
+		{"Tax", totals.AmountTax},
+		{"Total", totals.AmountTotal},
+	}
+	for i, row := range rows {
+		style := ""
+		if i == len(rows)-1 {
+			style = "B"
+		}
+		pdf.SetFont("Arial", style, 12)
+		pdf.Cell(120, 10, "")
+		pdf.Cell(40, 10, fmt.Sprintf("%s: Rp %.2f", row.label, row.amount))
+		pdf.Ln(6)
+	}
+}
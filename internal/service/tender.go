@@ -0,0 +1,531 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
+)
+
+type TenderService interface {
+	CreateTender(tender *entity.Tender, vendorIds []string) (*entity.Tender, error)
+	GetTender(tenderId string) (*entity.Tender, error)
+	FindAllTenders(page int) ([]entity.Tender, error)
+	SendTender(tenderId string) (map[string]string, error)
+	RecordBids(tenderId, vendorId string, bids []entity.TenderBid) ([]entity.TenderBid, error)
+	CompareBids(tenderId string) (map[string]interface{}, error)
+	AwardTender(tenderId string, awards map[string]string) (*entity.Tender, error)
+	CancelTender(tenderId string) (*entity.Tender, error)
+}
+
+type tenderService struct {
+	tenderRepo       repository.TenderRepository
+	rfqRepository    repository.RfqRepository
+	rfqProductRepo   repository.RfqProductRepository
+	vendorRepository repository.VendorRepository
+	materialRepo     repository.MaterialRepository
+	rfqService       RfqService
+	approvals        PoApprovalService
+}
+
+func NewTenderService(tenderRepo repository.TenderRepository, rfqRepository repository.RfqRepository,
+	rfqProductRepo repository.RfqProductRepository, vendorRepository repository.VendorRepository,
+	materialRepo repository.MaterialRepository, rfqService RfqService, approvals PoApprovalService) *tenderService {
+	return &tenderService{
+		tenderRepo:       tenderRepo,
+		rfqRepository:    rfqRepository,
+		rfqProductRepo:   rfqProductRepo,
+		vendorRepository: vendorRepository,
+		materialRepo:     materialRepo,
+		rfqService:       rfqService,
+		approvals:        approvals,
+	}
+}
+
+// CreateTender opens a tender for the lines and issues one RFQ per vendor.
+func (s *tenderService) CreateTender(tender *entity.Tender, vendorIds []string) (*entity.Tender, error) {
+	invited := make(map[string]bool)
+	var vendors []string
+	for _, vendorId := range vendorIds {
+		if invited[vendorId] {
+			continue
+		}
+		if _, err := s.vendorRepository.FindVendorByID(vendorId); err != nil {
+			return nil, fmt.Errorf("vendor with id %s not found", vendorId)
+		}
+		invited[vendorId] = true
+		vendors = append(vendors, vendorId)
+	}
+	if len(vendors) < 2 {
+		return nil, errors.New("a tender needs at least two vendors")
+	}
+	if len(tender.Lines) == 0 {
+		return nil, errors.New("a tender needs at least one line")
+	}
+
+	items := make(map[string]bool)
+	for i := range tender.Lines {
+		line := &tender.Lines[i]
+		if items[line.ItemId] {
+			return nil, fmt.Errorf("material %s is asked for more than once", line.ItemId)
+		}
+		items[line.ItemId] = true
+		if line.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity of %s must be greater than zero", line.ItemId)
+		}
+		material, err := s.materialRepo.FindMaterialByID(line.ItemId)
+		if err != nil {
+			return nil, fmt.Errorf("material with id %s not found", line.ItemId)
+		}
+		line.ItemName = material.Materialname
+	}
+
+	lastId, err := s.tenderRepo.GetLastTenderId()
+	if err != nil {
+		return nil, err
+	}
+	saved, err := s.tenderRepo.CreateTender(entity.NewTender(lastId, tender.Title, tender.OrderDate, tender.Deadline))
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range tender.Lines {
+		lastLineId, err := s.tenderRepo.GetLastTenderLineId()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.tenderRepo.CreateTenderLine(entity.NewTenderLine(lastLineId, saved.TenderId, line.ItemId, line.ItemName, line.Quantity)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, vendorId := range vendors {
+		rfq := entity.NewRfqs("", tender.OrderDate, "", vendorId)
+		rfq.TenderId = saved.TenderId
+		for _, line := range tender.Lines {
+			rfq.Products = append(rfq.Products, entity.RfqsProduct{
+				LineType:    "material",
+				MaterialId:  line.ItemId,
+				ProductName: line.ItemName,
+				Quantity:    strconv.FormatFloat(line.Quantity, 'f', -1, 64),
+				UnitPrice:   "0",
+				VendorId:    vendorId,
+			})
+		}
+		savedRfq, err := s.rfqService.CreateRfq(rfq)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create RFQ for vendor %s: %v", vendorId, err)
+		}
+
+		lastVendorId, err := s.tenderRepo.GetLastTenderVendorId()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.tenderRepo.CreateTenderVendor(entity.NewTenderVendor(lastVendorId, saved.TenderId, vendorId, savedRfq.RfqId)); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetTender(saved.TenderId)
+}
+
+func (s *tenderService) GetTender(tenderId string) (*entity.Tender, error) {
+	tender, err := s.tenderRepo.FindTenderByID(tenderId)
+	if err != nil {
+		return nil, err
+	}
+	if tender == nil {
+		return nil, fmt.Errorf("tender with id %s not found", tenderId)
+	}
+	return tender, nil
+}
+
+func (s *tenderService) FindAllTenders(page int) ([]entity.Tender, error) {
+	return s.tenderRepo.FindAllTenders(page)
+}
+
+func (s *tenderService) openTender(tenderId string) (*entity.Tender, error) {
+	tender, err := s.GetTender(tenderId)
+	if err != nil {
+		return nil, err
+	}
+	if tender.Status != "Open" {
+		return nil, fmt.Errorf("tender is %s", tender.Status)
+	}
+	return tender, nil
+}
+
+// SendTender emails every invited vendor their RFQ. A vendor that cannot be
+// reached does not stop the others; the result says what happened per vendor.
+func (s *tenderService) SendTender(tenderId string) (map[string]string, error) {
+	tender, err := s.openTender(tenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]string)
+	for i := range tender.Vendors {
+		vendor := &tender.Vendors[i]
+		email, err := s.rfqService.GetEmailByVendorId(vendor.VendorId)
+		if err == nil {
+			err = s.rfqService.SendRfqEmail(vendor.RfqId, email)
+		}
+		if err != nil {
+			results[vendor.VendorId] = err.Error()
+			continue
+		}
+
+		now := time.Now()
+		vendor.SentAt = &now
+		if vendor.Status == "invited" {
+			vendor.Status = "sent"
+		}
+		vendor.UpdatedAt = now
+		if _, err := s.tenderRepo.UpdateTenderVendor(vendor); err != nil {
+			return nil, err
+		}
+		results[vendor.VendorId] = "sent to " + email
+	}
+	return results, nil
+}
+
+// RecordBids stores the prices and lead times a vendor quoted. Quoting again
+// for a line replaces the earlier bid. The vendor's RFQ is kept in step.
+func (s *tenderService) RecordBids(tenderId, vendorId string, bids []entity.TenderBid) ([]entity.TenderBid, error) {
+	tender, err := s.openTender(tenderId)
+	if err != nil {
+		return nil, err
+	}
+	var vendor *entity.TenderVendor
+	for i := range tender.Vendors {
+		if tender.Vendors[i].VendorId == vendorId {
+			vendor = &tender.Vendors[i]
+		}
+	}
+	if vendor == nil {
+		return nil, fmt.Errorf("vendor %s is not invited to tender %s", vendorId, tenderId)
+	}
+	lines := make(map[string]entity.TenderLine)
+	for _, line := range tender.Lines {
+		lines[line.TenderLineId] = line
+	}
+
+	existing, err := s.tenderRepo.FindBidsByTenderId(tenderId)
+	if err != nil {
+		return nil, err
+	}
+	previous := make(map[string]*entity.TenderBid)
+	for i := range existing {
+		if existing[i].VendorId == vendorId {
+			previous[existing[i].TenderLineId] = &existing[i]
+		}
+	}
+
+	saved := make([]entity.TenderBid, 0, len(bids))
+	for _, bid := range bids {
+		line, ok := lines[bid.TenderLineId]
+		if !ok {
+			return nil, fmt.Errorf("line %s does not belong to tender %s", bid.TenderLineId, tenderId)
+		}
+		if bid.UnitPrice < 0 || bid.LeadTimeDays < 0 {
+			return nil, fmt.Errorf("price and lead time for %s cannot be negative", line.ItemName)
+		}
+
+		record := previous[bid.TenderLineId]
+		if record == nil {
+			lastId, err := s.tenderRepo.GetLastBidId()
+			if err != nil {
+				return nil, err
+			}
+			record = entity.NewTenderBid(lastId, tenderId, bid.TenderLineId, vendorId, bid.UnitPrice, bid.LeadTimeDays, bid.Note)
+		} else {
+			record.UnitPrice = bid.UnitPrice
+			record.LeadTimeDays = bid.LeadTimeDays
+			record.Note = bid.Note
+			record.UpdatedAt = time.Now()
+		}
+		if _, err := s.tenderRepo.SaveBid(record); err != nil {
+			return nil, err
+		}
+
+		product, err := s.rfqProductRepo.GetProductByRfqIdAndItemId(vendor.RfqId, line.ItemId)
+		if err != nil {
+			return nil, err
+		}
+		if product != nil {
+			product.UnitPrice = strconv.FormatFloat(bid.UnitPrice, 'f', -1, 64)
+			product.UpdatedAt = time.Now()
+			if _, err := s.rfqProductRepo.UpdateProduct(product); err != nil {
+				return nil, err
+			}
+		}
+		saved = append(saved, *record)
+	}
+	if _, err := s.rfqService.RecalculateRfq(vendor.RfqId); err != nil {
+		return nil, err
+	}
+
+	vendor.Status = "bid"
+	vendor.UpdatedAt = time.Now()
+	if _, err := s.tenderRepo.UpdateTenderVendor(vendor); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// CompareBids lays the bids out side by side per line, marking the cheapest
+// and the fastest vendor, with each vendor's total over the lines they bid.
+func (s *tenderService) CompareBids(tenderId string) (map[string]interface{}, error) {
+	tender, err := s.GetTender(tenderId)
+	if err != nil {
+		return nil, err
+	}
+	bids, err := s.tenderRepo.FindBidsByTenderId(tenderId)
+	if err != nil {
+		return nil, err
+	}
+	byLine := make(map[string][]entity.TenderBid)
+	for _, bid := range bids {
+		byLine[bid.TenderLineId] = append(byLine[bid.TenderLineId], bid)
+	}
+
+	vendorTotals := make(map[string]float64)
+	vendorLines := make(map[string]int)
+	lines := make([]map[string]interface{}, 0, len(tender.Lines))
+	for _, line := range tender.Lines {
+		offers := make([]map[string]interface{}, 0, len(byLine[line.TenderLineId]))
+		cheapest, fastest := "", ""
+		bestPrice, bestLead := math.Inf(1), math.MaxInt
+		for _, bid := range byLine[line.TenderLineId] {
+			total := roundCost(bid.UnitPrice * line.Quantity)
+			vendorTotals[bid.VendorId] += total
+			vendorLines[bid.VendorId]++
+			if bid.UnitPrice < bestPrice {
+				cheapest, bestPrice = bid.VendorId, bid.UnitPrice
+			}
+			if bid.LeadTimeDays < bestLead {
+				fastest, bestLead = bid.VendorId, bid.LeadTimeDays
+			}
+			offers = append(offers, map[string]interface{}{
+				"id_vendor":      bid.VendorId,
+				"unit_price":     bid.UnitPrice,
+				"total":          total,
+				"lead_time_days": bid.LeadTimeDays,
+				"note":           bid.Note,
+				"status":         bid.Status,
+			})
+		}
+		lines = append(lines, map[string]interface{}{
+			"id_tenderline":   line.TenderLineId,
+			"id_item":         line.ItemId,
+			"item_name":       line.ItemName,
+			"quantity":        line.Quantity,
+			"bids":            offers,
+			"cheapest_vendor": cheapest,
+			"fastest_vendor":  fastest,
+		})
+	}
+
+	vendors := make([]map[string]interface{}, 0, len(tender.Vendors))
+	for _, vendor := range tender.Vendors {
+		vendors = append(vendors, map[string]interface{}{
+			"id_vendor": vendor.VendorId,
+			"id_rfq":    vendor.RfqId,
+			"status":    vendor.Status,
+			"lines_bid": vendorLines[vendor.VendorId],
+			"complete":  vendorLines[vendor.VendorId] == len(tender.Lines),
+			"bid_total": roundCost(vendorTotals[vendor.VendorId]),
+		})
+	}
+
+	return map[string]interface{}{
+		"id_tender": tender.TenderId,
+		"title":     tender.Title,
+		"status":    tender.Status,
+		"deadline":  tender.Deadline,
+		"lines":     lines,
+		"vendors":   vendors,
+	}, nil
+}
+
+// AwardTender gives each awarded line to a vendor. A vendor's RFQ keeps the
+// lines it won, at the bid price, and becomes a purchase order; vendors that
+// won nothing have their RFQ cancelled as it was bid. Lines not awarded are
+// not ordered. Every vendor's RFQ must still be open.
+func (s *tenderService) AwardTender(tenderId string, awards map[string]string) (*entity.Tender, error) {
+	tender, err := s.openTender(tenderId)
+	if err != nil {
+		return nil, err
+	}
+	if len(awards) == 0 {
+		return nil, errors.New("award at least one line")
+	}
+
+	bids, err := s.tenderRepo.FindBidsByTenderId(tenderId)
+	if err != nil {
+		return nil, err
+	}
+	bidFor := make(map[string]*entity.TenderBid)
+	for i := range bids {
+		bidFor[bids[i].TenderLineId+"|"+bids[i].VendorId] = &bids[i]
+	}
+	lines := make(map[string]entity.TenderLine)
+	for _, line := range tender.Lines {
+		lines[line.TenderLineId] = line
+	}
+	won := make(map[string]map[string]*entity.TenderBid) // vendor -> item -> bid
+	for lineId, vendorId := range awards {
+		line, ok := lines[lineId]
+		if !ok {
+			return nil, fmt.Errorf("line %s does not belong to tender %s", lineId, tenderId)
+		}
+		bid, ok := bidFor[lineId+"|"+vendorId]
+		if !ok {
+			return nil, fmt.Errorf("vendor %s did not bid on %s", vendorId, line.ItemName)
+		}
+		if won[vendorId] == nil {
+			won[vendorId] = make(map[string]*entity.TenderBid)
+		}
+		won[vendorId][line.ItemId] = bid
+	}
+
+	rfqs := make(map[string]*entity.Rfqs)
+	for _, vendor := range tender.Vendors {
+		rfq, err := s.rfqRepository.GetRfqById(vendor.RfqId)
+		if err != nil {
+			return nil, err
+		}
+		if rfq == nil {
+			return nil, fmt.Errorf("RFQ %s of vendor %s no longer exists", vendor.RfqId, vendor.VendorId)
+		}
+		if rfq.Status != "RFQ" {
+			return nil, fmt.Errorf("RFQ %s of vendor %s is already %s", rfq.RfqId, vendor.VendorId, rfq.Status)
+		}
+		rfqs[vendor.VendorId] = rfq
+	}
+
+	// Every winning order must be approved as it will be placed, with only
+	// the lines won at the bid price, before anything is changed.
+	for vendorId, items := range won {
+		order := *rfqs[vendorId]
+		order.Products = nil
+		for _, product := range rfqs[vendorId].Products {
+			if bid, ok := items[product.ItemId()]; ok {
+				product.UnitPrice = strconv.FormatFloat(bid.UnitPrice, 'f', -1, 64)
+				order.Products = append(order.Products, product)
+			}
+		}
+		if err := s.approvals.RequireApproval(&order); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	for i := range tender.Vendors {
+		vendor := &tender.Vendors[i]
+		rfq := rfqs[vendor.VendorId]
+		if len(won[vendor.VendorId]) == 0 {
+			vendor.Status = "lost"
+			rfq.Status = "Cancelled"
+			rfq.UpdatedAt = time.Now()
+			if _, err := s.rfqRepository.UpdateRfqStatus(rfq); err != nil {
+				return nil, err
+			}
+			vendor.UpdatedAt = time.Now()
+			if _, err := s.tenderRepo.UpdateTenderVendor(vendor); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		for _, product := range rfq.Products {
+			bid, awarded := won[vendor.VendorId][product.ItemId()]
+			if !awarded {
+				if err := s.rfqProductRepo.DeleteProduct(&product); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			product.UnitPrice = strconv.FormatFloat(bid.UnitPrice, 'f', -1, 64)
+			if product.PromisedDate == nil {
+				promised := startOfDay(now).AddDate(0, 0, bid.LeadTimeDays)
+				product.PromisedDate = &promised
+			}
+			product.UpdatedAt = time.Now()
+			if _, err := s.rfqProductRepo.UpdateProduct(&product); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := s.rfqService.RecalculateRfq(rfq.RfqId); err != nil {
+			return nil, err
+		}
+
+		vendor.Status = "awarded"
+		rfq.Status = "Purchase Order"
+		rfq.OrderedAt = &now
+		rfq.UpdatedAt = time.Now()
+		if _, err := s.rfqRepository.UpdateRfqStatus(rfq); err != nil {
+			return nil, err
+		}
+		vendor.UpdatedAt = time.Now()
+		if _, err := s.tenderRepo.UpdateTenderVendor(vendor); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range bids {
+		bids[i].Status = "lost"
+		if awards[bids[i].TenderLineId] == bids[i].VendorId {
+			bids[i].Status = "awarded"
+		}
+		bids[i].UpdatedAt = time.Now()
+		if _, err := s.tenderRepo.SaveBid(&bids[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	tender.Status = "Awarded"
+	tender.UpdatedAt = time.Now()
+	if _, err := s.tenderRepo.UpdateTender(tender); err != nil {
+		return nil, err
+	}
+	return s.GetTender(tenderId)
+}
+
+// CancelTender calls the tender off and cancels every vendor's RFQ.
+func (s *tenderService) CancelTender(tenderId string) (*entity.Tender, error) {
+	tender, err := s.openTender(tenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range tender.Vendors {
+		vendor := &tender.Vendors[i]
+		rfq, err := s.rfqRepository.GetRfqById(vendor.RfqId)
+		if err != nil {
+			return nil, err
+		}
+		if rfq != nil {
+			rfq.Status = "Cancelled"
+			rfq.UpdatedAt = time.Now()
+			if _, err := s.rfqRepository.UpdateRfqStatus(rfq); err != nil {
+				return nil, err
+			}
+		}
+		vendor.Status = "cancelled"
+		vendor.UpdatedAt = time.Now()
+		if _, err := s.tenderRepo.UpdateTenderVendor(vendor); err != nil {
+			return nil, err
+		}
+	}
+
+	tender.Status = "Cancelled"
+	tender.UpdatedAt = time.Now()
+	if _, err := s.tenderRepo.UpdateTender(tender); err != nil {
+		return nil, err
+	}
+	return s.GetTender(tenderId)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
 	"github.com/Kevinmajesta/parfume-erp-backend/pkg/email"
 	"github.com/Kevinmajesta/parfume-erp-backend/pkg/encrypt"
+	"github.com/Kevinmajesta/parfume-erp-backend/pkg/token"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -24,6 +25,7 @@ type UserService interface {
 	GetUserProfileByID(userID string) (*entity.User, error)
 	VerifUser(resetCode string) error
 	CheckUserExists(id uuid.UUID) (bool, error)
+	Authenticate(accessToken string) (*entity.User, error)
 	// GetCart(UserId uuid.UUID) (binder.GetCartResponse, error)
 }
 
@@ -31,16 +33,18 @@ type userService struct {
 	userRepository repository.UserRepository
 	encryptTool    encrypt.EncryptTool
 	emailSender    *email.EmailSender
+	tokenUseCase   token.TokenUseCase
 }
 
 var InternalError = "internal server error"
 
 func NewUserService(userRepository repository.UserRepository,
-	encryptTool encrypt.EncryptTool, emailSender *email.EmailSender) *userService {
+	encryptTool encrypt.EncryptTool, emailSender *email.EmailSender, tokenUseCase token.TokenUseCase) *userService {
 	return &userService{
 		userRepository: userRepository,
 		encryptTool:    encryptTool,
 		emailSender:    emailSender,
+		tokenUseCase:   tokenUseCase,
 	}
 }
 
@@ -58,7 +62,29 @@ func (s *userService) LoginUser(email string, password string) (string, error) {
 	// Dekripsi nomor telepon jika perlu
 	user.Phone, _ = s.encryptTool.Decrypt(user.Phone)
 
-	return " ", nil
+	return issueSession(s.tokenUseCase, s.userRepository.UpdateUserJwtToken, user.UserId, user.Email, user.Role)
+}
+
+// Authenticate returns the user an access token was issued to, as long as it
+// is still their current session. Role and email come from the stored user,
+// not from the token, so changes apply straight away.
+func (s *userService) Authenticate(accessToken string) (*entity.User, error) {
+	claims, err := s.tokenUseCase.ParseAccessToken(accessToken)
+	if err != nil {
+		return nil, errors.New("invalid or expired session")
+	}
+	userId, err := uuid.Parse(claims.UserId)
+	if err != nil {
+		return nil, errors.New("invalid or expired session")
+	}
+	user, err := s.userRepository.FindUserBySession(userId, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.Status {
+		return nil, errors.New("invalid or expired session")
+	}
+	return user, nil
 }
 
 func (s *userService) CreateUser(user *entity.User) (*entity.User, error) {
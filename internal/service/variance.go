@@ -0,0 +1,241 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
+)
+
+type VarianceService interface {
+	GetMoVariance(moId string, reveal bool) (map[string]interface{}, error)
+	VarianceReport(groupBy, interval string, from, to time.Time, reveal bool) ([]map[string]interface{}, error)
+}
+
+type varianceService struct {
+	productionRepo repository.MoProductionRepository
+	moRepo         repository.MoRepository
+	productRepo    repository.ProductRepository
+}
+
+func NewVarianceService(productionRepo repository.MoProductionRepository, moRepo repository.MoRepository,
+	productRepo repository.ProductRepository) *varianceService {
+	return &varianceService{
+		productionRepo: productionRepo,
+		moRepo:         moRepo,
+		productRepo:    productRepo,
+	}
+}
+
+// varianceTotals accumulates theoretical against actual usage.
+type varianceTotals struct {
+	theoreticalQty, actualQty   float64
+	theoreticalCost, actualCost float64
+}
+
+func (t *varianceTotals) add(consumption entity.MoConsumption) {
+	t.theoreticalQty += consumption.QtyTheoretical
+	t.actualQty += consumption.QtyActual
+	t.theoreticalCost += consumption.QtyTheoretical * consumption.UnitCost
+	t.actualCost += consumption.QtyActual * consumption.UnitCost
+}
+
+// fill writes the totals and their variances into row. Quantities are only
+// comparable within one component, so callers mixing components pass
+// withQty false.
+func (t *varianceTotals) fill(row map[string]interface{}, withQty bool) map[string]interface{} {
+	if withQty {
+		row["qty_theoretical"] = roundCost(t.theoreticalQty)
+		row["qty_actual"] = roundCost(t.actualQty)
+		row["qty_variance"] = roundCost(t.actualQty - t.theoreticalQty)
+		row["qty_variance_percent"] = variancePercent(t.actualQty, t.theoreticalQty)
+	}
+	row["cost_theoretical"] = roundCost(t.theoreticalCost)
+	row["cost_actual"] = roundCost(t.actualCost)
+	row["cost_variance"] = roundCost(t.actualCost - t.theoreticalCost)
+	row["cost_variance_percent"] = variancePercent(t.actualCost, t.theoreticalCost)
+	return row
+}
+
+// redactVariance leaves only the variance percentages of a row.
+func redactVariance(row map[string]interface{}) {
+	for _, field := range []string{"qty_theoretical", "qty_actual", "qty_variance", "cost_theoretical", "cost_actual", "cost_variance"} {
+		delete(row, field)
+	}
+	row["redacted"] = true
+}
+
+func variancePercent(actual, theoretical float64) float64 {
+	if theoretical == 0 {
+		return 0
+	}
+	return math.Round((actual-theoretical)/theoretical*10000) / 100
+}
+
+// GetMoVariance compares what the MO used of each component with its BOM,
+// together with the MO's yield. Unless reveal is set, components only show
+// their variance percentages; their quantities and costs give the formula
+// away.
+func (s *varianceService) GetMoVariance(moId string, reveal bool) (map[string]interface{}, error) {
+	mo, err := s.moRepo.FindMoByID(moId)
+	if err != nil {
+		return nil, errors.New("manufacture order not found")
+	}
+	consumptions, err := s.productionRepo.GetConsumptionsByMoId(moId)
+	if err != nil {
+		return nil, err
+	}
+
+	components := make(map[string]*varianceTotals)
+	var order []string
+	names := make(map[string]entity.MoConsumption)
+	total := &varianceTotals{}
+	for _, consumption := range consumptions {
+		key := consumption.MaterialId + consumption.ProductId
+		if _, ok := components[key]; !ok {
+			components[key] = &varianceTotals{}
+			names[key] = consumption
+			order = append(order, key)
+		}
+		components[key].add(consumption)
+		total.add(consumption)
+	}
+
+	lines := make([]map[string]interface{}, 0, len(order))
+	for _, key := range order {
+		first := names[key]
+		line := components[key].fill(map[string]interface{}{
+			"id_material":    first.MaterialId,
+			"id_product":     first.ProductId,
+			"component_name": first.ComponentName,
+			"unit":           first.Unit,
+		}, true)
+		if !reveal {
+			redactVariance(line)
+		}
+		lines = append(lines, line)
+	}
+
+	planned, _ := strconv.ParseFloat(mo.Qtytoproduce, 64)
+	yieldPercent := 0.0
+	if planned > 0 {
+		yieldPercent = math.Round(mo.QtyProduced/planned*10000) / 100
+	}
+
+	return total.fill(map[string]interface{}{
+		"id_mo":         mo.MoId,
+		"id_product":    mo.ProductId,
+		"status":        mo.Status,
+		"qtytoproduce":  mo.Qtytoproduce,
+		"qty_produced":  mo.QtyProduced,
+		"yield_percent": yieldPercent,
+		"components":    lines,
+		"redacted":      !reveal,
+	}, false), nil
+}
+
+// varianceBucket is the period a consumption falls in for the report.
+func varianceBucket(at time.Time, interval string) string {
+	switch interval {
+	case "month":
+		return at.Format("2006-01")
+	case "week":
+		year, week := at.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	default:
+		return ""
+	}
+}
+
+// VarianceReport totals consumption variances over [from, to) per MO, per
+// produced product or per component material, optionally split by week or
+// month so trends show up. Unless reveal is set, only the variance
+// percentages are given; the quantities and costs give the formulas away.
+func (s *varianceService) VarianceReport(groupBy, interval string, from, to time.Time, reveal bool) ([]map[string]interface{}, error) {
+	if groupBy != "mo" && groupBy != "product" && groupBy != "material" {
+		return nil, errors.New("group_by must be mo, product or material")
+	}
+	if interval != "" && interval != "week" && interval != "month" {
+		return nil, errors.New("interval must be week or month")
+	}
+	consumptions, err := s.productionRepo.FindConsumptionsBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	mos := make(map[string]*entity.Mos)
+	productNames := make(map[string]string)
+	type varianceRow struct {
+		period, key, name, unit string
+		totals                  varianceTotals
+	}
+	rows := make(map[string]*varianceRow)
+	var order []string
+	for _, consumption := range consumptions {
+		key, name, unit := consumption.MoId, consumption.MoId, ""
+		switch groupBy {
+		case "material":
+			key, name, unit = consumption.MaterialId+consumption.ProductId, consumption.ComponentName, consumption.Unit
+		case "product":
+			mo, ok := mos[consumption.MoId]
+			if !ok {
+				mo, err = s.moRepo.FindMoByID(consumption.MoId)
+				if err != nil {
+					continue
+				}
+				mos[consumption.MoId] = mo
+			}
+			key = mo.ProductId
+			if _, ok := productNames[key]; !ok {
+				productNames[key] = key
+				if product, err := s.productRepo.FindProductByID(key); err == nil {
+					productNames[key] = product.Productname
+				}
+			}
+			name = productNames[key]
+		}
+
+		period := varianceBucket(consumption.CreatedAt, interval)
+		rowKey := period + "|" + key
+		row, ok := rows[rowKey]
+		if !ok {
+			row = &varianceRow{period: period, key: key, name: name, unit: unit}
+			rows[rowKey] = row
+			order = append(order, rowKey)
+		}
+		row.totals.add(consumption)
+	}
+
+	report := make([]map[string]interface{}, 0, len(order))
+	for _, rowKey := range order {
+		row := rows[rowKey]
+		entry := map[string]interface{}{
+			"id":   row.key,
+			"name": row.name,
+		}
+		if interval != "" {
+			entry["period"] = row.period
+		}
+		if groupBy == "material" {
+			entry["unit"] = row.unit
+		}
+		report = append(report, row.totals.fill(entry, groupBy == "material"))
+	}
+	sort.SliceStable(report, func(i, j int) bool {
+		if interval != "" && report[i]["period"] != report[j]["period"] {
+			return report[i]["period"].(string) < report[j]["period"].(string)
+		}
+		return report[i]["cost_variance"].(float64) > report[j]["cost_variance"].(float64)
+	})
+	if !reveal {
+		for _, row := range report {
+			redactVariance(row)
+		}
+	}
+	return report, nil
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
@@ -19,15 +21,18 @@ type VendorService interface {
 	FindAllVendor(page int) ([]entity.Vendors, error)
 	CreateVendorPDF(vendor *entity.Vendors) ([]byte, error)
 	CreateAllVendorsPDF(vendors []*entity.Vendors) ([]byte, error)
+	SetPaymentTerms(vendorId string, termDays int, upfrontPercent float64) (*entity.Vendors, error)
 }
 
 type vendorService struct {
 	vendorRepository repository.VendorRepository
+	scorecards       ScorecardService
 }
 
-func NewVendorService(vendorRepository repository.VendorRepository) *vendorService {
+func NewVendorService(vendorRepository repository.VendorRepository, scorecards ScorecardService) *vendorService {
 	return &vendorService{
 		vendorRepository: vendorRepository,
+		scorecards:       scorecards,
 	}
 }
 
@@ -100,6 +105,25 @@ func (s *vendorService) UpdateVendor(vendor *entity.Vendors) (*entity.Vendors, e
 	return updatedVendor, nil
 }
 
+// SetPaymentTerms sets when the vendor's bills fall due.
+func (s *vendorService) SetPaymentTerms(vendorId string, termDays int, upfrontPercent float64) (*entity.Vendors, error) {
+	if termDays < 0 {
+		return nil, errors.New("payment term days cannot be negative")
+	}
+	if upfrontPercent < 0 || upfrontPercent > 100 {
+		return nil, errors.New("upfront percent must be between 0 and 100")
+	}
+
+	vendor, err := s.vendorRepository.FindVendorByID(vendorId)
+	if err != nil {
+		return nil, errors.New("vendor not found")
+	}
+	vendor.PaymentTermDays = termDays
+	vendor.UpfrontPercent = upfrontPercent
+	vendor.UpdatedAt = time.Now()
+	return s.vendorRepository.UpdatePaymentTerms(vendor)
+}
+
 func (s *vendorService) FindVendorByID(vendorId string) (*entity.Vendors, error) {
 	return s.vendorRepository.FindVendorByID(vendorId)
 }
@@ -180,9 +204,17 @@ func (s *vendorService) CreateVendorPDF(vendor *entity.Vendors) ([]byte, error)
 		pdf.Ln(4)
 	}
 
+	// Performance over the last twelve months
+	to := startOfDay(time.Now()).AddDate(0, 0, 1)
+	scorecard, err := s.scorecards.GetVendorScorecard(vendor.VendorId, to.AddDate(-1, 0, 0), to)
+	if err != nil {
+		return nil, err
+	}
+	addScorecardSection(pdf, scorecard)
+
 	// Save the PDF to a buffer
 	var buf bytes.Buffer
-	err := pdf.Output(&buf)
+	err = pdf.Output(&buf)
 	if err != nil {
 		return nil, err
 	}
@@ -266,3 +298,36 @@ func (s *vendorService) CreateAllVendorsPDF(vendors []*entity.Vendors) ([]byte,
 	// Return the PDF as a byte slice
 	return buf.Bytes(), nil
 }
+
+// formatRate prints a scorecard rate, or "n/a" when there was nothing to
+// judge it on.
+func formatRate(rate *float64, suffix string) string {
+	if rate == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f%s", *rate, suffix)
+}
+
+func addScorecardSection(pdf *gofpdf.Fpdf, card *VendorScorecard) {
+	pdf.Ln(4)
+	pdf.SetFont("Arial", "B", 14)
+	pdf.Cell(0, 10, fmt.Sprintf("Performance %s - %s", card.From.Format("2006-01-02"), card.To.AddDate(0, 0, -1).Format("2006-01-02")))
+	pdf.Ln(10)
+
+	rows := [][2]string{
+		{"Purchase orders:", fmt.Sprintf("%d", card.Orders)},
+		{"On-time delivery:", formatRate(card.OnTimeRate, "%") + fmt.Sprintf(" (%d of %d lines)", card.LinesOnTime, card.LinesJudged)},
+		{"Quantity fill rate:", formatRate(card.FillRate, "%")},
+		{"Price variance:", formatRate(card.PriceVariance, "%")},
+		{"QC rejection rate:", formatRate(card.RejectRate, "%")},
+		{"Avg. lead time:", formatRate(card.AvgLeadDays, " days")},
+		{"Overall score:", formatRate(card.Score, " / 100")},
+	}
+	for _, row := range rows {
+		pdf.SetFont("Arial", "B", 12)
+		pdf.Cell(45, 8, row[0])
+		pdf.SetFont("Arial", "", 12)
+		pdf.Cell(140, 8, row[1])
+		pdf.Ln(8)
+	}
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+type VesselService interface {
+	CreateVessel(vessel *entity.Vessel) (*entity.Vessel, error)
+	UpdateVessel(vessel *entity.Vessel) (*entity.Vessel, error)
+	DeleteVessel(vesselId string) (bool, error)
+	GetVesselByID(vesselId string) (*entity.Vessel, error)
+	FindAllVessels() ([]entity.Vessel, error)
+	GetTankCalendar(from, to time.Time) (map[string]interface{}, error)
+	FindNextFreeSlot(litres float64, days int, from time.Time) (*TankSlot, error)
+}
+
+// TankSlot is a period in which a tank is free for a batch.
+type TankSlot struct {
+	VesselId   string    `json:"id_vessel"`
+	VesselName string    `json:"vessel_name"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+}
+
+// TankUnavailableError is returned when no tank can take a batch at the
+// requested date. Suggestion holds the earliest free slot, if any tank is big
+// enough at all.
+type TankUnavailableError struct {
+	Litres     float64
+	Days       int
+	Start      time.Time
+	Suggestion *TankSlot
+}
+
+func (e *TankUnavailableError) Error() string {
+	message := fmt.Sprintf("no tank available for %g litres over %d days from %s", e.Litres, e.Days, e.Start.Format("2006-01-02"))
+	if e.Suggestion == nil {
+		return message + "; no tank is large enough"
+	}
+	return fmt.Sprintf("%s; next free slot is %s (%s) from %s to %s", message, e.Suggestion.VesselId, e.Suggestion.VesselName,
+		e.Suggestion.Start.Format("2006-01-02"), e.Suggestion.End.Format("2006-01-02"))
+}
+
+// tankScheduler holds the booking rules shared by the schedule, vessel and
+// MO services: a tank takes one batch at a time and never more than its
+// capacity.
+type tankScheduler struct {
+	vesselRepo    repository.VesselRepository
+	schedulesRepo repository.SchedulesRepository
+}
+
+func newTankScheduler(vesselRepo repository.VesselRepository, schedulesRepo repository.SchedulesRepository) *tankScheduler {
+	return &tankScheduler{vesselRepo: vesselRepo, schedulesRepo: schedulesRepo}
+}
+
+// bookingPeriod reads the occupied period of a booking. Bookings without an
+// end date occupy their start day.
+func bookingPeriod(booking entity.Schedules) (time.Time, time.Time, bool) {
+	start, ok := parseDocumentDate(booking.Date_schedules)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	end := start.AddDate(0, 0, 1)
+	if booking.DateEnd != nil {
+		end = *booking.DateEnd
+	}
+	return start, end, true
+}
+
+// checkBooking returns an error describing the first reason the tank cannot
+// hold the batch in [start, end). The booking with id skip is ignored so a
+// booking can be moved without clashing with itself.
+func (t *tankScheduler) checkBooking(vesselId string, start, end time.Time, litres float64, skip uuid.UUID) error {
+	vessel, err := t.vesselRepo.FindVesselByID(vesselId)
+	if err != nil {
+		return err
+	}
+	if vessel == nil {
+		return fmt.Errorf("tank with id %s not found", vesselId)
+	}
+	if !end.After(start) {
+		return errors.New("booking must end after it starts")
+	}
+	if litres > vessel.CapacityLitres {
+		return fmt.Errorf("capacity conflict: %g litres exceeds the %g litres of tank %s", litres, vessel.CapacityLitres, vessel.Name)
+	}
+
+	bookings, err := t.schedulesRepo.FindTankBookings(vesselId, start, end)
+	if err != nil {
+		return err
+	}
+	for _, booking := range bookings {
+		if booking.SchedulesId == skip {
+			continue
+		}
+		bookedFrom, bookedTo, _ := bookingPeriod(booking)
+		return fmt.Errorf("tank %s is already booked for %q from %s to %s", vessel.Name, booking.Title,
+			bookedFrom.Format("2006-01-02"), bookedTo.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// nextFreeSlot finds the earliest period of the given length, starting on or
+// after from, in a tank that can hold the batch. Among tanks free on the same
+// day the smallest is preferred. It returns nil when no tank is big enough.
+func (t *tankScheduler) nextFreeSlot(litres float64, days int, from time.Time) (*TankSlot, error) {
+	vessels, err := t.vesselRepo.FindAllVessels()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(vessels, func(i, j int) bool { return vessels[i].CapacityLitres < vessels[j].CapacityLitres })
+
+	var best *TankSlot
+	for _, vessel := range vessels {
+		if litres > vessel.CapacityLitres {
+			continue
+		}
+		bookings, err := t.schedulesRepo.FindTankBookings(vessel.VesselId, from, from.AddDate(5, 0, 0))
+		if err != nil {
+			return nil, err
+		}
+
+		start := from
+		for _, booking := range bookings {
+			bookedFrom, bookedTo, ok := bookingPeriod(booking)
+			if !ok {
+				continue
+			}
+			if bookedFrom.Before(start.AddDate(0, 0, days)) && bookedTo.After(start) {
+				start = bookedTo
+			}
+		}
+		if best == nil || start.Before(best.Start) {
+			best = &TankSlot{VesselId: vessel.VesselId, VesselName: vessel.Name, Start: start, End: start.AddDate(0, 0, days)}
+		}
+	}
+	return best, nil
+}
+
+type vesselService struct {
+	vesselRepo    repository.VesselRepository
+	schedulesRepo repository.SchedulesRepository
+	tanks         *tankScheduler
+}
+
+func NewVesselService(vesselRepo repository.VesselRepository, schedulesRepo repository.SchedulesRepository) *vesselService {
+	return &vesselService{
+		vesselRepo:    vesselRepo,
+		schedulesRepo: schedulesRepo,
+		tanks:         newTankScheduler(vesselRepo, schedulesRepo),
+	}
+}
+
+func validateVessel(vessel *entity.Vessel) error {
+	if vessel.Name == "" {
+		return errors.New("tank name cannot be empty")
+	}
+	if vessel.CapacityLitres <= 0 {
+		return errors.New("tank capacity must be greater than zero")
+	}
+	return nil
+}
+
+func (s *vesselService) CreateVessel(vessel *entity.Vessel) (*entity.Vessel, error) {
+	if err := validateVessel(vessel); err != nil {
+		return nil, err
+	}
+
+	lastId, err := s.vesselRepo.GetLastVesselId()
+	if err != nil {
+		return nil, err
+	}
+	return s.vesselRepo.CreateVessel(entity.NewVessel(lastId, vessel.Name, vessel.CapacityLitres, vessel.Description))
+}
+
+func (s *vesselService) UpdateVessel(vessel *entity.Vessel) (*entity.Vessel, error) {
+	existing, err := s.vesselRepo.FindVesselByID(vessel.VesselId)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, errors.New("tank not found")
+	}
+	if err := validateVessel(vessel); err != nil {
+		return nil, err
+	}
+
+	existing.Name = vessel.Name
+	existing.CapacityLitres = vessel.CapacityLitres
+	existing.Description = vessel.Description
+	existing.UpdatedAt = time.Now()
+	return s.vesselRepo.UpdateVessel(existing)
+}
+
+func (s *vesselService) DeleteVessel(vesselId string) (bool, error) {
+	vessel, err := s.vesselRepo.FindVesselByID(vesselId)
+	if err != nil {
+		return false, err
+	}
+	if vessel == nil {
+		return false, errors.New("tank not found")
+	}
+	inUse, err := s.vesselRepo.CheckVesselInUse(vesselId)
+	if err != nil {
+		return false, err
+	}
+	if inUse {
+		return false, errors.New("tank still has bookings on the schedule")
+	}
+	return s.vesselRepo.DeleteVessel(vessel)
+}
+
+func (s *vesselService) GetVesselByID(vesselId string) (*entity.Vessel, error) {
+	vessel, err := s.vesselRepo.FindVesselByID(vesselId)
+	if err != nil {
+		return nil, err
+	}
+	if vessel == nil {
+		return nil, errors.New("tank not found")
+	}
+	return vessel, nil
+}
+
+func (s *vesselService) FindAllVessels() ([]entity.Vessel, error) {
+	return s.vesselRepo.FindAllVessels()
+}
+
+func (s *vesselService) FindNextFreeSlot(litres float64, days int, from time.Time) (*TankSlot, error) {
+	if days <= 0 {
+		return nil, errors.New("days must be greater than zero")
+	}
+	slot, err := s.tanks.nextFreeSlot(litres, days, startOfDay(from))
+	if err != nil {
+		return nil, err
+	}
+	if slot == nil {
+		return nil, fmt.Errorf("no tank can hold %g litres", litres)
+	}
+	return slot, nil
+}
+
+// GetTankCalendar lists the bookings of every tank in [from, to) with its
+// occupancy, and flags overlapping bookings and batches above capacity.
+func (s *vesselService) GetTankCalendar(from, to time.Time) (map[string]interface{}, error) {
+	if !to.After(from) {
+		return nil, errors.New("the end of the period must be after its start")
+	}
+	vessels, err := s.vesselRepo.FindAllVessels()
+	if err != nil {
+		return nil, err
+	}
+	windowDays := to.Sub(from).Hours() / 24
+
+	tanks := make([]map[string]interface{}, 0, len(vessels))
+	conflictCount := 0
+	for _, vessel := range vessels {
+		bookings, err := s.schedulesRepo.FindTankBookings(vessel.VesselId, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make([]map[string]interface{}, 0, len(bookings))
+		conflicts := make([]map[string]interface{}, 0)
+		occupiedDays := 0.0
+		var busyUntil time.Time
+		var busyWith *entity.Schedules
+		for i := range bookings {
+			booking := bookings[i]
+			start, end, ok := bookingPeriod(booking)
+			if !ok {
+				continue
+			}
+
+			fillPercent := 0.0
+			if vessel.CapacityLitres > 0 {
+				fillPercent = math.Round(booking.VolumeLitres/vessel.CapacityLitres*10000) / 100
+			}
+			entries = append(entries, map[string]interface{}{
+				"id_schedules":  booking.SchedulesId,
+				"title":         booking.Title,
+				"id_mo":         booking.MoId,
+				"start":         start,
+				"end":           end,
+				"volume_litres": booking.VolumeLitres,
+				"fill_percent":  fillPercent,
+			})
+
+			if booking.VolumeLitres > vessel.CapacityLitres {
+				conflicts = append(conflicts, map[string]interface{}{
+					"type":         "capacity",
+					"id_schedules": booking.SchedulesId,
+					"message":      fmt.Sprintf("%g litres exceeds the tank capacity of %g litres", booking.VolumeLitres, vessel.CapacityLitres),
+				})
+			}
+			if busyWith != nil && start.Before(busyUntil) {
+				conflicts = append(conflicts, map[string]interface{}{
+					"type":         "overbooking",
+					"id_schedules": []uuid.UUID{busyWith.SchedulesId, booking.SchedulesId},
+					"message":      fmt.Sprintf("%q overlaps %q", booking.Title, busyWith.Title),
+				})
+			}
+
+			// Occupancy counts overlapping bookings once.
+			clippedStart, clippedEnd := start, end
+			if clippedStart.Before(from) {
+				clippedStart = from
+			}
+			if busyWith != nil && clippedStart.Before(busyUntil) {
+				clippedStart = busyUntil
+			}
+			if clippedEnd.After(to) {
+				clippedEnd = to
+			}
+			if clippedEnd.After(clippedStart) {
+				occupiedDays += clippedEnd.Sub(clippedStart).Hours() / 24
+			}
+			if end.After(busyUntil) {
+				busyUntil = end
+				busyWith = &bookings[i]
+			}
+		}
+		conflictCount += len(conflicts)
+
+		tanks = append(tanks, map[string]interface{}{
+			"id_vessel":           vessel.VesselId,
+			"name":                vessel.Name,
+			"capacity_litres":     vessel.CapacityLitres,
+			"bookings":            entries,
+			"occupied_days":       math.Round(occupiedDays*100) / 100,
+			"utilisation_percent": math.Round(occupiedDays/windowDays*10000) / 100,
+			"conflicts":           conflicts,
+		})
+	}
+
+	return map[string]interface{}{
+		"from":      from,
+		"to":        to,
+		"tanks":     tanks,
+		"conflicts": conflictCount,
+	}, nil
+}
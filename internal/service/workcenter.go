@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
+	"github.com/Kevinmajesta/parfume-erp-backend/internal/repository"
+)
+
+type WorkCenterService interface {
+	CreateWorkCenter(workCenter *entity.WorkCenter) (*entity.WorkCenter, error)
+	UpdateWorkCenter(workCenter *entity.WorkCenter) (*entity.WorkCenter, error)
+	DeleteWorkCenter(workCenterId string) (bool, error)
+	GetWorkCenterByID(workCenterId string) (*entity.WorkCenter, error)
+	FindAllWorkCenters() ([]entity.WorkCenter, error)
+	SetRouting(bomId string, operations []entity.RoutingOperation) ([]entity.RoutingOperation, error)
+	GetRouting(bomId string) ([]entity.RoutingOperation, error)
+}
+
+type workCenterService struct {
+	workCenterRepo repository.WorkCenterRepository
+	routingRepo    repository.RoutingRepository
+	bomRepo        repository.BOMRepository
+}
+
+func NewWorkCenterService(workCenterRepo repository.WorkCenterRepository, routingRepo repository.RoutingRepository,
+	bomRepo repository.BOMRepository) *workCenterService {
+	return &workCenterService{
+		workCenterRepo: workCenterRepo,
+		routingRepo:    routingRepo,
+		bomRepo:        bomRepo,
+	}
+}
+
+func validateWorkCenter(workCenter *entity.WorkCenter) error {
+	if workCenter.Name == "" {
+		return errors.New("Work center name cannot be empty")
+	}
+	if workCenter.Capacity < 0 || workCenter.HourlyCost < 0 {
+		return errors.New("capacity and hourly cost cannot be negative")
+	}
+	return nil
+}
+
+func (s *workCenterService) CreateWorkCenter(workCenter *entity.WorkCenter) (*entity.WorkCenter, error) {
+	if err := validateWorkCenter(workCenter); err != nil {
+		return nil, err
+	}
+	if workCenter.Capacity == 0 {
+		workCenter.Capacity = 1
+	}
+
+	lastId, err := s.workCenterRepo.GetLastWorkCenterId()
+	if err != nil {
+		return nil, err
+	}
+	newWorkCenter := entity.NewWorkCenter(lastId, workCenter.Name, workCenter.Capacity, workCenter.HourlyCost, workCenter.Description)
+	return s.workCenterRepo.CreateWorkCenter(newWorkCenter)
+}
+
+func (s *workCenterService) UpdateWorkCenter(workCenter *entity.WorkCenter) (*entity.WorkCenter, error) {
+	existing, err := s.workCenterRepo.FindWorkCenterByID(workCenter.WorkCenterId)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, errors.New("work center not found")
+	}
+	if err := validateWorkCenter(workCenter); err != nil {
+		return nil, err
+	}
+
+	existing.Name = workCenter.Name
+	if workCenter.Capacity > 0 {
+		existing.Capacity = workCenter.Capacity
+	}
+	existing.HourlyCost = workCenter.HourlyCost
+	existing.Description = workCenter.Description
+	existing.UpdatedAt = time.Now()
+	return s.workCenterRepo.UpdateWorkCenter(existing)
+}
+
+func (s *workCenterService) DeleteWorkCenter(workCenterId string) (bool, error) {
+	workCenter, err := s.workCenterRepo.FindWorkCenterByID(workCenterId)
+	if err != nil {
+		return false, err
+	}
+	if workCenter == nil {
+		return false, errors.New("work center not found")
+	}
+	inUse, err := s.workCenterRepo.CheckWorkCenterInUse(workCenterId)
+	if err != nil {
+		return false, err
+	}
+	if inUse {
+		return false, errors.New("work center is still used by a routing")
+	}
+	return s.workCenterRepo.DeleteWorkCenter(workCenter)
+}
+
+func (s *workCenterService) GetWorkCenterByID(workCenterId string) (*entity.WorkCenter, error) {
+	workCenter, err := s.workCenterRepo.FindWorkCenterByID(workCenterId)
+	if err != nil {
+		return nil, err
+	}
+	if workCenter == nil {
+		return nil, errors.New("work center not found")
+	}
+	return workCenter, nil
+}
+
+func (s *workCenterService) FindAllWorkCenters() ([]entity.WorkCenter, error) {
+	return s.workCenterRepo.FindAllWorkCenters()
+}
+
+// SetRouting replaces the routing of a BOM. Work orders already generated for
+// MOs keep their own copy of the operations.
+func (s *workCenterService) SetRouting(bomId string, operations []entity.RoutingOperation) ([]entity.RoutingOperation, error) {
+	bom, err := s.bomRepo.FindBOMByID(bomId)
+	if err != nil {
+		return nil, err
+	}
+	if bom == nil {
+		return nil, errors.New("BOM not found")
+	}
+
+	sequences := make(map[int]bool)
+	for _, operation := range operations {
+		if operation.Name == "" {
+			return nil, errors.New("operation name cannot be empty")
+		}
+		if sequences[operation.Sequence] {
+			return nil, fmt.Errorf("sequence %d is used more than once", operation.Sequence)
+		}
+		sequences[operation.Sequence] = true
+		if operation.DurationMinutes < 0 || operation.MinutesPerUnit < 0 {
+			return nil, errors.New("operation durations cannot be negative")
+		}
+		workCenter, err := s.workCenterRepo.FindWorkCenterByID(operation.WorkCenterId)
+		if err != nil {
+			return nil, err
+		}
+		if workCenter == nil {
+			return nil, fmt.Errorf("Work center with id %s does not exist", operation.WorkCenterId)
+		}
+	}
+
+	if err := s.routingRepo.DeleteOperationsByBomId(bomId); err != nil {
+		return nil, err
+	}
+	for _, operation := range operations {
+		lastId, err := s.routingRepo.GetLastOperationId()
+		if err != nil {
+			return nil, err
+		}
+		newOperation := entity.NewRoutingOperation(lastId, bomId, operation.Sequence, operation.Name,
+			operation.WorkCenterId, operation.DurationMinutes, operation.MinutesPerUnit)
+		if _, err := s.routingRepo.CreateOperation(newOperation); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.routingRepo.GetOperationsByBomId(bomId)
+}
+
+func (s *workCenterService) GetRouting(bomId string) ([]entity.RoutingOperation, error) {
+	return s.routingRepo.GetOperationsByBomId(bomId)
+}
@@ -2,6 +2,7 @@ package email
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/Kevinmajesta/parfume-erp-backend/internal/entity"
 
@@ -62,7 +63,7 @@ func (e *EmailSender) SendTransactionInfo(to, Transactions_id, Cart_id, User_id,
 	return e.SendEmail([]string{to}, subject, body)
 }
 
-func (e *EmailSender) SendRfqEmail(to, rfqId, vendorId, orderDate, status string, products []entity.RfqsProduct) error {
+func (e *EmailSender) SendRfqEmail(to, rfqId, vendorId, orderDate, status string, products []entity.RfqsProduct, totals entity.DocumentTotals) error {
 	subject := fmt.Sprintf("RFQ Confirmation | RFQ ID: %s", rfqId)
 
 	// HTML body untuk email
@@ -136,10 +137,10 @@ func (e *EmailSender) SendRfqEmail(to, rfqId, vendorId, orderDate, status string
                 </tr>
             </table>
 
-            <h3>Products:</h3>
+            <h3>Items:</h3>
             <table border="1" cellpadding="5" cellspacing="0" style="border-collapse: collapse; width: 100%; margin-bottom: 20px; border: 1px solid #ddd;">
         <tr>
-            <th style="background-color: #f2f2f2; text-align: left; padding: 8px;">Product Name</th>
+            <th style="background-color: #f2f2f2; text-align: left; padding: 8px;">Item</th>
             <th style="background-color: #f2f2f2; text-align: left; padding: 8px;">Quantity</th>
             <th style="background-color: #f2f2f2; text-align: left; padding: 8px;">Unit Price</th>
             <th style="background-color: #f2f2f2; text-align: left; padding: 8px;">Subtotal</th>
@@ -155,10 +156,11 @@ func (e *EmailSender) SendRfqEmail(to, rfqId, vendorId, orderDate, status string
 					<td style="padding: 8px; text-align: right;">%s</td> <!-- Format sebagai string -->
 				</tr>`,
 			product.ProductName,
-			fmt.Sprintf("%s", product.Quantity),  // Konversi Quantity ke string
+			strings.TrimSpace(product.Quantity+" "+product.Unit),
 			fmt.Sprintf("%s", product.UnitPrice), // Konversi UnitPrice ke string
 			fmt.Sprintf("%s", product.Subtotal))  // Konversi Subtotal ke string
 	}
+	body += totalRows(totals)
 
 	// Menutup tabel dan body email
 	body += fmt.Sprintf(`
@@ -178,7 +180,7 @@ func (e *EmailSender) SendRfqEmail(to, rfqId, vendorId, orderDate, status string
 
 }
 
-func (e *EmailSender) SendQuoEmail(to, quotationId, costumerId, orderDate, status string, products []entity.QuotationsProduct) error {
+func (e *EmailSender) SendQuoEmail(to, quotationId, costumerId, orderDate, status string, products []entity.QuotationsProduct, totals entity.DocumentTotals) error {
 	subject := fmt.Sprintf("Quotation Confirmation | Quotation ID: %s", quotationId)
 
 	// HTML body untuk email
@@ -275,6 +277,7 @@ func (e *EmailSender) SendQuoEmail(to, quotationId, costumerId, orderDate, statu
 			fmt.Sprintf("%s", product.UnitPrice), // Konversi UnitPrice ke string
 			fmt.Sprintf("%s", product.Subtotal))  // Konversi Subtotal ke string
 	}
+	body += totalRows(totals)
 
 	// Menutup tabel dan body email
 	body += fmt.Sprintf(`
@@ -293,3 +296,40 @@ func (e *EmailSender) SendQuoEmail(to, quotationId, costumerId, orderDate, statu
 	return e.SendEmail([]string{to}, subject, body)
 
 }
+
+// totalRows closes a line table with the untaxed, tax and grand totals of the
+// document, under the subtotal column.
+func totalRows(totals entity.DocumentTotals) string {
+	rows := ""
+	for _, row := range []struct {
+		label  string
+		amount float64
+	}{
+		{"Untaxed", totals.AmountUntaxed},
+		{"Tax", totals.AmountTax},
+		{"<strong>Total</strong>", totals.AmountTotal},
+	} {
+		rows += fmt.Sprintf(`
+				<tr>
+					<td colspan="3" style="padding: 8px; text-align: right;">%s</td>
+					<td style="padding: 8px; text-align: right;">Rp %.2f</td>
+				</tr>`, row.label, row.amount)
+	}
+	return rows
+}
+
+func (e *EmailSender) SendPoApprovalEmail(to, approverName, approvalId, rfqId, vendorId, ruleName string, amount float64) error {
+	subject := fmt.Sprintf("Purchase Order Approval | RFQ ID: %s", rfqId)
+	body := fmt.Sprintf(`
+    <html>
+    <body>
+        <p>Dear %s,</p>
+        <p>RFQ <strong>%s</strong> of vendor %s needs your approval under the rule "%s" before it can become a purchase order.</p>
+        <p>Amount: <strong>%.2f</strong></p>
+        <p>Approval ID: <strong>%s</strong></p>
+        <p>Best regards,<br>Depublic Team</p>
+    </body>
+    </html>
+`, approverName, rfqId, vendorId, ruleName, amount, approvalId)
+	return e.SendEmail([]string{to}, subject, body)
+}
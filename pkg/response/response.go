@@ -35,6 +35,16 @@ func ErrorResponse(code int, message string) Response {
 	}
 }
 
+func ErrorResponseWithData(code int, message string, data interface{}) Response {
+	return Response{
+		Meta: Meta{
+			Code:    code,
+			Message: message,
+		},
+		Data: data,
+	}
+}
+
 func SuccessResponseBom(code int, message string, data interface{}) BOMResponse {
 	return BOMResponse{
 		Meta: Meta{
@@ -0,0 +1,70 @@
+package token
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// sessionLifetime is how long a login stays valid.
+const sessionLifetime = 24 * time.Hour
+
+type JwtCustomClaims struct {
+	UserId string `json:"id_user"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	jwt.StandardClaims
+}
+
+type TokenUseCase interface {
+	GenerateAccessToken(claims JwtCustomClaims) (string, time.Time, error)
+	ParseAccessToken(accessToken string) (*JwtCustomClaims, error)
+}
+
+type tokenUseCase struct {
+	secretKey string
+}
+
+func NewTokenUseCase(secretKey string) *tokenUseCase {
+	return &tokenUseCase{secretKey: secretKey}
+}
+
+// GenerateAccessToken signs the claims into a token that expires after one
+// session lifetime, returning the token and when it expires.
+func (t *tokenUseCase) GenerateAccessToken(claims JwtCustomClaims) (string, time.Time, error) {
+	if t.secretKey == "" {
+		return "", time.Time{}, errors.New("no JWT secret key is configured")
+	}
+	expiresAt := time.Now().Add(sessionLifetime)
+	claims.IssuedAt = time.Now().Unix()
+	claims.ExpiresAt = expiresAt.Unix()
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(t.secretKey))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseAccessToken checks the token's signature and expiry and returns its
+// claims.
+func (t *tokenUseCase) ParseAccessToken(accessToken string) (*JwtCustomClaims, error) {
+	if t.secretKey == "" {
+		return nil, errors.New("no JWT secret key is configured")
+	}
+	claims := new(JwtCustomClaims)
+	parsed, err := jwt.ParseWithClaims(accessToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(t.secretKey), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !parsed.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}